@@ -28,6 +28,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/Sirupsen/logrus"
@@ -54,32 +55,61 @@ const (
 var (
 	ErrPoolNotFound = errors.New("plugin pool not found")
 	ErrBadKey       = errors.New("bad key")
+	// ErrAllMembersBusy - error message when every plugin in a pool is at its
+	// concurrent call limit, so the call should be retried later
+	ErrAllMembersBusy = errors.New("all plugins in the pool are at their concurrent call limit")
+	// ErrHealthCheckTimeout - error message when a plugin does not respond
+	// to a health check within DefaultHealthCheckTimeout
+	ErrHealthCheckTimeout = errors.New("health check timed out")
 )
 
 // availablePlugin represents a plugin which is
 // running and available to respond to requests
 type availablePlugin struct {
-	meta               plugin.PluginMeta
-	key                string
-	pluginType         plugin.PluginType
-	client             client.PluginClient
-	name               string
-	version            int
-	id                 uint32
-	hitCount           int
-	lastHitTime        time.Time
-	emitter            gomit.Emitter
-	failedHealthChecks int
+	meta        plugin.PluginMeta
+	key         string
+	pluginType  plugin.PluginType
+	client      client.PluginClient
+	name        string
+	version     int
+	id          uint32
+	hitCount    int
+	lastHitTime time.Time
+	startTime   time.Time
+	emitter     gomit.Emitter
+	// failedHealthChecks is accessed atomically as health checks run
+	// concurrently with reads of plugin health
+	failedHealthChecks int32
 	healthChan         chan error
 	ePlugin            executablePlugin
 	exec               string
 	execPath           string
 	fromPackage        bool
+	// calls limits the number of concurrent calls to the plugin when it
+	// declares MaxConcurrentCalls
+	calls chan struct{}
+	// active counts the calls currently reserved on the plugin
+	active int32
+	// crashed is set when the plugin is found dead so it is reported once
+	crashed int32
+	// statsMutex guards hitCount and lastHitTime, which are updated by
+	// concurrent calls to the plugin
+	statsMutex sync.RWMutex
+}
+
+// callLimit returns the max number of concurrent calls to a running instance
+// of the plugin, zero meaning no limit.  Only plugins declaring
+// MaxConcurrentCalls are limited.
+func callLimit(meta plugin.PluginMeta) int {
+	if meta.MaxConcurrentCalls > 0 {
+		return meta.MaxConcurrentCalls
+	}
+	return 0
 }
 
 // newAvailablePlugin returns an availablePlugin with information from a
-// plugin.Response
-func newAvailablePlugin(resp *plugin.Response, emitter gomit.Emitter, ep executablePlugin) (*availablePlugin, error) {
+// plugin.Response whose client calls the plugin with the given timeouts
+func newAvailablePlugin(resp *plugin.Response, emitter gomit.Emitter, ep executablePlugin, timeouts client.Timeouts) (*availablePlugin, error) {
 	if resp.Type != plugin.CollectorPluginType && resp.Type != plugin.ProcessorPluginType && resp.Type != plugin.PublisherPluginType {
 		return nil, strategy.ErrBadType
 	}
@@ -91,9 +121,13 @@ func newAvailablePlugin(resp *plugin.Response, emitter gomit.Emitter, ep executa
 		emitter:     emitter,
 		healthChan:  make(chan error, 1),
 		lastHitTime: time.Now(),
+		startTime:   time.Now(),
 		ePlugin:     ep,
 	}
-	ap.key = fmt.Sprintf("%s:%s:%d", ap.pluginType.String(), ap.name, ap.version)
+	if limit := callLimit(resp.Meta); limit > 0 {
+		ap.calls = make(chan struct{}, limit)
+	}
+	ap.key = core.PluginKey(core.PluginType(ap.pluginType), ap.name, ap.version)
 
 	listenURL := fmt.Sprintf("http://%v/rpc", resp.ListenAddress)
 	// Create RPC Client
@@ -101,19 +135,19 @@ func newAvailablePlugin(resp *plugin.Response, emitter gomit.Emitter, ep executa
 	case plugin.CollectorPluginType:
 		switch resp.Meta.RPCType {
 		case plugin.JSONRPC:
-			c, e := client.NewCollectorHttpJSONRPCClient(listenURL, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewCollectorHttpJSONRPCClient(listenURL, timeouts, resp.PublicKey, !resp.Meta.Unsecure)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		case plugin.NativeRPC:
-			c, e := client.NewCollectorNativeClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewCollectorNativeClient(resp.ListenAddress, timeouts, resp.PublicKey, !resp.Meta.Unsecure)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		case plugin.GRPC:
-			c, e := client.NewCollectorGrpcClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewCollectorGrpcClient(resp.ListenAddress, timeouts, resp.PublicKey, !resp.Meta.Unsecure)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
@@ -123,19 +157,19 @@ func newAvailablePlugin(resp *plugin.Response, emitter gomit.Emitter, ep executa
 	case plugin.PublisherPluginType:
 		switch resp.Meta.RPCType {
 		case plugin.JSONRPC:
-			c, e := client.NewPublisherHttpJSONRPCClient(listenURL, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewPublisherHttpJSONRPCClient(listenURL, timeouts, resp.PublicKey, !resp.Meta.Unsecure)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		case plugin.NativeRPC:
-			c, e := client.NewPublisherNativeClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewPublisherNativeClient(resp.ListenAddress, timeouts, resp.PublicKey, !resp.Meta.Unsecure)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		case plugin.GRPC:
-			c, e := client.NewPublisherGrpcClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewPublisherGrpcClient(resp.ListenAddress, timeouts, resp.PublicKey, !resp.Meta.Unsecure)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
@@ -144,19 +178,19 @@ func newAvailablePlugin(resp *plugin.Response, emitter gomit.Emitter, ep executa
 	case plugin.ProcessorPluginType:
 		switch resp.Meta.RPCType {
 		case plugin.JSONRPC:
-			c, e := client.NewProcessorHttpJSONRPCClient(listenURL, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewProcessorHttpJSONRPCClient(listenURL, timeouts, resp.PublicKey, !resp.Meta.Unsecure)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		case plugin.NativeRPC:
-			c, e := client.NewProcessorNativeClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewProcessorNativeClient(resp.ListenAddress, timeouts, resp.PublicKey, !resp.Meta.Unsecure)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
 			ap.client = c
 		case plugin.GRPC:
-			c, e := client.NewProcessorGrpcClient(resp.ListenAddress, DefaultClientTimeout, resp.PublicKey, !resp.Meta.Unsecure)
+			c, e := client.NewProcessorGrpcClient(resp.ListenAddress, timeouts, resp.PublicKey, !resp.Meta.Unsecure)
 			if e != nil {
 				return nil, errors.New("error while creating client connection: " + e.Error())
 			}
@@ -214,13 +248,79 @@ func (a *availablePlugin) Version() int {
 }
 
 func (a *availablePlugin) HitCount() int {
+	a.statsMutex.RLock()
+	defer a.statsMutex.RUnlock()
 	return a.hitCount
 }
 
 func (a *availablePlugin) LastHit() time.Time {
+	a.statsMutex.RLock()
+	defer a.statsMutex.RUnlock()
 	return a.lastHitTime
 }
 
+// StartTime returns when the plugin was started.
+func (a *availablePlugin) StartTime() time.Time {
+	return a.startTime
+}
+
+// hit records a call to the plugin.
+func (a *availablePlugin) hit() {
+	a.statsMutex.Lock()
+	defer a.statsMutex.Unlock()
+	a.hitCount++
+	a.lastHitTime = time.Now()
+}
+
+// ActiveCalls returns the number of calls the plugin is serving.
+func (a *availablePlugin) ActiveCalls() int {
+	return int(atomic.LoadInt32(&a.active))
+}
+
+// tryAcquire reserves a call to the plugin if it is below its concurrent
+// call limit.
+func (a *availablePlugin) tryAcquire() bool {
+	if a.calls == nil {
+		atomic.AddInt32(&a.active, 1)
+		return true
+	}
+	select {
+	case a.calls <- struct{}{}:
+		atomic.AddInt32(&a.active, 1)
+		return true
+	default:
+		return false
+	}
+}
+
+// acquire reserves a call to the plugin, waiting until it is below its
+// concurrent call limit.
+func (a *availablePlugin) acquire() {
+	if a.calls != nil {
+		a.calls <- struct{}{}
+	}
+	atomic.AddInt32(&a.active, 1)
+}
+
+// release frees a call reserved with acquire or tryAcquire.
+func (a *availablePlugin) release() {
+	atomic.AddInt32(&a.active, -1)
+	if a.calls != nil {
+		<-a.calls
+	}
+}
+
+// atLimit returns whether the plugin is serving as many calls as its
+// concurrent call limit allows.  Plugins without a limit are never at it.
+func (a *availablePlugin) atLimit() bool {
+	return a.calls != nil && len(a.calls) >= cap(a.calls)
+}
+
+// busy returns whether the plugin is serving a call.
+func (a *availablePlugin) busy() bool {
+	return atomic.LoadInt32(&a.active) > 0
+}
+
 // Stop halts a running availablePlugin
 func (a *availablePlugin) Stop(r string) error {
 	log.WithFields(log.Fields{
@@ -250,6 +350,11 @@ func (a *availablePlugin) Kill(r string) error {
 	return a.ePlugin.Kill()
 }
 
+// healthCheckFailures returns the number of consecutive failed health checks.
+func (a *availablePlugin) healthCheckFailures() int {
+	return int(atomic.LoadInt32(&a.failedHealthChecks))
+}
+
 // CheckHealth checks the health of a plugin and updates
 // a.failedHealthChecks
 func (a *availablePlugin) CheckHealth() {
@@ -259,7 +364,7 @@ func (a *availablePlugin) CheckHealth() {
 	select {
 	case err := <-a.healthChan:
 		if err == nil {
-			if a.failedHealthChecks > 0 {
+			if a.healthCheckFailures() > 0 {
 				// only log on first ok health check
 				log.WithFields(log.Fields{
 					"_module": "control-aplugin",
@@ -267,25 +372,25 @@ func (a *availablePlugin) CheckHealth() {
 					"aplugin": a,
 				}).Debug("health is ok")
 			}
-			a.failedHealthChecks = 0
+			atomic.StoreInt32(&a.failedHealthChecks, 0)
 		} else {
-			a.healthCheckFailed()
+			a.healthCheckFailed(err)
 		}
 	case <-time.After(DefaultHealthCheckTimeout):
-		a.healthCheckFailed()
+		a.healthCheckFailed(ErrHealthCheckTimeout)
 	}
 }
 
 // healthCheckFailed increments a.failedHealthChecks and emits a DisabledPluginEvent
-// and a HealthCheckFailedEvent
-func (a *availablePlugin) healthCheckFailed() {
+// and a HealthCheckFailedEvent.  A PluginCrashedEvent with err is emitted the
+// first time the plugin is found dead.
+func (a *availablePlugin) healthCheckFailed(err error) {
 	log.WithFields(log.Fields{
 		"_module": "control-aplugin",
 		"block":   "check-health",
 		"aplugin": a,
 	}).Warning("heartbeat missed")
-	a.failedHealthChecks++
-	if a.failedHealthChecks >= DefaultHealthCheckFailureLimit {
+	if atomic.AddInt32(&a.failedHealthChecks, 1) >= DefaultHealthCheckFailureLimit {
 		log.WithFields(log.Fields{
 			"_module": "control-aplugin",
 			"block":   "check-health",
@@ -300,6 +405,16 @@ func (a *availablePlugin) healthCheckFailed() {
 			String:  a.String(),
 		}
 		defer a.emitter.Emit(pde)
+		if atomic.CompareAndSwapInt32(&a.crashed, 0, 1) {
+			defer a.emitter.Emit(&control_event.PluginCrashedEvent{
+				Name:    a.name,
+				Version: a.version,
+				Type:    int(a.pluginType),
+				Key:     a.key,
+				Id:      a.ID(),
+				Error:   err.Error(),
+			})
+		}
 	}
 	hcfe := &control_event.HealthCheckFailedEvent{
 		Name:    a.name,
@@ -316,12 +431,47 @@ type availablePlugins struct {
 	// The Pools' primary keys are equal to
 	// {plugin_type}:{plugin_name}:{plugin_version}
 	table map[string]strategy.Pool
+	// publisherCompression compresses published content for publishers
+	// which accept a content encoding
+	publisherCompression bool
+	// publisherChunkSize is the max size of the chunks content is published
+	// in to publishers which accept chunked content, zero disables chunking
+	publisherChunkSize int
+	// selectionTrace records plugin selections when selection tracing is
+	// enabled
+	selectionTrace *selectionTraceBuffer
+	// telemetry records the control self-telemetry exposed by
+	// PrometheusHandler
+	telemetry *controlTelemetry
+	// caching holds the plugins whose caching was set with
+	// SetPluginCaching
+	caching *pluginCaching
+	// inFlight tracks the calls to plugins which are in flight
+	inFlight *inFlightCalls
+	// maxBatchSize is the max number of metrics collected in one call to a
+	// member of a pool when the collection can be split across members,
+	// zero disables splitting
+	maxBatchSize int
+	// maxRunningPlugins is the max number of plugins run in each pool
+	maxRunningPlugins int
+	// typeStrategies are the routing strategies set for the plugins of a
+	// type with SetRoutingStrategyForType
+	typeStrategies map[core.PluginType]plugin.RoutingStrategyType
+	// cacheExpirations are the cache TTLs set for plugin keys with
+	// SetCacheExpirationForPlugin
+	cacheExpirations map[string]time.Duration
 }
 
 func newAvailablePlugins() *availablePlugins {
 	return &availablePlugins{
-		RWMutex: &sync.RWMutex{},
-		table:   make(map[string]strategy.Pool),
+		RWMutex:           &sync.RWMutex{},
+		table:             make(map[string]strategy.Pool),
+		telemetry:         newControlTelemetry(),
+		caching:           newPluginCaching(),
+		inFlight:          newInFlightCalls(),
+		maxRunningPlugins: strategy.MaximumRunningPlugins,
+		typeStrategies:    map[core.PluginType]plugin.RoutingStrategyType{},
+		cacheExpirations:  map[string]time.Duration{},
 	}
 }
 
@@ -333,19 +483,29 @@ func (ap *availablePlugins) insert(pl *availablePlugin) error {
 	ap.Lock()
 	defer ap.Unlock()
 
-	key := fmt.Sprintf("%s:%s:%d", pl.TypeName(), pl.name, pl.version)
+	key := core.PluginKey(core.PluginType(pl.pluginType), pl.name, pl.version)
 	_, exists := ap.table[key]
 	if !exists {
-		p, err := strategy.NewPool(key, pl)
+		p, err := ap.newPool(key)
 		if err != nil {
 			return serror.New(ErrBadKey, map[string]interface{}{
 				"key": key,
 			})
 		}
+		p.Insert(pl)
 		ap.table[key] = p
+		ap.applyCacheExpiration(key, p)
+		ap.applyTypeStrategy(p, pl)
 		return nil
 	}
-	ap.table[key].Insert(pl)
+	pool := ap.table[key]
+	pool.Insert(pl)
+	// the plugin's declared strategy and cache TTL were applied if the
+	// pool was empty
+	if pool.Count() == 1 {
+		ap.applyCacheExpiration(key, pool)
+		ap.applyTypeStrategy(pool, pl)
+	}
 	return nil
 }
 
@@ -378,6 +538,58 @@ func (ap *availablePlugins) getPool(key string) (strategy.Pool, serror.SnapError
 	return pool, nil
 }
 
+// selectAP selects the plugin to call from the pool and reserves a call on it
+// with reserveAP, recording the selection when selection tracing is enabled.
+// The caller must release the reserved call.  ErrAllMembersBusy is returned
+// rather than waiting when every plugin in the pool is at its concurrent call
+// limit.  The pool must be read locked by the caller.
+func (ap *availablePlugins) selectAP(pool strategy.Pool, key, taskID string, config map[string]ctypes.ConfigValue) (*availablePlugin, serror.SnapError) {
+	selected, serr := pool.SelectAP(taskID, config)
+	if serr != nil {
+		return nil, serr
+	}
+	reserved := reserveAP(pool, selected)
+	if reserved == nil {
+		return nil, serror.New(ErrAllMembersBusy, map[string]interface{}{"pool-key": key})
+	}
+	if ap.selectionTrace != nil {
+		ap.traceSelection(pool, key, taskID, reserved)
+	}
+	return reserved, nil
+}
+
+// reserveAP reserves a call on the selected available plugin.  When the
+// selected plugin is at its concurrent call limit the other plugins in the
+// pool are tried.  If the pool's strategy makes its plugins interchangeable
+// the first of them with a free call is used instead; otherwise the call
+// waits for the selected plugin, as the strategy routes the call to it, once
+// another is found to have a free call.  nil is returned when every plugin in
+// the pool is at its limit.  The pool must be read locked by the caller, and
+// the lock is released while waiting.
+func reserveAP(pool strategy.Pool, selected strategy.AvailablePlugin) *availablePlugin {
+	ap := selected.(*availablePlugin)
+	if ap.tryAcquire() {
+		return ap
+	}
+	interchangeable := pool.Strategy().Interchangeable()
+	for _, p := range pool.Plugins() {
+		other, ok := p.(*availablePlugin)
+		if !ok || other == ap || !other.tryAcquire() {
+			continue
+		}
+		if interchangeable {
+			return other
+		}
+		other.release()
+		// writers to the pool must not wait on the call
+		pool.RUnlock()
+		ap.acquire()
+		pool.RLock()
+		return ap
+	}
+	return nil
+}
+
 func (ap *availablePlugins) collectMetrics(pluginKey string, metricTypes []core.Metric, taskID string) ([]core.Metric, error) {
 	var results []core.Metric
 	pool, serr := ap.getPool(pluginKey)
@@ -393,8 +605,17 @@ func (ap *availablePlugins) collectMetrics(pluginKey string, metricTypes []core.
 	if pool.Strategy() == nil {
 		return nil, errors.New("Plugin strategy not set")
 	}
+	// Nothing is collected from a paused plugin but the collection does not
+	// fail.
+	if pool.Paused() {
+		return nil, nil
+	}
 
-	metricsToCollect, metricsFromCache := pool.CheckCache(metricTypes, taskID)
+	cached := ap.caching.enabled(pluginKey, pool)
+	metricsToCollect, metricsFromCache := metricTypes, []core.Metric(nil)
+	if cached {
+		metricsToCollect, metricsFromCache = pool.CheckCache(metricTypes, taskID)
+	}
 
 	if len(metricsToCollect) == 0 {
 		return metricsFromCache, nil
@@ -408,24 +629,31 @@ func (ap *availablePlugins) collectMetrics(pluginKey string, metricTypes []core.
 
 	pool.RLock()
 	defer pool.RUnlock()
-	p, serr := pool.SelectAP(taskID, cfg)
+	selected, serr := ap.selectAP(pool, pluginKey, taskID, cfg)
 	if serr != nil {
 		return nil, serr
 	}
 
-	// cast client to PluginCollectorClient
-	cli, ok := p.(*availablePlugin).client.(client.PluginCollectorClient)
-	if !ok {
-		return nil, serror.New(errors.New("unable to cast client to PluginCollectorClient"))
-	}
-
 	// collect metrics
-	metrics, err := cli.CollectMetrics(metricsToCollect)
-	if err != nil {
+	var (
+		metrics []core.Metric
+		err     error
+	)
+	if batches := ap.splitBatch(pool, selected, metricsToCollect); len(batches) > 1 {
+		// the sub-batches reserve their own calls
+		selected.release()
+		metrics, err = ap.collectBatches(pool, pluginKey, selected, batches, taskID)
+	} else {
+		metrics, err = ap.collectFrom(selected, pluginKey, metricsToCollect, taskID)
+	}
+	nerrs, partial := err.(plugin.NamespaceErrors)
+	if err != nil && !partial {
 		return nil, serror.New(err)
 	}
 
-	pool.UpdateCache(metrics, taskID)
+	if cached {
+		pool.UpdateCache(metrics, taskID)
+	}
 
 	results = make([]core.Metric, len(metricsFromCache)+len(metrics))
 	idx := 0
@@ -438,51 +666,133 @@ func (ap *availablePlugins) collectMetrics(pluginKey string, metricTypes []core.
 		idx++
 	}
 
-	// update plugin stats
-	p.(*availablePlugin).hitCount++
-	p.(*availablePlugin).lastHitTime = time.Now()
-
+	if partial {
+		return results, nerrs
+	}
 	return results, nil
 }
 
-func (ap *availablePlugins) publishMetrics(contentType string, content []byte, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string) []error {
+// collectFrom collects the metrics from the available plugin, on which the
+// caller has reserved a call, and releases the call.
+func (ap *availablePlugins) collectFrom(p *availablePlugin, pluginKey string, mts []core.Metric, taskID string) ([]core.Metric, error) {
+	// cast client to PluginCollectorClient
+	cli, ok := p.client.(client.PluginCollectorClient)
+	if !ok {
+		p.release()
+		return nil, errors.New("unable to cast client to PluginCollectorClient")
+	}
+
+	started := time.Now()
+	call := ap.inFlight.start(pluginKey, p, "collect", taskID)
+	metrics, err := cli.CollectMetrics(mts)
+	ap.inFlight.done(call)
+	p.release()
+	ap.telemetry.observeCollect(pluginKey, time.Since(started))
+	if err == client.ErrCollectResponseTooLarge {
+		// the response was not decoded so none of the metrics were collected
+		metrics, err = nil, p.collectResponseTooLarge(pluginKey, taskID, mts)
+	}
+	if _, partial := err.(plugin.NamespaceErrors); err != nil && !partial {
+		return nil, err
+	}
+
+	// update plugin stats
+	p.hit()
+	return metrics, err
+}
+
+// publishMetrics publishes content to the publisher and returns its ack of
+// the content, which is nil when the publisher does not ack content.
+func (ap *availablePlugins) publishMetrics(contentType string, content []byte, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string) (*plugin.PublishAck, []error) {
 	var errs []error
-	key := strings.Join([]string{plugin.PublisherPluginType.String(), pluginName, strconv.Itoa(pluginVersion)}, ":")
+	key := core.PluginKey(core.PublisherPluginType, pluginName, pluginVersion)
 	pool, serr := ap.getPool(key)
 	if serr != nil {
 		errs = append(errs, serr)
-		return errs
+		return nil, errs
 	}
 	if pool == nil {
-		return []error{serror.New(ErrPoolNotFound, map[string]interface{}{"pool-key": key})}
+		return nil, []error{serror.New(ErrPoolNotFound, map[string]interface{}{"pool-key": key})}
 	}
 
 	pool.RLock()
 	defer pool.RUnlock()
 
-	p, err := pool.SelectAP(taskID, config)
+	p, err := ap.selectAP(pool, key, taskID, config)
 	if err != nil {
 		errs = append(errs, err)
-		return errs
+		return nil, errs
 	}
+	defer p.release()
 
-	cli, ok := p.(*availablePlugin).client.(client.PluginPublisherClient)
+	cli, ok := p.client.(client.PluginPublisherClient)
 	if !ok {
-		return []error{errors.New("unable to cast client to PluginPublisherClient")}
+		return nil, []error{errors.New("unable to cast client to PluginPublisherClient")}
 	}
 
-	errp := cli.Publish(contentType, content, config)
+	var ack *plugin.PublishAck
+	var errp error
+	encoding := ap.publishEncoding(p)
+	call := ap.inFlight.start(key, p, "publish", taskID)
+	defer ap.inFlight.done(call)
+	if chunkCli, ok := ap.chunkedPublisher(p); ok {
+		encoded, err := plugin.EncodeContent(encoding, content)
+		if err != nil {
+			return nil, []error{err}
+		}
+		ack, errp = chunkCli.PublishChunked(contentType, encoding, encoded, ap.publisherChunkSize, config)
+	} else if ackCli, ok := p.client.(client.PluginAckingPublisherClient); ok {
+		encoded, err := plugin.EncodeContent(encoding, content)
+		if err != nil {
+			return nil, []error{err}
+		}
+		ack, errp = ackCli.PublishWithAck(contentType, encoding, encoded, config)
+	} else if encoding != "" {
+		encoded, err := plugin.EncodeContent(encoding, content)
+		if err != nil {
+			return nil, []error{err}
+		}
+		errp = p.client.(client.PluginEncodedPublisherClient).PublishEncoded(contentType, encoding, encoded, config)
+	} else {
+		errp = cli.Publish(contentType, content, config)
+	}
 	if errp != nil {
-		return []error{errp}
+		return nil, []error{errp}
 	}
-	p.(*availablePlugin).hitCount++
-	p.(*availablePlugin).lastHitTime = time.Now()
-	return nil
+	p.hit()
+	return ack, nil
+}
+
+// chunkedPublisher returns the client of the plugin to publish content in
+// chunks with, if chunking is enabled and the plugin accepts chunked content.
+func (ap *availablePlugins) chunkedPublisher(p *availablePlugin) (client.PluginChunkedPublisherClient, bool) {
+	if ap.publisherChunkSize <= 0 || !p.meta.HasCapability(plugin.ChunkedContentCapability) {
+		return nil, false
+	}
+	cli, ok := p.client.(client.PluginChunkedPublisherClient)
+	return cli, ok
+}
+
+// publishEncoding returns the content encoding to compress content published
+// to the plugin with, or an empty string if content is sent uncompressed.
+func (ap *availablePlugins) publishEncoding(p *availablePlugin) string {
+	if !ap.publisherCompression {
+		return ""
+	}
+	if _, ok := p.client.(client.PluginEncodedPublisherClient); !ok {
+		return ""
+	}
+	for _, encoding := range p.meta.AcceptedContentEncodings {
+		if encoding == plugin.GzipContentEncoding {
+			return encoding
+		}
+	}
+	return ""
 }
 
 func (ap *availablePlugins) processMetrics(contentType string, content []byte, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string) (string, []byte, []error) {
 	var errs []error
-	key := strings.Join([]string{plugin.ProcessorPluginType.String(), pluginName, strconv.Itoa(pluginVersion)}, ":")
+	key := core.PluginKey(core.ProcessorPluginType, pluginName, pluginVersion)
 	pool, serr := ap.getPool(key)
 	if serr != nil {
 		errs = append(errs, serr)
@@ -494,23 +804,25 @@ func (ap *availablePlugins) processMetrics(contentType string, content []byte, p
 
 	pool.RLock()
 	defer pool.RUnlock()
-	p, err := pool.SelectAP(taskID, config)
+	p, err := ap.selectAP(pool, key, taskID, config)
 	if err != nil {
 		errs = append(errs, err)
 		return "", nil, errs
 	}
+	defer p.release()
 
-	cli, ok := p.(*availablePlugin).client.(client.PluginProcessorClient)
+	cli, ok := p.client.(client.PluginProcessorClient)
 	if !ok {
 		return "", nil, []error{errors.New("unable to cast client to PluginProcessorClient")}
 	}
 
+	call := ap.inFlight.start(key, p, "process", taskID)
 	ct, c, errp := cli.Process(contentType, content, config)
+	ap.inFlight.done(call)
 	if errp != nil {
 		return "", nil, []error{errp}
 	}
-	p.(*availablePlugin).hitCount++
-	p.(*availablePlugin).lastHitTime = time.Now()
+	p.hit()
 	return ct, c, nil
 }
 
@@ -543,7 +855,7 @@ func (ap *availablePlugins) getOrCreatePool(key string) (strategy.Pool, error) {
 	if ok {
 		return pool, nil
 	}
-	pool, err = strategy.NewPool(key)
+	pool, err = ap.newPool(key)
 	if err != nil {
 		return nil, err
 	}
@@ -551,6 +863,17 @@ func (ap *availablePlugins) getOrCreatePool(key string) (strategy.Pool, error) {
 	return pool, nil
 }
 
+// newPool returns an empty pool limited to the max number of running plugins
+// of the available plugins.
+func (ap *availablePlugins) newPool(key string) (strategy.Pool, error) {
+	pool, err := strategy.NewPool(key)
+	if err != nil {
+		return nil, err
+	}
+	pool.SetMax(ap.maxRunningPlugins)
+	return pool, nil
+}
+
 func (ap *availablePlugins) pools() map[string]strategy.Pool {
 	ap.RLock()
 	defer ap.RUnlock()
@@ -562,9 +885,11 @@ func (ap *availablePlugins) all() []strategy.AvailablePlugin {
 	ap.RLock()
 	defer ap.RUnlock()
 	for _, pool := range ap.table {
+		pool.RLock()
 		for _, ap := range pool.Plugins() {
 			aps = append(aps, ap)
 		}
+		pool.RUnlock()
 	}
 	return aps
 }
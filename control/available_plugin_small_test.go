@@ -0,0 +1,185 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newLimitedAvailablePlugin(limit int) *availablePlugin {
+	return &availablePlugin{
+		name:    "mock",
+		version: 1,
+		calls:   make(chan struct{}, limit),
+	}
+}
+
+func TestReserveAP(t *testing.T) {
+	Convey("Given a pool of plugins limited to one concurrent call", t, func() {
+		a := newLimitedAvailablePlugin(1)
+		b := newLimitedAvailablePlugin(1)
+		pool := newTestPool("collector:mock:1", a, b)
+
+		Convey("the selected plugin is used when it is free", func() {
+			p := reserveAP(pool, a)
+			So(p, ShouldEqual, a)
+			p.release()
+		})
+		Convey("another plugin is used when the selected plugin is busy", func() {
+			So(a.tryAcquire(), ShouldBeTrue)
+			p := reserveAP(pool, a)
+			So(p, ShouldEqual, b)
+			So(b.tryAcquire(), ShouldBeFalse)
+			p.release()
+			a.release()
+		})
+		Convey("nil is returned when every plugin is busy", func() {
+			So(a.tryAcquire(), ShouldBeTrue)
+			So(b.tryAcquire(), ShouldBeTrue)
+			So(reserveAP(pool, a), ShouldBeNil)
+			a.release()
+			b.release()
+		})
+	})
+	Convey("Given a sticky pool of plugins limited to one concurrent call", t, func() {
+		a := newLimitedAvailablePlugin(1)
+		b := newLimitedAvailablePlugin(1)
+		pool := newTestPool("collector:mock:1", a, b)
+		So(pool.SetStrategy(plugin.StickyRouting), ShouldBeNil)
+
+		Convey("the call waits for the selected plugin while another is free", func() {
+			So(a.tryAcquire(), ShouldBeTrue)
+			reserved := make(chan *availablePlugin)
+			go func() {
+				pool.RLock()
+				defer pool.RUnlock()
+				reserved <- reserveAP(pool, a)
+			}()
+			select {
+			case <-reserved:
+				t.Fatal("reserved a busy plugin")
+			case <-time.After(50 * time.Millisecond):
+			}
+			// the pool is not held locked while waiting
+			pool.Subscribe("task", strategy.UnboundSubscriptionType)
+			So(b.tryAcquire(), ShouldBeTrue)
+			b.release()
+			a.release()
+			So(<-reserved, ShouldEqual, a)
+			a.release()
+		})
+		Convey("nil is returned when every plugin is busy", func() {
+			So(a.tryAcquire(), ShouldBeTrue)
+			So(b.tryAcquire(), ShouldBeTrue)
+			So(reserveAP(pool, a), ShouldBeNil)
+			a.release()
+			b.release()
+		})
+	})
+	Convey("A plugin without a limit is always available", t, func() {
+		ap := &availablePlugin{}
+		So(ap.tryAcquire(), ShouldBeTrue)
+		So(ap.tryAcquire(), ShouldBeTrue)
+	})
+}
+
+func TestCallLimit(t *testing.T) {
+	Convey("A plugin not declaring MaxConcurrentCalls is not limited", t, func() {
+		So(callLimit(plugin.PluginMeta{}), ShouldEqual, 0)
+	})
+	Convey("A plugin declaring MaxConcurrentCalls is limited to them", t, func() {
+		So(callLimit(plugin.PluginMeta{MaxConcurrentCalls: 3}), ShouldEqual, 3)
+	})
+}
+
+func TestSelectAPBusy(t *testing.T) {
+	Convey("Given a pool of plugins limited to one concurrent call", t, func() {
+		a := newLimitedAvailablePlugin(1)
+		b := newLimitedAvailablePlugin(1)
+		pool := newTestPool("collector:mock:1", a, b)
+		aps := newAvailablePlugins()
+
+		Convey("a plugin is selected while one has a free call", func() {
+			So(a.tryAcquire(), ShouldBeTrue)
+			selected, serr := aps.selectAP(pool, "collector:mock:1", "task", nil)
+			So(serr, ShouldBeNil)
+			So(selected, ShouldEqual, b)
+			So(b.tryAcquire(), ShouldBeFalse)
+			selected.release()
+			a.release()
+		})
+		Convey("ErrAllMembersBusy is returned when every plugin is at its limit", func() {
+			So(a.tryAcquire(), ShouldBeTrue)
+			So(b.tryAcquire(), ShouldBeTrue)
+			_, serr := aps.selectAP(pool, "collector:mock:1", "task", nil)
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, ErrAllMembersBusy.Error())
+			a.release()
+			b.release()
+		})
+	})
+}
+
+func TestAvailablePluginStats(t *testing.T) {
+	Convey("Collecting from a plugin records the hit", t, func() {
+		c := New(GetDefaultConfig())
+		mt := addFakeCollector(c, "mock", &fakeCollectorClient{})
+		pool, err := c.pluginRunner.AvailablePlugins().getPool("collector:mock:1")
+		So(err, ShouldBeNil)
+		var ap core.AvailablePlugin
+		for _, p := range pool.Plugins() {
+			ap = p
+		}
+		before := time.Now()
+		_, cerr := c.pluginRunner.AvailablePlugins().collectMetrics("collector:mock:1", []core.Metric{mt}, "task")
+		So(cerr, ShouldBeNil)
+		So(ap.HitCount(), ShouldEqual, 1)
+		So(ap.LastHit().Before(before), ShouldBeFalse)
+	})
+	Convey("Hits are recorded safely while read concurrently", t, func() {
+		ap := &availablePlugin{startTime: time.Now()}
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				ap.hit()
+			}()
+			go func() {
+				defer wg.Done()
+				ap.HitCount()
+				ap.LastHit()
+			}()
+		}
+		wg.Wait()
+		So(ap.HitCount(), ShouldEqual, 10)
+		So(ap.StartTime().IsZero(), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,65 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"github.com/intelsdi-x/snap/core"
+)
+
+// AvailablePluginsByType returns the running plugins of the type.
+// NOTE: The returned data from this function should be considered constant and read only
+func (p *pluginControl) AvailablePluginsByType(t core.PluginType) []core.AvailablePlugin {
+	return p.availablePluginsWhere(func(typ core.PluginType, _ string, _ int) bool {
+		return typ == t
+	})
+}
+
+// AvailablePluginsForKey returns the running plugins of the plugin identified
+// by its {type}:{name}:{version} key.  A version less than 1 matches every
+// version of the plugin.  A malformed key matches no plugins.
+// NOTE: The returned data from this function should be considered constant and read only
+func (p *pluginControl) AvailablePluginsForKey(key string) []core.AvailablePlugin {
+	t, name, version, err := core.ParsePluginKey(key)
+	if err != nil {
+		return nil
+	}
+	return p.availablePluginsWhere(func(typ core.PluginType, n string, v int) bool {
+		return typ == t && n == name && (version < 1 || v == version)
+	})
+}
+
+// availablePluginsWhere returns the running plugins of the pools whose key
+// the match function accepts.
+func (p *pluginControl) availablePluginsWhere(match func(core.PluginType, string, int) bool) []core.AvailablePlugin {
+	var caps []core.AvailablePlugin
+	aps := p.pluginRunner.AvailablePlugins()
+	aps.RLock()
+	defer aps.RUnlock()
+	for key, pool := range aps.table {
+		typ, name, version, err := core.ParsePluginKey(key)
+		if err != nil || !match(typ, name, version) {
+			continue
+		}
+		for _, ap := range pool.Plugins() {
+			caps = append(caps, ap)
+		}
+	}
+	return caps
+}
@@ -0,0 +1,72 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// addRunningPlugin adds a pool running one instance of the plugin.
+func addRunningPlugin(c *pluginControl, typ plugin.PluginType, name string, version int) {
+	ap := newFakeAvailablePlugin(typ, name, version, &failingClient{})
+	addPool(c.pluginRunner.AvailablePlugins(), core.PluginKey(core.PluginType(typ), name, version), ap)
+}
+
+// runningKeys returns the keys of the running plugins in order.
+func runningKeys(aps []core.AvailablePlugin) []string {
+	keys := []string{}
+	for _, ap := range aps {
+		keys = append(keys, fmt.Sprintf("%s:%s:%d", ap.TypeName(), ap.Name(), ap.Version()))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestAvailablePluginsFilter(t *testing.T) {
+	Convey("Given running plugins of several types and versions", t, func() {
+		c := New(GetDefaultConfig())
+		addRunningPlugin(c, plugin.CollectorPluginType, "mock", 1)
+		addRunningPlugin(c, plugin.CollectorPluginType, "mock", 2)
+		addRunningPlugin(c, plugin.CollectorPluginType, "other", 1)
+		addRunningPlugin(c, plugin.PublisherPluginType, "mock", 1)
+
+		So(runningKeys(c.AvailablePluginsByType(core.CollectorPluginType)), ShouldResemble,
+			[]string{"collector:mock:1", "collector:mock:2", "collector:other:1"})
+		So(runningKeys(c.AvailablePluginsByType(core.PublisherPluginType)), ShouldResemble,
+			[]string{"publisher:mock:1"})
+		So(c.AvailablePluginsByType(core.ProcessorPluginType), ShouldBeEmpty)
+
+		So(runningKeys(c.AvailablePluginsForKey("collector:mock:2")), ShouldResemble,
+			[]string{"collector:mock:2"})
+		So(runningKeys(c.AvailablePluginsForKey("collector:mock:-1")), ShouldResemble,
+			[]string{"collector:mock:1", "collector:mock:2"})
+		So(c.AvailablePluginsForKey("collector:mock:3"), ShouldBeEmpty)
+		So(c.AvailablePluginsForKey("collector:mock"), ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,66 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrInvalidCacheExpiration - error message when a cache expiration is
+	// negative
+	ErrInvalidCacheExpiration = errors.New("Cache expiration must not be negative")
+)
+
+// SetCacheExpirationForPlugin sets how long the metrics collected from the
+// plugin identified by its {type}:{name}:{version} key are cached, overriding
+// the CacheExpiration option and the CacheTTL metadata of the plugin.  It
+// applies to the running plugins and to plugins of the key started later.
+func (p *pluginControl) SetCacheExpirationForPlugin(key string, ttl time.Duration) error {
+	f := map[string]interface{}{"plugin-key": key}
+	if _, _, _, err := core.ParsePluginKey(key); err != nil {
+		return serror.New(err, f)
+	}
+	if ttl < 0 {
+		return serror.New(ErrInvalidCacheExpiration, f)
+	}
+	aps := p.pluginRunner.AvailablePlugins()
+	aps.Lock()
+	defer aps.Unlock()
+	aps.cacheExpirations[key] = ttl
+	if pool, ok := aps.table[key]; ok {
+		pool.SetCacheTTL(ttl)
+	}
+	return nil
+}
+
+// applyCacheExpiration sets the cache expiration set for the plugin key on
+// the pool, as the TTL declared by the plugin is applied when the first
+// plugin is inserted into the pool.  The caller must hold the lock.
+func (ap *availablePlugins) applyCacheExpiration(key string, pool strategy.Pool) {
+	if ttl, ok := ap.cacheExpirations[key]; ok {
+		pool.SetCacheTTL(ttl)
+	}
+}
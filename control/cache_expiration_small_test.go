@@ -0,0 +1,72 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSetCacheExpirationForPlugin(t *testing.T) {
+	Convey("Given a running collector", t, func() {
+		c := New(GetDefaultConfig(), CacheExpiration(time.Second))
+		addFakeCollector(c, "mock", &fakeCollectorClient{})
+		aps := c.pluginRunner.AvailablePlugins()
+		pool, err := aps.getPool("collector:mock:1")
+		So(err, ShouldBeNil)
+
+		Convey("plugins of other keys keep the global expiration", func() {
+			So(c.SetCacheExpirationForPlugin("collector:other:1", time.Minute), ShouldBeNil)
+			ttl, err := pool.CacheTTL("task")
+			So(err, ShouldBeNil)
+			So(ttl, ShouldEqual, time.Second)
+		})
+		Convey("the cache expiration of its pool can be changed", func() {
+			So(c.SetCacheExpirationForPlugin("collector:mock:1", 500*time.Millisecond), ShouldBeNil)
+			ttl, err := pool.CacheTTL("task")
+			So(err, ShouldBeNil)
+			So(ttl, ShouldEqual, 500*time.Millisecond)
+
+			So(c.SetCacheExpirationForPlugin("collector:mock:1", time.Minute), ShouldBeNil)
+			ttl, err = pool.CacheTTL("task")
+			So(err, ShouldBeNil)
+			So(ttl, ShouldEqual, time.Minute)
+		})
+		Convey("a plugin started later uses the cache expiration of its key", func() {
+			So(c.SetCacheExpirationForPlugin("collector:later:1", 10*time.Millisecond), ShouldBeNil)
+			So(aps.insert(newFakeAvailablePlugin(plugin.CollectorPluginType, "later", 1, &failingClient{})), ShouldBeNil)
+			later, err := aps.getPool("collector:later:1")
+			So(err, ShouldBeNil)
+			ttl, terr := later.CacheTTL("task")
+			So(terr, ShouldBeNil)
+			So(ttl, ShouldEqual, 10*time.Millisecond)
+		})
+		Convey("a bad key or a negative expiration is an error", func() {
+			So(c.SetCacheExpirationForPlugin("mock", time.Second), ShouldNotBeNil)
+			So(c.SetCacheExpirationForPlugin("collector:mock:1", -time.Second), ShouldNotBeNil)
+		})
+	})
+}
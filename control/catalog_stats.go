@@ -0,0 +1,58 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"time"
+)
+
+// CatalogStats summarizes the metric catalog.
+type CatalogStats struct {
+	// Metrics is the number of metrics, counting each version
+	Metrics int
+	// Plugins is the number of plugins with metrics in the catalog
+	Plugins int
+	// MetricsByPluginType is the number of metrics by the type name of
+	// their plugin
+	MetricsByPluginType map[string]int
+	// LastModified is when metrics were last added to or removed from the
+	// catalog, the zero time if never
+	LastModified time.Time
+}
+
+// CatalogStats returns a summary of the metric catalog, computed with a
+// single walk of it.
+func (p *pluginControl) CatalogStats() CatalogStats {
+	stats := CatalogStats{
+		MetricsByPluginType: map[string]int{},
+		LastModified:        p.metricCatalog.LastModified(),
+	}
+	plugins := map[string]bool{}
+	p.metricCatalog.Walk(func(mt *metricType) bool {
+		stats.Metrics++
+		if mt.Plugin != nil {
+			plugins[mt.Plugin.Key()] = true
+			stats.MetricsByPluginType[mt.Plugin.TypeName()]++
+		}
+		return true
+	})
+	stats.Plugins = len(plugins)
+	return stats
+}
@@ -0,0 +1,64 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCatalogStats(t *testing.T) {
+	Convey("An empty catalog has no metrics and was never modified", t, func() {
+		c := New(GetDefaultConfig())
+		stats := c.CatalogStats()
+		So(stats.Metrics, ShouldEqual, 0)
+		So(stats.Plugins, ShouldEqual, 0)
+		So(stats.MetricsByPluginType, ShouldBeEmpty)
+		So(stats.LastModified.IsZero(), ShouldBeTrue)
+	})
+	Convey("The stats count the metrics and plugins of the catalog", t, func() {
+		c := New(GetDefaultConfig())
+		before := time.Now()
+		foo := newCollector("foo")
+		catalogMetric(c, foo)
+		So(c.metricCatalog.AddLoadedMetricType(foo, plugin.MetricType{Namespace_: core.NewNamespace("intel", "foo", "bar"), Version_: 1}), ShouldBeNil)
+		catalogMetric(c, newCollector("baz"))
+
+		stats := c.CatalogStats()
+		So(stats.Metrics, ShouldEqual, 3)
+		So(stats.Plugins, ShouldEqual, 2)
+		So(stats.MetricsByPluginType, ShouldResemble, map[string]int{"collector": 3})
+		So(stats.LastModified.Before(before), ShouldBeFalse)
+
+		modified := stats.LastModified
+		c.metricCatalog.RmUnloadedPluginMetrics(foo)
+		stats = c.CatalogStats()
+		So(stats.Metrics, ShouldEqual, 1)
+		So(stats.Plugins, ShouldEqual, 1)
+		So(stats.LastModified.Before(modified), ShouldBeFalse)
+	})
+}
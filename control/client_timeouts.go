@@ -0,0 +1,44 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin/client"
+)
+
+// ClientTimeouts is the PluginControlOpt which sets the timeouts of the
+// clients calling plugins.  The connect timeout bounds connecting to a plugin
+// and, where the plugin's RPC transport allows, waiting for the first byte of
+// its response.  The response timeout bounds the whole call.  A call which
+// times out fails with client.ErrConnectTimeout or client.ErrResponseTimeout,
+// telling plugins which are slow to start apart from those which hang
+// mid-response.  The connect timeout defaults to DefaultClientTimeout; calls
+// are unbounded unless a positive response timeout is set.  A native RPC
+// plugin whose call times out is disconnected, as the call cannot be
+// cancelled.
+func ClientTimeouts(connect, response time.Duration) PluginControlOpt {
+	return func(c *pluginControl) {
+		t := client.Timeouts{Connect: connect, Response: response}
+		c.pluginManager.SetPluginClientTimeouts(t)
+		c.pluginRunner.SetClientTimeouts(t)
+	}
+}
@@ -0,0 +1,164 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// CollectMetricsAsync starts collecting the metrics as CollectMetrics does
+// and returns at once.  The metrics collected from each collector are sent
+// on the metric channel as the collector responds and the errors, including
+// a *core.MetricError for each metric a collector failed to collect, on the
+// error channel.  A failing collector does not discard the metrics of the
+// others.  Both channels are closed once every collector has responded or,
+// if the deadline is not zero, when the deadline passes, discarding the
+// metrics of the collectors which have not responded.  Both channels must be
+// received from until they are closed.  Metrics are sent in the order the collectors
+// respond, and the OrderedResults, DeltaCollection and MetricStalenessWindow
+// options do not apply.
+func (p *pluginControl) CollectMetricsAsync(metricTypes []core.Metric, deadline time.Time, taskID string, allTags map[string]map[string]string) (<-chan core.Metric, <-chan error) {
+	metrics := make(chan core.Metric)
+	errs := make(chan error)
+	go func() {
+		defer close(metrics)
+		defer close(errs)
+		ctx := context.Background()
+		if !deadline.IsZero() {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, deadline)
+			defer cancel()
+		}
+		a := &asyncCollection{ctx: ctx, metrics: metrics, errs: errs}
+		if !p.Started {
+			a.sendErrs(ErrControllerNotStarted)
+			return
+		}
+		p.collectAsync(a, metricTypes, deadline, taskID, allTags)
+	}()
+	return metrics, errs
+}
+
+// asyncCollection sends the results of a collection started with
+// CollectMetricsAsync until its context is done.
+type asyncCollection struct {
+	ctx context.Context
+	// requested are the metrics requested, holding the tag filters of the
+	// metrics sent
+	requested []core.Metric
+	metrics   chan<- core.Metric
+	errs      chan<- error
+}
+
+// send sends the metrics, sampled and filtered by tags, and errors.  It
+// returns false if the context was done before all were sent.
+func (a *asyncCollection) send(mts []core.Metric, errs ...error) bool {
+	for _, m := range filterMetricsByTags(a.requested, sampleMetrics(mts)) {
+		select {
+		case a.metrics <- m:
+		case <-a.ctx.Done():
+			return false
+		}
+	}
+	return a.sendErrs(errs...)
+}
+
+func (a *asyncCollection) sendErrs(errs ...error) bool {
+	for _, e := range errs {
+		select {
+		case a.errs <- e:
+		case <-a.ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// collectAsync collects the metrics, sending the results of each collector
+// as it responds.  Conditional metrics are collected once the metrics their
+// predicates depend on have been sent, and the metrics provided by remote
+// controls are sent once the local metrics have been.
+func (p *pluginControl) collectAsync(a *asyncCollection, metricTypes []core.Metric, deadline time.Time, taskID string, allTags map[string]map[string]string) {
+	metricTypes = p.subscriptionConfigs.apply(taskID, metricTypes)
+	a.requested = metricTypes
+	local, remote := p.remotes.split(p.metricCatalog, metricTypes)
+
+	type remoteResult struct {
+		metrics []core.Metric
+		errs    []error
+	}
+	remoteResults := make(chan remoteResult, 1)
+	if len(remote) > 0 {
+		go func() {
+			var r remoteResult
+			r.metrics, r.errs = p.remotes.collect(remote, deadline, taskID, allTags)
+			remoteResults <- r
+		}()
+	}
+
+	ready, pending := splitConditionalMetrics(local)
+	var collected []core.Metric
+	for len(ready) > 0 {
+		results, n, serr := p.startCollection(ready, deadline, taskID)
+		if serr != nil {
+			if !a.sendErrs(serr) {
+				return
+			}
+			break
+		}
+		for ; n > 0; n-- {
+			var r collectResult
+			select {
+			case r = <-results:
+			case <-a.ctx.Done():
+				return
+			}
+			errs := r.metricErrs
+			if r.err != nil {
+				errs = append(errs, r.err)
+			}
+			for i := range r.metrics {
+				r.metrics[i] = addStandardAndWorkflowTags(r.metrics[i], allTags)
+			}
+			if len(pending) > 0 {
+				collected = append(collected, r.metrics...)
+			}
+			if !a.send(r.metrics, errs...) {
+				return
+			}
+		}
+		if len(pending) == 0 {
+			break
+		}
+		ready, pending = dueConditionalMetrics(pending, collected)
+	}
+
+	if len(remote) > 0 {
+		select {
+		case r := <-remoteResults:
+			a.send(r.metrics, r.errs...)
+		case <-a.ctx.Done():
+		}
+	}
+}
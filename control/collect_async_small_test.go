@@ -0,0 +1,107 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// drain receives from the channels until both are closed.
+func drain(metrics <-chan core.Metric, errs <-chan error) ([]core.Metric, []error) {
+	var (
+		mts  []core.Metric
+		errl []error
+	)
+	for metrics != nil || errs != nil {
+		select {
+		case m, ok := <-metrics:
+			if !ok {
+				metrics = nil
+				continue
+			}
+			mts = append(mts, m)
+		case e, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			errl = append(errl, e)
+		}
+	}
+	return mts, errl
+}
+
+func TestCollectMetricsAsync(t *testing.T) {
+	Convey("Metrics are sent as each collector responds", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		mts := []core.Metric{
+			addFakeCollector(c, "fast", &fakeCollectorClient{}),
+			addFakeCollector(c, "slow", &fakeCollectorClient{delay: 50 * time.Millisecond}),
+		}
+		metrics, errs := c.CollectMetricsAsync(mts, time.Time{}, "task", nil)
+		first := <-metrics
+		So(first.Namespace().String(), ShouldEqual, "/intel/fast/foo")
+		rest, errl := drain(metrics, errs)
+		So(errl, ShouldBeEmpty)
+		So(len(rest), ShouldEqual, 1)
+		So(rest[0].Namespace().String(), ShouldEqual, "/intel/slow/foo")
+	})
+	Convey("A failing collector does not discard the metrics of the others", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		mts := []core.Metric{
+			addFakeCollector(c, "ok", &fakeCollectorClient{}),
+			addFakeCollector(c, "failing", &fakeCollectorClient{err: errors.New("collector failed")}),
+		}
+		metrics, errl := drain(c.CollectMetricsAsync(mts, time.Time{}, "task", nil))
+		So(len(metrics), ShouldEqual, 1)
+		So(len(errl), ShouldEqual, 1)
+		So(errl[0].Error(), ShouldEqual, "collector failed")
+	})
+	Convey("The channels are closed when the deadline passes", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		mts := []core.Metric{
+			addFakeCollector(c, "fast", &fakeCollectorClient{}),
+			addFakeCollector(c, "slow", &fakeCollectorClient{delay: time.Second}),
+		}
+		started := time.Now()
+		metrics, errl := drain(c.CollectMetricsAsync(mts, time.Now().Add(100*time.Millisecond), "task", nil))
+		So(time.Since(started), ShouldBeLessThan, time.Second)
+		So(errl, ShouldBeEmpty)
+		So(len(metrics), ShouldEqual, 1)
+		So(metrics[0].Namespace().String(), ShouldEqual, "/intel/fast/foo")
+	})
+	Convey("An error is sent when control is not started", t, func() {
+		c := New(GetDefaultConfig())
+		metrics, errl := drain(c.CollectMetricsAsync(nil, time.Time{}, "task", nil))
+		So(metrics, ShouldBeEmpty)
+		So(errl, ShouldResemble, []error{ErrControllerNotStarted})
+	})
+}
@@ -0,0 +1,178 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+)
+
+// collectBatcher coalesces requests to collect metrics from the same plugin
+// into a single call.  The first request waits for the batch window before
+// calling the plugin, and every request to the plugin arriving within the
+// window adds its metrics to the call.  Each request is returned the metrics
+// collected for the metrics it requested.
+type collectBatcher struct {
+	sync.Mutex
+	window time.Duration
+	aps    *availablePlugins
+	// pending maps a plugin key to the batch waiting for its window to
+	// close
+	pending map[string]*collectBatch
+}
+
+// collectBatch is a call to a plugin shared by the requests in a batch.
+type collectBatch struct {
+	done chan struct{}
+	// requested maps the namespace of each metric to collect to its key,
+	// identifying it by its namespace, version and config
+	requested map[string]string
+	mts       []core.Metric
+	metrics   []core.Metric
+	err       error
+}
+
+func newCollectBatcher(window time.Duration, aps *availablePlugins) *collectBatcher {
+	return &collectBatcher{
+		window:  window,
+		aps:     aps,
+		pending: make(map[string]*collectBatch),
+	}
+}
+
+// collectMetrics collects the metrics from the plugin as part of a batch.
+// Plugins which do not route least recently used keep state for each task so
+// requests to them are not batched.  A request for a metric already in the
+// batch with a different version or config is not batched either, as the
+// plugin returns the metric once for each call.
+func (b *collectBatcher) collectMetrics(pluginKey string, mts []core.Metric, taskID string) ([]core.Metric, error) {
+	if pool, err := b.aps.getPool(pluginKey); err == nil && pool != nil && pool.Strategy() != nil &&
+		pool.Strategy().String() != "least-recently-used" {
+		return b.aps.collectMetrics(pluginKey, mts, taskID)
+	}
+
+	b.Lock()
+	batch, ok := b.pending[pluginKey]
+	if !ok {
+		batch = &collectBatch{
+			done:      make(chan struct{}),
+			requested: make(map[string]string),
+		}
+		b.pending[pluginKey] = batch
+		go b.collect(pluginKey, batch, taskID)
+	}
+	if !batch.add(mts) {
+		b.Unlock()
+		return b.aps.collectMetrics(pluginKey, mts, taskID)
+	}
+	b.Unlock()
+
+	<-batch.done
+	return batch.resultsFor(mts)
+}
+
+// collect calls the plugin for the batch once its window closes.
+func (b *collectBatcher) collect(pluginKey string, batch *collectBatch, taskID string) {
+	time.Sleep(b.window)
+	b.Lock()
+	delete(b.pending, pluginKey)
+	b.Unlock()
+	batch.metrics, batch.err = b.aps.collectMetrics(pluginKey, batch.mts, taskID)
+	close(batch.done)
+}
+
+// add adds the metrics not already in the batch to it, returning false
+// without adding any when one of them is in the batch with a different
+// version or config.
+func (c *collectBatch) add(mts []core.Metric) bool {
+	for _, mt := range mts {
+		if key, ok := c.requested[mt.Namespace().String()]; ok && key != metricRequestKey(mt) {
+			return false
+		}
+	}
+	for _, mt := range mts {
+		ns := mt.Namespace().String()
+		if _, ok := c.requested[ns]; ok {
+			continue
+		}
+		c.requested[ns] = metricRequestKey(mt)
+		c.mts = append(c.mts, mt)
+	}
+	return true
+}
+
+// resultsFor returns copies of the metrics collected by the batch for the
+// requested metrics, along with the errors of any of them the plugin failed
+// to collect.
+func (c *collectBatch) resultsFor(mts []core.Metric) ([]core.Metric, error) {
+	nerrs, partial := c.err.(plugin.NamespaceErrors)
+	if c.err != nil && !partial {
+		return nil, c.err
+	}
+	var metrics []core.Metric
+	for _, mt := range mts {
+		metrics = append(metrics, collectedFor(mt, c.metrics)...)
+	}
+	if !partial {
+		return copyMetrics(metrics), nil
+	}
+	errs := plugin.NamespaceErrors{}
+	for ns, e := range nerrs {
+		failed := core.NewNamespace(strings.Split(strings.TrimPrefix(ns, "/"), "/")...)
+		for _, mt := range mts {
+			if namespaceMatches(mt.Namespace(), failed) {
+				errs[ns] = e
+				break
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return copyMetrics(metrics), nil
+	}
+	return copyMetrics(metrics), errs
+}
+
+// copyMetrics returns a copy of the metrics so each request in a batch can
+// add its own tags.
+func copyMetrics(mts []core.Metric) []core.Metric {
+	copies := make([]core.Metric, len(mts))
+	for i, m := range mts {
+		tags := make(map[string]string, len(m.Tags()))
+		for k, v := range m.Tags() {
+			tags[k] = v
+		}
+		copies[i] = plugin.MetricType{
+			Namespace_:          m.Namespace(),
+			Version_:            m.Version(),
+			LastAdvertisedTime_: m.LastAdvertisedTime(),
+			Config_:             m.Config(),
+			Data_:               m.Data(),
+			Tags_:               tags,
+			Description_:        m.Description(),
+			Unit_:               m.Unit(),
+			Timestamp_:          m.Timestamp(),
+		}
+	}
+	return copies
+}
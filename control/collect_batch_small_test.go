@@ -0,0 +1,95 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCollectBatch(t *testing.T) {
+	foo := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Version_: 1}
+	bar := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar"), Version_: 1}
+	Convey("Requests to a plugin within the window are collected in one call", t, func() {
+		c := New(GetDefaultConfig())
+		cli := &fakeCollectorClient{}
+		addFakeCollector(c, "mock", cli)
+		b := newCollectBatcher(50*time.Millisecond, c.pluginRunner.AvailablePlugins())
+
+		var wg sync.WaitGroup
+		results := make([][]core.Metric, 2)
+		for i, mts := range [][]core.Metric{{foo}, {foo, bar}} {
+			wg.Add(1)
+			go func(i int, mts []core.Metric) {
+				defer wg.Done()
+				metrics, err := b.collectMetrics("collector:mock:1", mts, "task")
+				So(err, ShouldBeNil)
+				results[i] = metrics
+			}(i, mts)
+		}
+		wg.Wait()
+		So(cli.calls, ShouldEqual, 1)
+		So(results[0], ShouldHaveLength, 1)
+		So(results[0][0].Namespace().String(), ShouldEqual, foo.Namespace().String())
+		So(results[1], ShouldHaveLength, 2)
+
+		Convey("and a request after the window makes another call", func() {
+			baz := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "baz"), Version_: 1}
+			_, err := b.collectMetrics("collector:mock:1", []core.Metric{baz}, "task")
+			So(err, ShouldBeNil)
+			So(cli.calls, ShouldEqual, 2)
+		})
+	})
+	Convey("A batch", t, func() {
+		batch := &collectBatch{requested: map[string]string{}}
+		So(batch.add([]core.Metric{foo, bar}), ShouldBeTrue)
+		So(batch.add([]core.Metric{foo}), ShouldBeTrue)
+		So(batch.mts, ShouldHaveLength, 2)
+		Convey("refuses a metric requested with a different version", func() {
+			So(batch.add([]core.Metric{plugin.MetricType{Namespace_: foo.Namespace(), Version_: 2}}), ShouldBeFalse)
+			So(batch.mts, ShouldHaveLength, 2)
+		})
+	})
+	Convey("A batch returns each request the errors of its own metrics", t, func() {
+		batch := &collectBatch{
+			metrics: []core.Metric{bar},
+			err:     plugin.NamespaceErrors{foo.Namespace().String(): "boom"},
+		}
+		metrics, err := batch.resultsFor([]core.Metric{bar})
+		So(err, ShouldBeNil)
+		So(metrics, ShouldHaveLength, 1)
+		_, err = batch.resultsFor([]core.Metric{foo})
+		So(err, ShouldResemble, plugin.NamespaceErrors{foo.Namespace().String(): "boom"})
+	})
+	Convey("copyMetrics copies the tags of each metric", t, func() {
+		m := plugin.MetricType{Namespace_: core.NewNamespace("intel", "foo"), Tags_: map[string]string{"a": "1"}}
+		copies := copyMetrics([]core.Metric{m})
+		copies[0].Tags()["a"] = "2"
+		So(m.Tags()["a"], ShouldEqual, "1")
+	})
+}
@@ -0,0 +1,101 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+)
+
+// MaxCollectResponse is the PluginControlOpt which caps a single collection
+// from a plugin at the number of metrics and the size in bytes of the
+// response.  Metrics past the metric cap are dropped and a *core.MetricError
+// naming the first dropped metric is returned by CollectMetrics.  A response
+// past the byte cap is not read from the plugin any further nor decoded, and a
+// *core.MetricError is returned for each of the metrics requested.  A
+// CollectResponseCappedEvent is emitted when either cap is hit.  A cap of zero
+// is unlimited, the default.
+func MaxCollectResponse(metrics int, bytes int) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.maxCollectMetrics = metrics
+		c.pluginRunner.SetMaxCollectResponseBytes(bytes)
+	}
+}
+
+// capCollectResponse returns the metrics collected from the plugin within
+// the collect response metric cap, and an error if any were dropped.
+func (p *pluginControl) capCollectResponse(pluginKey, taskID string, mts []core.Metric) ([]core.Metric, *core.MetricError) {
+	if p.maxCollectMetrics <= 0 || len(mts) <= p.maxCollectMetrics {
+		return mts, nil
+	}
+	accepted := p.maxCollectMetrics
+	dropped := len(mts) - accepted
+	_, name, version, _ := core.ParsePluginKey(pluginKey)
+	log.WithFields(log.Fields{
+		"_module":    "control",
+		"_block":     "cap-collect-response",
+		"plugin-key": pluginKey,
+		"task-id":    taskID,
+		"accepted":   accepted,
+		"dropped":    dropped,
+	}).Warn("collect response exceeded the cap")
+	p.eventManager.Emit(&control_event.CollectResponseCappedEvent{
+		TaskId:        taskID,
+		PluginName:    name,
+		PluginVersion: version,
+		Accepted:      accepted,
+		Dropped:       dropped,
+	})
+	return mts[:accepted], &core.MetricError{
+		Namespace: mts[accepted].Namespace().String(),
+		Err:       fmt.Sprintf("collect response from %s exceeded the cap, %d metrics dropped", pluginKey, dropped),
+	}
+}
+
+// collectResponseTooLarge returns an error for each of the metrics requested
+// by a collection whose response the client rejected for exceeding the max
+// collect response size, and emits a CollectResponseCappedEvent.
+func (a *availablePlugin) collectResponseTooLarge(pluginKey, taskID string, mts []core.Metric) plugin.NamespaceErrors {
+	log.WithFields(log.Fields{
+		"_module":    "control",
+		"_block":     "cap-collect-response",
+		"plugin-key": pluginKey,
+		"task-id":    taskID,
+		"dropped":    len(mts),
+	}).Warn("collect response exceeded the cap")
+	if a.emitter != nil {
+		a.emitter.Emit(&control_event.CollectResponseCappedEvent{
+			TaskId:        taskID,
+			PluginName:    a.name,
+			PluginVersion: a.version,
+			Dropped:       len(mts),
+		})
+	}
+	errs := make(plugin.NamespaceErrors, len(mts))
+	for _, mt := range mts {
+		errs[mt.Namespace().String()] = fmt.Sprintf("collect response from %s exceeded the cap", pluginKey)
+	}
+	return errs
+}
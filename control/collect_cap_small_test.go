@@ -0,0 +1,99 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func collectedMetrics(data ...interface{}) []core.Metric {
+	mts := make([]core.Metric, len(data))
+	for i, d := range data {
+		mts[i] = plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", string(rune('a'+i))), Data_: d}
+	}
+	return mts
+}
+
+func TestCapCollectResponse(t *testing.T) {
+	Convey("A collect response within the cap is accepted", t, func() {
+		c := New(GetDefaultConfig(), MaxCollectResponse(3, 0))
+		mts, err := c.capCollectResponse("collector:mock:1", "task", collectedMetrics(1, 2, 3))
+		So(err, ShouldBeNil)
+		So(len(mts), ShouldEqual, 3)
+	})
+	Convey("Metrics past the metric cap are dropped", t, func() {
+		c := New(GetDefaultConfig(), MaxCollectResponse(2, 0))
+		mts, err := c.capCollectResponse("collector:mock:1", "task", collectedMetrics(1, 2, 3, 4))
+		So(len(mts), ShouldEqual, 2)
+		So(err, ShouldNotBeNil)
+		So(err.Namespace, ShouldEqual, "/intel/mock/c")
+	})
+	Convey("Collect responses are not capped by default", t, func() {
+		c := New(GetDefaultConfig())
+		mts, err := c.capCollectResponse("collector:mock:1", "task", collectedMetrics(1, 2, 3, 4))
+		So(err, ShouldBeNil)
+		So(len(mts), ShouldEqual, 4)
+	})
+}
+
+// tooLargeCollectorClient rejects every collect response as too large, as
+// clients do for responses past the max collect response size.
+type tooLargeCollectorClient struct {
+	fakeCollectorClient
+}
+
+func (c *tooLargeCollectorClient) CollectMetrics([]core.Metric) ([]core.Metric, error) {
+	return nil, client.ErrCollectResponseTooLarge
+}
+
+func TestCollectResponseTooLarge(t *testing.T) {
+	Convey("A collect response rejected by the client as too large fails each metric", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		mt := addFakeCollector(c, "mock", &tooLargeCollectorClient{})
+		pool, err := c.pluginRunner.AvailablePlugins().getPool("collector:mock:1")
+		So(err, ShouldBeNil)
+		emitter := &recordingEmitter{}
+		for _, ap := range pool.Plugins() {
+			ap.(*availablePlugin).emitter = emitter
+		}
+
+		metrics, errs := c.CollectMetrics([]core.Metric{mt}, time.Now().Add(time.Second), "task", nil)
+		So(metrics, ShouldBeEmpty)
+		So(len(errs), ShouldEqual, 1)
+		me, ok := errs[0].(*core.MetricError)
+		So(ok, ShouldBeTrue)
+		So(me.Namespace, ShouldEqual, mt.Namespace().String())
+		So(len(emitter.events), ShouldEqual, 1)
+		capped, ok := emitter.events[0].(*control_event.CollectResponseCappedEvent)
+		So(ok, ShouldBeTrue)
+		So(capped.Dropped, ShouldEqual, 1)
+	})
+}
@@ -0,0 +1,116 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeCollectorClient returns the requested metrics, or err, after delay,
+// counting the collections.
+type fakeCollectorClient struct {
+	delay time.Duration
+	err   error
+	calls int
+}
+
+func (c *fakeCollectorClient) SetKey() error                                   { return nil }
+func (c *fakeCollectorClient) Ping() error                                     { return nil }
+func (c *fakeCollectorClient) Kill(string) error                               { return nil }
+func (c *fakeCollectorClient) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) { return nil, nil }
+func (c *fakeCollectorClient) GetMetricTypes(plugin.ConfigType) ([]core.Metric, error) {
+	return nil, nil
+}
+func (c *fakeCollectorClient) CollectMetrics(mts []core.Metric) ([]core.Metric, error) {
+	c.calls++
+	time.Sleep(c.delay)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return mts, nil
+}
+
+// addFakeCollector catalogs a metric of a collector with the name and runs
+// an instance of the collector using the client.
+func addFakeCollector(c *pluginControl, name string, cli client.PluginCollectorClient) core.Metric {
+	lp := &loadedPlugin{
+		Type:         plugin.CollectorPluginType,
+		Meta:         plugin.PluginMeta{Name: name, Version: 1},
+		ConfigPolicy: cpolicy.New(),
+	}
+	mt := plugin.MetricType{Namespace_: core.NewNamespace("intel", name, "foo"), Version_: 1}
+	So(c.metricCatalog.AddLoadedMetricType(lp, mt), ShouldBeNil)
+	ap := newFakeAvailablePlugin(plugin.CollectorPluginType, name, 1, cli)
+	addPool(c.pluginRunner.AvailablePlugins(), lp.Key(), ap)
+	return mt
+}
+
+// goroutinesSettle returns whether the number of goroutines falls to n.
+func goroutinesSettle(n int) bool {
+	for i := 0; i < 100; i++ {
+		if runtime.NumGoroutine() <= n {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestCollectFanIn(t *testing.T) {
+	Convey("The metrics of every collector are gathered", t, func() {
+		c := New(GetDefaultConfig())
+		mts := []core.Metric{
+			addFakeCollector(c, "fast", &fakeCollectorClient{}),
+			addFakeCollector(c, "slow", &fakeCollectorClient{delay: 20 * time.Millisecond}),
+		}
+		before := runtime.NumGoroutine()
+		metrics, metricErrs, errs := c.collectMetrics(nil, mts, time.Time{}, "task", nil)
+		So(errs, ShouldBeEmpty)
+		So(metricErrs, ShouldBeEmpty)
+		So(len(metrics), ShouldEqual, 2)
+		So(goroutinesSettle(before), ShouldBeTrue)
+	})
+	Convey("A failing collector fails the collection without leaking goroutines", t, func() {
+		c := New(GetDefaultConfig())
+		failed := errors.New("collector failed")
+		mts := []core.Metric{
+			addFakeCollector(c, "ok", &fakeCollectorClient{delay: 20 * time.Millisecond}),
+			addFakeCollector(c, "failing", &fakeCollectorClient{err: failed}),
+		}
+		before := runtime.NumGoroutine()
+		metrics, _, errs := c.collectMetrics(nil, mts, time.Time{}, "task", nil)
+		So(metrics, ShouldBeNil)
+		So(len(errs), ShouldEqual, 1)
+		So(errs[0].Error(), ShouldEqual, failed.Error())
+		So(goroutinesSettle(before), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,142 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+)
+
+// MaxBatchSize is the PluginControlOpt which splits a collection of more
+// than size metrics from a collector into sub-batches of at most size
+// metrics, collected in parallel across the members of the collector's pool.
+// Only collections from pools of more than one member of a plugin which is
+// not exclusive and routes least recently used are split, as the other
+// routing strategies keep state for each task or config in a member.  A size
+// of zero, the default, disables splitting.
+func MaxBatchSize(size int) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.pluginRunner.AvailablePlugins().maxBatchSize = size
+	}
+}
+
+// splitBatch returns the sub-batches the metrics are collected from the pool
+// in, which is a single batch of all the metrics when the collection is not
+// split.
+func (ap *availablePlugins) splitBatch(pool strategy.Pool, selected strategy.AvailablePlugin, mts []core.Metric) [][]core.Metric {
+	size := ap.maxBatchSize
+	if size <= 0 || len(mts) <= size || pool.Count() < 2 || selected.Exclusive() ||
+		pool.Strategy().String() != "least-recently-used" {
+		return [][]core.Metric{mts}
+	}
+	batches := make([][]core.Metric, 0, (len(mts)+size-1)/size)
+	for len(mts) > size {
+		batches = append(batches, mts[:size])
+		mts = mts[size:]
+	}
+	return append(batches, mts)
+}
+
+// collectBatches collects the sub-batches in parallel.  The selected plugin
+// collects the first sub-batch and the remaining sub-batches are spread
+// over the other members of the pool, least recently used first.  A member
+// given more than one sub-batch collects them one after the other.  The pool
+// must be read locked by the caller.
+func (ap *availablePlugins) collectBatches(pool strategy.Pool, pluginKey string, selected strategy.AvailablePlugin, batches [][]core.Metric, taskID string) ([]core.Metric, error) {
+	members := batchMembers(pool, selected.(*availablePlugin), len(batches))
+	assigned := make([][][]core.Metric, len(members))
+	for i, b := range batches {
+		assigned[i%len(members)] = append(assigned[i%len(members)], b)
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		metrics []core.Metric
+		nerrs   plugin.NamespaceErrors
+		failed  error
+	)
+	for i, m := range members {
+		wg.Add(1)
+		go func(m *availablePlugin, batches [][]core.Metric) {
+			defer wg.Done()
+			for _, b := range batches {
+				m.acquire()
+				mts, err := ap.collectFrom(m, pluginKey, b, taskID)
+				mu.Lock()
+				metrics = append(metrics, mts...)
+				if partial, ok := err.(plugin.NamespaceErrors); ok {
+					if nerrs == nil {
+						nerrs = plugin.NamespaceErrors{}
+					}
+					for ns, e := range partial {
+						nerrs[ns] = e
+					}
+				} else if err != nil && failed == nil {
+					failed = err
+				}
+				mu.Unlock()
+			}
+		}(m, assigned[i])
+	}
+	wg.Wait()
+
+	if failed != nil {
+		return nil, failed
+	}
+	if nerrs != nil {
+		return metrics, nerrs
+	}
+	return metrics, nil
+}
+
+// batchMembers returns up to n members of the pool to collect sub-batches
+// from, starting with the selected plugin followed by the others least
+// recently used first.
+func batchMembers(pool strategy.Pool, selected *availablePlugin, n int) []*availablePlugin {
+	var others []*availablePlugin
+	for _, p := range pool.Plugins() {
+		if a, ok := p.(*availablePlugin); ok && a != selected {
+			others = append(others, a)
+		}
+	}
+	sort.Sort(byLastHit(others))
+	members := append([]*availablePlugin{selected}, others...)
+	if len(members) > n {
+		members = members[:n]
+	}
+	return members
+}
+
+type byLastHit []*availablePlugin
+
+func (b byLastHit) Len() int      { return len(b) }
+func (b byLastHit) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byLastHit) Less(i, j int) bool {
+	li, lj := b[i].LastHit(), b[j].LastHit()
+	if li.Equal(lj) {
+		return b[i].id < b[j].id
+	}
+	return li.Before(lj)
+}
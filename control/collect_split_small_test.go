@@ -0,0 +1,133 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// batchRecordingClient records the size of each batch it collects.
+type batchRecordingClient struct {
+	fakeCollectorClient
+	sizes []int
+}
+
+func (c *batchRecordingClient) CollectMetrics(mts []core.Metric) ([]core.Metric, error) {
+	c.sizes = append(c.sizes, len(mts))
+	return c.fakeCollectorClient.CollectMetrics(mts)
+}
+
+// addSplitCollector runs a pool of instances of the collector, one for each
+// of the clients, and returns n metrics to collect from it.
+func addSplitCollector(c *pluginControl, exclusive bool, n int, clis ...*batchRecordingClient) (string, []core.Metric) {
+	key := "collector:split:1"
+	aps := make([]strategy.AvailablePlugin, len(clis))
+	for i, cli := range clis {
+		ap := newFakeAvailablePlugin(plugin.CollectorPluginType, "split", 1, cli)
+		ap.meta = plugin.PluginMeta{Name: "split", Version: 1, Exclusive: exclusive}
+		aps[i] = ap
+	}
+	addPool(c.pluginRunner.AvailablePlugins(), key, aps...)
+	mts := make([]core.Metric, n)
+	for i := range mts {
+		mts[i] = plugin.MetricType{Namespace_: core.NewNamespace("intel", "split", strconv.Itoa(i)), Version_: 1}
+	}
+	return key, mts
+}
+
+func TestMaxBatchSize(t *testing.T) {
+	Convey("A large batch is split across the members of the pool", t, func() {
+		c := New(GetDefaultConfig(), MaxBatchSize(2))
+		a, b := &batchRecordingClient{}, &batchRecordingClient{}
+		key, mts := addSplitCollector(c, false, 5, a, b)
+		metrics, err := c.pluginRunner.AvailablePlugins().collectMetrics(key, mts, "task")
+		So(err, ShouldBeNil)
+		So(metrics, ShouldHaveLength, 5)
+		So(len(a.sizes), ShouldBeGreaterThan, 0)
+		So(len(b.sizes), ShouldBeGreaterThan, 0)
+		So(len(a.sizes)+len(b.sizes), ShouldEqual, 3)
+		for _, s := range append(a.sizes, b.sizes...) {
+			So(s, ShouldBeLessThanOrEqualTo, 2)
+		}
+	})
+	Convey("A batch within the max size is collected in one call", t, func() {
+		c := New(GetDefaultConfig(), MaxBatchSize(5))
+		a, b := &batchRecordingClient{}, &batchRecordingClient{}
+		key, mts := addSplitCollector(c, false, 5, a, b)
+		_, err := c.pluginRunner.AvailablePlugins().collectMetrics(key, mts, "task")
+		So(err, ShouldBeNil)
+		So(append(a.sizes, b.sizes...), ShouldResemble, []int{5})
+	})
+	Convey("A batch from an exclusive plugin is not split", t, func() {
+		c := New(GetDefaultConfig(), MaxBatchSize(2))
+		a := &batchRecordingClient{}
+		key, mts := addSplitCollector(c, true, 5, a)
+		_, err := c.pluginRunner.AvailablePlugins().collectMetrics(key, mts, "task")
+		So(err, ShouldBeNil)
+		So(a.sizes, ShouldResemble, []int{5})
+	})
+	Convey("A batch is not split by default", t, func() {
+		c := New(GetDefaultConfig())
+		a, b := &batchRecordingClient{}, &batchRecordingClient{}
+		key, mts := addSplitCollector(c, false, 5, a, b)
+		_, err := c.pluginRunner.AvailablePlugins().collectMetrics(key, mts, "task")
+		So(err, ShouldBeNil)
+		So(append(a.sizes, b.sizes...), ShouldResemble, []int{5})
+	})
+	Convey("A failed sub-batch fails the collection", t, func() {
+		c := New(GetDefaultConfig(), MaxBatchSize(2))
+		a := &batchRecordingClient{}
+		b := &batchRecordingClient{fakeCollectorClient: fakeCollectorClient{err: errors.New("boom")}}
+		key, mts := addSplitCollector(c, false, 4, a, b)
+		metrics, err := c.pluginRunner.AvailablePlugins().collectMetrics(key, mts, "task")
+		So(err, ShouldNotBeNil)
+		So(metrics, ShouldBeNil)
+	})
+}
+
+func TestByLastHit(t *testing.T) {
+	Convey("Members are ordered by when they were last hit while being hit", t, func() {
+		a := &availablePlugin{id: 1}
+		b := &availablePlugin{id: 2}
+		b.hit()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				b.hit()
+			}
+		}()
+		members := []*availablePlugin{b, a}
+		sort.Sort(byLastHit(members))
+		<-done
+		So(members, ShouldResemble, []*availablePlugin{a, b})
+	})
+}
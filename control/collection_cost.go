@@ -0,0 +1,74 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sort"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// ExpensiveCollectorsFirst is the PluginControlOpt which makes CollectMetrics
+// start the calls to collectors hinting an expensive collection cost before
+// the calls to the others, so the expensive collectors have the most time
+// before the collection deadline.  Calls are started in no particular order
+// by default.
+func ExpensiveCollectorsFirst(enabled bool) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.expensiveCollectorsFirst = enabled
+	}
+}
+
+// collectOrder returns the plugin keys of the grouped metric types in the
+// order their collectors should be called.  When expensiveFirst is set the
+// keys are ordered by descending collection cost and then by key.
+func collectOrder(pmts map[string]metricTypes, expensiveFirst bool) []string {
+	keys := make([]string, 0, len(pmts))
+	for key := range pmts {
+		keys = append(keys, key)
+	}
+	if expensiveFirst {
+		sort.Sort(byCollectionCost{keys: keys, pmts: pmts})
+	}
+	return keys
+}
+
+type byCollectionCost struct {
+	keys []string
+	pmts map[string]metricTypes
+}
+
+func (b byCollectionCost) Len() int      { return len(b.keys) }
+func (b byCollectionCost) Swap(i, j int) { b.keys[i], b.keys[j] = b.keys[j], b.keys[i] }
+func (b byCollectionCost) Less(i, j int) bool {
+	ci, cj := b.cost(b.keys[i]), b.cost(b.keys[j])
+	if ci != cj {
+		return ci > cj
+	}
+	return b.keys[i] < b.keys[j]
+}
+
+func (b byCollectionCost) cost(key string) core.CollectionCost {
+	lp := b.pmts[key].plugin
+	if lp == nil {
+		return core.UnknownCollectionCost
+	}
+	return lp.Meta.CollectionCost
+}
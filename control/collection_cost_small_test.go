@@ -0,0 +1,60 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func costedPlugin(cost core.CollectionCost) *loadedPlugin {
+	return &loadedPlugin{Meta: plugin.PluginMeta{CollectionCost: cost}}
+}
+
+func TestCollectOrder(t *testing.T) {
+	pmts := map[string]metricTypes{
+		"collector:a:1": {plugin: costedPlugin(core.CheapCollectionCost)},
+		"collector:b:1": {plugin: costedPlugin(core.ExpensiveCollectionCost)},
+		"collector:c:1": {plugin: costedPlugin(core.UnknownCollectionCost)},
+		"collector:d:1": {plugin: costedPlugin(core.ExpensiveCollectionCost)},
+	}
+	Convey("Expensive collectors are ordered first", t, func() {
+		keys := collectOrder(pmts, true)
+		So(keys, ShouldResemble, []string{"collector:b:1", "collector:d:1", "collector:a:1", "collector:c:1"})
+	})
+	Convey("All collectors are returned when not ordering", t, func() {
+		So(len(collectOrder(pmts, false)), ShouldEqual, 4)
+	})
+}
+
+func TestMetricTypeCollectionCost(t *testing.T) {
+	Convey("The collection cost of a metric type is hinted by its plugin", t, func() {
+		mt := &metricType{Plugin: costedPlugin(core.ExpensiveCollectionCost)}
+		So(mt.CollectionCost(), ShouldEqual, core.ExpensiveCollectionCost)
+		So(mt.CollectionCost().String(), ShouldEqual, "expensive")
+		So((&metricType{}).CollectionCost(), ShouldEqual, core.UnknownCollectionCost)
+	})
+}
@@ -69,14 +69,14 @@ type pluginConfigItem struct {
 //         UnmarshalJSON method in this same file needs to be modified to
 //         match the field mapping that is defined here
 type Config struct {
-	MaxRunningPlugins int               `json:"max_running_plugins"yaml:"max_running_plugins"`
-	PluginTrust       int               `json:"plugin_trust_level"yaml:"plugin_trust_level"`
-	AutoDiscoverPath  string            `json:"auto_discover_path"yaml:"auto_discover_path"`
-	KeyringPaths      string            `json:"keyring_paths"yaml:"keyring_paths"`
-	CacheExpiration   jsonutil.Duration `json:"cache_expiration"yaml:"cache_expiration"`
-	Plugins           *pluginConfig     `json:"plugins"yaml:"plugins"`
-	ListenAddr        string            `json:"listen_addr,omitempty"yaml:"listen_addr"`
-	ListenPort        int               `json:"listen_port,omitempty"yaml:"listen_port"`
+	MaxRunningPlugins int               `json:"max_running_plugins" yaml:"max_running_plugins"`
+	PluginTrust       int               `json:"plugin_trust_level" yaml:"plugin_trust_level"`
+	AutoDiscoverPath  string            `json:"auto_discover_path" yaml:"auto_discover_path"`
+	KeyringPaths      string            `json:"keyring_paths" yaml:"keyring_paths"`
+	CacheExpiration   jsonutil.Duration `json:"cache_expiration" yaml:"cache_expiration"`
+	Plugins           *pluginConfig     `json:"plugins" yaml:"plugins"`
+	ListenAddr        string            `json:"listen_addr,omitempty" yaml:"listen_addr"`
+	ListenPort        int               `json:"listen_port,omitempty" yaml:"listen_port"`
 }
 
 const (
@@ -128,7 +128,7 @@ func GetDefaultConfig() *Config {
 		PluginTrust:       defaultPluginTrust,
 		AutoDiscoverPath:  defaultAutoDiscoverPath,
 		KeyringPaths:      defaultKeyringPaths,
-		CacheExpiration:   jsonutil.Duration{defaultCacheExpiration},
+		CacheExpiration:   jsonutil.Duration{Duration: defaultCacheExpiration},
 		Plugins:           newPluginConfig(),
 	}
 }
@@ -0,0 +1,54 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// PreviewMetricConfig returns the config a subscription to the metric with
+// the config would use, with the global plugin config merged and the defaults
+// of the metric's policy applied, along with the errors subscribing would
+// report.  Neither the cataloged metric nor the given config are modified.
+func (p *pluginControl) PreviewMetricConfig(ns core.Namespace, version int, cd *cdata.ConfigDataNode) (*cdata.ConfigDataNode, []serror.SnapError) {
+	f := map[string]interface{}{
+		SubscriptionErrorNamespaceField: ns.String(),
+		"version":                       version,
+	}
+	m, err := p.metricCatalog.Get(ns, version)
+	if err != nil {
+		return nil, []serror.SnapError{newSubscriptionError(MetricResolutionError, err, f)}
+	}
+	typ, err := core.ToPluginType(m.Plugin.TypeName())
+	if err != nil {
+		return nil, []serror.SnapError{serror.New(err, f)}
+	}
+
+	config := cdata.NewNode()
+	if cd != nil {
+		for k, v := range cd.Table() {
+			config.AddItem(k, v)
+		}
+	}
+	config.ReverseMerge(p.Config.Plugins.getPluginConfigDataNode(typ, m.Plugin.Name(), m.Plugin.Version()))
+	return processMetricConfig(m, config, f)
+}
@@ -0,0 +1,84 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPreviewMetricConfig(t *testing.T) {
+	Convey("Given a metric requiring a limit with a default unit", t, func() {
+		cfg := GetDefaultConfig()
+		cfg.Plugins.All.AddItem("host", ctypes.ConfigValueStr{Value: "localhost"})
+		c := New(cfg)
+		limit, err := cpolicy.NewIntegerRule("limit", true)
+		So(err, ShouldBeNil)
+		unit, err := cpolicy.NewStringRule("unit", false, "ms")
+		So(err, ShouldBeNil)
+		node := cpolicy.NewPolicyNode()
+		node.Add(limit, unit)
+		lp := newCollector("tunable")
+		lp.ConfigPolicy.Add([]string{"intel", "tunable", "foo"}, node)
+		ns := core.NewNamespace("intel", "tunable", "foo")
+		So(c.metricCatalog.AddLoadedMetricType(lp, plugin.MetricType{Namespace_: ns, Version_: 1}), ShouldBeNil)
+
+		Convey("a valid config is previewed with the defaults and global config", func() {
+			cd := cdata.NewNode()
+			cd.AddItem("limit", ctypes.ConfigValueInt{Value: 10})
+			config, serrs := c.PreviewMetricConfig(ns, 1, cd)
+			So(serrs, ShouldBeEmpty)
+			So(config.Table(), ShouldResemble, map[string]ctypes.ConfigValue{
+				"limit": ctypes.ConfigValueInt{Value: 10},
+				"unit":  ctypes.ConfigValueStr{Value: "ms"},
+				"host":  ctypes.ConfigValueStr{Value: "localhost"},
+			})
+
+			Convey("without modifying the given config or the cataloged metric", func() {
+				So(cd.Table(), ShouldResemble, map[string]ctypes.ConfigValue{
+					"limit": ctypes.ConfigValueInt{Value: 10},
+				})
+				m, err := c.metricCatalog.Get(ns, 1)
+				So(err, ShouldBeNil)
+				So(m.Config(), ShouldBeNil)
+			})
+		})
+		Convey("an invalid config is reported", func() {
+			_, serrs := c.PreviewMetricConfig(ns, 1, nil)
+			So(len(serrs), ShouldEqual, 1)
+			So(serrs[0].Fields()[SubscriptionErrorCategoryField], ShouldEqual, ConfigPolicyError)
+			So(serrs[0].Error(), ShouldContainSubstring, "limit")
+		})
+		Convey("an unknown metric is reported", func() {
+			_, serrs := c.PreviewMetricConfig(core.NewNamespace("intel", "tunable", "bar"), 1, nil)
+			So(len(serrs), ShouldEqual, 1)
+			So(serrs[0].Fields()[SubscriptionErrorCategoryField], ShouldEqual, MetricResolutionError)
+		})
+	})
+}
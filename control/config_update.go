@@ -0,0 +1,224 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrNotSubscribed - error message when the config of a metric is
+	// updated for a task which is not subscribed to the metric's plugin
+	ErrNotSubscribed = errors.New("task is not subscribed to the plugin providing the metric")
+)
+
+// subscriptionConfigs holds the configs of metrics updated with
+// UpdatePluginConfig, which replace the configs the metrics are collected
+// with for the task.
+type subscriptionConfigs struct {
+	sync.RWMutex
+	// tasks maps a task ID to the updated configs of its metrics by
+	// namespace
+	tasks map[string]map[string]*cdata.ConfigDataNode
+}
+
+func newSubscriptionConfigs() *subscriptionConfigs {
+	return &subscriptionConfigs{
+		tasks: make(map[string]map[string]*cdata.ConfigDataNode),
+	}
+}
+
+// UpdatePluginConfig replaces the config the metric with the namespace is
+// collected with for the task, taking effect from the task's next
+// collection.  The config is validated against the policy of the version of
+// the metric the task is subscribed to as it is when subscribing, and a
+// config which fails validation leaves the current config in place.  The
+// task stays subscribed throughout so the plugin's pool, and its running
+// plugins, are untouched.
+func (p *pluginControl) UpdatePluginConfig(taskID string, ns []string, cd *cdata.ConfigDataNode) serror.SnapError {
+	namespace := core.NewNamespace(ns...)
+	f := map[string]interface{}{
+		"task-id":                       taskID,
+		SubscriptionErrorNamespaceField: namespace.String(),
+	}
+	if !p.Started {
+		return serror.New(ErrControllerNotStarted, f)
+	}
+	m, serr := p.subscribedMetric(taskID, namespace)
+	if serr != nil {
+		serr.SetFields(f)
+		return serr
+	}
+	f["version"] = m.Version()
+	// the config is validated as a copy so neither the caller's config
+	// nor the metric in the catalog is modified
+	config := cdata.NewNode()
+	if cd != nil {
+		config.Merge(cd)
+	}
+	typ, err := core.ToPluginType(m.Plugin.TypeName())
+	if err != nil {
+		return serror.New(err, f)
+	}
+	config.ReverseMerge(p.Config.Plugins.getPluginConfigDataNode(typ, m.Plugin.Name(), m.Plugin.Version()))
+	config, serrs := processMetricConfig(m, config, f)
+	if len(serrs) > 0 {
+		return serrs[0]
+	}
+	if serrs := p.validateConfigWithPlugin(m.Plugin, config); len(serrs) > 0 {
+		return serrs[0]
+	}
+	p.subscriptionConfigs.set(taskID, namespace.String(), config)
+	return nil
+}
+
+// subscribedMetric returns the latest version of the metric with the
+// namespace whose plugin the task is subscribed to.
+func (p *pluginControl) subscribedMetric(taskID string, ns core.Namespace) (*metricType, serror.SnapError) {
+	mts, err := p.metricCatalog.GetVersions(ns)
+	if err != nil {
+		return nil, serror.New(err)
+	}
+	var subscribed *metricType
+	for _, m := range mts {
+		if subscribed != nil && subscribed.Version() >= m.Version() {
+			continue
+		}
+		if p.subscribedTo(taskID, m.Plugin.Key()) {
+			subscribed = m
+		}
+	}
+	if subscribed == nil {
+		return nil, serror.New(ErrNotSubscribed)
+	}
+	return subscribed, nil
+}
+
+// subscribedTo returns whether the task is subscribed to the pool of the
+// plugin with the key.
+func (p *pluginControl) subscribedTo(taskID, key string) bool {
+	pool, err := p.pluginRunner.AvailablePlugins().getPool(key)
+	if err != nil || pool == nil {
+		return false
+	}
+	for _, sub := range pool.Subscriptions() {
+		if sub.TaskID == taskID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *subscriptionConfigs) set(taskID, ns string, cd *cdata.ConfigDataNode) {
+	s.Lock()
+	defer s.Unlock()
+	configs, ok := s.tasks[taskID]
+	if !ok {
+		configs = make(map[string]*cdata.ConfigDataNode)
+		s.tasks[taskID] = configs
+	}
+	configs[ns] = cd
+}
+
+// apply returns the metrics with the configs updated for the task in place
+// of their own.  The metrics are returned as they are when the task has no
+// updated configs.
+func (s *subscriptionConfigs) apply(taskID string, mts []core.Metric) []core.Metric {
+	s.RLock()
+	defer s.RUnlock()
+	configs, ok := s.tasks[taskID]
+	if !ok {
+		return mts
+	}
+	out := make([]core.Metric, len(mts))
+	for i, mt := range mts {
+		out[i] = mt
+		if cd, ok := configs[mt.Namespace().String()]; ok {
+			out[i] = metricWithConfig(mt, cd)
+		}
+	}
+	return out
+}
+
+// forget drops the configs updated for the task.
+func (s *subscriptionConfigs) forget(taskID string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.tasks, taskID)
+}
+
+func metricWithConfig(m core.Metric, cd *cdata.ConfigDataNode) core.Metric {
+	return plugin.MetricType{
+		Namespace_:          m.Namespace(),
+		Version_:            m.Version(),
+		LastAdvertisedTime_: m.LastAdvertisedTime(),
+		Config_:             cd,
+		Data_:               m.Data(),
+		Tags_:               m.Tags(),
+		Description_:        m.Description(),
+		Unit_:               m.Unit(),
+		Timestamp_:          m.Timestamp(),
+	}
+}
+
+// controlConfigKeys are the metric config keys interpreted by control rather
+// than by the plugins, which are not passed to the plugins.
+var controlConfigKeys = []string{
+	CollectIfMetricConfigKey,
+	CollectIfAboveConfigKey,
+	TagFilterConfigKey,
+}
+
+// withoutConfigKeys returns the metrics with the keys dropped from their
+// configs.  The configs of the metrics passed are left unchanged.
+func withoutConfigKeys(mts []core.Metric, keys ...string) []core.Metric {
+	out := make([]core.Metric, len(mts))
+	for i, mt := range mts {
+		out[i] = mt
+		if mt.Config() == nil {
+			continue
+		}
+		table := mt.Config().Table()
+		var stripped map[string]ctypes.ConfigValue
+		for _, k := range keys {
+			if _, ok := table[k]; !ok {
+				continue
+			}
+			if stripped == nil {
+				stripped = make(map[string]ctypes.ConfigValue, len(table))
+				for tk, v := range table {
+					stripped[tk] = v
+				}
+			}
+			delete(stripped, k)
+		}
+		if stripped != nil {
+			out[i] = metricWithConfig(mt, cdata.FromTable(stripped))
+		}
+	}
+	return out
+}
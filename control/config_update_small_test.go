@@ -0,0 +1,149 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// configRecordingClient records the config of the metrics it collects.
+type configRecordingClient struct {
+	fakeCollectorClient
+	config *cdata.ConfigDataNode
+}
+
+func (c *configRecordingClient) CollectMetrics(mts []core.Metric) ([]core.Metric, error) {
+	c.config = mts[0].Config()
+	return mts, nil
+}
+
+// addConfigurableCollector runs a collector whose metric requires the
+// integer config value "limit".
+func addConfigurableCollector(c *pluginControl, cli *configRecordingClient) core.Metric {
+	rule, err := cpolicy.NewIntegerRule("limit", true)
+	So(err, ShouldBeNil)
+	node := cpolicy.NewPolicyNode()
+	node.Add(rule)
+	lp := &loadedPlugin{
+		Type:         plugin.CollectorPluginType,
+		Meta:         plugin.PluginMeta{Name: "tunable", Version: 1},
+		ConfigPolicy: cpolicy.New(),
+	}
+	lp.ConfigPolicy.Add([]string{"intel", "tunable", "foo"}, node)
+	mt := plugin.MetricType{Namespace_: core.NewNamespace("intel", "tunable", "foo"), Version_: 1}
+	So(c.metricCatalog.AddLoadedMetricType(lp, mt), ShouldBeNil)
+	ap := newFakeAvailablePlugin(plugin.CollectorPluginType, "tunable", 1, cli)
+	addPool(c.pluginRunner.AvailablePlugins(), lp.Key(), ap)
+	return mt
+}
+
+func configWithLimit(limit int) *cdata.ConfigDataNode {
+	cd := cdata.NewNode()
+	cd.AddItem("limit", ctypes.ConfigValueInt{Value: limit})
+	return cd
+}
+
+func TestUpdatePluginConfig(t *testing.T) {
+	ns := []string{"intel", "tunable", "foo"}
+	Convey("Metrics are collected with an updated config from the next collection", t, func() {
+		c := New(GetDefaultConfig(), CacheExpiration(time.Nanosecond))
+		c.Started = true
+		cli := &configRecordingClient{}
+		mt := addConfigurableCollector(c, cli)
+		subscribePool(c, "collector:tunable:1", "task", strategy.BoundSubscriptionType)
+		So(c.UpdatePluginConfig("task", ns, configWithLimit(5)), ShouldBeNil)
+		_, errs := c.CollectMetrics([]core.Metric{mt}, time.Now().Add(time.Second), "task", nil)
+		So(errs, ShouldBeEmpty)
+		So(cli.config, ShouldNotBeNil)
+		So(cli.config.Table()["limit"], ShouldResemble, ctypes.ConfigValueInt{Value: 5})
+	})
+	Convey("A config failing validation leaves the current config in place", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		addConfigurableCollector(c, &configRecordingClient{})
+		subscribePool(c, "collector:tunable:1", "task", strategy.BoundSubscriptionType)
+		So(c.UpdatePluginConfig("task", ns, configWithLimit(5)), ShouldBeNil)
+		serr := c.UpdatePluginConfig("task", ns, cdata.NewNode())
+		So(serr, ShouldNotBeNil)
+		So(serr.Fields()[SubscriptionErrorCategoryField], ShouldEqual, ConfigPolicyError)
+		cd := c.subscriptionConfigs.tasks["task"]["/intel/tunable/foo"]
+		So(cd.Table()["limit"], ShouldResemble, ctypes.ConfigValueInt{Value: 5})
+	})
+	Convey("Updating the config leaves the metric in the catalog untouched", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		addConfigurableCollector(c, &configRecordingClient{})
+		subscribePool(c, "collector:tunable:1", "task", strategy.BoundSubscriptionType)
+		m, err := c.metricCatalog.Get(core.NewNamespace(ns...), 1)
+		So(err, ShouldBeNil)
+		So(c.UpdatePluginConfig("task", ns, cdata.NewNode()), ShouldNotBeNil)
+		So(c.UpdatePluginConfig("task", ns, configWithLimit(5)), ShouldBeNil)
+		So(m.Config(), ShouldBeNil)
+	})
+	Convey("The config is validated against the version the task is subscribed to", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		addConfigurableCollector(c, &configRecordingClient{})
+		lp := &loadedPlugin{
+			Type:         plugin.CollectorPluginType,
+			Meta:         plugin.PluginMeta{Name: "tunable", Version: 2},
+			ConfigPolicy: cpolicy.New(),
+		}
+		mt := plugin.MetricType{Namespace_: core.NewNamespace(ns...), Version_: 2}
+		So(c.metricCatalog.AddLoadedMetricType(lp, mt), ShouldBeNil)
+		subscribePool(c, "collector:tunable:1", "task", strategy.BoundSubscriptionType)
+		serr := c.UpdatePluginConfig("task", ns, cdata.NewNode())
+		So(serr, ShouldNotBeNil)
+		So(serr.Fields()["version"], ShouldEqual, 1)
+	})
+	Convey("The config can only be updated for a subscribed task", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		addConfigurableCollector(c, &configRecordingClient{})
+		serr := c.UpdatePluginConfig("task", ns, configWithLimit(5))
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, ErrNotSubscribed.Error())
+	})
+	Convey("Updated configs are dropped when the task unsubscribes", t, func() {
+		s := newSubscriptionConfigs()
+		s.set("task", "/intel/tunable/foo", configWithLimit(5))
+		s.forget("task")
+		So(s.tasks, ShouldBeEmpty)
+	})
+	Convey("Metrics without an updated config are collected as they are", t, func() {
+		s := newSubscriptionConfigs()
+		mts := []core.Metric{metricWithConfig(deltaMetrics(1)[0], nil)}
+		So(s.apply("task", mts), ShouldResemble, mts)
+		s.set("task", "/intel/tunable/foo", configWithLimit(5))
+		So(s.apply("task", mts)[0].Config(), ShouldBeNil)
+	})
+}
@@ -28,6 +28,8 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -39,6 +41,8 @@ import (
 	"github.com/intelsdi-x/gomit"
 
 	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	"github.com/intelsdi-x/snap/control/strategy"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
@@ -48,6 +52,7 @@ import (
 	"github.com/intelsdi-x/snap/grpc/controlproxy/rpc"
 	"github.com/intelsdi-x/snap/pkg/aci"
 	"github.com/intelsdi-x/snap/pkg/psigning"
+	"golang.org/x/net/context"
 )
 
 const (
@@ -69,8 +74,53 @@ var (
 
 	// ErrControllerNotStarted - error message when the Controller was not started
 	ErrControllerNotStarted = errors.New("Must start Controller before use")
+
+	// ErrControllerDraining - error message when the Controller is stopping
+	// gracefully and accepts no new subscriptions or plugins
+	ErrControllerDraining = errors.New("Controller is stopping and accepts no new subscriptions or plugins")
+
+	// ErrSwapRolledBack - error message when swapping plugins failed to unload
+	// the plugin swapped out and the plugin swapped in was unloaded again
+	ErrSwapRolledBack = errors.New("Swap rolled back after failing to unload plugin")
+)
+
+const (
+	// SubscriptionErrorCategoryField is the field of an error returned when
+	// validating a subscription holding the category of the error
+	SubscriptionErrorCategoryField = "subscription-error-category"
+	// SubscriptionErrorNamespaceField is the field of an error returned
+	// when validating a subscription holding the offending metric namespace
+	SubscriptionErrorNamespaceField = "namespace"
+	// SubscriptionErrorConfigKeyField is the field of a config policy error
+	// returned when validating a subscription holding the offending key
+	SubscriptionErrorConfigKeyField = "config-key"
+
+	// MetricResolutionError is the category of errors for metrics which
+	// could not be found in the metric catalog
+	MetricResolutionError = "metric-resolution"
+	// PluginResolutionError is the category of errors for plugins which
+	// are not loaded
+	PluginResolutionError = "plugin-resolution"
+	// ConfigPolicyError is the category of errors for config rejected by a
+	// plugin's config policy or by the plugin itself
+	ConfigPolicyError = "config-policy"
+	// ProviderMismatchError is the category of errors for metrics which
+	// resolve to a plugin that is not the loaded plugin providing them
+	ProviderMismatchError = "provider-mismatch"
 )
 
+// newSubscriptionError returns an error of the category for a subscription.
+func newSubscriptionError(category string, err error, fields map[string]interface{}) serror.SnapError {
+	f := map[string]interface{}{SubscriptionErrorCategoryField: category}
+	for k, v := range fields {
+		f[k] = v
+	}
+	if ke, ok := err.(*cpolicy.KeyError); ok {
+		f[SubscriptionErrorConfigKeyField] = ke.Key
+	}
+	return serror.New(err, f)
+}
+
 type executablePlugins []plugin.ExecutablePlugin
 
 type pluginControl struct {
@@ -79,8 +129,41 @@ type pluginControl struct {
 	Started        bool
 	Config         *Config
 
-	autodiscoverPaths []string
-	eventManager      *gomit.EventController
+	autodiscoverPaths   []string
+	eventManager        *gomit.EventController
+	eventBuffer         *eventBuffer
+	collectBatcher      *collectBatcher
+	staleness           *stalenessTracker
+	delta               *deltaTracker
+	eventDebounce       time.Duration
+	fallbacks           *fallbackPlugins
+	minCollectIntervals *minCollectIntervals
+	remotes             *remoteControls
+	subscriptionConfigs *subscriptionConfigs
+	subscribedMetrics   *subscribedMetrics
+	subtrees            *subtreeSubscriptions
+	stateFile           string
+	orderedResults      bool
+	// namespaceIsolation prefixes the namespaces of collector metrics with
+	// the plugin name
+	namespaceIsolation bool
+	// provenanceTags tags collected metrics with the plugin they were
+	// collected from
+	provenanceTags bool
+	// expensiveCollectorsFirst starts calls to expensive collectors first
+	expensiveCollectorsFirst bool
+	// partialCollection returns the metrics collected from the other
+	// collectors when collection from a collector fails
+	partialCollection bool
+	// lazyCollectorSpawn starts collectors on demand when collecting
+	lazyCollectorSpawn bool
+	lazySpawnMutex     sync.Mutex
+	// poolJitter is the most collection from a contended pool is delayed by
+	poolJitter time.Duration
+	// draining is set while StopGraceful waits for calls in flight to
+	// return, refusing new subscriptions and loads
+	draining      bool
+	drainingMutex sync.RWMutex
 
 	pluginManager  managesPlugins
 	metricCatalog  catalogsMetrics
@@ -89,6 +172,18 @@ type pluginControl struct {
 
 	pluginTrust  int
 	keyringFiles []string
+	// pluginRoot is the directory plugins must be within to be loaded
+	pluginRoot string
+	// keyringWatchInterval is how often the keyring files are checked for
+	// changes
+	keyringWatchInterval time.Duration
+	keyringWatcher       *keyringWatcher
+	// pidDir is the directory the pids of plugin processes are recorded in
+	pidDir string
+	// shutdownOrder is the order Stop stops running plugins in by type
+	shutdownOrder []core.PluginType
+	// maxCollectMetrics caps the metrics accepted from a single collection
+	maxCollectMetrics int
 }
 
 type runsPlugins interface {
@@ -99,18 +194,33 @@ type runsPlugins interface {
 	SetEmitter(gomit.Emitter)
 	SetMetricCatalog(catalogsMetrics)
 	SetPluginManager(managesPlugins)
+	SetClientTimeouts(client.Timeouts)
+	SetMaxCollectResponseBytes(int)
 	Monitor() *monitor
 	runPlugin(*pluginDetails) error
+	spawnPlugin(*pluginDetails) (*availablePlugin, error)
+	HandleGomitEvent(gomit.Event)
 }
 
 type managesPlugins interface {
 	teardown()
 	get(string) (*loadedPlugin, error)
 	all() map[string]*loadedPlugin
+	setSigned(*loadedPlugin, bool) bool
 	LoadPlugin(*pluginDetails, gomit.Emitter) (*loadedPlugin, serror.SnapError)
+	LoadPluginWithContext(context.Context, *pluginDetails, gomit.Emitter) (*loadedPlugin, serror.SnapError)
+	InspectPlugin(*pluginDetails) (*plugin.PluginMeta, []core.Metric, serror.SnapError)
 	UnloadPlugin(core.Plugin) (*loadedPlugin, serror.SnapError)
+	restorePlugin(*loadedPlugin) serror.SnapError
 	SetMetricCatalog(catalogsMetrics)
-	GenerateArgs(pluginPath string) plugin.Arg
+	SetPluginTransport(plugin.TransportType)
+	SetPluginPidDir(string)
+	SetPluginClientTimeouts(client.Timeouts)
+	SetPluginOutput(*pluginOutput)
+	PluginOutput() *pluginOutput
+	newExecutablePlugin(*pluginDetails) (*plugin.ExecutablePlugin, error)
+	SetPluginLogLevel(key string, level string) error
+	GenerateArgs(*pluginDetails) plugin.Arg
 	SetPluginConfig(*pluginConfig)
 }
 
@@ -125,6 +235,8 @@ type catalogsMetrics interface {
 	Fetch(core.Namespace) ([]*metricType, error)
 	Item() (string, []*metricType)
 	Next() bool
+	Walk(func(*metricType) bool)
+	LastModified() time.Time
 	Subscribe([]string, int) error
 	Unsubscribe([]string, int) error
 	GetPlugin(core.Namespace, int) (*loadedPlugin, error)
@@ -140,7 +252,7 @@ type PluginControlOpt func(*pluginControl)
 // MaxRunningPlugins sets the maximum number of plugins to run per pool
 func MaxRunningPlugins(m int) PluginControlOpt {
 	return func(c *pluginControl) {
-		strategy.MaximumRunningPlugins = m
+		c.pluginRunner.AvailablePlugins().maxRunningPlugins = m
 	}
 }
 
@@ -151,6 +263,71 @@ func CacheExpiration(t time.Duration) PluginControlOpt {
 	}
 }
 
+// CollectBatchWindow is the PluginControlOpt which batches requests to
+// collect metrics from the same plugin arriving within the window into a
+// single call, whose results are split between the requests.  A window of
+// zero disables batching.
+func CollectBatchWindow(d time.Duration) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.collectBatcher = nil
+		if d > 0 {
+			c.collectBatcher = newCollectBatcher(d, c.pluginRunner.AvailablePlugins())
+		}
+	}
+}
+
+// PublisherCompression is the PluginControlOpt which enables compressing
+// published content for publishers which accept a content encoding.
+func PublisherCompression(enabled bool) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.pluginRunner.AvailablePlugins().publisherCompression = enabled
+	}
+}
+
+// PublisherChunkSize is the PluginControlOpt which publishes content in
+// chunks of at most size bytes to publishers declaring the chunked content
+// capability.  Content is published to other publishers in one call.  Only
+// native and JSON-RPC publishers accept chunked content, so content published
+// to gRPC publishers is still bound by gRPC's max message size.  A size of
+// zero, the default, disables chunking.
+func PublisherChunkSize(size int) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.pluginRunner.AvailablePlugins().publisherChunkSize = size
+	}
+}
+
+// MetricStalenessWindow is the PluginControlOpt which emits a
+// MetricStaleEvent when a metric previously collected for a task has not
+// been returned for longer than the window.  A window of zero disables
+// staleness detection.
+func MetricStalenessWindow(d time.Duration) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.staleness = nil
+		if d > 0 {
+			c.staleness = newStalenessTracker(d)
+		}
+	}
+}
+
+// EventDebounce is the PluginControlOpt which holds back plugin unload
+// events for the window from the handlers registered with
+// RegisterEventHandler.  A plugin unloaded and loaded again within the window
+// is reported to them with a single PluginReloadedEvent.  A window of zero
+// disables debouncing.
+func EventDebounce(d time.Duration) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.eventDebounce = d
+	}
+}
+
+// PoolStatsInterval is the PluginControlOpt which emits a PoolStatsEvent for
+// each plugin pool every interval.  An interval of zero disables pool stats.
+func PoolStatsInterval(d time.Duration) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.pluginRunner.Monitor().Option(MonitorPoolStatsOption(d))
+	}
+}
+
 // OptSetConfig sets the plugin control configuration.
 func OptSetConfig(cfg *Config) PluginControlOpt {
 	return func(c *pluginControl) {
@@ -159,20 +336,39 @@ func OptSetConfig(cfg *Config) PluginControlOpt {
 	}
 }
 
-// New returns a new pluginControl instance
-func New(cfg *Config) *pluginControl {
-	// construct a slice of options from the input configuration
-	opts := []PluginControlOpt{
+// New returns a new pluginControl instance.  It panics if the plugin runner
+// fails to start, use NewWithError to handle the error instead.
+func New(cfg *Config, opts ...PluginControlOpt) *pluginControl {
+	c, err := NewWithError(cfg, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// NewWithError returns a new pluginControl instance or the error returned
+// starting the plugin runner.
+func NewWithError(cfg *Config, opts ...PluginControlOpt) (*pluginControl, error) {
+	// construct a slice of options from the input configuration followed
+	// by the options provided
+	opts = append([]PluginControlOpt{
 		MaxRunningPlugins(cfg.MaxRunningPlugins),
 		CacheExpiration(cfg.CacheExpiration.Duration),
 		OptSetConfig(cfg),
-	}
+	}, opts...)
 	c := &pluginControl{}
 	c.Config = cfg
+	c.fallbacks = newFallbackPlugins()
+	c.minCollectIntervals = newMinCollectIntervals()
+	c.remotes = newRemoteControls()
+	c.subscriptionConfigs = newSubscriptionConfigs()
+	c.subscribedMetrics = newSubscribedMetrics()
+	c.subtrees = newSubtreeSubscriptions()
 	// Initialize components
 	//
 	// Event Manager
 	c.eventManager = gomit.NewEventController()
+	c.eventBuffer = newEventBuffer(DefaultEventReplayBufferSize)
 
 	controlLogger.WithFields(log.Fields{
 		"_block": "new",
@@ -211,7 +407,7 @@ func New(cfg *Config) *pluginControl {
 	// Start stuff
 	err := c.pluginRunner.Start()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	// apply options
@@ -221,8 +417,10 @@ func New(cfg *Config) *pluginControl {
 	for _, opt := range opts {
 		opt(c)
 	}
+	// the replay buffer keeps the events as the registered handlers see them
+	c.RegisterEventHandler("control.replay", c.eventBuffer)
 
-	return c
+	return c, nil
 }
 
 func (p *pluginControl) Name() string {
@@ -230,6 +428,9 @@ func (p *pluginControl) Name() string {
 }
 
 func (p *pluginControl) RegisterEventHandler(name string, h gomit.Handler) error {
+	if p.eventDebounce > 0 {
+		h = newDebouncingHandler(p.eventDebounce, h)
+	}
 	return p.eventManager.RegisterHandler(name, h)
 }
 
@@ -241,6 +442,16 @@ func (p *pluginControl) Start() error {
 		"_block": "start",
 	}).Info("control started")
 
+	// kill plugins left running by an unclean shutdown before starting any
+	if reaped, serr := p.ReapOrphanedPlugins(); serr != nil {
+		controlLogger.WithFields(serr.Fields()).Warn(serr)
+	} else if reaped > 0 {
+		controlLogger.WithFields(log.Fields{
+			"_block": "start",
+			"reaped": reaped,
+		}).Info("reaped orphaned plugins")
+	}
+
 	//Autodiscover
 	if p.Config.AutoDiscoverPath != "" {
 		controlLogger.WithFields(log.Fields{
@@ -266,61 +477,22 @@ func (p *pluginControl) Start() error {
 					"autodiscoverpath": pa,
 				}).Fatal(err)
 			}
-			for _, file := range files {
-				if file.IsDir() {
-					controlLogger.WithFields(log.Fields{
-						"_block":           "start",
-						"autodiscoverpath": pa,
-					}).Warning("Ignoring subdirectory: ", file.Name())
-					continue
-				}
-				// Ignore tasks files (JSON and YAML)
-				fname := strings.ToLower(file.Name())
-				if strings.HasSuffix(fname, ".json") || strings.HasSuffix(fname, ".yaml") || strings.HasSuffix(fname, ".yml") {
-					controlLogger.WithFields(log.Fields{
-						"_block":           "start",
-						"autodiscoverpath": pa,
-					}).Warning("Ignoring JSON/Yaml file: ", file.Name())
-					continue
-				}
-				if strings.HasSuffix(file.Name(), ".aci") || !(strings.HasSuffix(file.Name(), ".asc")) {
-					rp, err := core.NewRequestedPlugin(path.Join(fullPath, file.Name()))
-					if err != nil {
-						controlLogger.WithFields(log.Fields{
-							"_block":           "start",
-							"autodiscoverpath": pa,
-							"plugin":           file,
-						}).Error(err)
-					}
-					signatureFile := file.Name() + ".asc"
-					if _, err := os.Stat(path.Join(fullPath, signatureFile)); err == nil {
-						err = rp.ReadSignatureFile(path.Join(fullPath, signatureFile))
-						if err != nil {
-							controlLogger.WithFields(log.Fields{
-								"_block":           "start",
-								"autodiscoverpath": pa,
-								"plugin":           file.Name() + ".asc",
-							}).Error(err)
-						}
-					}
-					pl, err := p.Load(rp)
-					if err != nil {
-						controlLogger.WithFields(log.Fields{
-							"_block":           "start",
-							"autodiscoverpath": fullPath,
-							"plugin":           file,
-						}).Error(err)
-					} else {
-						controlLogger.WithFields(log.Fields{
-							"_block":           "start",
-							"autodiscoverpath": fullPath,
-							"plugin-file-name": file.Name(),
-							"plugin-name":      pl.Name(),
-							"plugin-version":   pl.Version(),
-							"plugin-type":      pl.TypeName(),
-						}).Info("Loading plugin")
-					}
-				}
+			plugins, serrs := p.loadInDependencyOrder(requestedPluginsInDir(fullPath, files))
+			for _, serr := range serrs {
+				controlLogger.WithFields(log.Fields{
+					"_block":           "start",
+					"autodiscoverpath": fullPath,
+				}).WithFields(serr.Fields()).Error(serr)
+			}
+			for _, pl := range plugins {
+				controlLogger.WithFields(log.Fields{
+					"_block":           "start",
+					"autodiscoverpath": fullPath,
+					"plugin-file-name": filepath.Base(pl.PluginPath()),
+					"plugin-name":      pl.Name(),
+					"plugin-version":   pl.Version(),
+					"plugin-type":      pl.TypeName(),
+				}).Info("Loading plugin")
 			}
 		}
 	} else {
@@ -345,15 +517,29 @@ func (p *pluginControl) Start() error {
 		}
 	}()
 
+	if p.keyringWatchInterval > 0 {
+		p.keyringWatcher = newKeyringWatcher(p.keyringWatchInterval, func() []string {
+			return p.keyrings()
+		}, func(e gomit.EventBody) {
+			p.eventManager.Emit(e)
+		})
+		p.keyringWatcher.start()
+	}
+
 	return nil
 }
 
+// Stop stops the controller, killing the running plugins and unloading
+// every plugin.  Calls to plugins in flight fail.
 func (p *pluginControl) Stop() {
 	p.Started = false
 	controlLogger.WithFields(log.Fields{
 		"_block": "stop",
 	}).Info("control stopped")
 
+	// stop running plugins in the shutdown order now no new work is accepted
+	p.stopRunningPlugins()
+
 	// stop runner
 	err := p.pluginRunner.Stop()
 	if err != nil {
@@ -366,18 +552,58 @@ func (p *pluginControl) Stop() {
 		rp.Kill()
 	}
 
+	if p.keyringWatcher != nil {
+		p.keyringWatcher.stop()
+		p.keyringWatcher = nil
+	}
+
 	// unload plugins
 	p.pluginManager.teardown()
 }
 
+// StopGraceful stops the controller as Stop does once the calls to plugins
+// in flight have returned, waiting at most the timeout for them.  No new
+// subscriptions or plugins are accepted while waiting, but the tasks already
+// running keep collecting, processing and publishing.
+func (p *pluginControl) StopGraceful(timeout time.Duration) {
+	p.setDraining(true)
+	defer p.setDraining(false)
+	select {
+	case <-p.pluginRunner.AvailablePlugins().inFlight.drained():
+	case <-time.After(timeout):
+		controlLogger.WithFields(log.Fields{
+			"_block":          "stop-graceful",
+			"timeout":         timeout,
+			"in-flight-calls": len(p.InFlightCalls()),
+		}).Warn("calls to plugins still in flight after timeout, stopping")
+	}
+	p.Stop()
+}
+
+func (p *pluginControl) setDraining(draining bool) {
+	p.drainingMutex.Lock()
+	defer p.drainingMutex.Unlock()
+	p.draining = draining
+}
+
+func (p *pluginControl) isDraining() bool {
+	p.drainingMutex.RLock()
+	defer p.drainingMutex.RUnlock()
+	return p.draining
+}
+
 // Load is the public method to load a plugin into
 // the LoadedPlugins array and issue an event when
-// successful.
+// successful.  The plugin's path is made absolute
+// with symlinks resolved before it is loaded.
 func (p *pluginControl) Load(rp *core.RequestedPlugin) (core.CatalogedPlugin, serror.SnapError) {
-	f := map[string]interface{}{
-		"_block": "load",
-	}
+	return p.LoadWithContext(context.Background(), rp)
+}
 
+// LoadWithContext loads the plugin like Load.  If the context is done before
+// the load completes the plugin process is killed, nothing is loaded and the
+// context's error is returned.
+func (p *pluginControl) LoadWithContext(ctx context.Context, rp *core.RequestedPlugin) (core.CatalogedPlugin, serror.SnapError) {
 	details, serr := p.returnPluginDetails(rp)
 	if serr != nil {
 		return nil, serr
@@ -385,6 +611,15 @@ func (p *pluginControl) Load(rp *core.RequestedPlugin) (core.CatalogedPlugin, se
 	if details.IsPackage {
 		defer os.RemoveAll(filepath.Dir(details.ExecPath))
 	}
+	return p.loadDetails(ctx, details)
+}
+
+// loadDetails loads the plugin whose details, including the outcome of
+// verifying its signature, have been resolved.
+func (p *pluginControl) loadDetails(ctx context.Context, details *pluginDetails) (core.CatalogedPlugin, serror.SnapError) {
+	f := map[string]interface{}{
+		"_block": "load",
+	}
 
 	controlLogger.WithFields(f).Info("plugin load called")
 	if !p.Started {
@@ -393,8 +628,13 @@ func (p *pluginControl) Load(rp *core.RequestedPlugin) (core.CatalogedPlugin, se
 		controlLogger.WithFields(f).Error(se)
 		return nil, se
 	}
+	if p.isDraining() {
+		se := serror.New(ErrControllerDraining, f)
+		controlLogger.WithFields(f).Error(se)
+		return nil, se
+	}
 
-	pl, se := p.pluginManager.LoadPlugin(details, p.eventManager)
+	pl, se := p.pluginManager.LoadPluginWithContext(ctx, details, p.eventManager)
 	if se != nil {
 		return nil, se
 	}
@@ -405,6 +645,8 @@ func (p *pluginControl) Load(rp *core.RequestedPlugin) (core.CatalogedPlugin, se
 		pl.Details.ExecPath = ""
 	}
 
+	p.resubscribeSubtrees()
+
 	// defer sending event
 	event := &control_event.LoadPluginEvent{
 		Name:    pl.Meta.Name,
@@ -412,39 +654,366 @@ func (p *pluginControl) Load(rp *core.RequestedPlugin) (core.CatalogedPlugin, se
 		Type:    int(pl.Meta.Type),
 		Signed:  pl.Details.Signed,
 	}
+	p.saveState()
 	defer p.eventManager.Emit(event)
 	return pl, nil
 }
 
+// InspectPlugin reads the metadata and advertised metric types of the plugin
+// at path without loading it.  The plugin is stopped before returning.
+func (p *pluginControl) InspectPlugin(path string) (*plugin.PluginMeta, []core.Metric, serror.SnapError) {
+	rp, err := core.NewRequestedPlugin(path)
+	if err != nil {
+		return nil, nil, serror.New(err, map[string]interface{}{"path": path})
+	}
+	details, serr := p.returnPluginDetails(rp)
+	if serr != nil {
+		return nil, nil, serr
+	}
+	if details.IsPackage {
+		defer os.RemoveAll(filepath.Dir(details.ExecPath))
+	}
+	controlLogger.WithFields(log.Fields{
+		"_block": "inspect-plugin",
+		"path":   path,
+	}).Debug("plugin inspect called")
+	return p.pluginManager.InspectPlugin(details)
+}
+
+// LoadDirectory loads every plugin found in dir.  Plugins declaring a
+// dependency on another plugin are loaded after the plugin they depend on.
+// Plugins whose dependencies can not be satisfied, including circular
+// dependencies, are not loaded and an error is returned for each of them.
+func (p *pluginControl) LoadDirectory(dir string) ([]core.CatalogedPlugin, []serror.SnapError) {
+	if !p.Started {
+		return nil, []serror.SnapError{serror.New(ErrControllerNotStarted, map[string]interface{}{"path": dir})}
+	}
+	fullPath, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, []serror.SnapError{serror.New(err, map[string]interface{}{"path": dir})}
+	}
+	files, err := ioutil.ReadDir(fullPath)
+	if err != nil {
+		return nil, []serror.SnapError{serror.New(err, map[string]interface{}{"path": fullPath})}
+	}
+	return p.loadInDependencyOrder(requestedPluginsInDir(fullPath, files))
+}
+
+// requestedPluginsInDir returns a requested plugin for each plugin file in
+// dir.  Subdirectories, task manifests and signature files are skipped.
+func requestedPluginsInDir(dir string, files []os.FileInfo) []*core.RequestedPlugin {
+	var rps []*core.RequestedPlugin
+	for _, file := range files {
+		if file.IsDir() {
+			controlLogger.WithFields(log.Fields{
+				"_block":           "requested-plugins-in-dir",
+				"autodiscoverpath": dir,
+			}).Warning("Ignoring subdirectory: ", file.Name())
+			continue
+		}
+		// Ignore tasks files (JSON and YAML)
+		fname := strings.ToLower(file.Name())
+		if strings.HasSuffix(fname, ".json") || strings.HasSuffix(fname, ".yaml") || strings.HasSuffix(fname, ".yml") {
+			controlLogger.WithFields(log.Fields{
+				"_block":           "requested-plugins-in-dir",
+				"autodiscoverpath": dir,
+			}).Warning("Ignoring JSON/Yaml file: ", file.Name())
+			continue
+		}
+		if !strings.HasSuffix(file.Name(), ".aci") && strings.HasSuffix(file.Name(), ".asc") {
+			continue
+		}
+		rp, err := core.NewRequestedPlugin(path.Join(dir, file.Name()))
+		if err != nil {
+			controlLogger.WithFields(log.Fields{
+				"_block":           "requested-plugins-in-dir",
+				"autodiscoverpath": dir,
+				"plugin":           file.Name(),
+			}).Error(err)
+			continue
+		}
+		if signatureFile := signatureFile(path.Join(dir, file.Name())); signatureFile != "" {
+			err = rp.ReadSignatureFile(signatureFile)
+			if err != nil {
+				controlLogger.WithFields(log.Fields{
+					"_block":           "requested-plugins-in-dir",
+					"autodiscoverpath": dir,
+					"plugin":           signatureFile,
+				}).Error(err)
+			}
+		}
+		rps = append(rps, rp)
+	}
+	return rps
+}
+
+// inspectedPlugin is a plugin requested to be loaded along with the
+// metadata it reported when inspected.
+type inspectedPlugin struct {
+	details *pluginDetails
+	meta    *plugin.PluginMeta
+}
+
+func (ip *inspectedPlugin) key() string {
+	return core.PluginKey(core.PluginType(ip.meta.Type), ip.meta.Name, ip.meta.Version)
+}
+
+// loadInDependencyOrder loads the requested plugins after the plugins they
+// depend on.  Each plugin is inspected to read the plugins it depends on and
+// the order is resolved from their metadata before any is loaded, so each
+// plugin is loaded once.
+func (p *pluginControl) loadInDependencyOrder(rps []*core.RequestedPlugin) ([]core.CatalogedPlugin, []serror.SnapError) {
+	var (
+		loaded    []core.CatalogedPlugin
+		serrs     []serror.SnapError
+		inspected []*inspectedPlugin
+	)
+	for _, rp := range rps {
+		details, serr := p.returnPluginDetails(rp)
+		if serr != nil {
+			serrs = append(serrs, serr)
+			continue
+		}
+		if details.IsPackage {
+			defer os.RemoveAll(filepath.Dir(details.ExecPath))
+		}
+		meta, _, serr := p.pluginManager.InspectPlugin(details)
+		if serr != nil {
+			serrs = append(serrs, serr)
+			continue
+		}
+		inspected = append(inspected, &inspectedPlugin{details: details, meta: meta})
+	}
+	ordered, blocked := p.dependencyOrder(inspected)
+	for _, ip := range ordered {
+		pl, serr := p.loadDetails(context.Background(), ip.details)
+		if serr != nil {
+			serrs = append(serrs, serr)
+			continue
+		}
+		loaded = append(loaded, pl)
+	}
+	for _, ip := range inspected {
+		if serr, ok := blocked[ip]; ok {
+			serrs = append(serrs, dependencyError(serr, blocked))
+		}
+	}
+	return loaded, serrs
+}
+
+// dependencyOrder orders the inspected plugins so each follows the plugins
+// it depends on which are not loaded yet.  Plugins whose dependencies can
+// not be satisfied are returned in blocked with the error of the first
+// missing dependency.
+func (p *pluginControl) dependencyOrder(inspected []*inspectedPlugin) (ordered []*inspectedPlugin, blocked map[*inspectedPlugin]serror.SnapError) {
+	blocked = make(map[*inspectedPlugin]serror.SnapError)
+	pending := inspected
+	for len(pending) > 0 {
+		var next []*inspectedPlugin
+		for _, ip := range pending {
+			if dep, ok := p.unmetDependency(ip, ordered); ok {
+				blocked[ip] = dependencyNotLoadedError(ip.meta, dep)
+				next = append(next, ip)
+				continue
+			}
+			delete(blocked, ip)
+			ordered = append(ordered, ip)
+		}
+		if len(next) == len(pending) {
+			break
+		}
+		pending = next
+	}
+	return ordered, blocked
+}
+
+// unmetDependency returns the first plugin the inspected plugin depends on
+// which is neither loaded nor among the plugins ordered before it.
+func (p *pluginControl) unmetDependency(ip *inspectedPlugin, ordered []*inspectedPlugin) (plugin.PluginRef, bool) {
+	for _, dep := range ip.meta.DependsOn {
+		if _, err := p.pluginManager.get(dep.Key()); err == nil {
+			continue
+		}
+		met := false
+		for _, o := range ordered {
+			if pluginRefMatches(dep.Key(), o.key()) {
+				met = true
+				break
+			}
+		}
+		if !met {
+			return dep, true
+		}
+	}
+	return plugin.PluginRef{}, false
+}
+
+// dependencyError returns a circular dependency error if the dependency
+// recorded in serr leads back to the plugin which failed to load.  Otherwise
+// serr, which describes the missing dependency, is returned unchanged.
+func dependencyError(serr serror.SnapError, blocked map[*inspectedPlugin]serror.SnapError) serror.SnapError {
+	deps := make(map[string]string, len(blocked))
+	for _, b := range blocked {
+		key, ok := b.Fields()["plugin"].(string)
+		if !ok {
+			continue
+		}
+		dependency, ok := b.Fields()["dependency"].(string)
+		if !ok {
+			continue
+		}
+		deps[key] = dependency
+	}
+	start, ok := serr.Fields()["plugin"].(string)
+	if !ok {
+		return serr
+	}
+	chain := []string{start}
+	cur := start
+	for len(chain) <= len(deps) {
+		next := ""
+		for key := range deps {
+			if pluginRefMatches(deps[cur], key) {
+				next = key
+				break
+			}
+		}
+		if next == "" {
+			return serr
+		}
+		chain = append(chain, next)
+		if next == start {
+			se := serror.New(fmt.Errorf("circular plugin dependency: %s", strings.Join(chain, " -> ")))
+			se.SetFields(map[string]interface{}{
+				"plugin":     start,
+				"dependency": deps[start],
+			})
+			return se
+		}
+		cur = next
+	}
+	return serr
+}
+
+// pluginRefMatches returns true if the plugin identified by key satisfies
+// the reference ref.  Both are in the {type}:{name}:{version} form and a
+// version less than 1 in ref matches any version.
+func pluginRefMatches(ref, key string) bool {
+	r := strings.Split(ref, ":")
+	k := strings.Split(key, ":")
+	if len(r) != 3 || len(k) != 3 || r[0] != k[0] || r[1] != k[1] {
+		return false
+	}
+	v, err := strconv.Atoi(r[2])
+	if err != nil {
+		return false
+	}
+	return v < 1 || r[2] == k[2]
+}
+
+// verifySignature validates the signature of the requested plugin as the
+// plugin trust level requires.  A SignatureValidationEvent recording the
+// outcome is emitted whether or not the signature validates.
 func (p *pluginControl) verifySignature(rp *core.RequestedPlugin) (bool, serror.SnapError) {
+	outcome, err := p.signatureOutcome(rp)
+	p.eventManager.Emit(p.signatureValidationEvent(rp.Path(), outcome, err))
+	if err != nil {
+		return false, newLoadError(LoadErrorSignatureInvalid, err)
+	}
+	return outcome == control_event.SignatureValid, nil
+}
+
+// signatureOutcome returns the outcome of validating the signature of the
+// requested plugin and, when it is invalid, the reason.
+func (p *pluginControl) signatureOutcome(rp *core.RequestedPlugin) (string, error) {
 	f := map[string]interface{}{
 		"_block": "verifySignature",
 	}
 	switch p.pluginTrust {
 	case PluginTrustDisabled:
-		return false, nil
+		return control_event.SignatureSkipped, nil
 	case PluginTrustEnabled:
-		err := p.signingManager.ValidateSignature(p.keyringFiles, rp.Path(), rp.Signature())
+		err := p.signingManager.ValidateSignature(p.keyrings(), rp.Path(), rp.Signature())
 		if err != nil {
-			return false, serror.New(err)
+			return control_event.SignatureInvalid, err
 		}
 	case PluginTrustWarn:
 		if rp.Signature() == nil {
 			controlLogger.WithFields(f).Warn("Loading unsigned plugin ", rp.Path())
-			return false, nil
+			return control_event.SignatureSkipped, nil
 		}
-		err := p.signingManager.ValidateSignature(p.keyringFiles, rp.Path(), rp.Signature())
+		err := p.signingManager.ValidateSignature(p.keyrings(), rp.Path(), rp.Signature())
 		if err != nil {
-			return false, serror.New(err)
+			return control_event.SignatureInvalid, err
 		}
 	}
-	return true, nil
+	return control_event.SignatureValid, nil
+}
 
+func (p *pluginControl) signatureValidationEvent(path, outcome string, err error) *control_event.SignatureValidationEvent {
+	e := &control_event.SignatureValidationEvent{
+		Path:       path,
+		Outcome:    outcome,
+		Keyrings:   p.keyrings(),
+		TrustLevel: p.pluginTrust,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// RevalidateSignatures validates the signature of every loaded plugin
+// against the current keyring files and updates whether each is signed.
+// A SignatureInvalidatedEvent is emitted for each plugin which was signed
+// and no longer validates.  If unloadUntrusted is true and plugin trust is
+// enabled those plugins are also unloaded.
+func (p *pluginControl) RevalidateSignatures(unloadUntrusted bool) []serror.SnapError {
+	if !p.Started {
+		return []serror.SnapError{serror.New(ErrControllerNotStarted)}
+	}
+	var serrs []serror.SnapError
+	for _, lp := range p.pluginManager.all() {
+		if lp.Details.Signature == nil {
+			continue
+		}
+		err := p.signingManager.ValidateSignature(p.keyrings(), lp.Details.Path, lp.Details.Signature)
+		wasSigned := p.pluginManager.setSigned(lp, err == nil)
+		if err == nil || !wasSigned {
+			continue
+		}
+		f := map[string]interface{}{
+			"plugin-name":    lp.Name(),
+			"plugin-version": lp.Version(),
+			"plugin-type":    lp.TypeName(),
+		}
+		controlLogger.WithFields(log.Fields{
+			"_block": "revalidate-signatures",
+		}).WithFields(f).Warn("plugin signature no longer valid: ", err)
+		se := serror.New(err)
+		se.SetFields(f)
+		serrs = append(serrs, se)
+		p.eventManager.Emit(&control_event.SignatureInvalidatedEvent{
+			Name:    lp.Meta.Name,
+			Version: lp.Meta.Version,
+			Type:    int(lp.Meta.Type),
+			Error:   err.Error(),
+		})
+		if unloadUntrusted && p.pluginTrust == PluginTrustEnabled {
+			if _, serr := p.UnloadForce(lp); serr != nil {
+				serrs = append(serrs, serr)
+			}
+		}
+	}
+	return serrs
 }
 
 func (p *pluginControl) returnPluginDetails(rp *core.RequestedPlugin) (*pluginDetails, serror.SnapError) {
 	details := &pluginDetails{}
-	var serr serror.SnapError
+	serr := p.resolvePluginPath(rp)
+	if serr != nil {
+		return nil, serr
+	}
 	//Check plugin signing
 	details.Signed, serr = p.verifySignature(rp)
 	if serr != nil {
@@ -484,11 +1053,42 @@ func (p *pluginControl) returnPluginDetails(rp *core.RequestedPlugin) (*pluginDe
 	return details, nil
 }
 
+// Unload unloads the plugin unless tasks are subscribed to it which would be
+// left without a plugin, in which case an ErrPluginSubscribed error holding
+// the subscribed tasks is returned.  Subscriptions to the latest version of a
+// plugin are moved to the next loaded version.
 func (p *pluginControl) Unload(pl core.Plugin) (core.CatalogedPlugin, serror.SnapError) {
+	return p.unload(pl, false)
+}
+
+// UnloadForce unloads the plugin even if tasks are subscribed to it.
+func (p *pluginControl) UnloadForce(pl core.Plugin) (core.CatalogedPlugin, serror.SnapError) {
+	return p.unload(pl, true)
+}
+
+func (p *pluginControl) unload(pl core.Plugin, force bool) (core.CatalogedPlugin, serror.SnapError) {
+	if !p.Started {
+		return nil, serror.New(ErrControllerNotStarted)
+	}
+	if !force {
+		if serr := p.checkUnloadSubscriptions(pl); serr != nil {
+			return nil, serr
+		}
+	}
 	up, err := p.pluginManager.UnloadPlugin(pl)
 	if err != nil {
 		return nil, err
 	}
+	if taskIDs, _ := p.PluginSubscriptions(up.Key()); len(taskIDs) > 0 {
+		controlLogger.WithFields(log.Fields{
+			"_block":   "unload",
+			"plugin":   up.Key(),
+			"task-ids": taskIDs,
+		}).Warn("unloaded plugin has task subscriptions")
+	}
+
+	p.resubscribeSubtrees()
+	p.saveState()
 
 	event := &control_event.UnloadPluginEvent{
 		Name:    up.Meta.Name,
@@ -499,10 +1099,43 @@ func (p *pluginControl) Unload(pl core.Plugin) (core.CatalogedPlugin, serror.Sna
 	return up, nil
 }
 
+// UnloadByKey unloads the plugin identified by its {type}:{name}:{version}
+// key as Unload does.
+func (p *pluginControl) UnloadByKey(key string) (core.CatalogedPlugin, serror.SnapError) {
+	f := map[string]interface{}{"plugin-key": key}
+	if _, _, _, err := core.ParsePluginKey(key); err != nil {
+		return nil, serror.New(err, f)
+	}
+	lp, err := p.pluginManager.get(key)
+	if err != nil {
+		return nil, serror.New(err, f)
+	}
+	return p.Unload(lp)
+}
+
+// SwapPlugins loads in and unloads out, refusing the swap with an
+// ErrSwapIncompatible error if in is not compatible with out.
 func (p *pluginControl) SwapPlugins(in *core.RequestedPlugin, out core.CatalogedPlugin) serror.SnapError {
+	_, serr := p.swapPlugins(in, out, false)
+	return serr
+}
+
+// SwapPluginsForce swaps the plugins as SwapPlugins does without checking
+// that in is compatible with out.
+func (p *pluginControl) SwapPluginsForce(in *core.RequestedPlugin, out core.CatalogedPlugin) serror.SnapError {
+	_, serr := p.swapPlugins(in, out, true)
+	return serr
+}
+
+// swapPlugins loads in and unloads out, unloading in again if out cannot be
+// unloaded, and returns the plugin loaded.
+func (p *pluginControl) swapPlugins(in *core.RequestedPlugin, out core.CatalogedPlugin, force bool) (*loadedPlugin, serror.SnapError) {
+	if !p.Started {
+		return nil, serror.New(ErrControllerNotStarted)
+	}
 	details, serr := p.returnPluginDetails(in)
 	if serr != nil {
-		return serr
+		return nil, serr
 	}
 	if details.IsPackage {
 		defer os.RemoveAll(filepath.Dir(details.ExecPath))
@@ -510,7 +1143,7 @@ func (p *pluginControl) SwapPlugins(in *core.RequestedPlugin, out core.Cataloged
 
 	lp, err := p.pluginManager.LoadPlugin(details, p.eventManager)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Make sure plugin types and names are the same
@@ -522,20 +1155,18 @@ func (p *pluginControl) SwapPlugins(in *core.RequestedPlugin, out core.Cataloged
 			"in-name":  lp.Name(),
 			"out-name": out.Name(),
 		})
-		_, err := p.pluginManager.UnloadPlugin(lp)
-		if err != nil {
-			se := serror.New(errors.New("Failed to rollback after error"))
-			se.SetFields(map[string]interface{}{
-				"original-unload-error": serr.Error(),
-				"rollback-unload-error": err.Error(),
-			})
-			return se
+		return nil, p.rollbackSwap(lp, serr)
+	}
+	if !force {
+		if serr := p.swapCompatibility(lp, out); serr != nil {
+			return nil, p.rollbackSwap(lp, serr)
 		}
-		return serr
 	}
 
 	up, err := p.pluginManager.UnloadPlugin(out)
 	if err != nil {
+		// Unloading the plugin swapped in also removes its metrics from
+		// the catalog
 		_, err2 := p.pluginManager.UnloadPlugin(lp)
 		if err2 != nil {
 			se := serror.New(errors.New("Failed to rollback after error"))
@@ -543,10 +1174,25 @@ func (p *pluginControl) SwapPlugins(in *core.RequestedPlugin, out core.Cataloged
 				"original-unload-error": err.Error(),
 				"rollback-unload-error": err2.Error(),
 			})
-			return se
+			return nil, se
 		}
-		return err
+		event := &control_event.SwapPluginsRolledBackEvent{
+			RolledBackPluginName:    lp.Meta.Name,
+			RolledBackPluginVersion: lp.Meta.Version,
+			OutPluginName:           out.Name(),
+			OutPluginVersion:        out.Version(),
+			PluginType:              int(lp.Meta.Type),
+			Error:                   err.Error(),
+		}
+		defer p.eventManager.Emit(event)
+		return nil, serror.New(ErrSwapRolledBack, map[string]interface{}{
+			"original-unload-error": err.Error(),
+			"rolled-back-plugin":    lp.Key(),
+			"plugin-key":            pluginKey(out),
+		})
 	}
+	p.resubscribeSubtrees()
+	p.saveState()
 
 	event := &control_event.SwapPluginsEvent{
 		LoadedPluginName:      lp.Meta.Name,
@@ -557,7 +1203,22 @@ func (p *pluginControl) SwapPlugins(in *core.RequestedPlugin, out core.Cataloged
 	}
 	defer p.eventManager.Emit(event)
 
-	return nil
+	return lp, nil
+}
+
+// rollbackSwap unloads the plugin loaded by a swap which failed with serr,
+// returning serr or the error unloading the plugin.
+func (p *pluginControl) rollbackSwap(lp *loadedPlugin, serr serror.SnapError) serror.SnapError {
+	_, err := p.pluginManager.UnloadPlugin(lp)
+	if err != nil {
+		se := serror.New(errors.New("Failed to rollback after error"))
+		se.SetFields(map[string]interface{}{
+			"original-unload-error": serr.Error(),
+			"rollback-unload-error": err.Error(),
+		})
+		return se
+	}
+	return serr
 }
 
 // MatchQueryToNamespaces performs the process of matching the 'ns' with namespaces of all cataloged metrics
@@ -582,7 +1243,12 @@ func (p *pluginControl) ExpandWildcards(ns core.Namespace) ([]core.Namespace, se
 }
 
 func (p *pluginControl) ValidateDeps(mts []core.Metric, plugins []core.SubscribedPlugin) []serror.SnapError {
-	var serrs []serror.SnapError
+	mts, subtrees := splitSubtrees(mts)
+	mts, remote := p.remotes.split(p.metricCatalog, mts)
+	serrs := p.remotes.validateDeps(remote)
+	for _, st := range subtrees {
+		serrs = append(serrs, p.validateSubtree(st)...)
+	}
 	for _, mt := range mts {
 		errs := p.validateMetricTypeSubscription(mt, mt.Config())
 		if len(errs) > 0 {
@@ -616,10 +1282,9 @@ func (p *pluginControl) validatePluginSubscription(pl core.SubscribedPlugin) []s
 		"_block": "validate-plugin-subscription",
 		"plugin": fmt.Sprintf("%s:%d", pl.Name(), pl.Version()),
 	}).Info(fmt.Sprintf("validating dependencies for plugin %s:%d", pl.Name(), pl.Version()))
-	lp, err := p.pluginManager.get(fmt.Sprintf("%s:%s:%d", pl.TypeName(), pl.Name(), pl.Version()))
+	lp, err := p.pluginManager.get(pluginKey(pl))
 	if err != nil {
-		se := serror.New(fmt.Errorf("Plugin not found: type(%s) name(%s) version(%d)", pl.TypeName(), pl.Name(), pl.Version()))
-		se.SetFields(map[string]interface{}{
+		se := newSubscriptionError(PluginResolutionError, fmt.Errorf("Plugin not found: type(%s) name(%s) version(%d)", pl.TypeName(), pl.Name(), pl.Version()), map[string]interface{}{
 			"name":    pl.Name(),
 			"version": pl.Version(),
 			"type":    pl.TypeName(),
@@ -633,12 +1298,61 @@ func (p *pluginControl) validatePluginSubscription(pl core.SubscribedPlugin) []s
 		_, errs := ncd.Process(pl.Config().Table())
 		if errs != nil && errs.HasErrors() {
 			for _, e := range errs.Errors() {
-				se := serror.New(e)
-				se.SetFields(map[string]interface{}{"name": pl.Name(), "version": pl.Version()})
+				se := newSubscriptionError(ConfigPolicyError, e, map[string]interface{}{"name": pl.Name(), "version": pl.Version()})
 				serrs = append(serrs, se)
 			}
 		}
 	}
+	return append(serrs, p.validateConfigWithPlugin(lp, pl.Config())...)
+}
+
+// validateConfigWithPlugin has a running instance of the plugin validate the
+// config if the plugin validates its own config.  It is best effort: plugins
+// are only started once a task subscribes to them, so the config is not
+// validated when the plugin has no running instances, nor when the plugin is
+// served over gRPC, which has no call to validate config.  The plugin's
+// config policy is still applied in both cases.
+func (p *pluginControl) validateConfigWithPlugin(lp *loadedPlugin, cfg *cdata.ConfigDataNode) []serror.SnapError {
+	f := map[string]interface{}{
+		"_block":  "validate-config-with-plugin",
+		"name":    lp.Name(),
+		"version": lp.Version(),
+		"type":    lp.TypeName(),
+	}
+	pool, serr := p.pluginRunner.AvailablePlugins().getPool(lp.Key())
+	if serr != nil || pool == nil {
+		controlLogger.WithFields(f).Debug("plugin has no running instances to validate config")
+		return nil
+	}
+	var cli client.PluginConfigValidatorClient
+	pool.RLock()
+	for _, ap := range pool.Plugins() {
+		if a, ok := ap.(*availablePlugin); ok {
+			cli, ok = a.client.(client.PluginConfigValidatorClient)
+			if ok {
+				break
+			}
+		}
+	}
+	pool.RUnlock()
+	if cli == nil {
+		controlLogger.WithFields(f).Debug("plugin client cannot validate config")
+		return nil
+	}
+	if cfg == nil {
+		cfg = cdata.NewNode()
+	}
+	msgs, err := cli.ValidateConfig(plugin.ConfigType{ConfigDataNode: cfg})
+	if err != nil {
+		// plugins built before config validation was added do not
+		// implement the call
+		controlLogger.WithFields(f).Debug(err)
+		return nil
+	}
+	var serrs []serror.SnapError
+	for _, msg := range msgs {
+		serrs = append(serrs, newSubscriptionError(ConfigPolicyError, errors.New(msg), map[string]interface{}{"name": lp.Name(), "version": lp.Version()}))
+	}
 	return serrs
 }
 
@@ -650,19 +1364,27 @@ func (p *pluginControl) validateMetricTypeSubscription(mt core.RequestedMetric,
 		"version":   mt.Version(),
 	}).Info("subscription called on metric")
 
+	f := map[string]interface{}{
+		SubscriptionErrorNamespaceField: mt.Namespace().String(),
+		"version":                       mt.Version(),
+	}
 	m, err := p.metricCatalog.Get(mt.Namespace(), mt.Version())
 
+	if (err != nil || m == nil) && skipOptionalMetric(mt, "validate-metric-subscription") {
+		return nil
+	}
 	if err != nil {
-		serrs = append(serrs, serror.New(err, map[string]interface{}{
-			"name":    mt.Namespace().String(),
-			"version": mt.Version(),
+		serrs = append(serrs, newSubscriptionError(MetricResolutionError, err, map[string]interface{}{
+			"name":                          mt.Namespace().String(),
+			SubscriptionErrorNamespaceField: mt.Namespace().String(),
+			"version":                       mt.Version(),
 		}))
 		return serrs
 	}
 
 	// No metric found return error.
 	if m == nil {
-		serrs = append(serrs, serror.New(fmt.Errorf("no metric found cannot subscribe: (%s) version(%d)", mt.Namespace(), mt.Version())))
+		serrs = append(serrs, newSubscriptionError(MetricResolutionError, fmt.Errorf("no metric found cannot subscribe: (%s) version(%d)", mt.Namespace(), mt.Version()), f))
 		return serrs
 	}
 
@@ -680,30 +1402,56 @@ func (p *pluginControl) validateMetricTypeSubscription(mt core.RequestedMetric,
 		m.config = p.Config.Plugins.getPluginConfigDataNode(typ, m.Plugin.Name(), m.Plugin.Version())
 	}
 
+	m.config, serrs = processMetricConfig(m, m.config, f)
+	if len(serrs) > 0 {
+		return serrs
+	}
+	return p.validateConfigWithPlugin(m.Plugin, m.config)
+}
+
+// processMetricConfig validates the config of a subscription to the metric
+// and applies the defaults of the metric's policy.  It returns the processed
+// config, or the config as given if it is not valid.  The table of the given
+// config is modified.
+func processMetricConfig(m *metricType, config *cdata.ConfigDataNode, f map[string]interface{}) (*cdata.ConfigDataNode, []serror.SnapError) {
+	var serrs []serror.SnapError
+	if _, errs := sampleRatePolicy.Process(config.Table()); errs.HasErrors() {
+		for _, e := range errs.Errors() {
+			serrs = append(serrs, newSubscriptionError(ConfigPolicyError, e, f))
+		}
+		return config, serrs
+	}
+	if err := validateTagFilter(config.Table()); err != nil {
+		serrs = append(serrs, newSubscriptionError(ConfigPolicyError, err, f))
+		return config, serrs
+	}
+
 	// When a metric is added to the MetricCatalog, the policy of rules defined by the plugin is added to the metric's policy.
 	// If no rules are defined for a metric, we set the metric's policy to an empty ConfigPolicyNode.
 	// Checking m.policy for nil will not work, we need to check if rules are nil.
 	if m.policy.HasRules() {
-		if m.Config() == nil {
-			serrs = append(serrs, serror.New(fmt.Errorf("Policy defined for metric, (%s) version (%d), but no config defined in manifest", mt.Namespace(), mt.Version())))
-			return serrs
+		if config == nil {
+			serrs = append(serrs, newSubscriptionError(ConfigPolicyError, fmt.Errorf("Policy defined for metric, (%s) version (%d), but no config defined in manifest", m.Namespace(), m.Version()), f))
+			return config, serrs
 		}
-		ncdTable, errs := m.policy.Process(m.Config().Table())
+		ncdTable, errs := m.policy.Process(config.Table())
 		if errs != nil && errs.HasErrors() {
 			for _, e := range errs.Errors() {
-				serrs = append(serrs, serror.New(e))
+				serrs = append(serrs, newSubscriptionError(ConfigPolicyError, e, f))
 			}
-			return serrs
+			return config, serrs
 		}
-		m.config = cdata.FromTable(*ncdTable)
+		config = cdata.FromTable(*ncdTable)
 	}
 
-	return serrs
+	return config, serrs
 }
 
 type gatheredPlugin struct {
 	plugin           core.Plugin
 	subscriptionType strategy.SubscriptionType
+	// metrics are the metrics requested of the plugin
+	metrics []core.Metric
 }
 
 func (p *pluginControl) gatherCollectors(mts []core.Metric) ([]gatheredPlugin, []serror.SnapError) {
@@ -721,6 +1469,9 @@ func (p *pluginControl) gatherCollectors(mts []core.Metric) ([]gatheredPlugin, [
 		// If the version provided is <1 we will get the latest
 		// plugin for the given metric.
 		m, err := p.metricCatalog.Get(mt.Namespace(), mt.Version())
+		if err != nil && skipOptionalMetric(mt, "gather") {
+			continue
+		}
 		if err != nil {
 			serrs = append(serrs, serror.New(err, map[string]interface{}{
 				"name":    mt.Namespace().String(),
@@ -732,9 +1483,21 @@ func (p *pluginControl) gatherCollectors(mts []core.Metric) ([]gatheredPlugin, [
 		if mt.Version() < 1 {
 			subType = strategy.UnboundSubscriptionType
 		}
-		colPlugins[fmt.Sprintf("%s:%d", m.Plugin.Key(), subType)] = gatheredPlugin{
+		key := fmt.Sprintf("%s:%d", m.Plugin.Key(), subType)
+		colPlugins[key] = gatheredPlugin{
 			plugin:           m.Plugin,
 			subscriptionType: subType,
+			metrics:          append(colPlugins[key].metrics, mt),
+		}
+		// A metric with a fallback plugin also subscribes to the fallback
+		// so it is running when the primary plugin is unavailable.
+		if fallback, ok := p.fallbackPlugin(mt.Namespace()); ok {
+			key := fmt.Sprintf("%s:%d", fallback.Key(), strategy.BoundSubscriptionType)
+			colPlugins[key] = gatheredPlugin{
+				plugin:           fallback,
+				subscriptionType: strategy.BoundSubscriptionType,
+				metrics:          colPlugins[key].metrics,
+			}
 		}
 	}
 	if len(serrs) > 0 {
@@ -751,99 +1514,180 @@ func (p *pluginControl) gatherCollectors(mts []core.Metric) ([]gatheredPlugin, [
 	return plugins, nil
 }
 
+// SubscribeDeps subscribes the task to the collectors of the metrics and to
+// the plugins.  A metric whose namespace ends in "*" subscribes the task to
+// the collectors of all metrics under the rest of the namespace, including
+// those of plugins loaded later, until it is unsubscribed with UnsubscribeDeps.
 func (p *pluginControl) SubscribeDeps(taskID string, mts []core.Metric, plugins []core.Plugin) []serror.SnapError {
-	var serrs []serror.SnapError
+	if !p.Started {
+		return []serror.SnapError{serror.New(ErrControllerNotStarted)}
+	}
+	if p.isDraining() {
+		return []serror.SnapError{serror.New(ErrControllerDraining)}
+	}
+	var subscribed []subscribedPool
+	mts, subtrees := splitSubtrees(mts)
+	mts, remote := p.remotes.split(p.metricCatalog, mts)
+	if errs := p.verifyMetricProviders(mts); len(errs) > 0 {
+		return errs
+	}
+	if errs := p.remotes.subscribeDeps(taskID, remote); len(errs) > 0 {
+		return errs
+	}
+	// abort undoes the subscriptions made so far so that a failed call
+	// does not leave the task partially subscribed.
+	abort := func(serrs ...serror.SnapError) []serror.SnapError {
+		p.rollbackSubscriptions(taskID, subscribed)
+		p.remotes.unsubscribeDeps(taskID, remote)
+		return serrs
+	}
 	if len(mts) != 0 {
 		collectors, errs := p.gatherCollectors(mts)
 		if len(errs) > 0 {
-			serrs = append(serrs, errs...)
+			return abort(errs...)
 		}
 
 		for _, gc := range collectors {
-			pool, err := p.pluginRunner.AvailablePlugins().getOrCreatePool(fmt.Sprintf("%s:%s:%d", gc.plugin.TypeName(), gc.plugin.Name(), gc.plugin.Version()))
-			if err != nil {
-				serrs = append(serrs, serror.New(err))
-				return serrs
+			sp, serr := p.subscribeCollector(taskID, gc)
+			if sp.pool != nil {
+				subscribed = append(subscribed, sp)
 			}
-			pool.Subscribe(taskID, gc.subscriptionType)
-			if pool.Eligible() {
-				err = p.verifyPlugin(gc.plugin.(*loadedPlugin))
-				if err != nil {
-					serrs = append(serrs, serror.New(err))
-					return serrs
-				}
-				err = p.pluginRunner.runPlugin(gc.plugin.(*loadedPlugin).Details)
-				if err != nil {
-					serrs = append(serrs, serror.New(err))
-					return serrs
-				}
-			}
-			serr := p.sendPluginSubscriptionEvent(taskID, gc.plugin)
 			if serr != nil {
-				serrs = append(serrs, serr)
+				return abort(serr)
 			}
 		}
 	}
+	for _, st := range subtrees {
+		sps, serr := p.subscribeSubtree(taskID, st)
+		subscribed = append(subscribed, sps...)
+		if serr != nil {
+			return abort(serr)
+		}
+	}
 	for _, sub := range plugins {
 		// pools are created statically, not with keys like "publisher:foo:-1"
 		// here we check to see if the version of the incoming plugin is -1, and
 		// if it is, we look up the latest in loaded plugins, and use that key to
 		// create the pool.
 		if sub.Version() < 1 {
-			latest, err := p.pluginManager.get(fmt.Sprintf("%s:%s:%d", sub.TypeName(), sub.Name(), sub.Version()))
+			latest, err := p.pluginManager.get(pluginKey(sub))
 			if err != nil {
-				serrs = append(serrs, serror.New(err))
-				return serrs
+				return abort(serror.New(err))
 			}
 			pool, err := p.pluginRunner.AvailablePlugins().getOrCreatePool(latest.Key())
 			if err != nil {
-				serrs = append(serrs, serror.New(err))
-				return serrs
+				return abort(serror.New(err))
 			}
 			pool.Subscribe(taskID, strategy.UnboundSubscriptionType)
+			subscribed = append(subscribed, subscribedPool{pool: pool, plugin: sub})
 			if pool.Eligible() {
 				err = p.verifyPlugin(latest)
 				if err != nil {
-					serrs = append(serrs, serror.New(err))
-					return serrs
+					return abort(serror.New(err))
 				}
-				err = p.pluginRunner.runPlugin(latest.Details)
+				ap, err := p.pluginRunner.spawnPlugin(latest.Details)
 				if err != nil {
-					serrs = append(serrs, serror.New(err))
-					return serrs
+					return abort(serror.New(err))
 				}
+				subscribed[len(subscribed)-1].started = ap
 			}
 		} else {
-			pool, err := p.pluginRunner.AvailablePlugins().getOrCreatePool(fmt.Sprintf("%s:%s:%d", sub.TypeName(), sub.Name(), sub.Version()))
+			pool, err := p.pluginRunner.AvailablePlugins().getOrCreatePool(pluginKey(sub))
 			if err != nil {
-				serrs = append(serrs, serror.New(err))
-				return serrs
+				return abort(serror.New(err))
 			}
 			pool.Subscribe(taskID, strategy.BoundSubscriptionType)
+			subscribed = append(subscribed, subscribedPool{pool: pool, plugin: sub})
 			if pool.Eligible() {
-				pl, err := p.pluginManager.get(fmt.Sprintf("%s:%s:%d", sub.TypeName(), sub.Name(), sub.Version()))
+				pl, err := p.pluginManager.get(pluginKey(sub))
 				if err != nil {
-					serrs = append(serrs, serror.New(err))
-					return serrs
+					return abort(serror.New(err))
 				}
 				err = p.verifyPlugin(pl)
 				if err != nil {
-					serrs = append(serrs, serror.New(err))
-					return serrs
+					return abort(serror.New(err))
 				}
-				err = p.pluginRunner.runPlugin(pl.Details)
+				ap, err := p.pluginRunner.spawnPlugin(pl.Details)
 				if err != nil {
-					serrs = append(serrs, serror.New(err))
-					return serrs
+					return abort(serror.New(err))
 				}
+				subscribed[len(subscribed)-1].started = ap
 			}
 		}
 		serr := p.sendPluginSubscriptionEvent(taskID, sub)
 		if serr != nil {
-			serrs = append(serrs, serr)
+			return abort(serr)
+		}
+		subscribed[len(subscribed)-1].notified = true
+	}
+	p.subtrees.add(taskID, subtrees)
+	return nil
+}
+
+// subscribeCollector subscribes the task to the pool of the gathered
+// collector, starting the collector if the pool is eligible to grow.  The
+// pool is returned once subscribed to, even if starting the collector fails.
+func (p *pluginControl) subscribeCollector(taskID string, gc gatheredPlugin) (subscribedPool, serror.SnapError) {
+	pool, err := p.pluginRunner.AvailablePlugins().getOrCreatePool(pluginKey(gc.plugin))
+	if err != nil {
+		return subscribedPool{}, serror.New(err)
+	}
+	pool.Subscribe(taskID, gc.subscriptionType)
+	sp := subscribedPool{pool: pool, plugin: gc.plugin}
+	if pool.Eligible() {
+		err = p.verifyPlugin(gc.plugin.(*loadedPlugin))
+		if err != nil {
+			return sp, serror.New(err)
+		}
+		sp.started, err = p.pluginRunner.spawnPlugin(gc.plugin.(*loadedPlugin).Details)
+		if err != nil {
+			return sp, serror.New(err)
+		}
+	}
+	if serr := p.sendPluginSubscriptionEvent(taskID, gc.plugin); serr != nil {
+		return sp, serr
+	}
+	sp.notified = true
+	p.subscribedMetrics.add(taskID, gc.plugin.Name(), gc.metrics)
+	return sp, nil
+}
+
+// subscribedPool records a pool subscribed to by SubscribeDeps, the plugin
+// instance started for the subscription, if any, and whether the
+// subscription event for it was sent.
+type subscribedPool struct {
+	pool     strategy.Pool
+	plugin   core.Plugin
+	started  *availablePlugin
+	notified bool
+}
+
+// rollbackSubscriptions unsubscribes the task from the given pools, most
+// recent first, stopping the plugin instances started for the subscriptions
+// and sending an unsubscription event for each subscription which was
+// announced.
+func (p *pluginControl) rollbackSubscriptions(taskID string, subscribed []subscribedPool) {
+	p.subscribedMetrics.forget(taskID)
+	for i := len(subscribed) - 1; i >= 0; i-- {
+		sp := subscribed[i]
+		sp.pool.Unsubscribe(taskID)
+		if sp.started != nil {
+			sp.started.Stop("subscription rolled back")
+			sp.pool.Kill(sp.started.ID(), "subscription rolled back")
+		}
+		if !sp.notified {
+			continue
+		}
+		if serr := p.sendPluginUnsubscriptionEvent(taskID, sp.plugin); serr != nil {
+			controlLogger.WithFields(log.Fields{
+				"_block":         "rollback-subscriptions",
+				"task-id":        taskID,
+				"plugin-name":    sp.plugin.Name(),
+				"plugin-version": sp.plugin.Version(),
+				"plugin-type":    sp.plugin.TypeName(),
+			}).Error(serr)
 		}
 	}
-	return serrs
 }
 
 func (p *pluginControl) verifyPlugin(lp *loadedPlugin) error {
@@ -856,7 +1700,7 @@ func (p *pluginControl) verifyPlugin(lp *loadedPlugin) error {
 		return fmt.Errorf(fmt.Sprintf("Current plugin checksum (%x) does not match checksum when plugin was first loaded (%x).", cs, lp.Details.CheckSum))
 	}
 	if lp.Details.Signed {
-		return p.signingManager.ValidateSignature(p.keyringFiles, lp.Details.Path, lp.Details.Signature)
+		return p.signingManager.ValidateSignature(p.keyrings(), lp.Details.Path, lp.Details.Signature)
 	}
 	return nil
 }
@@ -883,21 +1727,39 @@ func (p *pluginControl) sendPluginSubscriptionEvent(taskID string, pl core.Plugi
 }
 
 func (p *pluginControl) UnsubscribeDeps(taskID string, mts []core.Metric, plugins []core.Plugin) []serror.SnapError {
-	var serrs []serror.SnapError
+	if !p.Started {
+		return []serror.SnapError{serror.New(ErrControllerNotStarted)}
+	}
+	mts, subtrees := splitSubtrees(mts)
+	mts, remote := p.remotes.split(p.metricCatalog, mts)
 	// If no metrics to unsubscribe then skip this section. Avoids errors when
 	// workflow is distributed and each node may not have metrics.
 	if len(mts) > 0 {
 		collectors, errs := p.gatherCollectors(mts)
 		if len(errs) > 0 {
-			serrs = append(serrs, errs...)
+			return errs
 		}
 		for _, gc := range collectors {
 			plugins = append(plugins, gc.plugin)
 		}
 	}
+	if p.staleness != nil {
+		p.staleness.forget(taskID)
+	}
+	if p.delta != nil {
+		p.delta.forget(taskID)
+	}
+	p.subscriptionConfigs.forget(taskID)
+	p.subscribedMetrics.forget(taskID)
+	for _, st := range subtrees {
+		for _, pl := range p.subtrees.remove(taskID, st) {
+			plugins = append(plugins, pl)
+		}
+	}
+	serrs := p.remotes.unsubscribeDeps(taskID, remote)
 
 	for _, sub := range plugins {
-		pool, err := p.pluginRunner.AvailablePlugins().getPool(fmt.Sprintf("%s:%s:%d", sub.TypeName(), sub.Name(), sub.Version()))
+		pool, err := p.pluginRunner.AvailablePlugins().getPool(pluginKey(sub))
 		if err != nil {
 			serrs = append(serrs, err)
 			return serrs
@@ -949,6 +1811,18 @@ func (p *pluginControl) PluginCatalog() core.PluginCatalog {
 	return plugins
 }
 
+// PluginsWithCapability returns the loaded plugins which support the
+// capability.
+func (p *pluginControl) PluginsWithCapability(c plugin.Capability) []core.CatalogedPlugin {
+	var plugins []core.CatalogedPlugin
+	for _, lp := range p.pluginManager.all() {
+		if lp.Meta.HasCapability(c) {
+			plugins = append(plugins, lp)
+		}
+	}
+	return plugins
+}
+
 // AvailablePlugins returns pointers to all the running plugins in the pools
 // NOTE: The returned data from this function should be considered constant and read only
 func (p *pluginControl) AvailablePlugins() []core.AvailablePlugin {
@@ -959,13 +1833,76 @@ func (p *pluginControl) AvailablePlugins() []core.AvailablePlugin {
 	return caps
 }
 
+// PoolInfo is a snapshot of a pool of running plugins and how requests are
+// routed to its members.
+type PoolInfo struct {
+	Key               string           `json:"key"`
+	Strategy          string           `json:"strategy"`
+	SubscriptionCount int              `json:"subscription_count"`
+	RestartCount      int              `json:"restart_count"`
+	Members           []PoolMemberInfo `json:"members"`
+}
+
+// PoolMemberInfo is a snapshot of a running plugin in a pool.
+type PoolMemberInfo struct {
+	ID       uint32    `json:"id"`
+	HitCount int       `json:"hit_count"`
+	LastHit  time.Time `json:"last_hit"`
+}
+
+// PoolsInfo returns a snapshot of every pool, ordered by key, and its members,
+// ordered by ID.
+func (p *pluginControl) PoolsInfo() []PoolInfo {
+	aps := p.pluginRunner.AvailablePlugins()
+	aps.RLock()
+	defer aps.RUnlock()
+	pools := make([]PoolInfo, 0, len(aps.table))
+	for key, pool := range aps.table {
+		pi := PoolInfo{
+			Key:               key,
+			SubscriptionCount: pool.SubscriptionCount(),
+		}
+		if pool.Strategy() != nil {
+			pi.Strategy = pool.Strategy().String()
+		}
+		pool.RLock()
+		pi.RestartCount = pool.RestartCount()
+		for _, ap := range pool.Plugins() {
+			pi.Members = append(pi.Members, PoolMemberInfo{
+				ID:       ap.ID(),
+				HitCount: ap.HitCount(),
+				LastHit:  ap.LastHit(),
+			})
+		}
+		pool.RUnlock()
+		sort.Sort(byPoolMemberID(pi.Members))
+		pools = append(pools, pi)
+	}
+	sort.Sort(byPoolInfoKey(pools))
+	return pools
+}
+
+type byPoolInfoKey []PoolInfo
+
+func (b byPoolInfoKey) Len() int           { return len(b) }
+func (b byPoolInfoKey) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byPoolInfoKey) Less(i, j int) bool { return b[i].Key < b[j].Key }
+
+type byPoolMemberID []PoolMemberInfo
+
+func (b byPoolMemberID) Len() int           { return len(b) }
+func (b byPoolMemberID) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byPoolMemberID) Less(i, j int) bool { return b[i].ID < b[j].ID }
+
 // MetricCatalog returns the entire metric catalog
 // NOTE: The returned data from this function should be considered constant and read only
 func (p *pluginControl) MetricCatalog() ([]core.CatalogedMetric, error) {
 	return p.FetchMetrics(core.Namespace{}, 0)
 }
 
-// FetchMetrics returns the metrics which fall under the given namespace
+// FetchMetrics returns the metrics which fall under the given namespace.  A
+// "*" element matches any single namespace element and a "**" element matches
+// zero or more elements.
 // NOTE: The returned data from this function should be considered constant and read only
 func (p *pluginControl) FetchMetrics(ns core.Namespace, version int) ([]core.CatalogedMetric, error) {
 	mts, err := p.metricCatalog.Fetch(ns)
@@ -985,6 +1922,22 @@ func (p *pluginControl) FetchMetrics(ns core.Namespace, version int) ([]core.Cat
 	return cmt, nil
 }
 
+// MetricsByPlugin returns the cataloged metrics grouped by the
+// {type}:{name}:{version} key of the plugin providing them.  Within a plugin
+// metrics are in the order they were cataloged.
+// NOTE: The returned data from this function should be considered constant and read only
+func (p *pluginControl) MetricsByPlugin() map[string][]core.CatalogedMetric {
+	byPlugin := make(map[string][]core.CatalogedMetric)
+	p.metricCatalog.Walk(func(mt *metricType) bool {
+		if mt.Plugin != nil {
+			key := mt.Plugin.Key()
+			byPlugin[key] = append(byPlugin[key], mt)
+		}
+		return true
+	})
+	return byPlugin
+}
+
 func (p *pluginControl) GetMetric(ns core.Namespace, ver int) (core.CatalogedMetric, error) {
 	return p.metricCatalog.Get(ns, ver)
 }
@@ -1010,14 +1963,26 @@ func (p *pluginControl) MetricExists(mns core.Namespace, ver int) bool {
 	return false
 }
 
-// CollectMetrics is a blocking call to collector plugins returning a collection
-// of metrics and errors.  If an error is encountered no metrics will be
-// returned.
+// CollectMetrics is a blocking call to collector plugins returning the
+// metrics collected from them.  A *core.MetricError is returned alongside the
+// collected metrics for each metric a collector failed to collect.  Any other
+// error fails the collection and no metrics are returned, unless the
+// PartialCollectionAllowed option is set.  How metrics are collected and
+// returned is further shaped by the options control was created with.
 func (p *pluginControl) CollectMetrics(metricTypes []core.Metric, deadline time.Time, taskID string, allTags map[string]map[string]string) (metrics []core.Metric, errs []error) {
+	return p.CollectMetricsInto(nil, metricTypes, deadline, taskID, allTags)
+}
+
+// CollectMetricsInto is CollectMetrics appending the collected metrics to buf
+// rather than to a freshly allocated slice, so callers collecting at a high
+// frequency can reuse a buffer, passing buf[:0], between collections.  The
+// metrics already in buf are left in place, and if an error is encountered buf
+// is returned unchanged.
+func (p *pluginControl) CollectMetricsInto(buf []core.Metric, metricTypes []core.Metric, deadline time.Time, taskID string, allTags map[string]map[string]string) (metrics []core.Metric, errs []error) {
 	// If control is not started we don't want tasks to be able to
 	// go through a workflow.
 	if !p.Started {
-		return nil, []error{ErrControllerNotStarted}
+		return buf, []error{ErrControllerNotStarted}
 	}
 
 	for ns, nsTags := range allTags {
@@ -1033,18 +1998,104 @@ func (p *pluginControl) CollectMetrics(metricTypes []core.Metric, deadline time.
 		}
 	}
 
-	pluginToMetricMap, err := groupMetricTypesByPlugin(p.metricCatalog, metricTypes)
+	// only the metrics appended to buf are processed once collected
+	n := len(buf)
+	metricTypes = p.subscriptionConfigs.apply(taskID, metricTypes)
+	local, remote := p.remotes.split(p.metricCatalog, metricTypes)
+	ready, pending := splitConditionalMetrics(local)
+	metrics, metricErrs, errs := p.collectMetrics(buf, ready, deadline, taskID, allTags)
+	for len(errs) == 0 && len(pending) > 0 {
+		var due []core.Metric
+		due, pending = dueConditionalMetrics(pending, metrics[n:])
+		if len(due) == 0 {
+			break
+		}
+		var mErrs []error
+		metrics, mErrs, errs = p.collectMetrics(metrics, due, deadline, taskID, allTags)
+		metricErrs = append(metricErrs, mErrs...)
+	}
+	if len(errs) == 0 && len(remote) > 0 {
+		var remoteMetrics []core.Metric
+		remoteMetrics, errs = p.remotes.collect(remote, deadline, taskID, allTags)
+		metrics = append(metrics, remoteMetrics...)
+	}
+
+	if len(errs) > 0 {
+		return buf, errs
+	}
+	collected := sampleMetrics(metrics[n:])
+	collected = filterMetricsByTags(metricTypes, collected)
+	if p.staleness != nil {
+		for _, e := range p.staleness.collected(taskID, collected, time.Now()) {
+			p.eventManager.Emit(e)
+		}
+	}
+	if p.delta != nil {
+		collected = p.delta.changed(taskID, collected)
+	}
+	if p.orderedResults {
+		collected = orderMetrics(metricTypes, collected, metricErrs)
+	}
+	// Metrics the collectors failed to collect are reported alongside the
+	// metrics which were collected.
+	return append(metrics[:n], collected...), metricErrs
+}
+
+// collectMetrics collects the metrics from their plugins concurrently.  A
+// *core.MetricError is returned in metricErrs for each metric a collector
+// reported it failed to collect.  Collection from a contended pool is delayed
+// by the pool jitter, up to the deadline.  The collected metrics are appended
+// to buf.
+func (p *pluginControl) collectMetrics(buf []core.Metric, metricTypes []core.Metric, deadline time.Time, taskID string, allTags map[string]map[string]string) (metrics []core.Metric, metricErrs []error, errs []error) {
+	metrics = buf
+	if len(metricTypes) == 0 {
+		return metrics, nil, nil
+	}
+	results, n, err := p.startCollection(metricTypes, deadline, taskID)
 	if err != nil {
 		errs = append(errs, err)
-		return
+		return nil, nil, errs
+	}
+
+	for ; n > 0; n-- {
+		r := <-results
+		metricErrs = append(metricErrs, r.metricErrs...)
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+		// Reapply standard tags after collection as a precaution.  It is common for
+		// plugin authors to inadvertently overwrite or not pass along the data
+		// passed to CollectMetrics so we will help them out here.
+		for i := range r.metrics {
+			r.metrics[i] = addStandardAndWorkflowTags(r.metrics[i], allTags)
+		}
+		metrics = append(metrics, r.metrics...)
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	return
+}
+
+// startCollection starts collecting the metrics from their plugins
+// concurrently and returns the channel the result of each of the n plugins is
+// sent to.
+func (p *pluginControl) startCollection(metricTypes []core.Metric, deadline time.Time, taskID string) (<-chan collectResult, int, serror.SnapError) {
+	pluginToMetricMap, err := groupMetricTypesByPlugin(p.metricCatalog, metricTypes)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	cMetrics := make(chan []core.Metric)
-	cError := make(chan error)
-	var wg sync.WaitGroup
+	// Each collection sends exactly one result to the buffered channel so
+	// no send blocks, whether or not the results are still being read, and
+	// the results are read until every collection has responded.
+	results := make(chan collectResult, len(pluginToMetricMap))
 
 	// For each available plugin call available plugin using RPC client and wait for response (goroutines)
-	for pluginKey, pmt := range pluginToMetricMap {
+	for _, pluginKey := range collectOrder(pluginToMetricMap, p.expensiveCollectorsFirst) {
+		pmt := pluginToMetricMap[pluginKey]
 		// merge global plugin config into the config for the metric
 		for _, mt := range pmt.metricTypes {
 			if mt.Config() != nil {
@@ -1052,54 +2103,93 @@ func (p *pluginControl) CollectMetrics(metricTypes []core.Metric, deadline time.
 			}
 		}
 
-		wg.Add(1)
-
-		go func(pluginKey string, mt []core.Metric) {
-			mts, err := p.pluginRunner.AvailablePlugins().collectMetrics(pluginKey, mt, taskID)
-			if err != nil {
-				cError <- err
-			} else {
-				cMetrics <- mts
+		go func(pluginKey string, lp *loadedPlugin, mt []core.Metric) {
+			r := p.collectFromPlugin(pluginKey, lp, mt, deadline, taskID)
+			if r.err != nil && p.partialCollection {
+				r.metricErrs = append(r.metricErrs, newPluginCollectError(pluginKey, mt, r.err))
+				r.err = nil
 			}
-		}(pluginKey, pmt.metricTypes)
+			results <- r
+		}(pluginKey, pmt.plugin, pmt.metricTypes)
 	}
+	return results, len(pluginToMetricMap), nil
+}
 
-	go func() {
-		for m := range cMetrics {
-			// Reapply standard tags after collection as a precaution.  It is common for
-			// plugin authors to inadvertently overwrite or not pass along the data
-			// passed to CollectMetrics so we will help them out here.
-			for i := range m {
-				m[i] = addStandardAndWorkflowTags(m[i], allTags)
-			}
-			metrics = append(metrics, m...)
-			wg.Done()
-		}
-	}()
+// collectResult is the result of collecting metrics from a single plugin.
+type collectResult struct {
+	metrics []core.Metric
+	// metricErrs holds a *core.MetricError for each metric which was not
+	// collected while others were
+	metricErrs []error
+	err        error
+}
 
-	go func() {
-		for e := range cError {
-			errs = append(errs, e)
-			wg.Done()
+// collectFromPlugin collects the metrics from the plugin identified by the
+// plugin key.  The config keys interpreted by control are not passed to the
+// plugin.
+func (p *pluginControl) collectFromPlugin(pluginKey string, lp *loadedPlugin, mt []core.Metric, deadline time.Time, taskID string) (r collectResult) {
+	pluginName := lp.Name()
+	mt = withoutConfigKeys(mt, controlConfigKeys...)
+	if p.lazyCollectorSpawn {
+		p.spawnCollector(pluginKey, lp)
+	}
+	if p.namespaceIsolation {
+		mt = pluginMetrics(pluginName, mt)
+	}
+	collect := p.pluginRunner.AvailablePlugins().collectMetrics
+	if p.collectBatcher != nil {
+		collect = p.collectBatcher.collectMetrics
+	}
+	var (
+		mts []core.Metric
+		err error
+	)
+	fellBack := false
+	if p.primaryUnavailable(pluginKey) {
+		mts, fellBack, err = p.collectFromFallback(pluginKey, mt, taskID)
+	}
+	if !fellBack {
+		p.jitter(pluginKey, deadline)
+		mts, err = p.minCollectIntervals.collectMetrics(pluginKey, mt, taskID, collect)
+		mts = p.tagProvenance(pluginKey, mts)
+	}
+	if p.namespaceIsolation {
+		mts = isolatedMetrics(pluginName, mts)
+	}
+	if nerrs, ok := err.(plugin.NamespaceErrors); ok {
+		for ns, e := range nerrs {
+			if p.namespaceIsolation {
+				ns = "/" + pluginName + ns
+			}
+			r.metricErrs = append(r.metricErrs, &core.MetricError{Namespace: ns, Err: e})
 		}
-	}()
-
-	wg.Wait()
-	close(cMetrics)
-	close(cError)
-
-	if len(errs) > 0 {
-		return nil, errs
+		err = nil
+	}
+	if err != nil {
+		r.err = err
+		return
+	}
+	var capErr *core.MetricError
+	if r.metrics, capErr = p.capCollectResponse(pluginKey, taskID, mts); capErr != nil {
+		r.metricErrs = append(r.metricErrs, capErr)
 	}
 	return
 }
 
 // PublishMetrics
 func (p *pluginControl) PublishMetrics(contentType string, content []byte, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string) []error {
+	_, errs := p.PublishMetricsWithAck(contentType, content, pluginName, pluginVersion, config, taskID)
+	return errs
+}
+
+// PublishMetricsWithAck publishes content like PublishMetrics and returns the
+// publisher's acknowledgment of it.  The ack is nil when the publisher does
+// not acknowledge the content it publishes.
+func (p *pluginControl) PublishMetricsWithAck(contentType string, content []byte, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string) (*plugin.PublishAck, []error) {
 	// If control is not started we don't want tasks to be able to
 	// go through a workflow.
 	if !p.Started {
-		return []error{ErrControllerNotStarted}
+		return nil, []error{ErrControllerNotStarted}
 	}
 	// merge global plugin config into the config for this request
 	// without over-writing the task specific config
@@ -1115,6 +2205,41 @@ func (p *pluginControl) PublishMetrics(contentType string, content []byte, plugi
 	return p.pluginRunner.AvailablePlugins().publishMetrics(contentType, content, pluginName, pluginVersion, merged, taskID)
 }
 
+// PublishMetricsNegotiated publishes to the publisher using the first of the
+// candidate content types it accepts.  content holds the metrics serialized
+// in each of the candidate content types.
+func (p *pluginControl) PublishMetricsNegotiated(candidates []string, content map[string][]byte, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string) []error {
+	if !p.Started {
+		return []error{ErrControllerNotStarted}
+	}
+	accepted, _, err := p.GetPluginContentTypes(pluginName, core.PublisherPluginType, pluginVersion)
+	if err != nil {
+		return []error{err}
+	}
+	contentType, err := negotiateContentType(candidates, content, accepted)
+	if err != nil {
+		return []error{fmt.Errorf("publisher %s:%d: %v", pluginName, pluginVersion, err)}
+	}
+	return p.PublishMetrics(contentType, content[contentType], pluginName, pluginVersion, config, taskID)
+}
+
+// negotiateContentType returns the first candidate content type, with
+// content available, that is accepted.  An accepted type of snap.* accepts
+// any of the snap content types.
+func negotiateContentType(candidates []string, content map[string][]byte, accepted []string) (string, error) {
+	for _, c := range candidates {
+		if _, ok := content[c]; !ok {
+			continue
+		}
+		for _, a := range accepted {
+			if c == a || (a == plugin.SnapAllContentType && strings.HasPrefix(c, "snap.")) {
+				return c, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("none of the content types %v are accepted, accepted content types are %v", candidates, accepted)
+}
+
 // ProcessMetrics
 func (p *pluginControl) ProcessMetrics(contentType string, content []byte, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string) (string, []byte, []error) {
 	// If control is not started we don't want tasks to be able to
@@ -1141,7 +2266,7 @@ func (p *pluginControl) ProcessMetrics(contentType string, content []byte, plugi
 // If the version provided is 0 or less the newest plugin by version will be
 // returned.
 func (p *pluginControl) GetPluginContentTypes(n string, t core.PluginType, v int) ([]string, []string, error) {
-	lp, err := p.pluginManager.get(fmt.Sprintf("%s:%s:%d", t.String(), n, v))
+	lp, err := p.pluginManager.get(core.PluginKey(t, n, v))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1160,10 +2285,127 @@ func (p *pluginControl) SetPluginTrustLevel(trust int) {
 	p.pluginTrust = trust
 }
 
+// SetKeyringFile adds a keyring file, or a directory of keyring files, the
+// signatures of plugins are validated against.  A signature is valid if any
+// of the keyrings verifies it.
 func (p *pluginControl) SetKeyringFile(keyring string) {
 	p.keyringFiles = append(p.keyringFiles, keyring)
 }
 
+// SetPluginLogLevel sets the log level, such as debug or info, of the loaded
+// plugin identified by its {type}:{name}:{version} key.  The level is passed
+// to instances of the plugin started after this call, running instances keep
+// their current level until they are restarted.  Lines plugins log through
+// the standard log package are logged at the info level, so they are
+// discarded at less verbose levels.
+func (p *pluginControl) SetPluginLogLevel(key string, level string) error {
+	if _, err := log.ParseLevel(level); err != nil {
+		return serror.New(err, map[string]interface{}{"plugin": key})
+	}
+	if err := p.pluginManager.SetPluginLogLevel(key, level); err != nil {
+		return serror.New(err, map[string]interface{}{"plugin": key})
+	}
+	return nil
+}
+
+// SetRoutingStrategy changes the routing and caching strategy of the running
+// plugin identified by its {type}:{name}:{version} key.  Calls already
+// selecting an instance complete before the strategy is replaced.  The
+// strategy reverts to the one declared by the plugin when the plugin is
+// reloaded.
+func (p *pluginControl) SetRoutingStrategy(key string, r plugin.RoutingStrategyType) error {
+	f := map[string]interface{}{
+		"pool-key": key,
+		"strategy": r.String(),
+	}
+	if !p.Started {
+		return serror.New(ErrControllerNotStarted, f)
+	}
+	pool, serr := p.pluginRunner.AvailablePlugins().getPool(key)
+	if serr != nil {
+		serr.SetFields(f)
+		return serr
+	}
+	if pool == nil {
+		return serror.New(ErrPoolNotFound, f)
+	}
+	var previous string
+	if pool.Strategy() != nil {
+		previous = pool.Strategy().String()
+	}
+	if err := pool.SetStrategy(r); err != nil {
+		return serror.New(err, f)
+	}
+	controlLogger.WithFields(f).Info("plugin routing strategy changed")
+	typ, name, _, _ := core.ParsePluginKey(key)
+	p.eventManager.Emit(&control_event.StrategyChangedEvent{
+		PluginName:       name,
+		PluginVersion:    pool.Version(),
+		PluginType:       int(typ),
+		PreviousStrategy: previous,
+		Strategy:         pool.Strategy().String(),
+	})
+	return nil
+}
+
+// PausePlugin stops collecting from, publishing to and processing with the
+// running plugin identified by its {type}:{name}:{version} key without
+// unloading it or removing its subscriptions.  Collections from a paused
+// collector return no metrics, while publishing and processing return an
+// error.  The pause is lost if the plugin is reloaded.
+func (p *pluginControl) PausePlugin(key string) error {
+	return p.setPluginPaused(key, true)
+}
+
+// ResumePlugin resumes the plugin paused by PausePlugin.
+func (p *pluginControl) ResumePlugin(key string) error {
+	return p.setPluginPaused(key, false)
+}
+
+func (p *pluginControl) setPluginPaused(key string, paused bool) error {
+	f := map[string]interface{}{
+		"pool-key": key,
+		"paused":   paused,
+	}
+	if !p.Started {
+		return serror.New(ErrControllerNotStarted, f)
+	}
+	pool, serr := p.pluginRunner.AvailablePlugins().getPool(key)
+	if serr != nil {
+		serr.SetFields(f)
+		return serr
+	}
+	if pool == nil {
+		return serror.New(ErrPoolNotFound, f)
+	}
+	typ, name, _, _ := core.ParsePluginKey(key)
+	if paused {
+		pool.Pause()
+		controlLogger.WithFields(f).Info("plugin paused")
+		p.eventManager.Emit(&control_event.PluginPausedEvent{
+			PluginName:    name,
+			PluginVersion: pool.Version(),
+			PluginType:    int(typ),
+		})
+		return nil
+	}
+	pool.Resume()
+	controlLogger.WithFields(f).Info("plugin resumed")
+	p.eventManager.Emit(&control_event.PluginResumedEvent{
+		PluginName:    name,
+		PluginVersion: pool.Version(),
+		PluginType:    int(typ),
+	})
+	return nil
+}
+
+// SetPluginTransport sets the transport plugins started after this call
+// listen on.  Plugins listen on a Unix domain socket by default and fall back
+// to TCP where Unix domain sockets are not supported.
+func (p *pluginControl) SetPluginTransport(t plugin.TransportType) {
+	p.pluginManager.SetPluginTransport(t)
+}
+
 type requestedPlugin struct {
 	name    string
 	version int
@@ -1194,9 +2436,36 @@ func (p *metricTypes) Count() int {
 	return len(p.metricTypes)
 }
 
-// groupMetricTypesByPlugin groups metricTypes by a plugin.Key() and returns appropriate structure
+// skipOptionalMetric logs a warning and returns true if the metric, which
+// could not be found in the metric catalog, is marked optional.
+func skipOptionalMetric(mt core.RequestedMetric, block string) bool {
+	if !core.IsOptional(mt) {
+		return false
+	}
+	controlLogger.WithFields(log.Fields{
+		"_block":  block,
+		"metric":  mt.Namespace().String(),
+		"version": mt.Version(),
+	}).Warn("optional metric not found, skipping")
+	return true
+}
+
+// pluginKey returns the key of pl in the form returned by core.PluginKey.  A
+// plugin with an unknown type name is given a key which matches no plugin.
+func pluginKey(pl core.Plugin) string {
+	typ, err := core.ToPluginType(pl.TypeName())
+	if err != nil {
+		return fmt.Sprintf("%s:%s:%d", pl.TypeName(), pl.Name(), pl.Version())
+	}
+	return core.PluginKey(typ, pl.Name(), pl.Version())
+}
+
+// groupMetricTypesByPlugin groups metricTypes by a plugin.Key() and returns appropriate structure.
+// Identical requests, with the same namespace, version and config, are only
+// included once in a plugin's group.
 func groupMetricTypesByPlugin(cat catalogsMetrics, mts []core.Metric) (map[string]metricTypes, serror.SnapError) {
 	pmts := make(map[string]metricTypes)
+	requested := make(map[string]struct{})
 	// For each plugin type select a matching available plugin to call
 	for _, incomingmt := range mts {
 		version := incomingmt.Version()
@@ -1205,6 +2474,9 @@ func groupMetricTypesByPlugin(cat catalogsMetrics, mts []core.Metric) (map[strin
 			version = -1
 		}
 		catalogedmt, err := cat.Get(incomingmt.Namespace(), version)
+		if err != nil && skipOptionalMetric(incomingmt, "collect-metrics") {
+			continue
+		}
 		if err != nil {
 			return nil, serror.New(err)
 		}
@@ -1221,6 +2493,11 @@ func groupMetricTypesByPlugin(cat catalogsMetrics, mts []core.Metric) (map[strin
 			return nil, serror.New(errorMetricNotFound(incomingmt.Namespace().String()))
 		}
 		key := lp.Key()
+		rkey := key + "|" + metricRequestKey(returnedmt)
+		if _, ok := requested[rkey]; ok {
+			continue
+		}
+		requested[rkey] = struct{}{}
 		pmt, _ := pmts[key]
 		pmt.plugin = lp
 		pmt.metricTypes = append(pmt.metricTypes, returnedmt)
@@ -1228,3 +2505,28 @@ func groupMetricTypesByPlugin(cat catalogsMetrics, mts []core.Metric) (map[strin
 	}
 	return pmts, nil
 }
+
+// metricRequestKey returns a key identifying a requested metric by its
+// namespace, version and config.
+func metricRequestKey(mt core.Metric) string {
+	return mt.Namespace().String() + metricConfigKey(mt)
+}
+
+// metricConfigKey returns the part of the key of a requested metric
+// identifying its version and config.
+func metricConfigKey(mt core.Metric) string {
+	key := fmt.Sprintf("|%d", mt.Version())
+	if mt.Config() == nil {
+		return key
+	}
+	table := mt.Config().Table()
+	names := make([]string, 0, len(table))
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key += fmt.Sprintf("|%s=%s:%v", name, table[name].Type(), table[name])
+	}
+	return key
+}
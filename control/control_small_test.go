@@ -0,0 +1,286 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+	"github.com/intelsdi-x/snap/core/serror"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func dependencyNotLoaded(plugin, dependency string) serror.SnapError {
+	se := serror.New(ErrPluginDependencyNotLoaded)
+	se.SetFields(map[string]interface{}{
+		"plugin":     plugin,
+		"dependency": dependency,
+	})
+	return se
+}
+
+func TestDependencyError(t *testing.T) {
+	Convey("Given plugins blocked on their dependencies", t, func() {
+		Convey("a missing dependency is reported unchanged", func() {
+			a := dependencyNotLoaded("collector:a:1", "collector:missing:1")
+			blocked := map[*inspectedPlugin]serror.SnapError{
+				{}: a,
+			}
+			So(dependencyError(a, blocked), ShouldEqual, a)
+		})
+		Convey("a circular dependency is reported", func() {
+			a := dependencyNotLoaded("collector:a:1", "processor:b:0")
+			b := dependencyNotLoaded("processor:b:2", "collector:a:1")
+			blocked := map[*inspectedPlugin]serror.SnapError{
+				{}: a,
+				{}: b,
+			}
+			serr := dependencyError(a, blocked)
+			So(serr.Error(), ShouldEqual, "circular plugin dependency: collector:a:1 -> processor:b:2 -> collector:a:1")
+		})
+	})
+}
+
+func newInspectedPlugin(typ plugin.PluginType, name string, version int, deps ...plugin.PluginRef) *inspectedPlugin {
+	return &inspectedPlugin{
+		details: &pluginDetails{},
+		meta:    plugin.NewPluginMeta(name, version, typ, nil, nil, plugin.DependsOn(deps...)),
+	}
+}
+
+func TestDependencyOrder(t *testing.T) {
+	Convey("Given inspected plugins declaring dependencies", t, func() {
+		c := New(GetDefaultConfig())
+		a := newInspectedPlugin(plugin.CollectorPluginType, "a", 1)
+		b := newInspectedPlugin(plugin.ProcessorPluginType, "b", 1, plugin.PluginRef{Type: plugin.CollectorPluginType, Name: "a"})
+
+		Convey("plugins are ordered after the plugins they depend on", func() {
+			ordered, blocked := c.dependencyOrder([]*inspectedPlugin{b, a})
+			So(ordered, ShouldResemble, []*inspectedPlugin{a, b})
+			So(blocked, ShouldBeEmpty)
+		})
+		Convey("plugins with a missing dependency are blocked", func() {
+			ordered, blocked := c.dependencyOrder([]*inspectedPlugin{b})
+			So(ordered, ShouldBeEmpty)
+			So(len(blocked), ShouldEqual, 1)
+			So(blocked[b].Fields()["dependency"], ShouldEqual, "collector:a:0")
+		})
+		Convey("plugins with a circular dependency are blocked", func() {
+			d := newInspectedPlugin(plugin.CollectorPluginType, "d", 1, plugin.PluginRef{Type: plugin.CollectorPluginType, Name: "e"})
+			e := newInspectedPlugin(plugin.CollectorPluginType, "e", 1, plugin.PluginRef{Type: plugin.CollectorPluginType, Name: "d"})
+			ordered, blocked := c.dependencyOrder([]*inspectedPlugin{a, d, e})
+			So(ordered, ShouldResemble, []*inspectedPlugin{a})
+			So(len(blocked), ShouldEqual, 2)
+			So(dependencyError(blocked[d], blocked).Error(), ShouldEqual, "circular plugin dependency: collector:d:1 -> collector:e:1 -> collector:d:1")
+		})
+	})
+}
+
+func TestPluginRefMatches(t *testing.T) {
+	Convey("pluginRefMatches", t, func() {
+		So(pluginRefMatches("collector:a:1", "collector:a:1"), ShouldBeTrue)
+		So(pluginRefMatches("collector:a:0", "collector:a:5"), ShouldBeTrue)
+		So(pluginRefMatches("collector:a:1", "collector:a:2"), ShouldBeFalse)
+		So(pluginRefMatches("collector:a:1", "publisher:a:1"), ShouldBeFalse)
+	})
+}
+
+func TestGroupMetricTypesByPlugin(t *testing.T) {
+	Convey("Given a catalog with a metric from a single plugin", t, func() {
+		lp := &loadedPlugin{Meta: plugin.PluginMeta{Name: "mock", Version: 1}}
+		ns := core.NewNamespace("intel", "mock", "foo")
+		mc := newMetricCatalog()
+		mc.Add(newMetricType(ns, time.Now(), lp))
+		cfg := cdata.NewNode()
+		cfg.AddItem("password", ctypes.ConfigValueStr{Value: "secret"})
+
+		Convey("identical requests are grouped once", func() {
+			mts := []core.Metric{
+				plugin.MetricType{Namespace_: ns, Config_: cfg},
+				plugin.MetricType{Namespace_: ns, Config_: cfg},
+			}
+			pmts, serr := groupMetricTypesByPlugin(mc, mts)
+			So(serr, ShouldBeNil)
+			So(len(pmts[lp.Key()].metricTypes), ShouldEqual, 1)
+		})
+		Convey("requests with a different config are kept", func() {
+			mts := []core.Metric{
+				plugin.MetricType{Namespace_: ns, Config_: cfg},
+				plugin.MetricType{Namespace_: ns},
+			}
+			pmts, serr := groupMetricTypesByPlugin(mc, mts)
+			So(serr, ShouldBeNil)
+			So(len(pmts[lp.Key()].metricTypes), ShouldEqual, 2)
+		})
+	})
+}
+
+func TestNegotiateContentType(t *testing.T) {
+	Convey("negotiateContentType", t, func() {
+		content := map[string][]byte{
+			"snap.json":  []byte("[]"),
+			"text/plain": []byte(""),
+		}
+		Convey("picks the first accepted candidate", func() {
+			ct, err := negotiateContentType([]string{"text/plain", "snap.json"}, content, []string{"snap.json", "text/plain"})
+			So(err, ShouldBeNil)
+			So(ct, ShouldEqual, "text/plain")
+		})
+		Convey("matches snap content types against snap.*", func() {
+			ct, err := negotiateContentType([]string{"text/plain", "snap.json"}, content, []string{"snap.*"})
+			So(err, ShouldBeNil)
+			So(ct, ShouldEqual, "snap.json")
+		})
+		Convey("skips candidates without content", func() {
+			_, err := negotiateContentType([]string{"snap.gob"}, content, []string{"snap.gob"})
+			So(err, ShouldNotBeNil)
+		})
+		Convey("returns an error when no candidate is accepted", func() {
+			_, err := negotiateContentType([]string{"text/plain"}, content, []string{"snap.json"})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestPluginsWithCapability(t *testing.T) {
+	Convey("Given loaded plugins declaring capabilities", t, func() {
+		pm := newPluginManager()
+		pm.loadedPlugins.add(&loadedPlugin{
+			Type: plugin.CollectorPluginType,
+			Meta: *plugin.NewPluginMeta("streamer", 1, plugin.CollectorPluginType, nil, nil, plugin.Capabilities(plugin.StreamingCapability)),
+		})
+		pm.loadedPlugins.add(&loadedPlugin{
+			Type: plugin.PublisherPluginType,
+			Meta: *plugin.NewPluginMeta("file", 1, plugin.PublisherPluginType, nil, nil, plugin.Exclusive(true), plugin.AcceptedContentEncodings(plugin.GzipContentEncoding)),
+		})
+		c := &pluginControl{pluginManager: pm}
+		Convey("declared capabilities are matched", func() {
+			plugins := c.PluginsWithCapability(plugin.StreamingCapability)
+			So(len(plugins), ShouldEqual, 1)
+			So(plugins[0].Name(), ShouldEqual, "streamer")
+		})
+		Convey("capabilities implied by the metadata are matched", func() {
+			plugins := c.PluginsWithCapability(plugin.ExclusiveCapability | plugin.CompressionCapability)
+			So(len(plugins), ShouldEqual, 1)
+			So(plugins[0].Name(), ShouldEqual, "file")
+		})
+	})
+}
+
+func TestNewSubscriptionError(t *testing.T) {
+	Convey("Given a config policy error for a key", t, func() {
+		err := &cpolicy.KeyError{Key: "password", Err: errors.New("required key missing (password)")}
+		se := newSubscriptionError(ConfigPolicyError, err, map[string]interface{}{SubscriptionErrorNamespaceField: "/intel/mock/foo"})
+		Convey("the category, namespace and config key are recorded", func() {
+			So(se.Error(), ShouldEqual, "required key missing (password)")
+			So(se.Fields()[SubscriptionErrorCategoryField], ShouldEqual, ConfigPolicyError)
+			So(se.Fields()[SubscriptionErrorNamespaceField], ShouldEqual, "/intel/mock/foo")
+			So(se.Fields()[SubscriptionErrorConfigKeyField], ShouldEqual, "password")
+		})
+	})
+}
+
+type optionalMetric struct {
+	plugin.MetricType
+}
+
+func (optionalMetric) Optional() bool { return true }
+
+func TestOptionalMetrics(t *testing.T) {
+	Convey("Given metrics missing from the metric catalog", t, func() {
+		c := &pluginControl{metricCatalog: newMetricCatalog()}
+		required := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "missing")}
+		optional := optionalMetric{required}
+		Convey("a missing required metric fails validation", func() {
+			errs := c.validateMetricTypeSubscription(required, cdata.NewNode())
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Fields()[SubscriptionErrorCategoryField], ShouldEqual, MetricResolutionError)
+		})
+		Convey("a missing optional metric is skipped", func() {
+			So(c.validateMetricTypeSubscription(optional, cdata.NewNode()), ShouldBeEmpty)
+			pmts, err := groupMetricTypesByPlugin(c.metricCatalog, []core.Metric{optional})
+			So(err, ShouldBeNil)
+			So(pmts, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestNotStarted(t *testing.T) {
+	Convey("Given a controller which has not been started", t, func() {
+		c := New(GetDefaultConfig())
+		mts := []core.Metric{plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo")}}
+		Convey("Load returns ErrControllerNotStarted", func() {
+			rp, err := core.NewRequestedPlugin(os.Args[0])
+			So(err, ShouldBeNil)
+			_, serr := c.Load(rp)
+			So(serr.Error(), ShouldEqual, ErrControllerNotStarted.Error())
+		})
+		Convey("LoadDirectory returns ErrControllerNotStarted", func() {
+			_, serrs := c.LoadDirectory(os.TempDir())
+			So(serrs[0].Error(), ShouldEqual, ErrControllerNotStarted.Error())
+		})
+		Convey("Unload returns ErrControllerNotStarted", func() {
+			_, serr := c.Unload(&loadedPlugin{Meta: plugin.PluginMeta{Name: "mock", Version: 1}})
+			So(serr.Error(), ShouldEqual, ErrControllerNotStarted.Error())
+		})
+		Convey("SwapPlugins returns ErrControllerNotStarted", func() {
+			serr := c.SwapPlugins(&core.RequestedPlugin{}, &loadedPlugin{})
+			So(serr.Error(), ShouldEqual, ErrControllerNotStarted.Error())
+		})
+		Convey("SubscribeDeps returns ErrControllerNotStarted", func() {
+			serrs := c.SubscribeDeps("task", mts, nil)
+			So(serrs[0].Error(), ShouldEqual, ErrControllerNotStarted.Error())
+		})
+		Convey("UnsubscribeDeps returns ErrControllerNotStarted", func() {
+			serrs := c.UnsubscribeDeps("task", mts, nil)
+			So(serrs[0].Error(), ShouldEqual, ErrControllerNotStarted.Error())
+		})
+		Convey("RevalidateSignatures returns ErrControllerNotStarted", func() {
+			serrs := c.RevalidateSignatures(true)
+			So(serrs[0].Error(), ShouldEqual, ErrControllerNotStarted.Error())
+		})
+		Convey("SetRoutingStrategy returns ErrControllerNotStarted", func() {
+			err := c.SetRoutingStrategy("collector:mock:1", plugin.DefaultRouting)
+			So(err.Error(), ShouldEqual, ErrControllerNotStarted.Error())
+		})
+		Convey("CollectMetrics returns ErrControllerNotStarted", func() {
+			_, errs := c.CollectMetrics(mts, time.Now(), "task", nil)
+			So(errs, ShouldResemble, []error{ErrControllerNotStarted})
+		})
+		Convey("PublishMetrics returns ErrControllerNotStarted", func() {
+			errs := c.PublishMetrics(plugin.SnapGOBContentType, nil, "file", 1, nil, "task")
+			So(errs, ShouldResemble, []error{ErrControllerNotStarted})
+		})
+		Convey("ProcessMetrics returns ErrControllerNotStarted", func() {
+			_, _, errs := c.ProcessMetrics(plugin.SnapGOBContentType, nil, "passthru", 1, nil, "task")
+			So(errs, ShouldResemble, []error{ErrControllerNotStarted})
+		})
+	})
+}
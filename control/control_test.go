@@ -29,6 +29,7 @@ import (
 	"math/rand"
 	"net"
 	"path"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -41,6 +42,7 @@ import (
 	"github.com/intelsdi-x/gomit"
 	"github.com/intelsdi-x/snap/control/fixtures"
 	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/client"
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	"github.com/intelsdi-x/snap/control/strategy"
 	"github.com/intelsdi-x/snap/core"
@@ -48,6 +50,7 @@ import (
 	"github.com/intelsdi-x/snap/core/control_event"
 	"github.com/intelsdi-x/snap/core/ctypes"
 	"github.com/intelsdi-x/snap/core/serror"
+	"golang.org/x/net/context"
 )
 
 // Mock Executor used to test
@@ -69,15 +72,35 @@ type MockPluginManagerBadSwap struct {
 func (m *MockPluginManagerBadSwap) LoadPlugin(*pluginDetails, gomit.Emitter) (*loadedPlugin, serror.SnapError) {
 	return new(loadedPlugin), nil
 }
+func (m *MockPluginManagerBadSwap) LoadPluginWithContext(context.Context, *pluginDetails, gomit.Emitter) (*loadedPlugin, serror.SnapError) {
+	return new(loadedPlugin), nil
+}
+func (m *MockPluginManagerBadSwap) InspectPlugin(*pluginDetails) (*plugin.PluginMeta, []core.Metric, serror.SnapError) {
+	return nil, nil, nil
+}
 func (m *MockPluginManagerBadSwap) UnloadPlugin(c core.Plugin) (*loadedPlugin, serror.SnapError) {
 	return nil, serror.New(errors.New("fake"))
 }
-func (m *MockPluginManagerBadSwap) get(string) (*loadedPlugin, error) { return nil, nil }
-func (m *MockPluginManagerBadSwap) teardown()                         {}
-func (m *MockPluginManagerBadSwap) SetPluginConfig(*pluginConfig)     {}
-func (m *MockPluginManagerBadSwap) SetMetricCatalog(catalogsMetrics)  {}
-func (m *MockPluginManagerBadSwap) SetEmitter(gomit.Emitter)          {}
-func (m *MockPluginManagerBadSwap) GenerateArgs(string) plugin.Arg    { return plugin.Arg{} }
+func (m *MockPluginManagerBadSwap) restorePlugin(*loadedPlugin) serror.SnapError {
+	return nil
+}
+func (m *MockPluginManagerBadSwap) get(string) (*loadedPlugin, error)       { return nil, nil }
+func (m *MockPluginManagerBadSwap) teardown()                               {}
+func (m *MockPluginManagerBadSwap) SetPluginConfig(*pluginConfig)           {}
+func (m *MockPluginManagerBadSwap) SetMetricCatalog(catalogsMetrics)        {}
+func (m *MockPluginManagerBadSwap) SetPluginTransport(plugin.TransportType) {}
+func (m *MockPluginManagerBadSwap) SetPluginPidDir(string)                  {}
+func (m *MockPluginManagerBadSwap) SetPluginClientTimeouts(client.Timeouts) {}
+func (m *MockPluginManagerBadSwap) SetPluginOutput(*pluginOutput)           {}
+func (m *MockPluginManagerBadSwap) PluginOutput() *pluginOutput             { return nil }
+func (m *MockPluginManagerBadSwap) newExecutablePlugin(*pluginDetails) (*plugin.ExecutablePlugin, error) {
+	return nil, nil
+}
+func (m *MockPluginManagerBadSwap) SetEmitter(gomit.Emitter)               {}
+func (m *MockPluginManagerBadSwap) SetPluginLogLevel(string, string) error { return nil }
+func (m *MockPluginManagerBadSwap) GenerateArgs(*pluginDetails) plugin.Arg { return plugin.Arg{} }
+
+func (m *MockPluginManagerBadSwap) setSigned(*loadedPlugin, bool) bool { return false }
 
 func (m *MockPluginManagerBadSwap) all() map[string]*loadedPlugin {
 	return m.loadedPlugins.table
@@ -261,6 +284,52 @@ func TestSwapPlugin(t *testing.T) {
 	}
 }
 
+func TestReloadPlugin(t *testing.T) {
+	if fixtures.SnapPath != "" {
+		c := New(getTestConfig())
+		c.Start()
+		time.Sleep(100 * time.Millisecond)
+		lpe := newListenToPluginEvent()
+		c.eventManager.RegisterHandler("Control.PluginsSwapped", lpe)
+
+		_, e := load(c, fixtures.PluginPath)
+		Convey("Loading the plugin should not error", t, func() {
+			So(e, ShouldBeNil)
+		})
+		if e != nil {
+			t.FailNow()
+		}
+		<-lpe.done
+
+		// The binary is unchanged so the plugin is reloaded with the same version
+		lp, err := c.Reload(pluginKey(c.PluginCatalog()[0]))
+		Convey("Reloading the plugin should not error", t, func() {
+			So(err, ShouldBeNil)
+		})
+		if err != nil {
+			t.FailNow()
+		}
+		<-lpe.done
+
+		Convey("Reloading the plugin", t, func() {
+			Convey("Should keep a single plugin in the catalog", func() {
+				So(len(c.PluginCatalog()), ShouldEqual, 1)
+				So(pluginKey(c.PluginCatalog()[0]), ShouldEqual, pluginKey(lp))
+			})
+			Convey("Should generate a swapped plugins event", func() {
+				So(lpe.plugin.LoadedPluginName, ShouldEqual, "mock")
+				So(lpe.plugin.LoadedPluginVersion, ShouldEqual, 2)
+				So(lpe.plugin.UnloadedPluginName, ShouldEqual, "mock")
+				So(lpe.plugin.UnloadedPluginVersion, ShouldEqual, 2)
+				So(lpe.plugin.PluginType, ShouldEqual, int(plugin.CollectorPluginType))
+			})
+		})
+
+		c.Stop()
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
 type mockPluginEvent struct {
 	LoadedPluginName      string
 	LoadedPluginVersion   int
@@ -400,6 +469,81 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestInspectPlugin(t *testing.T) {
+	if fixtures.SnapPath != "" {
+		Convey("pluginControl.InspectPlugin", t, func() {
+			c := New(getTestConfig())
+			c.Start()
+			meta, mts, err := c.InspectPlugin(fixtures.PluginPath)
+			Convey("should return the plugin's metadata", func() {
+				So(err, ShouldBeNil)
+				So(meta.Name, ShouldEqual, "mock")
+				So(meta.Version, ShouldEqual, 2)
+			})
+			Convey("should return the plugin's metric types", func() {
+				So(mts, ShouldNotBeEmpty)
+			})
+			Convey("should not load the plugin", func() {
+				So(len(c.pluginManager.all()), ShouldEqual, 0)
+				mc, err := c.MetricCatalog()
+				So(err, ShouldBeNil)
+				So(mc, ShouldBeEmpty)
+			})
+			c.Stop()
+		})
+	}
+}
+
+func TestLoadWithContext(t *testing.T) {
+	if fixtures.SnapPath != "" {
+		Convey("pluginControl.LoadWithContext", t, func() {
+			c := New(getTestConfig())
+			c.Start()
+			rp, err := core.NewRequestedPlugin(fixtures.PluginPath)
+			So(err, ShouldBeNil)
+			Convey("should return the context's error when it is cancelled", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				_, serr := c.LoadWithContext(ctx, rp)
+				So(serr, ShouldNotBeNil)
+				So(serr.Error(), ShouldEqual, context.Canceled.Error())
+				Convey("and should not load the plugin", func() {
+					So(len(c.pluginManager.all()), ShouldEqual, 0)
+					mc, err := c.MetricCatalog()
+					So(err, ShouldBeNil)
+					So(mc, ShouldBeEmpty)
+				})
+			})
+			Convey("should load the plugin when the context is not done", func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				_, serr := c.LoadWithContext(ctx, rp)
+				So(serr, ShouldBeNil)
+				So(len(c.pluginManager.all()), ShouldEqual, 1)
+			})
+			c.Stop()
+		})
+	}
+}
+
+func TestMetricCatalogUnitAndDescription(t *testing.T) {
+	if fixtures.SnapPath != "" {
+		Convey("Given a loaded plugin declaring metric units and descriptions", t, func() {
+			c := New(getTestConfig())
+			c.Start()
+			_, err := load(c, fixtures.PluginPath)
+			So(err, ShouldBeNil)
+			Convey("they are surfaced on the cataloged metrics", func() {
+				mt, err := c.GetMetric(core.NewNamespace("intel", "mock", "foo"), 2)
+				So(err, ShouldBeNil)
+				So(mt.Unit(), ShouldEqual, "mock unit")
+				So(mt.Description(), ShouldEqual, "mock description")
+			})
+			c.Stop()
+		})
+	}
+}
+
 func TestLoadWithSignedPlugins(t *testing.T) {
 	if fixtures.SnapPath != "" {
 		Convey("pluginControl.Load should successufully load a signed plugin with trust enabled", t, func() {
@@ -694,6 +838,10 @@ func (m *mc) Next() bool {
 	return false
 }
 
+func (m *mc) Walk(func(*metricType) bool) {}
+
+func (m *mc) LastModified() time.Time { return time.Time{} }
+
 func (m *mc) AddLoadedMetricType(*loadedPlugin, core.Metric) error {
 	return nil
 
@@ -1018,7 +1166,7 @@ func TestCollectDynamicMetrics(t *testing.T) {
 	Convey("given a plugin using the native client", t, func() {
 		config := getTestConfig()
 		config.Plugins.All.AddItem("password", ctypes.ConfigValueStr{Value: "testval"})
-		config.CacheExpiration = jsonutil.Duration{time.Second * 1}
+		config.CacheExpiration = jsonutil.Duration{Duration: time.Second * 1}
 		c := New(config)
 		c.Start()
 		So(strategy.GlobalCacheExpiration, ShouldResemble, time.Second*1)
@@ -1322,6 +1470,127 @@ func TestCollectMetrics(t *testing.T) {
 	})
 }
 
+func TestCollectMetricsMultiVersion(t *testing.T) {
+	Convey("given two loaded versions of a plugin", t, func() {
+		// adjust HB timeouts for test
+		plugin.PingTimeoutLimit = 1
+		plugin.PingTimeoutDurationDefault = time.Second * 1
+
+		c := New(getTestConfig())
+		c.pluginRunner.(*runner).monitor.duration = time.Millisecond * 100
+		c.Start()
+		lpe := newListenToPluginEvent()
+		c.eventManager.RegisterHandler("Control.PluginLoaded", lpe)
+
+		_, e := load(c, fixtures.PluginPath)
+		So(e, ShouldBeNil)
+		<-lpe.done
+		_, e = load(c, strings.Replace(fixtures.PluginPath, "snap-collector-mock2", "snap-collector-mock1", 1))
+		So(e, ShouldBeNil)
+		<-lpe.done
+
+		for _, key := range []string{"collector:mock:1", "collector:mock:2"} {
+			lp, err := c.pluginManager.get(key)
+			So(err, ShouldBeNil)
+			pool, errp := c.pluginRunner.AvailablePlugins().getOrCreatePool(key)
+			So(errp, ShouldBeNil)
+			pool.Subscribe("1", strategy.BoundSubscriptionType)
+			err = c.pluginRunner.runPlugin(lp.Details)
+			So(err, ShouldBeNil)
+		}
+
+		cd := cdata.NewNode()
+		cd.AddItem("password", ctypes.ConfigValueStr{Value: "testval"})
+		Convey("metrics are collected from each version and tagged with it", func() {
+			mts, errs := c.CollectMetricsMultiVersion(core.NewNamespace("intel", "mock", "foo"), []int{1, 2}, cd, uuid.New())
+			So(errs, ShouldBeEmpty)
+			So(len(mts), ShouldEqual, 2)
+			versions := []string{mts[0].Tags()[CollectedVersionTag], mts[1].Tags()[CollectedVersionTag]}
+			sort.Strings(versions)
+			So(versions, ShouldResemble, []string{"1", "2"})
+		})
+		Convey("a version which is not loaded is reported", func() {
+			mts, errs := c.CollectMetricsMultiVersion(core.NewNamespace("intel", "mock", "foo"), []int{1, 3}, cd, uuid.New())
+			So(mts, ShouldBeNil)
+			So(len(errs), ShouldEqual, 1)
+		})
+		c.Stop()
+	})
+}
+
+func TestLazyCollectorSpawn(t *testing.T) {
+	Convey("given a loaded collector which has not been started", t, func() {
+		// adjust HB timeouts for test
+		plugin.PingTimeoutLimit = 1
+		plugin.PingTimeoutDurationDefault = time.Second * 1
+
+		c := New(getTestConfig(), LazyCollectorSpawn(true))
+		c.pluginRunner.(*runner).monitor.duration = time.Millisecond * 100
+		c.Start()
+		lpe := newListenToPluginEvent()
+		c.eventManager.RegisterHandler("Control.PluginLoaded", lpe)
+		_, e := load(c, fixtures.PluginPath)
+		So(e, ShouldBeNil)
+		<-lpe.done
+
+		cd := cdata.NewNode()
+		cd.AddItem("password", ctypes.ConfigValueStr{Value: "testval"})
+		Convey("collecting spawns the collector", func() {
+			m := []core.Metric{plugin.MetricType{
+				Namespace_: core.NewNamespace("intel", "mock", "foo"),
+				Config_:    cd,
+			}}
+			mts, errs := c.CollectMetrics(m, time.Now().Add(time.Second*10), uuid.New(), nil)
+			So(errs, ShouldBeEmpty)
+			So(len(mts), ShouldEqual, 1)
+			pool, err := c.pluginRunner.AvailablePlugins().getPool("collector:mock:2")
+			So(err, ShouldBeNil)
+			So(pool.Count(), ShouldEqual, 1)
+		})
+		c.Stop()
+	})
+}
+
+func TestCollectMetricsInto(t *testing.T) {
+	Convey("given a loaded collector", t, func() {
+		// adjust HB timeouts for test
+		plugin.PingTimeoutLimit = 1
+		plugin.PingTimeoutDurationDefault = time.Second * 1
+
+		c := New(getTestConfig())
+		c.pluginRunner.(*runner).monitor.duration = time.Millisecond * 100
+		c.Start()
+		lpe := newListenToPluginEvent()
+		c.eventManager.RegisterHandler("Control.PluginLoaded", lpe)
+		_, e := load(c, fixtures.PluginPath)
+		So(e, ShouldBeNil)
+		<-lpe.done
+
+		cd := cdata.NewNode()
+		cd.AddItem("password", ctypes.ConfigValueStr{Value: "testval"})
+		m := []core.Metric{plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "mock", "foo"),
+			Config_:    cd,
+		}}
+		Convey("collected metrics are appended to the buffer", func() {
+			kept := plugin.MetricType{Namespace_: core.NewNamespace("intel", "kept")}
+			buf := make([]core.Metric, 1, 8)
+			buf[0] = kept
+			mts, errs := c.CollectMetricsInto(buf, m, time.Now().Add(time.Second*10), uuid.New(), nil)
+			So(errs, ShouldBeEmpty)
+			So(len(mts), ShouldEqual, 2)
+			So(mts[0].Namespace().String(), ShouldEqual, kept.Namespace().String())
+			So(&mts[0], ShouldPointTo, &buf[0])
+
+			mts, errs = c.CollectMetricsInto(mts[:0], m, time.Now().Add(time.Second*10), uuid.New(), nil)
+			So(errs, ShouldBeEmpty)
+			So(len(mts), ShouldEqual, 1)
+			So(&mts[0], ShouldPointTo, &buf[0])
+		})
+		c.Stop()
+	})
+}
+
 func TestExpandWildcards(t *testing.T) {
 	Convey("pluginControl.ExpandWildcards()", t, func() {
 		// adjust HB timeouts for test
@@ -1511,6 +1780,15 @@ func TestPublishMetrics(t *testing.T) {
 				ap := c.AvailablePlugins()
 				So(ap, ShouldNotBeEmpty)
 			})
+			Convey("Publish to file without an ack", func() {
+				var buf bytes.Buffer
+				gob.NewEncoder(&buf).Encode([]plugin.MetricType{
+					*plugin.NewMetricType(core.NewNamespace("foo"), time.Now(), nil, "", 1),
+				})
+				ack, errs := c.PublishMetricsWithAck(plugin.SnapGOBContentType, buf.Bytes(), "mock-file", 3, n.Table(), uuid.New())
+				So(errs, ShouldBeNil)
+				So(ack, ShouldBeNil)
+			})
 		})
 		c.Stop()
 		time.Sleep(100 * time.Millisecond)
@@ -0,0 +1,106 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sync"
+	"time"
+)
+
+// deadPluginRestarts tracks the restarts of dead available plugins so that
+// each pool has at most one restart waiting for its backoff, and the waiting
+// restarts are abandoned when the runner stops.
+type deadPluginRestarts struct {
+	sync.Mutex
+	// pending holds the keys of the pools with a restart waiting
+	pending map[string]bool
+	// restarted maps the key of a pool to when a plugin was last restarted
+	// in it
+	restarted map[string]time.Time
+	quit      chan struct{}
+}
+
+func newDeadPluginRestarts() *deadPluginRestarts {
+	return &deadPluginRestarts{
+		pending:   make(map[string]bool),
+		restarted: make(map[string]time.Time),
+	}
+}
+
+// start allows restarts to wait for their backoff until stop is called.
+func (d *deadPluginRestarts) start() {
+	d.Lock()
+	defer d.Unlock()
+	d.quit = make(chan struct{})
+}
+
+// stop abandons the restarts waiting for their backoff.
+func (d *deadPluginRestarts) stop() {
+	d.Lock()
+	defer d.Unlock()
+	if d.quit == nil {
+		return
+	}
+	select {
+	case <-d.quit:
+	default:
+		close(d.quit)
+	}
+}
+
+// stopped returns a channel closed when the restarts are stopped.  The
+// channel is nil, and so never closed, before start is called.
+func (d *deadPluginRestarts) stopped() <-chan struct{} {
+	d.Lock()
+	defer d.Unlock()
+	return d.quit
+}
+
+// begin marks a restart of a plugin in the pool with the key as pending.  It
+// returns false if the pool already has a restart pending.
+func (d *deadPluginRestarts) begin(key string) bool {
+	d.Lock()
+	defer d.Unlock()
+	if d.pending[key] {
+		return false
+	}
+	d.pending[key] = true
+	return true
+}
+
+// end marks the restart in the pool with the key as no longer pending,
+// recording when the plugin was restarted if it was.
+func (d *deadPluginRestarts) end(key string, restarted bool) {
+	d.Lock()
+	defer d.Unlock()
+	delete(d.pending, key)
+	if restarted {
+		d.restarted[key] = time.Now()
+	}
+}
+
+// healthySince returns whether the pool with the key was last restarted at
+// least the period ago.
+func (d *deadPluginRestarts) healthySince(key string, period time.Duration) bool {
+	d.Lock()
+	defer d.Unlock()
+	restarted, ok := d.restarted[key]
+	return ok && time.Since(restarted) >= period
+}
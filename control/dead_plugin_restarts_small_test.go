@@ -0,0 +1,130 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/gomit"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core/control_event"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// restartCountingManager counts the attempts to restart a plugin, failing
+// each of them.
+type restartCountingManager struct {
+	managesPlugins
+	sync.Mutex
+	gets int
+}
+
+func (m *restartCountingManager) get(string) (*loadedPlugin, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.gets++
+	return nil, errors.New("not loaded")
+}
+
+func (m *restartCountingManager) attempts() int {
+	m.Lock()
+	defer m.Unlock()
+	return m.gets
+}
+
+// deadPluginRunner returns a runner restarting dead plugins after the
+// backoff and a pool needing a plugin.
+func deadPluginRunner(backoff time.Duration) (*runner, *restartCountingManager, strategy.Pool) {
+	r := newRunner()
+	r.monitor.Option(MonitorRestartBackoffOption(backoff))
+	m := &restartCountingManager{}
+	r.pluginManager = m
+	pool := addPool(r.availablePlugins, "collector:mock:1")
+	pool.Subscribe("task", strategy.BoundSubscriptionType)
+	r.restarts.start()
+	return r, m, pool
+}
+
+func deadPluginEvent() *control_event.DeadAvailablePluginEvent {
+	return &control_event.DeadAvailablePluginEvent{Name: "mock", Version: 1, Key: "collector:mock:1"}
+}
+
+// restartsSettle returns whether no restart is pending within a second.
+func restartsSettle(r *runner) bool {
+	for i := 0; i < 100; i++ {
+		r.restarts.Lock()
+		pending := len(r.restarts.pending)
+		r.restarts.Unlock()
+		if pending == 0 {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestDeadPluginRestarts(t *testing.T) {
+	Convey("A dead plugin is restarted after the backoff", t, func() {
+		r, m, _ := deadPluginRunner(10 * time.Millisecond)
+		r.HandleGomitEvent(gomit.Event{Body: deadPluginEvent()})
+		So(restartsSettle(r), ShouldBeTrue)
+		So(m.attempts(), ShouldEqual, 1)
+	})
+	Convey("A pool has one restart pending at a time", t, func() {
+		r, m, _ := deadPluginRunner(50 * time.Millisecond)
+		r.HandleGomitEvent(gomit.Event{Body: deadPluginEvent()})
+		r.HandleGomitEvent(gomit.Event{Body: deadPluginEvent()})
+		So(restartsSettle(r), ShouldBeTrue)
+		So(m.attempts(), ShouldEqual, 1)
+	})
+	Convey("Stopping the runner abandons the pending restarts", t, func() {
+		r, m, _ := deadPluginRunner(time.Hour)
+		r.HandleGomitEvent(gomit.Event{Body: deadPluginEvent()})
+		r.restarts.stop()
+		So(restartsSettle(r), ShouldBeTrue)
+		So(m.attempts(), ShouldEqual, 0)
+	})
+	Convey("The restart count is reset once the pool has been healthy", t, func() {
+		r, _, pool := deadPluginRunner(time.Hour)
+		r.monitor.Option(MonitorRestartResetOption(time.Millisecond))
+		pool.IncRestartCount()
+		pool.IncRestartCount()
+		r.restarts.end("collector:mock:1", true)
+		time.Sleep(2 * time.Millisecond)
+		r.HandleGomitEvent(gomit.Event{Body: deadPluginEvent()})
+		So(pool.RestartCount(), ShouldEqual, 0)
+		r.restarts.stop()
+	})
+	Convey("The restart count is kept while the pool keeps dying", t, func() {
+		r, _, pool := deadPluginRunner(time.Hour)
+		pool.IncRestartCount()
+		r.restarts.end("collector:mock:1", true)
+		r.HandleGomitEvent(gomit.Event{Body: deadPluginEvent()})
+		So(pool.RestartCount(), ShouldEqual, 1)
+		r.restarts.stop()
+	})
+}
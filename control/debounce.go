@@ -0,0 +1,138 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/gomit"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+)
+
+// eventDebouncer holds back plugin unload events so a plugin unloaded and
+// loaded again within the window is reported with a single
+// PluginReloadedEvent instead of an unload and a load event.
+type eventDebouncer struct {
+	sync.Mutex
+	window time.Duration
+	emit   func(gomit.EventBody)
+	// pending maps a plugin key to the event held back for it
+	pending map[string]*debouncedEvent
+}
+
+type debouncedEvent struct {
+	timer *time.Timer
+	event gomit.EventBody
+}
+
+func newEventDebouncer(window time.Duration, emit func(gomit.EventBody)) *eventDebouncer {
+	return &eventDebouncer{
+		window:  window,
+		emit:    emit,
+		pending: make(map[string]*debouncedEvent),
+	}
+}
+
+// unloaded holds back the unload event until the window elapses without the
+// plugin being loaded again.
+func (d *eventDebouncer) unloaded(e *control_event.UnloadPluginEvent) {
+	d.Lock()
+	defer d.Unlock()
+	d.hold(debounceKey(e.Type, e.Name, e.Version), e)
+}
+
+// loaded reports whether the load event is part of a reload of a plugin with
+// an event held back.  The held back event is then replaced by a
+// PluginReloadedEvent which is emitted once the window elapses.
+func (d *eventDebouncer) loaded(e *control_event.LoadPluginEvent) bool {
+	d.Lock()
+	defer d.Unlock()
+	key := debounceKey(e.Type, e.Name, e.Version)
+	if _, ok := d.pending[key]; !ok {
+		return false
+	}
+	d.hold(key, &control_event.PluginReloadedEvent{
+		Name:    e.Name,
+		Version: e.Version,
+		Type:    e.Type,
+		Signed:  e.Signed,
+	})
+	return true
+}
+
+// hold replaces any event held back for the key and restarts its window.
+// The caller must hold the lock.
+func (d *eventDebouncer) hold(key string, e gomit.EventBody) {
+	if de, ok := d.pending[key]; ok {
+		de.timer.Stop()
+	}
+	de := &debouncedEvent{event: e}
+	de.timer = time.AfterFunc(d.window, func() {
+		d.Lock()
+		if d.pending[key] != de {
+			d.Unlock()
+			return
+		}
+		delete(d.pending, key)
+		d.Unlock()
+		d.emit(de.event)
+	})
+	d.pending[key] = de
+}
+
+func debounceKey(typ int, name string, version int) string {
+	return core.PluginKey(core.PluginType(typ), name, version)
+}
+
+// debouncingHandler passes the events emitted by control on to the handler,
+// holding back plugin unload events with a debouncer of its own.
+type debouncingHandler struct {
+	handler   gomit.Handler
+	debouncer *eventDebouncer
+}
+
+func newDebouncingHandler(window time.Duration, h gomit.Handler) *debouncingHandler {
+	return &debouncingHandler{
+		handler: h,
+		debouncer: newEventDebouncer(window, func(body gomit.EventBody) {
+			e := gomit.Event{Body: body}
+			e.Header.Time = time.Now()
+			h.HandleGomitEvent(e)
+		}),
+	}
+}
+
+// HandleGomitEvent holds back unload events and the load events reloading a
+// plugin with an unload event held back, and passes on all other events.
+func (d *debouncingHandler) HandleGomitEvent(e gomit.Event) {
+	switch v := e.Body.(type) {
+	case *control_event.UnloadPluginEvent:
+		d.debouncer.unloaded(v)
+		return
+	case *control_event.LoadPluginEvent:
+		if d.debouncer.loaded(v) {
+			return
+		}
+	}
+	d.handler.HandleGomitEvent(e)
+}
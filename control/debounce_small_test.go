@@ -0,0 +1,108 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/gomit"
+
+	"github.com/intelsdi-x/snap/core/control_event"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEventDebouncer(t *testing.T) {
+	Convey("Given an event debouncer", t, func() {
+		emitted := make(chan gomit.EventBody, 10)
+		d := newEventDebouncer(20*time.Millisecond, func(e gomit.EventBody) {
+			emitted <- e
+		})
+		unload := &control_event.UnloadPluginEvent{Name: "mock", Version: 1, Type: 0}
+		load := &control_event.LoadPluginEvent{Name: "mock", Version: 1, Type: 0}
+
+		Convey("a load without a held back unload is not debounced", func() {
+			So(d.loaded(load), ShouldBeFalse)
+		})
+		Convey("an unload not followed by a load is emitted after the window", func() {
+			d.unloaded(unload)
+			So(<-emitted, ShouldEqual, unload)
+		})
+		Convey("an unload and load within the window emit a single reload event", func() {
+			d.unloaded(unload)
+			So(d.loaded(load), ShouldBeTrue)
+			d.unloaded(unload)
+			So(d.loaded(load), ShouldBeTrue)
+			e := <-emitted
+			So(e.Namespace(), ShouldEqual, control_event.PluginReloaded)
+			time.Sleep(40 * time.Millisecond)
+			So(len(emitted), ShouldEqual, 0)
+		})
+		Convey("debouncing is per plugin", func() {
+			d.unloaded(unload)
+			other := &control_event.LoadPluginEvent{Name: "mock", Version: 2, Type: 0}
+			So(d.loaded(other), ShouldBeFalse)
+			So(<-emitted, ShouldEqual, unload)
+		})
+	})
+}
+
+// handledEvents is a gomit.Handler sending the bodies of the events it
+// handles on a channel.
+type handledEvents chan gomit.EventBody
+
+func (h handledEvents) HandleGomitEvent(e gomit.Event) {
+	h <- e.Body
+}
+
+func TestDebouncingHandler(t *testing.T) {
+	unload := &control_event.UnloadPluginEvent{Name: "mock", Version: 1, Type: 0}
+	load := &control_event.LoadPluginEvent{Name: "mock", Version: 1, Type: 0}
+	Convey("A debouncing handler passes on an unload once after the window", t, func() {
+		handled := make(handledEvents, 10)
+		h := newDebouncingHandler(20*time.Millisecond, handled)
+		h.HandleGomitEvent(gomit.Event{Body: unload})
+		So(len(handled), ShouldEqual, 0)
+		So(<-handled, ShouldEqual, unload)
+		time.Sleep(40 * time.Millisecond)
+		So(len(handled), ShouldEqual, 0)
+	})
+	Convey("A debouncing handler passes on a reload as a single event", t, func() {
+		handled := make(handledEvents, 10)
+		h := newDebouncingHandler(20*time.Millisecond, handled)
+		h.HandleGomitEvent(gomit.Event{Body: unload})
+		h.HandleGomitEvent(gomit.Event{Body: load})
+		So(len(handled), ShouldEqual, 0)
+		e := <-handled
+		So(e.Namespace(), ShouldEqual, control_event.PluginReloaded)
+		time.Sleep(40 * time.Millisecond)
+		So(len(handled), ShouldEqual, 0)
+	})
+	Convey("A debouncing handler passes on other events as they happen", t, func() {
+		handled := make(handledEvents, 10)
+		h := newDebouncingHandler(20*time.Millisecond, handled)
+		h.HandleGomitEvent(gomit.Event{Body: load})
+		So(len(handled), ShouldEqual, 1)
+		So(<-handled, ShouldEqual, load)
+	})
+}
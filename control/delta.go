@@ -0,0 +1,97 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// DeltaCollection is the PluginControlOpt which has CollectMetrics return
+// only the metrics whose values changed since they were last collected for
+// the task, along with a full snapshot of the collected metrics every
+// fullEvery collections.  The first collection after a task subscribes is
+// always a full snapshot.  A fullEvery of 1 or less returns every metric on
+// every collection, which is the default.
+func DeltaCollection(fullEvery int) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.delta = nil
+		if fullEvery > 1 {
+			c.delta = newDeltaTracker(fullEvery)
+		}
+	}
+}
+
+// deltaTracker records the values last collected for each task so that
+// unchanged metrics can be left out of collections.
+type deltaTracker struct {
+	sync.Mutex
+	fullEvery int
+	// tasks maps a task ID to the values collected for it
+	tasks map[string]*taskValues
+}
+
+type taskValues struct {
+	// collections is the number of collections made for the task
+	collections int
+	values      map[string]interface{}
+}
+
+func newDeltaTracker(fullEvery int) *deltaTracker {
+	return &deltaTracker{
+		fullEvery: fullEvery,
+		tasks:     make(map[string]*taskValues),
+	}
+}
+
+// changed records the values of the metrics collected for the task and
+// returns those whose value changed since they were last collected, or all
+// of them when the collection is due a full snapshot.
+func (d *deltaTracker) changed(taskID string, mts []core.Metric) []core.Metric {
+	d.Lock()
+	defer d.Unlock()
+	tv, ok := d.tasks[taskID]
+	if !ok {
+		tv = &taskValues{values: make(map[string]interface{})}
+		d.tasks[taskID] = tv
+	}
+	full := tv.collections%d.fullEvery == 0
+	tv.collections++
+	changed := make([]core.Metric, 0, len(mts))
+	for _, mt := range mts {
+		ns := mt.Namespace().String()
+		last, seen := tv.values[ns]
+		tv.values[ns] = mt.Data()
+		if full || !seen || !reflect.DeepEqual(last, mt.Data()) {
+			changed = append(changed, mt)
+		}
+	}
+	return changed
+}
+
+// forget drops the values collected for the task so that its next
+// collection is a full snapshot.
+func (d *deltaTracker) forget(taskID string) {
+	d.Lock()
+	defer d.Unlock()
+	delete(d.tasks, taskID)
+}
@@ -0,0 +1,98 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func deltaMetrics(values ...int) []core.Metric {
+	mts := make([]core.Metric, len(values))
+	for i, v := range values {
+		mts[i] = plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", string(rune('a'+i))), Data_: v}
+	}
+	return mts
+}
+
+func TestDeltaTracker(t *testing.T) {
+	Convey("The first collection for a task returns every metric", t, func() {
+		d := newDeltaTracker(3)
+		So(len(d.changed("task", deltaMetrics(1, 2))), ShouldEqual, 2)
+	})
+	Convey("Only changed metrics are returned between full snapshots", t, func() {
+		d := newDeltaTracker(3)
+		d.changed("task", deltaMetrics(1, 2))
+		changed := d.changed("task", deltaMetrics(1, 3))
+		So(len(changed), ShouldEqual, 1)
+		So(changed[0].Data(), ShouldEqual, 3)
+		So(d.changed("task", deltaMetrics(1, 3)), ShouldBeEmpty)
+	})
+	Convey("A full snapshot is returned every fullEvery collections", t, func() {
+		d := newDeltaTracker(3)
+		d.changed("task", deltaMetrics(1, 2))
+		d.changed("task", deltaMetrics(1, 2))
+		d.changed("task", deltaMetrics(1, 2))
+		So(len(d.changed("task", deltaMetrics(1, 2))), ShouldEqual, 2)
+	})
+	Convey("Metrics collected for the first time are returned", t, func() {
+		d := newDeltaTracker(3)
+		d.changed("task", deltaMetrics(1))
+		So(len(d.changed("task", deltaMetrics(1, 2))), ShouldEqual, 1)
+	})
+	Convey("State is kept per task", t, func() {
+		d := newDeltaTracker(3)
+		d.changed("task", deltaMetrics(1, 2))
+		So(len(d.changed("other", deltaMetrics(1, 2))), ShouldEqual, 2)
+	})
+	Convey("A forgotten task's next collection is a full snapshot", t, func() {
+		d := newDeltaTracker(3)
+		d.changed("task", deltaMetrics(1, 2))
+		d.forget("task")
+		So(len(d.changed("task", deltaMetrics(1, 2))), ShouldEqual, 2)
+	})
+}
+
+func TestDeltaCollection(t *testing.T) {
+	Convey("Delta collection is disabled by a fullEvery of 1 or less", t, func() {
+		So(New(GetDefaultConfig()).delta, ShouldBeNil)
+		So(New(GetDefaultConfig(), DeltaCollection(1)).delta, ShouldBeNil)
+		So(New(GetDefaultConfig(), DeltaCollection(5)).delta, ShouldNotBeNil)
+	})
+	Convey("CollectMetrics leaves out metrics which have not changed", t, func() {
+		c := New(GetDefaultConfig(), DeltaCollection(10))
+		c.Started = true
+		mt := addFakeCollector(c, "delta", &fakeCollectorClient{})
+		deadline := time.Now().Add(time.Second)
+		mts, errs := c.CollectMetrics([]core.Metric{mt}, deadline, "task", nil)
+		So(errs, ShouldBeEmpty)
+		So(len(mts), ShouldEqual, 1)
+		mts, errs = c.CollectMetrics([]core.Metric{mt}, deadline, "task", nil)
+		So(errs, ShouldBeEmpty)
+		So(mts, ShouldBeEmpty)
+	})
+}
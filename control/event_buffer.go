@@ -0,0 +1,89 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/gomit"
+)
+
+// DefaultEventReplayBufferSize is the number of recent control events kept
+// for replay to late subscribers
+var DefaultEventReplayBufferSize = 1000
+
+// eventBuffer is a gomit.Handler keeping the most recent events emitted by
+// control in a ring buffer.
+type eventBuffer struct {
+	sync.RWMutex
+	events []gomit.Event
+	next   int
+	full   bool
+}
+
+func newEventBuffer(size int) *eventBuffer {
+	return &eventBuffer{
+		events: make([]gomit.Event, size),
+	}
+}
+
+// HandleGomitEvent records the event, replacing the oldest event once the
+// buffer is full.
+func (b *eventBuffer) HandleGomitEvent(e gomit.Event) {
+	b.Lock()
+	defer b.Unlock()
+	if len(b.events) == 0 {
+		return
+	}
+	b.events[b.next] = e
+	b.next = (b.next + 1) % len(b.events)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// since returns the buffered events emitted after t, oldest first.
+func (b *eventBuffer) since(t time.Time) []gomit.Event {
+	b.RLock()
+	defer b.RUnlock()
+	ordered := b.events[:b.next]
+	if b.full {
+		ordered = append(append([]gomit.Event{}, b.events[b.next:]...), b.events[:b.next]...)
+	}
+	var events []gomit.Event
+	for _, e := range ordered {
+		if e.Header.Time.After(t) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// ReplayEvents sends the buffered control events emitted after since to the
+// handler, oldest first, and returns the number of events replayed.  This
+// allows a handler registered after startup to catch up on events it missed.
+func (p *pluginControl) ReplayEvents(h gomit.Handler, since time.Time) int {
+	events := p.eventBuffer.since(since)
+	for _, e := range events {
+		h.HandleGomitEvent(e)
+	}
+	return len(events)
+}
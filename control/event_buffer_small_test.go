@@ -0,0 +1,61 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/gomit"
+	"github.com/intelsdi-x/snap/core/control_event"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func eventAt(name string, t time.Time) gomit.Event {
+	e := gomit.Event{Body: &control_event.LoadPluginEvent{Name: name}}
+	e.Header.Time = t
+	return e
+}
+
+func TestEventBuffer(t *testing.T) {
+	Convey("Given an event buffer holding two events", t, func() {
+		start := time.Now()
+		b := newEventBuffer(2)
+		b.HandleGomitEvent(eventAt("a", start.Add(time.Second)))
+		b.HandleGomitEvent(eventAt("b", start.Add(2*time.Second)))
+
+		Convey("events after a time are returned oldest first", func() {
+			events := b.since(start)
+			So(len(events), ShouldEqual, 2)
+			So(events[0].Body.(*control_event.LoadPluginEvent).Name, ShouldEqual, "a")
+			So(len(b.since(start.Add(time.Second))), ShouldEqual, 1)
+		})
+		Convey("the oldest event is dropped once the buffer is full", func() {
+			b.HandleGomitEvent(eventAt("c", start.Add(3*time.Second)))
+			events := b.since(start)
+			So(len(events), ShouldEqual, 2)
+			So(events[0].Body.(*control_event.LoadPluginEvent).Name, ShouldEqual, "b")
+			So(events[1].Body.(*control_event.LoadPluginEvent).Name, ShouldEqual, "c")
+		})
+	})
+}
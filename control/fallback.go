@@ -0,0 +1,118 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sync"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// fallbackPlugins maps a metric namespace to the key of the collector used
+// when the plugin providing the metric has no running instances.
+type fallbackPlugins struct {
+	sync.RWMutex
+	table map[string]string
+}
+
+func newFallbackPlugins() *fallbackPlugins {
+	return &fallbackPlugins{
+		table: make(map[string]string),
+	}
+}
+
+func (f *fallbackPlugins) get(ns core.Namespace) (string, bool) {
+	f.RLock()
+	defer f.RUnlock()
+	key, ok := f.table[ns.String()]
+	return key, ok
+}
+
+// SetFallbackPlugin sets the collector, by its {type}:{name}:{version} key,
+// used for the metric namespace when the plugin providing the metric is not
+// loaded or has no running instances.  A version less than 1 uses the latest
+// loaded version.  An empty key removes the fallback.
+func (p *pluginControl) SetFallbackPlugin(namespace []string, fallbackKey string) error {
+	ns := core.NewNamespace(namespace...).String()
+	p.fallbacks.Lock()
+	defer p.fallbacks.Unlock()
+	if fallbackKey == "" {
+		delete(p.fallbacks.table, ns)
+		return nil
+	}
+	if typ, _, _, err := core.ParsePluginKey(fallbackKey); err != nil || typ != core.CollectorPluginType {
+		return serror.New(ErrBadKey, map[string]interface{}{"key": fallbackKey})
+	}
+	p.fallbacks.table[ns] = fallbackKey
+	return nil
+}
+
+// fallbackPlugin returns the loaded fallback plugin for the namespace.
+func (p *pluginControl) fallbackPlugin(ns core.Namespace) (*loadedPlugin, bool) {
+	key, ok := p.fallbacks.get(ns)
+	if !ok {
+		return nil, false
+	}
+	lp, err := p.pluginManager.get(key)
+	if err != nil {
+		return nil, false
+	}
+	return lp, true
+}
+
+// primaryUnavailable returns true if the pool for the plugin key has no
+// running plugins.
+func (p *pluginControl) primaryUnavailable(pluginKey string) bool {
+	pool, serr := p.pluginRunner.AvailablePlugins().getPool(pluginKey)
+	return serr != nil || pool == nil || pool.Count() == 0
+}
+
+// collectFromFallback collects the metrics from the fallback plugin of their
+// namespace.  It returns false if any of the metrics has no fallback.
+func (p *pluginControl) collectFromFallback(pluginKey string, mts []core.Metric, taskID string) ([]core.Metric, bool, error) {
+	byFallback := make(map[string][]core.Metric)
+	for _, mt := range mts {
+		lp, ok := p.fallbackPlugin(mt.Namespace())
+		if !ok {
+			return nil, false, nil
+		}
+		byFallback[lp.Key()] = append(byFallback[lp.Key()], mt)
+	}
+	var metrics []core.Metric
+	for key, fmts := range byFallback {
+		collected, err := p.pluginRunner.AvailablePlugins().collectMetrics(key, fmts, taskID)
+		if err != nil {
+			return nil, true, err
+		}
+		metrics = append(metrics, p.tagProvenance(key, collected)...)
+		e := &control_event.CollectFallbackEvent{
+			TaskId:         taskID,
+			PrimaryPlugin:  pluginKey,
+			FallbackPlugin: key,
+		}
+		for _, mt := range fmts {
+			e.Namespaces = append(e.Namespaces, mt.Namespace().String())
+		}
+		p.eventManager.Emit(e)
+	}
+	return metrics, true, nil
+}
@@ -0,0 +1,112 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+# Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// addFallbackCollector loads and runs a collector with the name to fall back
+// to.
+func addFallbackCollector(c *pluginControl, name string, cli *fakeCollectorClient) {
+	addFakeCollector(c, name, cli)
+	c.pluginManager.(*pluginManager).loadedPlugins.add(&loadedPlugin{
+		Type:         plugin.CollectorPluginType,
+		Meta:         plugin.PluginMeta{Name: name, Version: 1},
+		ConfigPolicy: cpolicy.New(),
+	})
+}
+
+// stopPlugins replaces the pool of the plugin key with one without running
+// plugins, returning the pool replaced.
+func stopPlugins(c *pluginControl, key string) strategy.Pool {
+	aps := c.pluginRunner.AvailablePlugins()
+	aps.RLock()
+	pool := aps.table[key]
+	aps.RUnlock()
+	addPool(aps, key)
+	return pool
+}
+
+func TestSetFallbackPlugin(t *testing.T) {
+	Convey("Collection switches to the fallback while the primary is unavailable and back after", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		primary, backup := &fakeCollectorClient{}, &fakeCollectorClient{}
+		mt := addFakeCollector(c, "primary", primary)
+		addFallbackCollector(c, "backup", backup)
+		So(c.SetFallbackPlugin(mt.Namespace().Strings(), "collector:backup:1"), ShouldBeNil)
+
+		pool := stopPlugins(c, "collector:primary:1")
+		metrics, errs := c.CollectMetrics([]core.Metric{mt}, time.Now().Add(time.Second), "task", nil)
+		So(errs, ShouldBeEmpty)
+		So(metrics, ShouldHaveLength, 1)
+		So(backup.calls, ShouldEqual, 1)
+		So(primary.calls, ShouldEqual, 0)
+
+		setPool(c.pluginRunner.AvailablePlugins(), "collector:primary:1", pool)
+		metrics, errs = c.CollectMetrics([]core.Metric{mt}, time.Now().Add(time.Second), "task", nil)
+		So(errs, ShouldBeEmpty)
+		So(metrics, ShouldHaveLength, 1)
+		So(primary.calls, ShouldEqual, 1)
+		So(backup.calls, ShouldEqual, 1)
+	})
+	Convey("Collection fails while the primary is unavailable once the fallback is removed", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		backup := &fakeCollectorClient{}
+		mt := addFakeCollector(c, "primary", &fakeCollectorClient{})
+		addFallbackCollector(c, "backup", backup)
+		So(c.SetFallbackPlugin(mt.Namespace().Strings(), "collector:backup:1"), ShouldBeNil)
+		So(c.SetFallbackPlugin(mt.Namespace().Strings(), ""), ShouldBeNil)
+
+		stopPlugins(c, "collector:primary:1")
+		_, errs := c.CollectMetrics([]core.Metric{mt}, time.Now().Add(time.Second), "task", nil)
+		So(errs, ShouldNotBeEmpty)
+		So(backup.calls, ShouldEqual, 0)
+	})
+	Convey("Metrics collected from the fallback are tagged with its provenance", t, func() {
+		c := New(GetDefaultConfig(), ProvenanceTags(true))
+		c.Started = true
+		mt := addFakeCollector(c, "primary", &fakeCollectorClient{})
+		addFallbackCollector(c, "backup", &fakeCollectorClient{})
+		So(c.SetFallbackPlugin(mt.Namespace().Strings(), "collector:backup:1"), ShouldBeNil)
+
+		stopPlugins(c, "collector:primary:1")
+		metrics, errs := c.CollectMetrics([]core.Metric{mt}, time.Now().Add(time.Second), "task", nil)
+		So(errs, ShouldBeEmpty)
+		So(metrics, ShouldHaveLength, 1)
+		So(metrics[0].Tags()[ProvenancePluginTag], ShouldEqual, "backup")
+		So(metrics[0].Tags()[CollectedVersionTag], ShouldEqual, "1")
+	})
+	Convey("A fallback which is not a collector is rejected", t, func() {
+		c := New(GetDefaultConfig())
+		So(c.SetFallbackPlugin([]string{"intel", "mock", "foo"}, "publisher:file:1"), ShouldNotBeNil)
+		So(c.SetFallbackPlugin([]string{"intel", "mock", "foo"}, "bad"), ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,222 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+	"github.com/intelsdi-x/snap/grpc/controlproxy"
+)
+
+var (
+	// ErrRemoteControlNoMetrics - error message when a remote control added
+	// with AddRemoteControl has no metrics in its catalog
+	ErrRemoteControlNoMetrics = errors.New("remote control has no metrics in its catalog")
+)
+
+// remoteControl is the part of a remote control's API used to collect from
+// it, which is implemented by controlproxy.ControlProxy.
+type remoteControl interface {
+	MatchQueryToNamespaces(core.Namespace) ([]core.Namespace, serror.SnapError)
+	CollectMetrics([]core.Metric, time.Time, string, map[string]map[string]string) ([]core.Metric, []error)
+	ValidateDeps([]core.Metric, []core.SubscribedPlugin) []serror.SnapError
+	SubscribeDeps(string, []core.Metric, []core.Plugin) []serror.SnapError
+	UnsubscribeDeps(string, []core.Metric, []core.Plugin) []serror.SnapError
+}
+
+// remoteControls holds the remote controls whose catalogs are federated
+// with the local catalog and which of them provides each remote metric.
+type remoteControls struct {
+	sync.RWMutex
+	controls map[string]remoteControl
+	// providers maps the namespaces of remote metrics to the address of the
+	// remote control which provides them
+	providers map[string]string
+}
+
+func newRemoteControls() *remoteControls {
+	return &remoteControls{
+		controls:  make(map[string]remoteControl),
+		providers: make(map[string]string),
+	}
+}
+
+// AddRemoteControl federates the metric catalog of the control listening on
+// the host:port addr with the local catalog.  CollectMetrics collects the
+// metrics which are not in the local catalog but are in a remote control's
+// catalog from that control, so metrics of collectors running on remote
+// agents can be collected as if they were local.  Subscriptions to those
+// metrics are validated and made by the remote control.  The remote catalog
+// is read when the control is added, and adding the control again rereads it.
+func (p *pluginControl) AddRemoteControl(addr string) error {
+	f := map[string]interface{}{"remote-control": addr}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return serror.New(err, f)
+	}
+	portNum, err := strconv.Atoi(port)
+	if err != nil {
+		return serror.New(err, f)
+	}
+	rc, err := controlproxy.New(host, portNum)
+	if err != nil {
+		return serror.New(err, f)
+	}
+	return p.remotes.add(addr, rc)
+}
+
+// add reads the catalog of the remote control and records the remote
+// control as the provider of its metrics.
+func (r *remoteControls) add(addr string, rc remoteControl) error {
+	f := map[string]interface{}{"remote-control": addr}
+	nss, serr := rc.MatchQueryToNamespaces(core.NewNamespace("*"))
+	if serr != nil {
+		serr.SetFields(f)
+		return serr
+	}
+	if len(nss) == 0 {
+		return serror.New(ErrRemoteControlNoMetrics, f)
+	}
+	r.Lock()
+	defer r.Unlock()
+	for ns, provider := range r.providers {
+		if provider == addr {
+			delete(r.providers, ns)
+		}
+	}
+	for _, ns := range nss {
+		r.providers[ns.String()] = addr
+	}
+	r.controls[addr] = rc
+	return nil
+}
+
+// split returns the metrics to collect locally, which are those in the local
+// catalog or provided by no remote control, and the metrics to collect from
+// each remote control keyed by its address.
+func (r *remoteControls) split(cat catalogsMetrics, mts []core.Metric) ([]core.Metric, map[string][]core.Metric) {
+	r.RLock()
+	defer r.RUnlock()
+	if len(r.providers) == 0 {
+		return mts, nil
+	}
+	local := make([]core.Metric, 0, len(mts))
+	remote := map[string][]core.Metric{}
+	for _, mt := range mts {
+		version := mt.Version()
+		if version == 0 {
+			version = -1
+		}
+		if _, err := cat.Get(mt.Namespace(), version); err == nil {
+			local = append(local, mt)
+			continue
+		}
+		addr, ok := r.providers[mt.Namespace().String()]
+		if !ok {
+			local = append(local, mt)
+			continue
+		}
+		remote[addr] = append(remote[addr], mt)
+	}
+	return local, remote
+}
+
+// collect collects the metrics from their remote controls concurrently.
+func (r *remoteControls) collect(remote map[string][]core.Metric, deadline time.Time, taskID string, allTags map[string]map[string]string) ([]core.Metric, []error) {
+	type remoteResult struct {
+		metrics []core.Metric
+		errs    []error
+	}
+	results := make(chan remoteResult, len(remote))
+	r.RLock()
+	for addr, mts := range remote {
+		go func(rc remoteControl, mts []core.Metric) {
+			metrics, errs := rc.CollectMetrics(mts, deadline, taskID, allTags)
+			results <- remoteResult{metrics: metrics, errs: errs}
+		}(r.controls[addr], mts)
+	}
+	r.RUnlock()
+
+	var (
+		metrics []core.Metric
+		errs    []error
+	)
+	for range remote {
+		res := <-results
+		metrics = append(metrics, res.metrics...)
+		errs = append(errs, res.errs...)
+	}
+	return metrics, errs
+}
+
+func (r *remoteControls) validateDeps(remote map[string][]core.Metric) []serror.SnapError {
+	var serrs []serror.SnapError
+	for rc, mts := range r.byControl(remote) {
+		serrs = append(serrs, rc.ValidateDeps(mts, nil)...)
+	}
+	return serrs
+}
+
+// subscribeDeps subscribes the task to the metrics on their remote controls.
+// If any subscription fails those made are undone.
+func (r *remoteControls) subscribeDeps(taskID string, remote map[string][]core.Metric) []serror.SnapError {
+	subscribed := map[string][]core.Metric{}
+	for addr, mts := range remote {
+		rc := r.get(addr)
+		if serrs := rc.SubscribeDeps(taskID, mts, nil); len(serrs) > 0 {
+			r.unsubscribeDeps(taskID, subscribed)
+			return serrs
+		}
+		subscribed[addr] = mts
+	}
+	return nil
+}
+
+func (r *remoteControls) unsubscribeDeps(taskID string, remote map[string][]core.Metric) []serror.SnapError {
+	var serrs []serror.SnapError
+	for rc, mts := range r.byControl(remote) {
+		serrs = append(serrs, rc.UnsubscribeDeps(taskID, mts, nil)...)
+	}
+	return serrs
+}
+
+func (r *remoteControls) get(addr string) remoteControl {
+	r.RLock()
+	defer r.RUnlock()
+	return r.controls[addr]
+}
+
+// byControl returns the metrics keyed by the remote control providing them
+// rather than its address.
+func (r *remoteControls) byControl(remote map[string][]core.Metric) map[remoteControl][]core.Metric {
+	r.RLock()
+	defer r.RUnlock()
+	controls := make(map[remoteControl][]core.Metric, len(remote))
+	for addr, mts := range remote {
+		controls[r.controls[addr]] = mts
+	}
+	return controls
+}
@@ -0,0 +1,132 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeRemoteControl is a remote control whose catalog holds nss.
+type fakeRemoteControl struct {
+	nss          []core.Namespace
+	subscribed   []core.Metric
+	unsubscribed []core.Metric
+	subErr       serror.SnapError
+}
+
+func (f *fakeRemoteControl) MatchQueryToNamespaces(core.Namespace) ([]core.Namespace, serror.SnapError) {
+	return f.nss, nil
+}
+
+func (f *fakeRemoteControl) CollectMetrics(mts []core.Metric, _ time.Time, _ string, _ map[string]map[string]string) ([]core.Metric, []error) {
+	metrics := make([]core.Metric, len(mts))
+	for i, mt := range mts {
+		metrics[i] = plugin.MetricType{Namespace_: mt.Namespace(), Data_: "remote"}
+	}
+	return metrics, nil
+}
+
+func (f *fakeRemoteControl) ValidateDeps([]core.Metric, []core.SubscribedPlugin) []serror.SnapError {
+	return nil
+}
+
+func (f *fakeRemoteControl) SubscribeDeps(_ string, mts []core.Metric, _ []core.Plugin) []serror.SnapError {
+	if f.subErr != nil {
+		return []serror.SnapError{f.subErr}
+	}
+	f.subscribed = append(f.subscribed, mts...)
+	return nil
+}
+
+func (f *fakeRemoteControl) UnsubscribeDeps(_ string, mts []core.Metric, _ []core.Plugin) []serror.SnapError {
+	f.unsubscribed = append(f.unsubscribed, mts...)
+	return nil
+}
+
+func TestRemoteControls(t *testing.T) {
+	remoteNs := core.NewNamespace("intel", "remote", "foo")
+	remoteMt := plugin.MetricType{Namespace_: remoteNs}
+	Convey("Adding a remote control with an empty catalog fails", t, func() {
+		c := New(GetDefaultConfig())
+		err := c.remotes.add("remote:8082", &fakeRemoteControl{})
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, ErrRemoteControlNoMetrics.Error())
+	})
+	Convey("Adding a remote control with a bad address fails", t, func() {
+		c := New(GetDefaultConfig())
+		So(c.AddRemoteControl("remote"), ShouldNotBeNil)
+		So(c.AddRemoteControl("remote:port"), ShouldNotBeNil)
+	})
+	Convey("Metrics are split between the local catalog and remote controls", t, func() {
+		c := New(GetDefaultConfig())
+		local := addFakeCollector(c, "local", &fakeCollectorClient{})
+		So(c.remotes.add("remote:8082", &fakeRemoteControl{nss: []core.Namespace{remoteNs}}), ShouldBeNil)
+		unknown := plugin.MetricType{Namespace_: core.NewNamespace("intel", "unknown")}
+		lmts, remote := c.remotes.split(c.metricCatalog, []core.Metric{local, remoteMt, unknown})
+		So(len(lmts), ShouldEqual, 2)
+		So(lmts[0].Namespace().String(), ShouldEqual, local.Namespace().String())
+		So(lmts[1].Namespace().String(), ShouldEqual, unknown.Namespace().String())
+		So(len(remote["remote:8082"]), ShouldEqual, 1)
+	})
+	Convey("Remote metrics are collected from their remote control", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		local := addFakeCollector(c, "local", &fakeCollectorClient{})
+		So(c.remotes.add("remote:8082", &fakeRemoteControl{nss: []core.Namespace{remoteNs}}), ShouldBeNil)
+		mts, errs := c.CollectMetrics([]core.Metric{local, remoteMt}, time.Now().Add(time.Second), "task", nil)
+		So(errs, ShouldBeEmpty)
+		So(len(mts), ShouldEqual, 2)
+		var found bool
+		for _, mt := range mts {
+			if mt.Namespace().String() == remoteNs.String() {
+				found = true
+				So(mt.Data(), ShouldEqual, "remote")
+			}
+		}
+		So(found, ShouldBeTrue)
+	})
+	Convey("Remote metrics are subscribed and unsubscribed on their remote control", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		rc := &fakeRemoteControl{nss: []core.Namespace{remoteNs}}
+		So(c.remotes.add("remote:8082", rc), ShouldBeNil)
+		So(c.ValidateDeps([]core.Metric{remoteMt}, nil), ShouldBeEmpty)
+		So(c.SubscribeDeps("task", []core.Metric{remoteMt}, nil), ShouldBeEmpty)
+		So(len(rc.subscribed), ShouldEqual, 1)
+		So(c.UnsubscribeDeps("task", []core.Metric{remoteMt}, nil), ShouldBeEmpty)
+		So(len(rc.unsubscribed), ShouldEqual, 1)
+	})
+	Convey("A failed remote subscription fails SubscribeDeps", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		rc := &fakeRemoteControl{nss: []core.Namespace{remoteNs}, subErr: serror.New(ErrRemoteControlNoMetrics)}
+		So(c.remotes.add("remote:8082", rc), ShouldBeNil)
+		So(c.SubscribeDeps("task", []core.Metric{remoteMt}, nil), ShouldNotBeEmpty)
+	})
+}
@@ -0,0 +1,106 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func catalogNamespace(c *pluginControl, lp *loadedPlugin, ns ...string) {
+	mt := plugin.MetricType{Namespace_: core.NewNamespace(ns...), Version_: lp.Version()}
+	So(c.metricCatalog.AddLoadedMetricType(lp, mt), ShouldBeNil)
+}
+
+func fetchedNamespaces(mts []core.CatalogedMetric) map[string]bool {
+	out := map[string]bool{}
+	for _, mt := range mts {
+		out[mt.Namespace().String()] = true
+	}
+	return out
+}
+
+func TestFetchMetricsGlob(t *testing.T) {
+	Convey("Given a catalog of psutil and mock metrics", t, func() {
+		c := New(GetDefaultConfig())
+		psutil := newCollector("psutil")
+		mock := newCollector("mock")
+		catalogNamespace(c, psutil, "intel", "psutil", "load", "load1")
+		catalogNamespace(c, psutil, "intel", "psutil", "load", "load5")
+		catalogNamespace(c, psutil, "intel", "psutil", "cpu", "user")
+		catalogNamespace(c, mock, "intel", "mock", "load", "load1")
+		catalogNamespace(c, mock, "intel", "mock", "load")
+
+		Convey("* matches a single element", func() {
+			mts, err := c.FetchMetrics(core.NewNamespace("intel", "*", "load", "load1"), 0)
+			So(err, ShouldBeNil)
+			So(fetchedNamespaces(mts), ShouldResemble, map[string]bool{
+				"/intel/psutil/load/load1": true,
+				"/intel/mock/load/load1":   true,
+			})
+		})
+		Convey("** matches zero or more elements", func() {
+			mts, err := c.FetchMetrics(core.NewNamespace("intel", "psutil", "**"), 0)
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 3)
+
+			mts, err = c.FetchMetrics(core.NewNamespace("intel", "mock", "load", "**"), 0)
+			So(err, ShouldBeNil)
+			So(fetchedNamespaces(mts), ShouldResemble, map[string]bool{
+				"/intel/mock/load":       true,
+				"/intel/mock/load/load1": true,
+			})
+		})
+		Convey("overlapping patterns return each metric once", func() {
+			mts, err := c.FetchMetrics(core.NewNamespace("**", "load", "**"), 0)
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 4)
+
+			mts, err = c.FetchMetrics(core.NewNamespace("intel", "**", "*"), 0)
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 5)
+		})
+		Convey("a pattern matching nothing returns no metrics", func() {
+			mts, err := c.FetchMetrics(core.NewNamespace("intel", "*", "disk"), 0)
+			So(err, ShouldBeNil)
+			So(mts, ShouldBeEmpty)
+
+			mts, err = c.FetchMetrics(core.NewNamespace("intel", "*"), 0)
+			So(err, ShouldBeNil)
+			So(mts, ShouldBeEmpty)
+		})
+		Convey("the version filter still applies", func() {
+			mts, err := c.FetchMetrics(core.NewNamespace("**"), 2)
+			So(err, ShouldBeNil)
+			So(mts, ShouldBeEmpty)
+		})
+		Convey("a namespace without wildcards is still a prefix fetch", func() {
+			mts, err := c.FetchMetrics(core.NewNamespace("intel", "psutil"), 0)
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 3)
+		})
+	})
+}
@@ -0,0 +1,122 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+)
+
+// DefaultHealthStaleThreshold is how long a subscribed plugin may go
+// without being hit before it is reported unhealthy
+var DefaultHealthStaleThreshold = 10 * time.Minute
+
+// HealthReport summarizes the health of plugin control and the liveness of
+// its plugin pools.
+type HealthReport struct {
+	// Healthy is true when control is started and every plugin is healthy
+	Healthy bool
+	Started bool
+	Plugins []PluginHealth
+}
+
+// PluginHealth describes the liveness of a plugin pool.
+type PluginHealth struct {
+	// Key is the pool key in the {type}:{name}:{version} form
+	Key                string
+	Healthy            bool
+	Reasons            []string
+	RunningCount       int
+	SubscriptionCount  int
+	RestartCount       int
+	FailedHealthChecks int
+	HitCount           int
+	// LastHit is the most recent hit on any running instance of the plugin
+	LastHit time.Time
+}
+
+// Health returns a report of the health of control and each plugin pool.
+// A plugin is unhealthy when it has reached its restart limit, is failing
+// health checks, or is subscribed to but has no running instances or has
+// not been hit within DefaultHealthStaleThreshold.
+func (p *pluginControl) Health() HealthReport {
+	report := HealthReport{
+		Healthy: p.Started,
+		Started: p.Started,
+	}
+	aps := p.pluginRunner.AvailablePlugins()
+	aps.RLock()
+	for key, pool := range aps.table {
+		ph := poolHealth(key, pool, time.Now())
+		if !ph.Healthy {
+			report.Healthy = false
+		}
+		report.Plugins = append(report.Plugins, ph)
+	}
+	aps.RUnlock()
+	sort.Sort(byPluginHealthKey(report.Plugins))
+	return report
+}
+
+func poolHealth(key string, pool strategy.Pool, now time.Time) PluginHealth {
+	ph := PluginHealth{
+		Key:               key,
+		Healthy:           true,
+		SubscriptionCount: pool.SubscriptionCount(),
+		RestartCount:      pool.RestartCount(),
+	}
+	pool.RLock()
+	for _, ap := range pool.Plugins() {
+		ph.RunningCount++
+		ph.HitCount += ap.HitCount()
+		if ap.LastHit().After(ph.LastHit) {
+			ph.LastHit = ap.LastHit()
+		}
+		if a, ok := ap.(*availablePlugin); ok {
+			ph.FailedHealthChecks += a.healthCheckFailures()
+		}
+	}
+	pool.RUnlock()
+
+	if ph.RestartCount >= MaxPluginRestartCount {
+		ph.Reasons = append(ph.Reasons, "restart limit reached")
+	}
+	if ph.FailedHealthChecks > 0 {
+		ph.Reasons = append(ph.Reasons, fmt.Sprintf("%d failed health checks", ph.FailedHealthChecks))
+	}
+	if ph.SubscriptionCount > 0 {
+		if ph.RunningCount == 0 {
+			ph.Reasons = append(ph.Reasons, "no running instances")
+		} else if now.Sub(ph.LastHit) > DefaultHealthStaleThreshold {
+			ph.Reasons = append(ph.Reasons, fmt.Sprintf("not hit since %s", ph.LastHit.Format(time.RFC3339)))
+		}
+	}
+	ph.Healthy = len(ph.Reasons) == 0
+	return ph
+}
+
+type byPluginHealthKey []PluginHealth
+
+func (b byPluginHealthKey) Len() int           { return len(b) }
+func (b byPluginHealthKey) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byPluginHealthKey) Less(i, j int) bool { return b[i].Key < b[j].Key }
@@ -0,0 +1,93 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/control/strategy/fixtures"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPoolHealth(t *testing.T) {
+	Convey("Given a subscribed plugin pool", t, func() {
+		now := time.Now()
+		ap := fixtures.NewMockAvailablePlugin().WithName("mock").WithLastHit(now.Add(-time.Minute))
+		pool := newTestPool("collector:mock:1", ap)
+		pool.Subscribe("task", strategy.BoundSubscriptionType)
+
+		Convey("a recently hit plugin is healthy", func() {
+			ph := poolHealth("collector:mock:1", pool, now)
+			So(ph.Healthy, ShouldBeTrue)
+			So(ph.RunningCount, ShouldEqual, 1)
+			So(ph.SubscriptionCount, ShouldEqual, 1)
+		})
+		Convey("a plugin not hit within the threshold is unhealthy", func() {
+			ph := poolHealth("collector:mock:1", pool, now.Add(DefaultHealthStaleThreshold))
+			So(ph.Healthy, ShouldBeFalse)
+			So(len(ph.Reasons), ShouldEqual, 1)
+		})
+		Convey("a plugin at its restart limit is unhealthy", func() {
+			for i := 0; i < MaxPluginRestartCount; i++ {
+				pool.IncRestartCount()
+			}
+			ph := poolHealth("collector:mock:1", pool, now)
+			So(ph.Healthy, ShouldBeFalse)
+			So(ph.Reasons, ShouldContain, "restart limit reached")
+		})
+	})
+}
+
+func TestPoolHealthFailedChecks(t *testing.T) {
+	Convey("Failed health checks are counted while health checks run", t, func() {
+		ap := &availablePlugin{
+			name:       "mock",
+			version:    1,
+			key:        "collector:mock:1",
+			pluginType: plugin.CollectorPluginType,
+			client:     &unreachableClient{failingClient{err: errors.New("connection is shut down")}},
+			healthChan: make(chan error, 1),
+			emitter:    &recordingEmitter{},
+		}
+		pool := newTestPool("collector:mock:1", ap)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < DefaultHealthCheckFailureLimit-1; i++ {
+				ap.CheckHealth()
+			}
+		}()
+		for i := 0; i < 10; i++ {
+			poolHealth("collector:mock:1", pool, time.Now())
+		}
+		<-done
+		ph := poolHealth("collector:mock:1", pool, time.Now())
+		So(ph.FailedHealthChecks, ShouldEqual, DefaultHealthCheckFailureLimit-1)
+		So(ph.Healthy, ShouldBeFalse)
+	})
+}
@@ -0,0 +1,130 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CallInfo describes a call to a plugin which is in flight.
+type CallInfo struct {
+	// PluginKey is the {type}:{name}:{version} key of the plugin called
+	PluginKey string
+	// PluginID is the ID of the running instance of the plugin called
+	PluginID uint32
+	// Method is the plugin call, which is collect, process or publish
+	Method string
+	// TaskID is the task the call is made for, if known
+	TaskID  string
+	Started time.Time
+	// Duration is how long the call had been in flight when it was
+	// returned by InFlightCalls
+	Duration time.Duration
+}
+
+type byCallStart []CallInfo
+
+func (b byCallStart) Len() int           { return len(b) }
+func (b byCallStart) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byCallStart) Less(i, j int) bool { return b[i].Started.Before(b[j].Started) }
+
+// InFlightCalls returns the calls to collect from, process with and publish
+// to plugins which are in flight, longest running first, so that a plugin
+// holding up a collection can be identified.
+func (p *pluginControl) InFlightCalls() []CallInfo {
+	return p.pluginRunner.AvailablePlugins().inFlight.calls(time.Now())
+}
+
+// inFlightCalls tracks the calls to plugins which are in flight.  Starting
+// and finishing a call only holds the lock to add or remove it.
+type inFlightCalls struct {
+	next uint64
+	sync.Mutex
+	inFlight map[uint64]CallInfo
+	// waiters are closed once no calls are in flight
+	waiters []chan struct{}
+}
+
+func newInFlightCalls() *inFlightCalls {
+	return &inFlightCalls{
+		inFlight: make(map[uint64]CallInfo),
+	}
+}
+
+// start records the call to the running plugin in the pool with the key as
+// in flight and returns the ID to pass to done once it returns.
+func (c *inFlightCalls) start(key string, p *availablePlugin, method, taskID string) uint64 {
+	id := atomic.AddUint64(&c.next, 1)
+	call := CallInfo{
+		PluginKey: key,
+		PluginID:  p.id,
+		Method:    method,
+		TaskID:    taskID,
+		Started:   time.Now(),
+	}
+	c.Lock()
+	c.inFlight[id] = call
+	c.Unlock()
+	return id
+}
+
+// done records that the call with the ID returned.
+func (c *inFlightCalls) done(id uint64) {
+	c.Lock()
+	delete(c.inFlight, id)
+	if len(c.inFlight) == 0 {
+		for _, w := range c.waiters {
+			close(w)
+		}
+		c.waiters = nil
+	}
+	c.Unlock()
+}
+
+// drained returns a channel which is closed once no calls are in flight.
+func (c *inFlightCalls) drained() <-chan struct{} {
+	c.Lock()
+	defer c.Unlock()
+	ch := make(chan struct{})
+	if len(c.inFlight) == 0 {
+		close(ch)
+		return ch
+	}
+	c.waiters = append(c.waiters, ch)
+	return ch
+}
+
+// calls returns the calls in flight at now, longest running first.
+func (c *inFlightCalls) calls(now time.Time) []CallInfo {
+	c.Lock()
+	calls := make([]CallInfo, 0, len(c.inFlight))
+	for _, call := range c.inFlight {
+		calls = append(calls, call)
+	}
+	c.Unlock()
+	for i := range calls {
+		calls[i].Duration = now.Sub(calls[i].Started)
+	}
+	sort.Sort(byCallStart(calls))
+	return calls
+}
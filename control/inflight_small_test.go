@@ -0,0 +1,66 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestInFlightCalls(t *testing.T) {
+	Convey("A call is in flight until it returns", t, func() {
+		c := New(GetDefaultConfig())
+		cli := &fakeCollectorClient{delay: 200 * time.Millisecond}
+		mt := addFakeCollector(c, "slow", cli)
+		done := make(chan struct{})
+		go func() {
+			c.pluginRunner.AvailablePlugins().collectMetrics("collector:slow:1", []core.Metric{mt}, "task")
+			close(done)
+		}()
+		So(eventually(func() bool { return len(c.InFlightCalls()) == 1 }), ShouldBeTrue)
+		call := c.InFlightCalls()[0]
+		So(call.PluginKey, ShouldEqual, "collector:slow:1")
+		So(call.Method, ShouldEqual, "collect")
+		So(call.TaskID, ShouldEqual, "task")
+		So(call.Duration, ShouldBeGreaterThanOrEqualTo, 0)
+		<-done
+		So(c.InFlightCalls(), ShouldBeEmpty)
+	})
+	Convey("Calls are returned longest running first", t, func() {
+		calls := newInFlightCalls()
+		ap := &availablePlugin{id: 1}
+		first := calls.start("collector:a:1", ap, "collect", "task")
+		time.Sleep(time.Millisecond)
+		calls.start("publisher:b:1", ap, "publish", "task")
+		now := time.Now()
+		inFlight := calls.calls(now)
+		So(len(inFlight), ShouldEqual, 2)
+		So(inFlight[0].PluginKey, ShouldEqual, "collector:a:1")
+		So(inFlight[0].Duration, ShouldBeGreaterThan, inFlight[1].Duration)
+		calls.done(first)
+		So(len(calls.calls(now)), ShouldEqual, 1)
+	})
+}
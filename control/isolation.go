@@ -0,0 +1,82 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+)
+
+// NamespaceIsolation is the PluginControlOpt which catalogs the metrics of
+// each collector under a namespace prefixed with the name of the plugin, so
+// that /intel/mock/foo exposed by the plugin mock is cataloged and subscribed
+// to as /mock/intel/mock/foo.  This prevents plugins exposing the same
+// namespaces from colliding in the catalog.  Namespaces are not prefixed by
+// default.  Isolation applies to the default metric catalog.
+func NamespaceIsolation(isolate bool) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.namespaceIsolation = isolate
+		if mc, ok := c.metricCatalog.(*metricCatalog); ok {
+			mc.isolateNamespaces = isolate
+		}
+	}
+}
+
+// isolateNamespace returns the namespace prefixed with the plugin name.
+func isolateNamespace(pluginName string, ns core.Namespace) core.Namespace {
+	return append(core.NewNamespace(pluginName), ns...)
+}
+
+// pluginMetrics returns the metrics with the plugin name prefix removed from
+// their namespaces, as they are known to the plugin.
+func pluginMetrics(pluginName string, mts []core.Metric) []core.Metric {
+	out := make([]core.Metric, len(mts))
+	for i, m := range mts {
+		out[i] = m
+		if ns := m.Namespace(); len(ns) > 1 && ns[0].Value == pluginName {
+			out[i] = metricWithNamespace(m, ns[1:])
+		}
+	}
+	return out
+}
+
+// isolatedMetrics returns the metrics collected from the plugin with their
+// namespaces prefixed with the plugin name.
+func isolatedMetrics(pluginName string, mts []core.Metric) []core.Metric {
+	out := make([]core.Metric, len(mts))
+	for i, m := range mts {
+		out[i] = metricWithNamespace(m, isolateNamespace(pluginName, m.Namespace()))
+	}
+	return out
+}
+
+func metricWithNamespace(m core.Metric, ns core.Namespace) core.Metric {
+	return plugin.MetricType{
+		Namespace_:          ns,
+		Version_:            m.Version(),
+		LastAdvertisedTime_: m.LastAdvertisedTime(),
+		Config_:             m.Config(),
+		Data_:               m.Data(),
+		Tags_:               m.Tags(),
+		Description_:        m.Description(),
+		Unit_:               m.Unit(),
+		Timestamp_:          m.Timestamp(),
+	}
+}
@@ -0,0 +1,66 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNamespaceIsolation(t *testing.T) {
+	Convey("Given plugins exposing the same namespace", t, func() {
+		c := New(GetDefaultConfig(), NamespaceIsolation(true))
+		mc := c.metricCatalog.(*metricCatalog)
+		for _, name := range []string{"vendora", "vendorb"} {
+			lp := &loadedPlugin{
+				Type:         plugin.CollectorPluginType,
+				Meta:         plugin.PluginMeta{Name: name, Version: 1},
+				ConfigPolicy: cpolicy.New(),
+			}
+			mt := plugin.MetricType{Namespace_: core.NewNamespace("system", "cpu"), Version_: 1}
+			So(mc.AddLoadedMetricType(lp, mt), ShouldBeNil)
+		}
+		Convey("each is cataloged under the plugin name", func() {
+			a, err := mc.Get(core.NewNamespace("vendora", "system", "cpu"), 1)
+			So(err, ShouldBeNil)
+			So(a.Plugin.Name(), ShouldEqual, "vendora")
+			b, err := mc.Get(core.NewNamespace("vendorb", "system", "cpu"), 1)
+			So(err, ShouldBeNil)
+			So(b.Plugin.Name(), ShouldEqual, "vendorb")
+		})
+		Convey("the prefix is removed for and restored after collection", func() {
+			requested := []core.Metric{plugin.MetricType{Namespace_: core.NewNamespace("vendora", "system", "cpu")}}
+			mts := pluginMetrics("vendora", requested)
+			So(mts[0].Namespace().String(), ShouldEqual, "/system/cpu")
+			So(isolatedMetrics("vendora", mts)[0].Namespace().String(), ShouldEqual, "/vendora/system/cpu")
+		})
+	})
+	Convey("Namespaces are not isolated by default", t, func() {
+		c := New(GetDefaultConfig())
+		So(c.namespaceIsolation, ShouldBeFalse)
+		So(c.metricCatalog.(*metricCatalog).isolateNamespaces, ShouldBeFalse)
+	})
+}
@@ -0,0 +1,82 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"math/rand"
+	"time"
+)
+
+// PoolJitter is the PluginControlOpt which delays collecting from a plugin
+// pool whose plugins are all serving calls by a random duration of up to max.
+// This spreads the load of tasks which collect from the plugin at the same
+// time.  The delay never runs past the collection deadline.  Jitter is
+// disabled when max is zero, the default.
+func PoolJitter(max time.Duration) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.poolJitter = max
+	}
+}
+
+// jitter delays collecting from the pool for the plugin key when the pool is
+// contended.
+func (p *pluginControl) jitter(pluginKey string, deadline time.Time) {
+	if p.poolJitter <= 0 || !p.pluginRunner.AvailablePlugins().contended(pluginKey) {
+		return
+	}
+	if d := jitterDelay(p.poolJitter, time.Now(), deadline); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// jitterDelay returns a random delay of up to max which ends before the
+// deadline.  A zero deadline does not bound the delay.
+func jitterDelay(max time.Duration, now, deadline time.Time) time.Duration {
+	if !deadline.IsZero() {
+		if remaining := deadline.Sub(now); remaining < max {
+			max = remaining
+		}
+	}
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+// contended returns whether every plugin in the pool for the plugin key is
+// serving a call.
+func (ap *availablePlugins) contended(pluginKey string) bool {
+	pool, err := ap.getPool(pluginKey)
+	if err != nil || pool == nil {
+		return false
+	}
+	pool.RLock()
+	defer pool.RUnlock()
+	plugins := pool.Plugins()
+	if len(plugins) == 0 {
+		return false
+	}
+	for _, p := range plugins {
+		if a, ok := p.(*availablePlugin); !ok || !a.busy() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJitterDelay(t *testing.T) {
+	Convey("Jitter delays", t, func() {
+		now := time.Now()
+		Convey("are within the max jitter", func() {
+			for i := 0; i < 100; i++ {
+				d := jitterDelay(time.Millisecond, now, time.Time{})
+				So(d, ShouldBeGreaterThanOrEqualTo, 0)
+				So(d, ShouldBeLessThan, time.Millisecond)
+			}
+		})
+		Convey("end before the deadline", func() {
+			for i := 0; i < 100; i++ {
+				d := jitterDelay(time.Second, now, now.Add(time.Millisecond))
+				So(d, ShouldBeLessThan, time.Millisecond)
+			}
+		})
+		Convey("are zero past the deadline", func() {
+			So(jitterDelay(time.Second, now, now.Add(-time.Second)), ShouldEqual, 0)
+		})
+		Convey("are zero when jitter is disabled", func() {
+			So(jitterDelay(0, now, time.Time{}), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestPoolContended(t *testing.T) {
+	Convey("Given a pool of two plugins", t, func() {
+		a := &availablePlugin{name: "mock", version: 1}
+		b := &availablePlugin{name: "mock", version: 1}
+		aps := newAvailablePlugins()
+		addPool(aps, "collector:mock:1", a, b)
+
+		Convey("it is not contended while a plugin is idle", func() {
+			a.acquire()
+			So(aps.contended("collector:mock:1"), ShouldBeFalse)
+			a.release()
+		})
+		Convey("it is contended when every plugin is serving a call", func() {
+			a.acquire()
+			b.acquire()
+			So(aps.contended("collector:mock:1"), ShouldBeTrue)
+			a.release()
+			So(aps.contended("collector:mock:1"), ShouldBeFalse)
+			b.release()
+		})
+		Convey("an unknown pool is not contended", func() {
+			So(aps.contended("collector:other:1"), ShouldBeFalse)
+		})
+	})
+}
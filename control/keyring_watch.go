@@ -0,0 +1,155 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"os"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/gomit"
+	"golang.org/x/crypto/openpgp"
+
+	"github.com/intelsdi-x/snap/core/control_event"
+)
+
+// KeyringWatchInterval is the PluginControlOpt which checks the keyring files
+// for changes every interval once control is started.  A changed keyring is
+// reloaded once it has been completely written, which is when it is
+// unchanged between two checks and reads as a keyring, and a
+// KeyringReloadedEvent is emitted.  Plugin signatures are validated against
+// the reloaded keyring, so RevalidateSignatures can be called on the event to
+// rotate keys without a restart.  Keyring files are not watched by default.
+func KeyringWatchInterval(d time.Duration) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.keyringWatchInterval = d
+	}
+}
+
+// keyringState identifies the contents of a keyring file.
+type keyringState struct {
+	modTime time.Time
+	size    int64
+}
+
+// keyringWatcher polls the keyring files for changes.
+type keyringWatcher struct {
+	interval time.Duration
+	files    func() []string
+	emit     func(gomit.EventBody)
+	// seen is the state of each keyring file at the last check
+	seen map[string]keyringState
+	// loaded is the state of each keyring file when it was last loaded
+	loaded map[string]keyringState
+	done   chan struct{}
+}
+
+func newKeyringWatcher(interval time.Duration, files func() []string, emit func(gomit.EventBody)) *keyringWatcher {
+	return &keyringWatcher{
+		interval: interval,
+		files:    files,
+		emit:     emit,
+		seen:     make(map[string]keyringState),
+		loaded:   make(map[string]keyringState),
+		done:     make(chan struct{}),
+	}
+}
+
+func (w *keyringWatcher) start() {
+	w.check()
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.check()
+			case <-w.done:
+				return
+			}
+		}
+	}()
+}
+
+func (w *keyringWatcher) stop() {
+	close(w.done)
+}
+
+// check reloads each keyring file which changed and has settled since the
+// last check.  Files which are missing, such as while being replaced by a
+// rename, are checked again next time.
+func (w *keyringWatcher) check() {
+	for _, file := range w.files() {
+		fi, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		current := keyringState{modTime: fi.ModTime(), size: fi.Size()}
+		loaded, ok := w.loaded[file]
+		if !ok {
+			w.loaded[file] = current
+			w.seen[file] = current
+			continue
+		}
+		if current == loaded {
+			w.seen[file] = current
+			continue
+		}
+		if current != w.seen[file] {
+			// the file is still being written
+			w.seen[file] = current
+			continue
+		}
+		if err := readKeyringFile(file); err != nil {
+			log.WithFields(log.Fields{
+				"_module":      "control",
+				"_block":       "keyring-watch",
+				"keyring-file": file,
+				"error":        err,
+			}).Warn("changed keyring could not be read")
+			continue
+		}
+		w.loaded[file] = current
+		log.WithFields(log.Fields{
+			"_module":      "control",
+			"_block":       "keyring-watch",
+			"keyring-file": file,
+		}).Info("keyring reloaded")
+		w.emit(&control_event.KeyringReloadedEvent{KeyringFile: file})
+	}
+}
+
+// readKeyringFile returns an error if the file is not an armored or
+// unarmored keyring.
+func readKeyringFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := openpgp.ReadArmoredKeyRing(f); err == nil {
+		return nil
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return err
+	}
+	_, err = openpgp.ReadKeyRing(f)
+	return err
+}
@@ -0,0 +1,85 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intelsdi-x/gomit"
+	"github.com/intelsdi-x/snap/core/control_event"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// watchKeyring returns a watcher which has checked a keyring file written
+// with the content, and the events it emits.
+func watchKeyring(dir string, content []byte) (string, *keyringWatcher, *[]gomit.EventBody) {
+	keyring := filepath.Join(dir, "keyring.gpg")
+	So(ioutil.WriteFile(keyring, content, 0644), ShouldBeNil)
+	events := &[]gomit.EventBody{}
+	w := newKeyringWatcher(0, func() []string { return []string{keyring} }, func(e gomit.EventBody) {
+		*events = append(*events, e)
+	})
+	w.check()
+	So(*events, ShouldBeEmpty)
+	return keyring, w, events
+}
+
+func TestKeyringWatcher(t *testing.T) {
+	original, err := ioutil.ReadFile("../pkg/psigning/pubring.gpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, err := ioutil.ReadFile("../pkg/psigning/pubkeys.gpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Convey("A replaced keyring is reloaded once it is unchanged between checks", t, func() {
+		dir, err := ioutil.TempDir("", "snap-keyring")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		keyring, w, events := watchKeyring(dir, original)
+
+		So(ioutil.WriteFile(keyring, rotated, 0644), ShouldBeNil)
+		w.check()
+		So(*events, ShouldBeEmpty)
+		w.check()
+		So(len(*events), ShouldEqual, 1)
+		So((*events)[0].(*control_event.KeyringReloadedEvent).KeyringFile, ShouldEqual, keyring)
+		w.check()
+		So(len(*events), ShouldEqual, 1)
+	})
+	Convey("A partially written keyring is not reloaded", t, func() {
+		dir, err := ioutil.TempDir("", "snap-keyring")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		keyring, w, events := watchKeyring(dir, original)
+
+		So(ioutil.WriteFile(keyring, rotated[:len(rotated)/3], 0644), ShouldBeNil)
+		w.check()
+		w.check()
+		So(*events, ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,67 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// keyringExtensions are the extensions of the files loaded as keyrings from a
+// keyring directory.
+var keyringExtensions = map[string]bool{
+	".gpg":     true,
+	".asc":     true,
+	".pub":     true,
+	".pubring": true,
+}
+
+// keyrings returns the keyring files set with SetKeyringFile with each
+// directory replaced by the keyring files in it.  Directories are read on
+// every call so keyrings added to or removed from them are used without
+// setting them again.
+func (p *pluginControl) keyrings() []string {
+	var keyrings []string
+	for _, path := range p.keyringFiles {
+		fi, err := os.Stat(path)
+		if err != nil || !fi.IsDir() {
+			// a missing keyring file is reported when it is read
+			keyrings = append(keyrings, path)
+			continue
+		}
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			controlLogger.WithFields(log.Fields{
+				"_block":       "keyrings",
+				"keyring-path": path,
+			}).Error(err)
+			continue
+		}
+		for _, f := range files {
+			if !f.IsDir() && keyringExtensions[filepath.Ext(f.Name())] {
+				keyrings = append(keyrings, filepath.Join(path, f.Name()))
+			}
+		}
+	}
+	return keyrings
+}
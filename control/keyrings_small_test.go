@@ -0,0 +1,77 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestKeyrings(t *testing.T) {
+	Convey("Given a keyring directory", t, func() {
+		dir, err := ioutil.TempDir("", "snap-keyrings-")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		for _, name := range []string{"team-a.gpg", "team-b.asc", "README.txt"} {
+			So(ioutil.WriteFile(filepath.Join(dir, name), []byte("key"), 0600), ShouldBeNil)
+		}
+		So(os.Mkdir(filepath.Join(dir, "old.gpg"), 0700), ShouldBeNil)
+		c := New(GetDefaultConfig())
+		c.SetKeyringFile("/etc/snap/keyring.gpg")
+		c.SetKeyringFile(dir)
+
+		Convey("the keyring files in the directory are used", func() {
+			So(c.keyrings(), ShouldResemble, []string{
+				"/etc/snap/keyring.gpg",
+				filepath.Join(dir, "team-a.gpg"),
+				filepath.Join(dir, "team-b.asc"),
+			})
+		})
+		Convey("keyrings added to the directory are used", func() {
+			So(ioutil.WriteFile(filepath.Join(dir, "team-c.gpg"), []byte("key"), 0600), ShouldBeNil)
+			So(c.keyrings(), ShouldContain, filepath.Join(dir, "team-c.gpg"))
+		})
+		Convey("signatures are validated against the keyring files", func() {
+			s := &keyringRecordingManager{}
+			c.signingManager = s
+			c.SetPluginTrustLevel(PluginTrustEnabled)
+			_, err := c.signatureOutcome(signedPlugin([]byte("sig")))
+			So(err, ShouldBeNil)
+			So(s.keyrings, ShouldResemble, c.keyrings())
+		})
+	})
+}
+
+// keyringRecordingManager records the keyrings signatures are validated
+// against.
+type keyringRecordingManager struct {
+	keyrings []string
+}
+
+func (s *keyringRecordingManager) ValidateSignature(keyrings []string, _ string, _ []byte) error {
+	s.keyrings = keyrings
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	log "github.com/Sirupsen/logrus"
+)
+
+// LazyCollectorSpawn is the PluginControlOpt which starts an instance of a
+// collector when CollectMetrics finds the collector has no pool or no running
+// plugins, instead of failing the collection with ErrPoolNotFound.  This
+// allows the first collection after a subscription to succeed before the
+// collector's pool has been started.  Collectors are not spawned on demand by
+// default.
+func LazyCollectorSpawn(enabled bool) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.lazyCollectorSpawn = enabled
+	}
+}
+
+// spawnCollector creates the pool for the collector and starts an instance
+// of the plugin when the pool has no running plugins.  A collector which
+// cannot be started is logged and the collection reports its missing pool.
+func (p *pluginControl) spawnCollector(pluginKey string, lp *loadedPlugin) {
+	// serialize spawns so concurrent collections start a single instance
+	p.lazySpawnMutex.Lock()
+	defer p.lazySpawnMutex.Unlock()
+
+	aps := p.pluginRunner.AvailablePlugins()
+	aps.Lock()
+	pool, err := aps.getOrCreatePool(pluginKey)
+	aps.Unlock()
+	if err == nil && pool.Count() > 0 {
+		return
+	}
+	if err == nil {
+		err = p.verifyPlugin(lp)
+	}
+	if err == nil {
+		err = p.pluginRunner.runPlugin(lp.Details)
+	}
+	f := log.Fields{
+		"_block":     "spawn-collector",
+		"plugin-key": pluginKey,
+	}
+	if err != nil {
+		controlLogger.WithFields(f).WithField("error", err).Warn("unable to spawn collector on demand")
+		return
+	}
+	controlLogger.WithFields(f).Info("spawned collector on demand")
+}
@@ -0,0 +1,68 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+const (
+	// LoadErrorCodeField is the field of an error returned when loading a
+	// plugin holding the code of the error
+	LoadErrorCodeField = "error_code"
+
+	// LoadErrorSignatureInvalid is the code of errors for plugins whose
+	// signature did not validate
+	LoadErrorSignatureInvalid = "signature-invalid"
+	// LoadErrorHandshakeFailed is the code of errors for plugins which did
+	// not respond when started or could not be pinged
+	LoadErrorHandshakeFailed = "handshake-failed"
+	// LoadErrorUnsupportedType is the code of errors for plugins of a type
+	// which is not supported
+	LoadErrorUnsupportedType = "unsupported-plugin-type"
+	// LoadErrorAlreadyLoaded is the code of errors for plugins which are
+	// already loaded
+	LoadErrorAlreadyLoaded = "already-loaded"
+)
+
+// newLoadError returns an error with the code for a plugin load.
+func newLoadError(code string, err error, fields ...map[string]interface{}) serror.SnapError {
+	return setLoadErrorCode(serror.New(err, fields...), code)
+}
+
+// setLoadErrorCode adds the code to the fields of the error.
+func setLoadErrorCode(se serror.SnapError, code string) serror.SnapError {
+	f := map[string]interface{}{LoadErrorCodeField: code}
+	for k, v := range se.Fields() {
+		f[k] = v
+	}
+	se.SetFields(f)
+	return se
+}
+
+// availablePluginErrorCode returns the code of an error creating the
+// available plugin of a plugin being loaded.
+func availablePluginErrorCode(err error) string {
+	if err == strategy.ErrBadType {
+		return LoadErrorUnsupportedType
+	}
+	return LoadErrorHandshakeFailed
+}
@@ -0,0 +1,68 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core/serror"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLoadErrorCodes(t *testing.T) {
+	Convey("An invalid signature has the signature invalid code", t, func() {
+		c := signatureControl(PluginTrustEnabled, errors.New("bad signature"))
+		_, serr := c.verifySignature(signedPlugin([]byte("sig")))
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, "bad signature")
+		So(serr.Fields()[LoadErrorCodeField], ShouldEqual, LoadErrorSignatureInvalid)
+	})
+	Convey("A plugin which is already loaded has the already loaded code", t, func() {
+		l := newLoadedPlugins()
+		So(l.add(newCollector("mock")), ShouldBeNil)
+		serr := setLoadErrorCode(l.add(newCollector("mock")), LoadErrorAlreadyLoaded)
+		So(serr.Error(), ShouldEqual, ErrPluginAlreadyLoaded.Error())
+		So(serr.Fields()[LoadErrorCodeField], ShouldEqual, LoadErrorAlreadyLoaded)
+		Convey("and keeps its other fields", func() {
+			So(serr.Fields()["plugin-name"], ShouldEqual, "mock")
+		})
+	})
+	Convey("An available plugin of an unsupported type has the unsupported type code", t, func() {
+		_, err := newAvailablePlugin(&plugin.Response{Type: plugin.PluginType(9)}, nil, nil, client.NewTimeouts(DefaultClientTimeout))
+		So(err, ShouldEqual, strategy.ErrBadType)
+		So(availablePluginErrorCode(err), ShouldEqual, LoadErrorUnsupportedType)
+		So(availablePluginErrorCode(errors.New("connection refused")), ShouldEqual, LoadErrorHandshakeFailed)
+	})
+	Convey("A load error has the code and fields", t, func() {
+		var serr serror.SnapError = newLoadError(LoadErrorHandshakeFailed, errors.New("no response"), map[string]interface{}{"path": "mock"})
+		So(serr.Error(), ShouldEqual, "no response")
+		So(serr.Fields(), ShouldResemble, map[string]interface{}{
+			LoadErrorCodeField: LoadErrorHandshakeFailed,
+			"path":             "mock",
+		})
+	})
+}
@@ -0,0 +1,102 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrInvalidPluginName - error message when the name a plugin is loaded
+	// from a reader with is not a file name
+	ErrInvalidPluginName = errors.New("Plugin name must be a file name")
+)
+
+// LoadFromReader loads the plugin read from r as Load does.  The plugin is
+// staged as an executable file with the name in a new temporary directory,
+// within the plugin root if one is set.  The signature, if not nil, is
+// validated as the signature of the plugin.  The directory is removed if the
+// load fails and, as for plugins uploaded through the REST API, when the
+// plugin is unloaded.
+func (p *pluginControl) LoadFromReader(r io.Reader, name string, signature []byte) (core.CatalogedPlugin, serror.SnapError) {
+	f := map[string]interface{}{"name": name}
+	if name == "" || name == "." || name == ".." || filepath.Base(name) != name {
+		return nil, serror.New(ErrInvalidPluginName, f)
+	}
+	path, err := stagePlugin(p.pluginRoot, name, r)
+	if err != nil {
+		return nil, serror.New(err, f)
+	}
+	rp, err := core.NewRequestedPlugin(path)
+	if err != nil {
+		os.RemoveAll(filepath.Dir(path))
+		return nil, serror.New(err, f)
+	}
+	rp.SetSignature(signature)
+	rp.SetAutoLoaded(false)
+	pl, serr := p.Load(rp)
+	if serr != nil {
+		os.RemoveAll(filepath.Dir(path))
+		return nil, serr
+	}
+	return pl, nil
+}
+
+// stagePlugin writes the plugin read from r to an executable file with the
+// name in a new temporary directory in dir and returns its path.  Nothing is
+// left behind if staging fails.
+func stagePlugin(dir, name string, r io.Reader) (string, error) {
+	tmp, err := ioutil.TempDir(dir, "snap-plugin-")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(tmp, name)
+	if err := writePlugin(path, r); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	return path, nil
+}
+
+func writePlugin(path string, r io.Reader) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0700)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	if runtime.GOOS != "windows" {
+		if err := file.Chmod(0700); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
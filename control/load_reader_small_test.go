@@ -0,0 +1,95 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// failingReader fails after returning some of the plugin.
+type failingReader struct {
+	read bool
+}
+
+func (r *failingReader) Read(b []byte) (int, error) {
+	if r.read {
+		return 0, errors.New("read failed")
+	}
+	r.read = true
+	return copy(b, "plugin"), nil
+}
+
+func TestLoadFromReader(t *testing.T) {
+	Convey("The staged plugin is removed when the load fails", t, func() {
+		root, err := ioutil.TempDir("", "snap-plugin-root")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(root)
+		c := New(GetDefaultConfig())
+		So(c.SetPluginRoot(root), ShouldBeNil)
+		_, serr := c.LoadFromReader(bytes.NewBufferString("plugin"), "snap-plugin-collector-mock", nil)
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, ErrControllerNotStarted.Error())
+		files, err := ioutil.ReadDir(root)
+		So(err, ShouldBeNil)
+		So(files, ShouldBeEmpty)
+	})
+	Convey("The staged plugin is removed when reading the plugin fails", t, func() {
+		root, err := ioutil.TempDir("", "snap-plugin-root")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(root)
+		c := New(GetDefaultConfig())
+		So(c.SetPluginRoot(root), ShouldBeNil)
+		_, serr := c.LoadFromReader(&failingReader{}, "snap-plugin-collector-mock", nil)
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, "read failed")
+		files, err := ioutil.ReadDir(root)
+		So(err, ShouldBeNil)
+		So(files, ShouldBeEmpty)
+	})
+	Convey("A name which is not a file name is rejected", t, func() {
+		c := New(GetDefaultConfig())
+		_, serr := c.LoadFromReader(bytes.NewBufferString("plugin"), filepath.Join("..", "mock"), nil)
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, ErrInvalidPluginName.Error())
+	})
+	Convey("The plugin is staged as an executable file", t, func() {
+		root, err := ioutil.TempDir("", "snap-plugin-root")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(root)
+		path, err := stagePlugin(root, "mock", bytes.NewBufferString("plugin"))
+		So(err, ShouldBeNil)
+		So(filepath.Base(path), ShouldEqual, "mock")
+		info, err := os.Stat(path)
+		So(err, ShouldBeNil)
+		So(info.Mode().Perm(), ShouldEqual, os.FileMode(0700))
+		b, err := ioutil.ReadFile(path)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, "plugin")
+	})
+}
@@ -0,0 +1,78 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/strategy"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newMockCollector returns a mock collector with the metadata.
+func newMockCollector(meta plugin.PluginMeta) *availablePlugin {
+	ap := newFakeAvailablePlugin(plugin.CollectorPluginType, "mock", 1, &failingClient{})
+	ap.meta = meta
+	return ap
+}
+
+// fillPool inserts mock collectors into a pool with more subscriptions than
+// the pool can serve until the pool is no longer eligible to grow, and
+// returns the number of collectors in the pool.
+func fillPool(c *pluginControl) int {
+	aps := c.pluginRunner.AvailablePlugins()
+	pool, err := aps.getOrCreatePool("collector:mock:1")
+	So(err, ShouldBeNil)
+	for _, task := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		pool.Subscribe(task, strategy.UnboundSubscriptionType)
+	}
+	for pool.Eligible() {
+		So(aps.insert(newMockCollector(plugin.PluginMeta{ConcurrencyCount: 1})), ShouldBeNil)
+	}
+	return pool.Count()
+}
+
+func TestMaxRunningPlugins(t *testing.T) {
+	Convey("Each controller limits its pools to its own max", t, func() {
+		a := New(GetDefaultConfig(), MaxRunningPlugins(2))
+		b := New(GetDefaultConfig(), MaxRunningPlugins(5))
+		So(fillPool(a), ShouldEqual, 2)
+		So(fillPool(b), ShouldEqual, 5)
+	})
+	Convey("The configured max is used by default", t, func() {
+		cfg := GetDefaultConfig()
+		cfg.MaxRunningPlugins = 4
+		So(fillPool(New(cfg)), ShouldEqual, 4)
+	})
+	Convey("A pool of an exclusive plugin runs a single plugin", t, func() {
+		c := New(GetDefaultConfig(), MaxRunningPlugins(5))
+		aps := c.pluginRunner.AvailablePlugins()
+		So(aps.insert(newMockCollector(plugin.PluginMeta{ConcurrencyCount: 1, Exclusive: true})), ShouldBeNil)
+		pool, err := aps.getPool("collector:mock:1")
+		So(err, ShouldBeNil)
+		pool.Subscribe("a", strategy.UnboundSubscriptionType)
+		pool.Subscribe("b", strategy.UnboundSubscriptionType)
+		So(pool.Eligible(), ShouldBeFalse)
+	})
+}
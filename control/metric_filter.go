@@ -0,0 +1,134 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrMetricFilterUnsupported - error message when metric filters are set
+	// on a metric catalog other than the default one
+	ErrMetricFilterUnsupported = errors.New("Metric filters are only supported by the default metric catalog")
+)
+
+// metricFilter restricts the metrics of a plugin which are cataloged.
+type metricFilter struct {
+	allow []core.Namespace
+	deny  []core.Namespace
+}
+
+// permits returns whether a metric with the namespace is cataloged.  Denied
+// namespaces are never cataloged and when there is an allow list only the
+// namespaces it matches are.
+func (f *metricFilter) permits(ns core.Namespace) bool {
+	for _, d := range f.deny {
+		if namespaceMatches(d, ns) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, a := range f.allow {
+		if namespaceMatches(a, ns) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFilterNamespace returns the namespace for a filter pattern such as
+// /intel/mock/*, where * matches any single element.
+func parseFilterNamespace(s string) core.Namespace {
+	return core.NewNamespace(strings.Split(strings.Trim(s, "/"), "/")...)
+}
+
+// SetPluginMetricFilter sets the allow and deny lists for the metrics of the
+// plugin identified by its {type}:{name}:{version} key.  Metrics matching a
+// deny pattern are never added to the catalog and, when allow patterns are
+// given, only metrics matching one of them are.  Patterns are namespaces such
+// as /intel/mock/* where * matches any single element, and are matched
+// against the namespaces the plugin exposes.  The filter is applied when the
+// plugin is next loaded; empty lists remove it.
+func (p *pluginControl) SetPluginMetricFilter(key string, allow []string, deny []string) error {
+	f := map[string]interface{}{
+		"plugin-key": key,
+		"allow":      allow,
+		"deny":       deny,
+	}
+	if _, _, _, err := core.ParsePluginKey(key); err != nil {
+		return serror.New(err, f)
+	}
+	mc, ok := p.metricCatalog.(*metricCatalog)
+	if !ok {
+		return serror.New(ErrMetricFilterUnsupported, f)
+	}
+	if len(allow) == 0 && len(deny) == 0 {
+		mc.setMetricFilter(key, nil)
+		return nil
+	}
+	filter := &metricFilter{}
+	for _, a := range allow {
+		filter.allow = append(filter.allow, parseFilterNamespace(a))
+	}
+	for _, d := range deny {
+		filter.deny = append(filter.deny, parseFilterNamespace(d))
+	}
+	mc.setMetricFilter(key, filter)
+	return nil
+}
+
+// setMetricFilter sets the metric filter for the plugin key, removing it
+// when the filter is nil.
+func (mc *metricCatalog) setMetricFilter(key string, filter *metricFilter) {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	if filter == nil {
+		delete(mc.metricFilters, key)
+		return
+	}
+	mc.metricFilters[key] = filter
+}
+
+// filtered returns whether the metric filter for the plugin excludes the
+// namespace from the catalog.
+func (mc *metricCatalog) filtered(lp *loadedPlugin, ns core.Namespace) bool {
+	mc.mutex.Lock()
+	filter, ok := mc.metricFilters[pluginKey(lp)]
+	mc.mutex.Unlock()
+	if !ok || filter.permits(ns) {
+		return false
+	}
+	log.WithFields(log.Fields{
+		"_module":   "control",
+		"_file":     "metric_filter.go,",
+		"_block":    "filtered",
+		"plugin":    pluginKey(lp),
+		"namespace": ns.String(),
+	}).Debug("metric excluded from the catalog by the plugin metric filter")
+	return true
+}
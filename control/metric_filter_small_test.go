@@ -0,0 +1,85 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPluginMetricFilter(t *testing.T) {
+	Convey("Given a collector exposing several metrics", t, func() {
+		c := New(GetDefaultConfig())
+		mc := c.metricCatalog.(*metricCatalog)
+		lp := &loadedPlugin{
+			Type:         plugin.CollectorPluginType,
+			Meta:         plugin.PluginMeta{Name: "mock", Version: 1},
+			ConfigPolicy: cpolicy.New(),
+		}
+		load := func() {
+			mc.RmUnloadedPluginMetrics(lp)
+			for _, ns := range [][]string{{"intel", "mock", "foo"}, {"intel", "mock", "bar"}, {"intel", "secret", "key"}} {
+				mt := plugin.MetricType{Namespace_: core.NewNamespace(ns...), Version_: 1}
+				So(mc.AddLoadedMetricType(lp, mt), ShouldBeNil)
+			}
+		}
+		cataloged := func(ns ...string) bool {
+			_, err := mc.Get(core.NewNamespace(ns...), 1)
+			return err == nil
+		}
+
+		Convey("denied metrics are not cataloged", func() {
+			So(c.SetPluginMetricFilter("collector:mock:1", nil, []string{"/intel/secret/*"}), ShouldBeNil)
+			load()
+			So(cataloged("intel", "mock", "foo"), ShouldBeTrue)
+			So(cataloged("intel", "mock", "bar"), ShouldBeTrue)
+			So(cataloged("intel", "secret", "key"), ShouldBeFalse)
+		})
+		Convey("only allowed metrics are cataloged", func() {
+			So(c.SetPluginMetricFilter("collector:mock:1", []string{"/intel/mock/*"}, []string{"/intel/mock/bar"}), ShouldBeNil)
+			load()
+			So(cataloged("intel", "mock", "foo"), ShouldBeTrue)
+			So(cataloged("intel", "mock", "bar"), ShouldBeFalse)
+			So(cataloged("intel", "secret", "key"), ShouldBeFalse)
+		})
+		Convey("filters are re-evaluated when the plugin is reloaded", func() {
+			So(c.SetPluginMetricFilter("collector:mock:1", []string{"/intel/mock/foo"}, nil), ShouldBeNil)
+			load()
+			So(cataloged("intel", "mock", "bar"), ShouldBeFalse)
+			So(c.SetPluginMetricFilter("collector:mock:1", nil, nil), ShouldBeNil)
+			load()
+			So(cataloged("intel", "mock", "bar"), ShouldBeTrue)
+		})
+		Convey("filters for other plugins do not apply", func() {
+			So(c.SetPluginMetricFilter("collector:other:1", nil, []string{"/intel/mock/foo"}), ShouldBeNil)
+			load()
+			So(cataloged("intel", "mock", "foo"), ShouldBeTrue)
+		})
+		Convey("an invalid plugin key returns an error", func() {
+			So(c.SetPluginMetricFilter("mock", nil, []string{"/intel/mock/foo"}), ShouldNotBeNil)
+		})
+	})
+}
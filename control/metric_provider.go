@@ -0,0 +1,92 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrMetricProviderMismatch - error message when the plugin a metric
+	// resolves to in the catalog is not the loaded plugin providing it
+	ErrMetricProviderMismatch = errors.New("metric is not provided by the plugin it resolves to")
+)
+
+// verifyMetricProviders cross-checks that the plugin each metric resolves to
+// in the catalog is the loaded collector serving it, so that a task is not
+// subscribed to a plugin which does not provide its metrics.  Metrics which
+// are not in the catalog are left to gatherCollectors to report.
+func (p *pluginControl) verifyMetricProviders(mts []core.Metric) []serror.SnapError {
+	var serrs []serror.SnapError
+	for _, mt := range mts {
+		m, err := p.metricCatalog.Get(mt.Namespace(), mt.Version())
+		if err != nil {
+			continue
+		}
+		if err := p.verifyMetricProvider(mt, m); err != nil {
+			serrs = append(serrs, newSubscriptionError(ProviderMismatchError, err, map[string]interface{}{
+				SubscriptionErrorNamespaceField: mt.Namespace().String(),
+				"version":                       mt.Version(),
+				"plugin":                        pluginKeyOf(m),
+			}))
+		}
+	}
+	return serrs
+}
+
+// verifyMetricProvider returns an error naming the plugin which would serve
+// the metric mt, resolved to m in the catalog, if that plugin is not the
+// loaded collector of the requested version.
+func (p *pluginControl) verifyMetricProvider(mt core.Metric, m *metricType) error {
+	key := pluginKeyOf(m)
+	mismatch := func(reason string) error {
+		return fmt.Errorf("%v: metric %s would be served by plugin %s which %s", ErrMetricProviderMismatch, mt.Namespace(), key, reason)
+	}
+	if m.Plugin == nil {
+		return mismatch("is not loaded")
+	}
+	if m.Plugin.Type != plugin.CollectorPluginType {
+		return mismatch("is not a collector")
+	}
+	if mt.Version() > 0 && m.Plugin.Version() != mt.Version() {
+		return mismatch(fmt.Sprintf("is not version %d", mt.Version()))
+	}
+	lp, err := p.pluginManager.get(key)
+	if err != nil {
+		return mismatch("is not loaded")
+	}
+	if lp != m.Plugin {
+		return mismatch("was reloaded since the metric was cataloged")
+	}
+	return nil
+}
+
+// pluginKeyOf returns the key of the plugin providing the cataloged metric.
+func pluginKeyOf(m *metricType) string {
+	if m.Plugin == nil {
+		return ""
+	}
+	return m.Plugin.Key()
+}
@@ -0,0 +1,99 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// catalogMetric catalogs a metric of lp and returns the metric.
+func catalogMetric(c *pluginControl, lp *loadedPlugin) core.Metric {
+	mt := plugin.MetricType{Namespace_: core.NewNamespace("intel", lp.Name(), "foo"), Version_: lp.Version()}
+	So(c.metricCatalog.AddLoadedMetricType(lp, mt), ShouldBeNil)
+	return mt
+}
+
+func newCollector(name string) *loadedPlugin {
+	return &loadedPlugin{
+		Type:         plugin.CollectorPluginType,
+		Meta:         plugin.PluginMeta{Name: name, Version: 1},
+		ConfigPolicy: cpolicy.New(),
+	}
+}
+
+func TestVerifyMetricProviders(t *testing.T) {
+	Convey("A metric provided by its loaded collector passes", t, func() {
+		c := New(GetDefaultConfig())
+		lp := newCollector("mock")
+		c.pluginManager.(*pluginManager).loadedPlugins.add(lp)
+		mt := catalogMetric(c, lp)
+		So(c.verifyMetricProviders([]core.Metric{mt}), ShouldBeEmpty)
+	})
+	Convey("A metric whose collector is not loaded fails", t, func() {
+		c := New(GetDefaultConfig())
+		mt := catalogMetric(c, newCollector("mock"))
+		serrs := c.verifyMetricProviders([]core.Metric{mt})
+		So(len(serrs), ShouldEqual, 1)
+		So(serrs[0].Fields()[SubscriptionErrorCategoryField], ShouldEqual, ProviderMismatchError)
+		So(serrs[0].Fields()[SubscriptionErrorNamespaceField], ShouldEqual, "/intel/mock/foo")
+		So(serrs[0].Fields()["plugin"], ShouldEqual, "collector:mock:1")
+		So(strings.Contains(serrs[0].Error(), "collector:mock:1"), ShouldBeTrue)
+	})
+	Convey("A metric cataloged for a different instance of the loaded collector fails", t, func() {
+		c := New(GetDefaultConfig())
+		c.pluginManager.(*pluginManager).loadedPlugins.add(newCollector("mock"))
+		mt := catalogMetric(c, newCollector("mock"))
+		serrs := c.verifyMetricProviders([]core.Metric{mt})
+		So(len(serrs), ShouldEqual, 1)
+		So(strings.Contains(serrs[0].Error(), "reloaded"), ShouldBeTrue)
+	})
+	Convey("A metric cataloged for a plugin which is not a collector fails", t, func() {
+		c := New(GetDefaultConfig())
+		lp := newCollector("mock")
+		lp.Type = plugin.ProcessorPluginType
+		c.pluginManager.(*pluginManager).loadedPlugins.add(lp)
+		mt := catalogMetric(c, lp)
+		serrs := c.verifyMetricProviders([]core.Metric{mt})
+		So(len(serrs), ShouldEqual, 1)
+		So(strings.Contains(serrs[0].Error(), "not a collector"), ShouldBeTrue)
+	})
+	Convey("A metric which is not cataloged is left to be reported elsewhere", t, func() {
+		c := New(GetDefaultConfig())
+		mt := plugin.MetricType{Namespace_: core.NewNamespace("intel", "missing")}
+		So(c.verifyMetricProviders([]core.Metric{mt}), ShouldBeEmpty)
+	})
+	Convey("SubscribeDeps fails for a metric served by an unexpected plugin", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		mt := catalogMetric(c, newCollector("mock"))
+		serrs := c.SubscribeDeps("task", []core.Metric{mt}, nil)
+		So(len(serrs), ShouldEqual, 1)
+		So(serrs[0].Fields()[SubscriptionErrorCategoryField], ShouldEqual, ProviderMismatchError)
+	})
+}
@@ -0,0 +1,49 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"strings"
+
+	"github.com/intelsdi-x/snap/core"
+)
+
+// MaxMetricSearchResults is the most metrics returned by SearchMetrics
+var MaxMetricSearchResults = 100
+
+// SearchMetrics returns the cataloged metrics whose namespace or description
+// contains the query, ignoring case, so that metrics can be found without
+// knowing their exact namespace.  At most MaxMetricSearchResults metrics are
+// returned.  An empty query matches no metrics.
+func (p *pluginControl) SearchMetrics(query string) []core.CatalogedMetric {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return nil
+	}
+	var results []core.CatalogedMetric
+	p.metricCatalog.Walk(func(mt *metricType) bool {
+		if strings.Contains(strings.ToLower(mt.Namespace().String()), q) ||
+			strings.Contains(strings.ToLower(mt.Description()), q) {
+			results = append(results, mt)
+		}
+		return len(results) < MaxMetricSearchResults
+	})
+	return results
+}
@@ -0,0 +1,103 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSearchMetrics(t *testing.T) {
+	Convey("Given a catalog of metrics", t, func() {
+		c := New(GetDefaultConfig())
+		lp := &loadedPlugin{
+			Type:         plugin.CollectorPluginType,
+			Meta:         plugin.PluginMeta{Name: "mock", Version: 1},
+			ConfigPolicy: cpolicy.New(),
+		}
+		for _, mt := range []plugin.MetricType{
+			{Namespace_: core.NewNamespace("intel", "cpu", "idle"), Version_: 1, Description_: "Idle CPU time"},
+			{Namespace_: core.NewNamespace("intel", "memory", "free"), Version_: 1, Description_: "Free memory"},
+			{Namespace_: core.NewNamespace("intel", "disk", "reads"), Version_: 1, Description_: "Reads from the disk"},
+		} {
+			So(c.metricCatalog.AddLoadedMetricType(lp, mt), ShouldBeNil)
+		}
+		search := func(q string) []string {
+			var nss []string
+			for _, m := range c.SearchMetrics(q) {
+				nss = append(nss, m.Namespace().String())
+			}
+			return nss
+		}
+
+		Convey("namespaces are matched by substring ignoring case", func() {
+			So(search("MEM"), ShouldResemble, []string{"/intel/memory/free"})
+			So(search("cpu/id"), ShouldResemble, []string{"/intel/cpu/idle"})
+		})
+		Convey("descriptions are matched", func() {
+			So(search("from the"), ShouldResemble, []string{"/intel/disk/reads"})
+		})
+		Convey("results are capped", func() {
+			max := MaxMetricSearchResults
+			MaxMetricSearchResults = 2
+			defer func() { MaxMetricSearchResults = max }()
+			So(len(search("intel")), ShouldEqual, 2)
+		})
+		Convey("an empty query matches nothing", func() {
+			So(search(" "), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestMetricsByPlugin(t *testing.T) {
+	Convey("Cataloged metrics are grouped by their plugin", t, func() {
+		c := New(GetDefaultConfig())
+		cpu := newCollector("cpu")
+		mem := newCollector("memory")
+		for _, m := range []struct {
+			lp *loadedPlugin
+			mt plugin.MetricType
+		}{
+			{cpu, plugin.MetricType{Namespace_: core.NewNamespace("intel", "cpu", "idle"), Version_: 1}},
+			{mem, plugin.MetricType{Namespace_: core.NewNamespace("intel", "memory", "free"), Version_: 1}},
+			{cpu, plugin.MetricType{Namespace_: core.NewNamespace("intel", "cpu", "user"), Version_: 1}},
+		} {
+			So(c.metricCatalog.AddLoadedMetricType(m.lp, m.mt), ShouldBeNil)
+		}
+		byPlugin := c.MetricsByPlugin()
+		So(len(byPlugin), ShouldEqual, 2)
+		var cpuNss []string
+		for _, m := range byPlugin["collector:cpu:1"] {
+			cpuNss = append(cpuNss, m.Namespace().String())
+		}
+		So(cpuNss, ShouldResemble, []string{"/intel/cpu/idle", "/intel/cpu/user"})
+		So(len(byPlugin["collector:memory:1"]), ShouldEqual, 1)
+	})
+	Convey("An empty catalog has no plugins", t, func() {
+		So(New(GetDefaultConfig()).MetricsByPlugin(), ShouldBeEmpty)
+	})
+}
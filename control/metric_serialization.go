@@ -0,0 +1,101 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// serializableContentTypes are the content types control can serialize
+// metrics into itself.
+var serializableContentTypes = []string{
+	plugin.SnapGOBContentType,
+	plugin.SnapJSONContentType,
+	plugin.SnapProtoBuffContentType,
+}
+
+// PublishMetricTypes serializes the metrics into the first content type
+// accepted by the publisher that control can serialize and publishes them.
+func (p *pluginControl) PublishMetricTypes(metrics []core.Metric, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string) []error {
+	if !p.Started {
+		return []error{ErrControllerNotStarted}
+	}
+	accepted, _, err := p.GetPluginContentTypes(pluginName, core.PublisherPluginType, pluginVersion)
+	if err != nil {
+		return []error{err}
+	}
+	contentType, err := serializationContentType(accepted)
+	if err != nil {
+		return []error{fmt.Errorf("publisher %s:%d: %v", pluginName, pluginVersion, err)}
+	}
+	content, contentType, err := plugin.MarshalMetricTypes(contentType, toPluginMetricTypes(metrics))
+	if err != nil {
+		return []error{err}
+	}
+	return p.PublishMetrics(contentType, content, pluginName, pluginVersion, config, taskID)
+}
+
+// serializationContentType returns the first of the accepted content types
+// that control can serialize metrics into.  An accepted type of snap.*
+// resolves to gob.
+func serializationContentType(accepted []string) (string, error) {
+	for _, a := range accepted {
+		if a == plugin.SnapAllContentType {
+			return plugin.SnapGOBContentType, nil
+		}
+		for _, s := range serializableContentTypes {
+			if a == s {
+				return a, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("none of the accepted content types %v can be serialized, serializable content types are %v", accepted, serializableContentTypes)
+}
+
+// toPluginMetricTypes converts metrics into the plugin.MetricType the
+// built-in serializers encode.
+func toPluginMetricTypes(metrics []core.Metric) []plugin.MetricType {
+	mts := make([]plugin.MetricType, len(metrics))
+	for i, m := range metrics {
+		switch mt := m.(type) {
+		case plugin.MetricType:
+			mts[i] = mt
+		case *plugin.MetricType:
+			mts[i] = *mt
+		default:
+			mts[i] = plugin.MetricType{
+				Namespace_:          m.Namespace(),
+				LastAdvertisedTime_: m.LastAdvertisedTime(),
+				Version_:            m.Version(),
+				Config_:             m.Config(),
+				Data_:               m.Data(),
+				Tags_:               m.Tags(),
+				Unit_:               m.Unit(),
+				Description_:        m.Description(),
+				Timestamp_:          m.Timestamp(),
+			}
+		}
+	}
+	return mts
+}
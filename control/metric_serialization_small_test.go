@@ -0,0 +1,76 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSerializationContentType(t *testing.T) {
+	Convey("The first accepted content type control can serialize is chosen", t, func() {
+		ct, err := serializationContentType([]string{"influx.line", plugin.SnapJSONContentType, plugin.SnapGOBContentType})
+		So(err, ShouldBeNil)
+		So(ct, ShouldEqual, plugin.SnapJSONContentType)
+	})
+	Convey("snap.* is serialized as gob", t, func() {
+		ct, err := serializationContentType([]string{plugin.SnapAllContentType})
+		So(err, ShouldBeNil)
+		So(ct, ShouldEqual, plugin.SnapGOBContentType)
+	})
+	Convey("An error is returned when no accepted content type can be serialized", t, func() {
+		_, err := serializationContentType([]string{"influx.line"})
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestToPluginMetricTypes(t *testing.T) {
+	Convey("Metrics serialize in each built-in content type", t, func() {
+		ts := time.Now()
+		metrics := []core.Metric{
+			*plugin.NewMetricType(core.NewNamespace("intel", "foo"), ts, nil, "", 1),
+			&metricType{
+				namespace:          core.NewNamespace("intel", "bar"),
+				version:            2,
+				lastAdvertisedTime: ts,
+			},
+		}
+		mts := toPluginMetricTypes(metrics)
+		So(mts, ShouldHaveLength, 2)
+		So(mts[1].Namespace().String(), ShouldEqual, "/intel/bar")
+		So(mts[1].Version(), ShouldEqual, 2)
+		for _, ct := range serializableContentTypes {
+			content, contentType, err := plugin.MarshalMetricTypes(ct, mts)
+			So(err, ShouldBeNil)
+			So(contentType, ShouldEqual, ct)
+			out, err := plugin.UnmarshallMetricTypes(ct, content)
+			So(err, ShouldBeNil)
+			So(out, ShouldHaveLength, 2)
+			So(out[0].Namespace().String(), ShouldEqual, "/intel/foo")
+		}
+	})
+}
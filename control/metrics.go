@@ -211,6 +211,14 @@ func (m *metricType) Unit() string {
 	return m.unit
 }
 
+// CollectionCost returns the collection cost hinted by the metric's plugin.
+func (m *metricType) CollectionCost() core.CollectionCost {
+	if m.Plugin == nil {
+		return core.UnknownCollectionCost
+	}
+	return m.Plugin.Meta.CollectionCost
+}
+
 type metricCatalog struct {
 	tree  *MTTrie
 	mutex *sync.Mutex
@@ -219,15 +227,27 @@ type metricCatalog struct {
 	// mKeys holds requested metric's keys which can include wildcards and matched to them the cataloged keys
 	mKeys       map[string][]string
 	currentIter int
+
+	// isolateNamespaces catalogs metrics under a namespace prefixed with the
+	// name of their plugin
+	isolateNamespaces bool
+
+	// metricFilters holds the metric filters of plugins by plugin key
+	metricFilters map[string]*metricFilter
+
+	// lastModified is when metrics were last added to or removed from the
+	// catalog
+	lastModified time.Time
 }
 
 func newMetricCatalog() *metricCatalog {
 	return &metricCatalog{
-		tree:        NewMTTrie(),
-		mutex:       &sync.Mutex{},
-		currentIter: 0,
-		keys:        []string{},
-		mKeys:       make(map[string][]string),
+		tree:          NewMTTrie(),
+		mutex:         &sync.Mutex{},
+		currentIter:   0,
+		keys:          []string{},
+		mKeys:         make(map[string][]string),
+		metricFilters: make(map[string]*metricFilter),
 	}
 }
 
@@ -394,6 +414,9 @@ func (mc *metricCatalog) AddLoadedMetricType(lp *loadedPlugin, mt core.Metric) e
 		}).Error("error adding loaded metric type")
 		return err
 	}
+	if mc.filtered(lp, mt.Namespace()) {
+		return nil
+	}
 	newMt := metricType{
 		Plugin:             lp,
 		namespace:          mt.Namespace(),
@@ -404,6 +427,9 @@ func (mc *metricCatalog) AddLoadedMetricType(lp *loadedPlugin, mt core.Metric) e
 		description:        mt.Description(),
 		unit:               mt.Unit(),
 	}
+	if mc.isolateNamespaces && lp.Type == plugin.CollectorPluginType {
+		newMt.namespace = isolateNamespace(lp.Name(), mt.Namespace())
+	}
 	mc.Add(&newMt)
 	return nil
 }
@@ -416,6 +442,7 @@ func (mc *metricCatalog) RmUnloadedPluginMetrics(lp *loadedPlugin) {
 	mc.tree.DeleteByPlugin(lp)
 	// update the contents of matching map (mKeys)
 	mc.updateMatchingMap()
+	mc.lastModified = time.Now()
 }
 
 // Add adds a metricType
@@ -429,10 +456,13 @@ func (mc *metricCatalog) Add(m *metricType) {
 	mc.keys = appendIfMissing(mc.keys, key)
 
 	mc.tree.Add(m)
+	mc.lastModified = time.Now()
 }
 
 // Get retrieves a metric given a namespace and version.
-// If provided a version of -1 the latest plugin will be returned.
+// If provided a version of -1 the latest plugin will be returned.  The
+// highest version is the latest; between plugins of the same version the
+// most recently loaded and then the plugin whose name sorts first wins.
 func (mc *metricCatalog) Get(ns core.Namespace, version int) (*metricType, error) {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
@@ -446,11 +476,16 @@ func (mc *metricCatalog) GetVersions(ns core.Namespace) ([]*metricType, error) {
 	return mc.getVersions(ns.Strings())
 }
 
-// Fetch transactionally retrieves all metrics which fall under namespace ns
+// Fetch transactionally retrieves all metrics which fall under namespace ns.
+// If ns contains "*" or "**" elements it is treated as a glob and only the
+// metrics whose namespace matches the whole pattern are returned.
 func (mc *metricCatalog) Fetch(ns core.Namespace) ([]*metricType, error) {
 	mc.mutex.Lock()
 	defer mc.mutex.Unlock()
 
+	if isGlob(ns.Strings()) {
+		return mc.tree.Match(ns.Strings()), nil
+	}
 	mtsi, err := mc.tree.Fetch(ns.Strings())
 	if err != nil {
 		log.WithFields(log.Fields{
@@ -474,10 +509,41 @@ func (mc *metricCatalog) Remove(ns core.Namespace) {
 	// remove all items from map mKey mapped for this 'ns'
 	key := ns.Key()
 	mc.removeMatchedKey(key)
+	mc.lastModified = time.Now()
+}
+
+// LastModified returns when metrics were last added to or removed from the
+// catalog, or the zero time if the catalog was never modified.
+func (mc *metricCatalog) LastModified() time.Time {
+	mc.mutex.Lock()
+	defer mc.mutex.Unlock()
+	return mc.lastModified
+}
+
+// Walk calls fn for each metricType in the catalog in the order their
+// namespaces were cataloged, stopping when fn returns false.  The catalog is
+// locked only while a snapshot of it is taken, so concurrent walks do not
+// interfere and fn may call back into the catalog.
+func (mc *metricCatalog) Walk(fn func(*metricType) bool) {
+	mc.mutex.Lock()
+	var mts []*metricType
+	for _, key := range mc.keys {
+		mtsi, _ := mc.tree.Get(strings.Split(key, "."))
+		mts = append(mts, mtsi...)
+	}
+	mc.mutex.Unlock()
+
+	for _, mt := range mts {
+		if !fn(mt) {
+			return
+		}
+	}
 }
 
 // Item returns the current metricType in the collection.  The method Next()
 // provides the  means to move the iterator forward.
+//
+// Deprecated: Item and Next share the iterator between callers, use Walk.
 func (mc *metricCatalog) Item() (string, []*metricType) {
 	key := mc.keys[mc.currentIter-1]
 	ns := strings.Split(key, ".")
@@ -492,6 +558,8 @@ func (mc *metricCatalog) Item() (string, []*metricType) {
 // Next returns true until the "end" of the collection is reached.  When
 // the end of the collection is reached the iterator is reset back to the
 // head of the collection.
+//
+// Deprecated: Item and Next share the iterator between callers, use Walk.
 func (mc *metricCatalog) Next() bool {
 	mc.currentIter++
 	if mc.currentIter > len(mc.keys) {
@@ -604,16 +672,33 @@ func getMetricNamespace(key string) core.Namespace {
 	return core.NewNamespace(strings.Split(key, ".")...)
 }
 
+// getLatest returns the latest metric type.  The highest version wins; ties
+// go to the most recently loaded plugin and then to the plugin name which
+// sorts first, so the same metric type is selected regardless of the order
+// of c.
 func getLatest(c []*metricType) *metricType {
 	cur := c[0]
 	for _, mt := range c {
-		if mt.Version() > cur.Version() {
+		if mt.Version() > cur.Version() || (mt.Version() == cur.Version() && loadedBefore(cur.Plugin, mt.Plugin)) {
 			cur = mt
 		}
 	}
 	return cur
 }
 
+// loadedBefore returns true if a loses the tie-break between plugins of the
+// same version to b: a was loaded before b, or they were loaded at the same
+// time and b's name sorts first.
+func loadedBefore(a, b *loadedPlugin) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	if !a.LoadedTime.Equal(b.LoadedTime) {
+		return a.LoadedTime.Before(b.LoadedTime)
+	}
+	return b.Name() < a.Name()
+}
+
 func appendIfMissing(keys []string, ns string) []string {
 	for _, key := range keys {
 		if ns == key {
@@ -623,12 +708,18 @@ func appendIfMissing(keys []string, ns string) []string {
 	return append(keys, ns)
 }
 
+// getVersion returns the metric type of the plugin version, breaking ties as
+// getLatest does.
 func getVersion(c []*metricType, ver int) (*metricType, error) {
+	var found *metricType
 	for _, m := range c {
-		if m.Plugin.Version() == ver {
-			return m, nil
+		if m.Plugin.Version() == ver && (found == nil || loadedBefore(found.Plugin, m.Plugin)) {
+			found = m
 		}
 	}
+	if found != nil {
+		return found, nil
+	}
 	return nil, errMetricNotFound
 }
 
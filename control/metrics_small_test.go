@@ -23,6 +23,7 @@ package control
 
 import (
 	"testing"
+	"time"
 
 	"github.com/intelsdi-x/snap/control/plugin"
 	"github.com/intelsdi-x/snap/core"
@@ -119,3 +120,36 @@ func prepareTestCases() []testCase {
 
 	return testCases
 }
+
+func TestGetLatest(t *testing.T) {
+	Convey("Given metric types from several plugins", t, func() {
+		now := time.Now()
+		newMT := func(name string, version int, loaded time.Time) *metricType {
+			lp := &loadedPlugin{Meta: plugin.PluginMeta{Name: name, Version: version}, LoadedTime: loaded}
+			mt := newMetricType(core.NewNamespace("intel", "mock", "foo"), now, lp)
+			mt.version = version
+			return mt
+		}
+		older := newMT("b", 2, now.Add(-time.Minute))
+		newer := newMT("c", 2, now)
+		sameTime := newMT("a", 2, now)
+		lower := newMT("d", 1, now.Add(time.Minute))
+		Convey("the highest version wins", func() {
+			So(getLatest([]*metricType{lower, older}), ShouldEqual, older)
+			So(getLatest([]*metricType{older, lower}), ShouldEqual, older)
+		})
+		Convey("ties go to the most recently loaded plugin", func() {
+			So(getLatest([]*metricType{older, newer}), ShouldEqual, newer)
+			So(getLatest([]*metricType{newer, older}), ShouldEqual, newer)
+		})
+		Convey("then to the plugin name which sorts first", func() {
+			So(getLatest([]*metricType{newer, sameTime, older}), ShouldEqual, sameTime)
+			So(getLatest([]*metricType{older, sameTime, newer}), ShouldEqual, sameTime)
+		})
+		Convey("getVersion breaks ties the same way", func() {
+			mt, err := getVersion([]*metricType{older, newer, sameTime}, 2)
+			So(err, ShouldBeNil)
+			So(mt, ShouldEqual, sameTime)
+		})
+	})
+}
@@ -22,6 +22,7 @@ limitations under the License.
 package control
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -399,6 +400,53 @@ func TestMetricCatalog(t *testing.T) {
 		})
 	})
 
+	Convey("metricCatalog.Walk()", t, func() {
+		ns := []core.Namespace{
+			core.NewNamespace("test1"),
+			core.NewNamespace("test2"),
+			core.NewNamespace("test3"),
+		}
+		lp := new(loadedPlugin)
+		mc := newMetricCatalog()
+		for _, n := range ns {
+			mc.Add(newMetricType(n, time.Now(), lp))
+		}
+		Convey("visits each metric in the order it was cataloged", func() {
+			var visited []string
+			mc.Walk(func(mt *metricType) bool {
+				visited = append(visited, mt.Namespace().String())
+				return true
+			})
+			So(visited, ShouldResemble, []string{"/test1", "/test2", "/test3"})
+		})
+		Convey("stops when the callback returns false", func() {
+			count := 0
+			mc.Walk(func(mt *metricType) bool {
+				count++
+				return false
+			})
+			So(count, ShouldEqual, 1)
+		})
+		Convey("is safe to use concurrently", func() {
+			var wg sync.WaitGroup
+			counts := make([]int, 10)
+			for i := range counts {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					mc.Walk(func(mt *metricType) bool {
+						counts[i]++
+						return true
+					})
+				}(i)
+			}
+			wg.Wait()
+			for _, c := range counts {
+				So(c, ShouldEqual, len(ns))
+			}
+		})
+	})
+
 	Convey("metricCatalog.Remove()", t, func() {
 		mc := newMetricCatalog()
 		ts := time.Now()
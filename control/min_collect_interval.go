@@ -0,0 +1,159 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// minCollectIntervals limits how often metrics are collected from plugins
+// which have a minimum collect interval.
+type minCollectIntervals struct {
+	sync.Mutex
+	plugins map[string]*minCollectInterval
+}
+
+// minCollectInterval holds the last collections from a plugin which is not
+// collected from more often than the interval.
+type minCollectInterval struct {
+	sync.Mutex
+	interval time.Duration
+	// collections maps the key of each requested metric, identifying it
+	// by its namespace, version and config, to its last collection
+	collections map[string]intervalCollection
+}
+
+// intervalCollection holds the metrics collected for a requested metric.
+type intervalCollection struct {
+	collected time.Time
+	requested core.Metric
+	metrics   []core.Metric
+}
+
+func newMinCollectIntervals() *minCollectIntervals {
+	return &minCollectIntervals{
+		plugins: make(map[string]*minCollectInterval),
+	}
+}
+
+// SetMinCollectInterval sets the minimum interval between collections from
+// the collector identified by its {type}:{name}:{version} key.  A metric
+// requested again with the same config within the interval is returned from
+// its last collection instead of calling the plugin; only the metrics not
+// collected within the interval are collected from the plugin.  An interval
+// of zero removes the minimum.
+func (p *pluginControl) SetMinCollectInterval(key string, d time.Duration) error {
+	if _, _, _, err := core.ParsePluginKey(key); err != nil {
+		return serror.New(err, map[string]interface{}{"plugin-key": key})
+	}
+	p.minCollectIntervals.set(key, d)
+	return nil
+}
+
+func (m *minCollectIntervals) set(key string, d time.Duration) {
+	m.Lock()
+	defer m.Unlock()
+	if d <= 0 {
+		delete(m.plugins, key)
+		return
+	}
+	m.plugins[key] = &minCollectInterval{
+		interval:    d,
+		collections: make(map[string]intervalCollection),
+	}
+}
+
+// collectMetrics collects the metrics with collect unless the plugin was
+// collected from within its minimum collect interval, in which case the
+// metrics collected within the interval are taken from their last collection
+// and only the others are collected.  Collections from the same plugin are
+// serialized so that concurrent tasks share a collection.
+func (m *minCollectIntervals) collectMetrics(pluginKey string, mts []core.Metric, taskID string, collect func(string, []core.Metric, string) ([]core.Metric, error)) ([]core.Metric, error) {
+	m.Lock()
+	mci, ok := m.plugins[pluginKey]
+	m.Unlock()
+	if !ok {
+		return collect(pluginKey, mts, taskID)
+	}
+
+	mci.Lock()
+	defer mci.Unlock()
+	now := time.Now()
+	for key, c := range mci.collections {
+		if now.Sub(c.collected) >= mci.interval {
+			delete(mci.collections, key)
+		}
+	}
+	var cached, missing []core.Metric
+	for _, mt := range mts {
+		if metrics, ok := mci.lastCollected(mt); ok {
+			cached = append(cached, metrics...)
+			continue
+		}
+		missing = append(missing, mt)
+	}
+	cached = copyMetrics(cached)
+	if len(missing) == 0 {
+		return cached, nil
+	}
+	metrics, err := collect(pluginKey, missing, taskID)
+	if _, partial := err.(plugin.NamespaceErrors); err == nil || partial {
+		for _, mt := range missing {
+			mci.collections[metricRequestKey(mt)] = intervalCollection{
+				collected: now,
+				requested: mt,
+				metrics:   copyMetrics(collectedFor(mt, metrics)),
+			}
+		}
+	}
+	return append(cached, metrics...), err
+}
+
+// lastCollected returns the metrics last collected for the requested metric,
+// either requested the same way or as part of a request with the same
+// version and config whose namespace matches it, such as with a "*".
+func (mci *minCollectInterval) lastCollected(mt core.Metric) ([]core.Metric, bool) {
+	if c, ok := mci.collections[metricRequestKey(mt)]; ok {
+		return c.metrics, true
+	}
+	configKey := metricConfigKey(mt)
+	for _, c := range mci.collections {
+		if metricConfigKey(c.requested) == configKey && namespaceMatches(c.requested.Namespace(), mt.Namespace()) {
+			return collectedFor(mt, c.metrics), true
+		}
+	}
+	return nil, false
+}
+
+// collectedFor returns the collected metrics matching the requested metric.
+func collectedFor(requested core.Metric, collected []core.Metric) []core.Metric {
+	var out []core.Metric
+	for _, c := range collected {
+		if namespaceMatches(requested.Namespace(), c.Namespace()) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
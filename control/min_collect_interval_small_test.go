@@ -0,0 +1,138 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// countingCollect returns a collect function which counts its calls.
+func countingCollect(calls *int) func(string, []core.Metric, string) ([]core.Metric, error) {
+	return func(pluginKey string, mts []core.Metric, taskID string) ([]core.Metric, error) {
+		*calls++
+		return []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Data_: *calls},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar"), Data_: *calls},
+		}, nil
+	}
+}
+
+// echoCollect returns a collect function which returns the requested
+// metrics, recording each request.
+func echoCollect(requests *[][]core.Metric) func(string, []core.Metric, string) ([]core.Metric, error) {
+	return func(pluginKey string, mts []core.Metric, taskID string) ([]core.Metric, error) {
+		*requests = append(*requests, mts)
+		return mts, nil
+	}
+}
+
+func TestMinCollectIntervalRequests(t *testing.T) {
+	foo := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo")}
+	bar := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar")}
+	Convey("Metrics missing from the last collection are collected", t, func() {
+		m := newMinCollectIntervals()
+		m.set("collector:mock:1", time.Hour)
+		var requests [][]core.Metric
+		m.collectMetrics("collector:mock:1", []core.Metric{foo}, "task", echoCollect(&requests))
+		mts, err := m.collectMetrics("collector:mock:1", []core.Metric{foo, bar}, "task", echoCollect(&requests))
+		So(err, ShouldBeNil)
+		So(len(mts), ShouldEqual, 2)
+		So(len(requests), ShouldEqual, 2)
+		So(len(requests[1]), ShouldEqual, 1)
+		So(requests[1][0].Namespace().String(), ShouldEqual, "/intel/mock/bar")
+	})
+	Convey("A metric requested with another config is collected", t, func() {
+		m := newMinCollectIntervals()
+		m.set("collector:mock:1", time.Hour)
+		var requests [][]core.Metric
+		m.collectMetrics("collector:mock:1", []core.Metric{foo}, "task", echoCollect(&requests))
+		configured := plugin.MetricType{Namespace_: foo.Namespace(), Config_: configWithLimit(5)}
+		mts, err := m.collectMetrics("collector:mock:1", []core.Metric{configured}, "task", echoCollect(&requests))
+		So(err, ShouldBeNil)
+		So(len(mts), ShouldEqual, 1)
+		So(len(requests), ShouldEqual, 2)
+		mts, err = m.collectMetrics("collector:mock:1", []core.Metric{configured}, "task", echoCollect(&requests))
+		So(err, ShouldBeNil)
+		So(len(mts), ShouldEqual, 1)
+		So(len(requests), ShouldEqual, 2)
+	})
+}
+
+func TestMinCollectInterval(t *testing.T) {
+	Convey("Given a collector with a minimum collect interval", t, func() {
+		c := New(GetDefaultConfig())
+		requested := []core.Metric{plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "*")}}
+
+		Convey("collecting within the interval returns the last collection", func() {
+			calls := 0
+			So(c.SetMinCollectInterval("collector:mock:1", time.Hour), ShouldBeNil)
+			first, err := c.minCollectIntervals.collectMetrics("collector:mock:1", requested, "task", countingCollect(&calls))
+			So(err, ShouldBeNil)
+			So(len(first), ShouldEqual, 2)
+			second, err := c.minCollectIntervals.collectMetrics("collector:mock:1", requested, "task", countingCollect(&calls))
+			So(err, ShouldBeNil)
+			So(calls, ShouldEqual, 1)
+			So(len(second), ShouldEqual, 2)
+			So(second[0].Data(), ShouldEqual, 1)
+		})
+		Convey("only the requested metrics are returned from the last collection", func() {
+			calls := 0
+			So(c.SetMinCollectInterval("collector:mock:2", time.Hour), ShouldBeNil)
+			c.minCollectIntervals.collectMetrics("collector:mock:2", requested, "task", countingCollect(&calls))
+			foo := []core.Metric{plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo")}}
+			mts, err := c.minCollectIntervals.collectMetrics("collector:mock:2", foo, "task", countingCollect(&calls))
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 1)
+			So(mts[0].Namespace().String(), ShouldEqual, "/intel/mock/foo")
+		})
+		Convey("the plugin is collected from again once the interval passes", func() {
+			calls := 0
+			So(c.SetMinCollectInterval("collector:mock:3", time.Millisecond), ShouldBeNil)
+			c.minCollectIntervals.collectMetrics("collector:mock:3", requested, "task", countingCollect(&calls))
+			time.Sleep(2 * time.Millisecond)
+			c.minCollectIntervals.collectMetrics("collector:mock:3", requested, "task", countingCollect(&calls))
+			So(calls, ShouldEqual, 2)
+		})
+		Convey("plugins without a minimum are not limited", func() {
+			calls := 0
+			c.minCollectIntervals.collectMetrics("collector:other:1", requested, "task", countingCollect(&calls))
+			c.minCollectIntervals.collectMetrics("collector:other:1", requested, "task", countingCollect(&calls))
+			So(calls, ShouldEqual, 2)
+		})
+		Convey("an interval of zero removes the minimum", func() {
+			calls := 0
+			So(c.SetMinCollectInterval("collector:mock:4", time.Hour), ShouldBeNil)
+			So(c.SetMinCollectInterval("collector:mock:4", 0), ShouldBeNil)
+			c.minCollectIntervals.collectMetrics("collector:mock:4", requested, "task", countingCollect(&calls))
+			c.minCollectIntervals.collectMetrics("collector:mock:4", requested, "task", countingCollect(&calls))
+			So(calls, ShouldEqual, 2)
+		})
+		Convey("an invalid plugin key returns an error", func() {
+			So(c.SetMinCollectInterval("mock", time.Second), ShouldNotBeNil)
+		})
+	})
+}
@@ -19,7 +19,14 @@ limitations under the License.
 
 package control
 
-import "time"
+import (
+	"time"
+
+	"github.com/intelsdi-x/gomit"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+)
 
 const (
 	// MonitorStopped - enum representation of monitor stopped state
@@ -29,6 +36,16 @@ const (
 
 	// DefaultMonitorDuration - the default monitor duration.
 	DefaultMonitorDuration = time.Second * 1
+	// DefaultRestartBackoff - the default delay before the first restart of
+	// a dead available plugin.
+	DefaultRestartBackoff = time.Second * 1
+	// DefaultMaxRestartBackoff - the default max delay before the restart of
+	// a dead available plugin.
+	DefaultMaxRestartBackoff = time.Second * 30
+	// DefaultRestartResetPeriod - the default time the plugins of a pool
+	// must run without dying after a restart for the restart backoff of the
+	// pool to be reset.
+	DefaultRestartResetPeriod = time.Minute * 5
 )
 
 type monitorState int
@@ -38,6 +55,24 @@ type monitor struct {
 
 	duration time.Duration
 	quit     chan struct{}
+
+	// poolStatsInterval is how often a PoolStatsEvent is emitted for each
+	// pool.  Zero disables pool stats.
+	poolStatsInterval time.Duration
+	emitter           gomit.Emitter
+	lastPoolStats     time.Time
+	// lastHits maps a pool key to its hit count at the last pool stats
+	lastHits map[string]int
+
+	// restartBackoff is the delay before the first restart of a dead
+	// available plugin in a pool, which doubles with each restart of the
+	// pool up to maxRestartBackoff.
+	restartBackoff    time.Duration
+	maxRestartBackoff time.Duration
+	// restartResetPeriod is how long the plugins of a pool must run
+	// without dying after a restart for the restart count of the pool, and
+	// so the backoff, to be reset.
+	restartResetPeriod time.Duration
 }
 
 type monitorOption func(m *monitor) monitorOption
@@ -61,10 +96,57 @@ func MonitorDurationOption(v time.Duration) monitorOption {
 	}
 }
 
+// MonitorPoolStatsOption sets how often the monitor emits a PoolStatsEvent
+// for each pool to v.  Pool stats are emitted on the monitor's tick so the
+// interval is rounded up to the monitor's duration.  Zero disables pool stats.
+func MonitorPoolStatsOption(v time.Duration) monitorOption {
+	return func(m *monitor) monitorOption {
+		previous := m.poolStatsInterval
+		m.poolStatsInterval = v
+		return MonitorPoolStatsOption(previous)
+	}
+}
+
+// MonitorRestartBackoffOption sets the delay before the first restart of a
+// dead available plugin in a pool to v.  The delay doubles with each restart
+// of the pool.
+func MonitorRestartBackoffOption(v time.Duration) monitorOption {
+	return func(m *monitor) monitorOption {
+		previous := m.restartBackoff
+		m.restartBackoff = v
+		return MonitorRestartBackoffOption(previous)
+	}
+}
+
+// MonitorMaxRestartBackoffOption sets the max delay before the restart of a
+// dead available plugin to v.
+func MonitorMaxRestartBackoffOption(v time.Duration) monitorOption {
+	return func(m *monitor) monitorOption {
+		previous := m.maxRestartBackoff
+		m.maxRestartBackoff = v
+		return MonitorMaxRestartBackoffOption(previous)
+	}
+}
+
+// MonitorRestartResetOption sets how long the plugins of a pool must run
+// without dying after a restart for the restart count of the pool, and so the
+// restart backoff, to be reset to v.
+func MonitorRestartResetOption(v time.Duration) monitorOption {
+	return func(m *monitor) monitorOption {
+		previous := m.restartResetPeriod
+		m.restartResetPeriod = v
+		return MonitorRestartResetOption(previous)
+	}
+}
+
 func newMonitor(opts ...monitorOption) *monitor {
 	mon := &monitor{
-		State:    MonitorStopped,
-		duration: DefaultMonitorDuration,
+		State:              MonitorStopped,
+		duration:           DefaultMonitorDuration,
+		lastHits:           make(map[string]int),
+		restartBackoff:     DefaultRestartBackoff,
+		maxRestartBackoff:  DefaultMaxRestartBackoff,
+		restartResetPeriod: DefaultRestartResetPeriod,
 	}
 	//set options
 	for _, opt := range opts {
@@ -79,10 +161,11 @@ func (m *monitor) Start(availablePlugins *availablePlugins) {
 	//over available plugins and firing a health check routine
 	ticker := time.NewTicker(m.duration)
 	m.quit = make(chan struct{})
+	m.lastPoolStats = time.Now()
 	go func() {
 		for {
 			select {
-			case <-ticker.C:
+			case now := <-ticker.C:
 				go func() {
 					availablePlugins.RLock()
 					for _, ap := range availablePlugins.all() {
@@ -90,6 +173,11 @@ func (m *monitor) Start(availablePlugins *availablePlugins) {
 					}
 					availablePlugins.RUnlock()
 				}()
+				if m.poolStatsInterval > 0 && m.emitter != nil && now.Sub(m.lastPoolStats) >= m.poolStatsInterval {
+					for _, e := range m.poolStats(availablePlugins, now) {
+						m.emitter.Emit(e)
+					}
+				}
 			case <-m.quit:
 				ticker.Stop()
 				m.State = MonitorStopped
@@ -105,3 +193,49 @@ func (m *monitor) Stop() {
 	close(m.quit)
 	m.State = MonitorStopped
 }
+
+// restartDelay returns the delay before restarting a dead available plugin
+// in a pool which has been restarted the given number of times.
+func (m *monitor) restartDelay(restarts int) time.Duration {
+	d := m.restartBackoff
+	for i := 0; i < restarts && d < m.maxRestartBackoff; i++ {
+		d *= 2
+	}
+	if d > m.maxRestartBackoff {
+		d = m.maxRestartBackoff
+	}
+	return d
+}
+
+// poolStats returns an event describing each pool with its hit rate since
+// the previous call.
+func (m *monitor) poolStats(availablePlugins *availablePlugins, now time.Time) []*control_event.PoolStatsEvent {
+	elapsed := now.Sub(m.lastPoolStats).Seconds()
+	m.lastPoolStats = now
+	hits := make(map[string]int)
+	var events []*control_event.PoolStatsEvent
+	availablePlugins.RLock()
+	for key, pool := range availablePlugins.table {
+		typ, name, _, _ := core.ParsePluginKey(key)
+		e := &control_event.PoolStatsEvent{
+			PluginName:        name,
+			PluginVersion:     pool.Version(),
+			PluginType:        int(typ),
+			SubscriptionCount: pool.SubscriptionCount(),
+		}
+		pool.RLock()
+		for _, ap := range pool.Plugins() {
+			e.MemberCount++
+			e.HitCount += ap.HitCount()
+		}
+		pool.RUnlock()
+		if elapsed > 0 && e.HitCount >= m.lastHits[key] {
+			e.HitRate = float64(e.HitCount-m.lastHits[key]) / elapsed
+		}
+		hits[key] = e.HitCount
+		events = append(events, e)
+	}
+	availablePlugins.RUnlock()
+	m.lastHits = hits
+	return events
+}
@@ -0,0 +1,81 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/control/strategy/fixtures"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMonitorPoolStats(t *testing.T) {
+	Convey("Given a plugin pool", t, func() {
+		now := time.Now()
+		ap := fixtures.NewMockAvailablePlugin().WithName("mock").WithHitCount(10)
+		aps := newAvailablePlugins()
+		pool := addPool(aps, "collector:mock:1", ap)
+		pool.Subscribe("task", strategy.BoundSubscriptionType)
+		m := newMonitor()
+		m.lastPoolStats = now
+
+		Convey("pool stats describe the pool and its hit rate", func() {
+			events := m.poolStats(aps, now.Add(5*time.Second))
+			So(len(events), ShouldEqual, 1)
+			So(events[0].PluginName, ShouldEqual, "mock")
+			So(events[0].PluginVersion, ShouldEqual, 1)
+			So(events[0].MemberCount, ShouldEqual, 1)
+			So(events[0].SubscriptionCount, ShouldEqual, 1)
+			So(events[0].HitCount, ShouldEqual, 10)
+			So(events[0].HitRate, ShouldEqual, 2)
+
+			Convey("the hit rate is relative to the previous stats", func() {
+				events := m.poolStats(aps, now.Add(10*time.Second))
+				So(events[0].HitRate, ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+func TestMonitorRestartDelay(t *testing.T) {
+	Convey("The restart delay doubles with each restart", t, func() {
+		m := newMonitor(MonitorRestartBackoffOption(time.Second), MonitorMaxRestartBackoffOption(5*time.Second))
+		So(m.restartDelay(0), ShouldEqual, time.Second)
+		So(m.restartDelay(1), ShouldEqual, 2*time.Second)
+		So(m.restartDelay(2), ShouldEqual, 4*time.Second)
+
+		Convey("up to the max restart backoff", func() {
+			So(m.restartDelay(3), ShouldEqual, 5*time.Second)
+			So(m.restartDelay(100), ShouldEqual, 5*time.Second)
+		})
+	})
+	Convey("Setting a backoff option returns an option restoring the previous value", t, func() {
+		m := newMonitor()
+		restore := m.Option(MonitorRestartBackoffOption(0))
+		So(m.restartDelay(2), ShouldEqual, 0)
+		m.Option(restore)
+		So(m.restartDelay(0), ShouldEqual, DefaultRestartBackoff)
+	})
+}
@@ -168,6 +168,58 @@ func (mtt *mttNode) Fetch(ns []string) ([]*metricType, error) {
 	return mts, nil
 }
 
+// Match collects the metric types whose namespace matches the given
+// pattern. A "*" element matches any single namespace element and a "**"
+// element matches zero or more elements. Unlike Fetch, the pattern must
+// match the whole namespace and an empty result is not an error.
+func (mtt *mttNode) Match(pattern []string) []*metricType {
+	matched := map[*mttNode]bool{}
+	mtt.match(pattern, matched)
+
+	var mts []*metricType
+	for node := range matched {
+		for _, mt := range node.mts {
+			mts = append(mts, mt)
+		}
+	}
+	return mts
+}
+
+func (mtt *mttNode) match(pattern []string, matched map[*mttNode]bool) {
+	if len(pattern) == 0 {
+		if mtt.mts != nil {
+			matched[mtt] = true
+		}
+		return
+	}
+	switch pattern[0] {
+	case "**":
+		// match zero elements, then one or more
+		mtt.match(pattern[1:], matched)
+		for _, child := range mtt.children {
+			child.match(pattern, matched)
+		}
+	case "*":
+		for _, child := range mtt.children {
+			child.match(pattern[1:], matched)
+		}
+	default:
+		if child, ok := mtt.children[pattern[0]]; ok {
+			child.match(pattern[1:], matched)
+		}
+	}
+}
+
+// isGlob returns true if any element of ns is a wildcard
+func isGlob(ns []string) bool {
+	for _, n := range ns {
+		if n == "*" || n == "**" {
+			return true
+		}
+	}
+	return false
+}
+
 // Remove removes all children below a given namespace
 func (mtt *mttNode) Remove(ns []string) error {
 	_, err := mtt.find(ns)
@@ -0,0 +1,83 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// CollectedVersionTag is the tag added to metrics collected by
+// CollectMetricsMultiVersion recording the version of the plugin the metric
+// was collected from.
+const CollectedVersionTag = "plugin_version"
+
+// CollectMetricsMultiVersion collects the metric ns from each of the given
+// versions of the plugin exposing it, tagging each collected metric with the
+// version of the plugin it was collected from.  It allows the output of a new
+// collector version to be compared against the current version before the
+// plugins are swapped.  If a version cannot be resolved or collected from no
+// metrics are returned.
+func (p *pluginControl) CollectMetricsMultiVersion(ns core.Namespace, versions []int, config *cdata.ConfigDataNode, taskID string) ([]core.Metric, []error) {
+	if !p.Started {
+		return nil, []error{ErrControllerNotStarted}
+	}
+
+	var (
+		metrics []core.Metric
+		errs    []error
+	)
+	for _, v := range versions {
+		mt := plugin.MetricType{
+			Namespace_: ns,
+			Version_:   v,
+			Config_:    config,
+		}
+		// resolve the plugin for this version before collecting so that a
+		// version which isn't loaded is reported rather than collected from
+		// the latest plugin.
+		cm, err := p.metricCatalog.Get(ns, v)
+		if err != nil {
+			errs = append(errs, serror.New(err, map[string]interface{}{
+				"name":    ns.String(),
+				"version": v,
+			}))
+			continue
+		}
+		collected, _, cErrs := p.collectMetrics(nil, []core.Metric{mt}, time.Time{}, taskID, nil)
+		if len(cErrs) > 0 {
+			errs = append(errs, cErrs...)
+			continue
+		}
+		for _, m := range collected {
+			m.Tags()[CollectedVersionTag] = strconv.Itoa(cm.Plugin.Version())
+			metrics = append(metrics, m)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return metrics, nil
+}
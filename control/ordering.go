@@ -0,0 +1,127 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+)
+
+const (
+	// MetricErrorTag is the tag holding the error of the placeholder which
+	// takes the place of a metric which failed to be collected when results
+	// are ordered
+	MetricErrorTag = "snap.metric_error"
+)
+
+// OrderedResults is the PluginControlOpt which returns the metrics collected
+// by CollectMetrics in the order the metrics were requested.  A metric which
+// failed to be collected is replaced by a placeholder without data whose
+// MetricErrorTag tag holds the error.  Metrics are otherwise returned in the
+// order their collectors responded.
+func OrderedResults(ordered bool) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.orderedResults = ordered
+	}
+}
+
+// orderMetrics sorts the collected metrics by the position of the first
+// requested metric each matches.  Metrics matching the same request keep the
+// order they were collected in and metrics matching no request are moved to
+// the end.  The gap left by each metric which failed to be collected, as
+// reported by a *core.MetricError or *core.PluginCollectError, is filled by
+// a placeholder carrying the error.
+func orderMetrics(requested []core.Metric, collected []core.Metric, metricErrs []error) []core.Metric {
+	for _, err := range metricErrs {
+		switch e := err.(type) {
+		case *core.MetricError:
+			collected = append(collected, failedMetric(requested, e.Namespace, e.Error()))
+		case *core.PluginCollectError:
+			for _, ns := range e.Namespaces {
+				collected = append(collected, failedMetric(requested, ns, e.Error()))
+			}
+		}
+	}
+	o := byRequestOrder{
+		metrics: collected,
+		order:   make([]int, len(collected)),
+	}
+	for i, m := range collected {
+		o.order[i] = len(requested)
+		for j, r := range requested {
+			if namespaceMatches(r.Namespace(), m.Namespace()) {
+				o.order[i] = j
+				break
+			}
+		}
+	}
+	sort.Stable(o)
+	return o.metrics
+}
+
+// failedMetric returns the placeholder for the metric with the namespace,
+// as returned by Namespace.String, which failed to be collected.  The
+// placeholder has the version and config of the requested metric it matches.
+func failedMetric(requested []core.Metric, namespace, err string) core.Metric {
+	ns := core.NewNamespace(strings.Split(strings.TrimPrefix(namespace, "/"), "/")...)
+	m := plugin.MetricType{
+		Namespace_: ns,
+		Tags_:      map[string]string{MetricErrorTag: err},
+		Timestamp_: time.Now(),
+	}
+	for _, r := range requested {
+		if namespaceMatches(r.Namespace(), ns) {
+			m.Version_ = r.Version()
+			m.Config_ = r.Config()
+			break
+		}
+	}
+	return m
+}
+
+// namespaceMatches returns true if the collected namespace matches the
+// requested namespace, where a "*" element matches any element.
+func namespaceMatches(requested, collected core.Namespace) bool {
+	if len(requested) != len(collected) {
+		return false
+	}
+	for i := range requested {
+		if requested[i].Value != "*" && requested[i].Value != collected[i].Value {
+			return false
+		}
+	}
+	return true
+}
+
+type byRequestOrder struct {
+	metrics []core.Metric
+	order   []int
+}
+
+func (b byRequestOrder) Len() int { return len(b.metrics) }
+func (b byRequestOrder) Swap(i, j int) {
+	b.metrics[i], b.metrics[j] = b.metrics[j], b.metrics[i]
+	b.order[i], b.order[j] = b.order[j], b.order[i]
+}
+func (b byRequestOrder) Less(i, j int) bool { return b.order[i] < b.order[j] }
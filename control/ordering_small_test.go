@@ -0,0 +1,105 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOrderMetrics(t *testing.T) {
+	Convey("Given metrics collected out of request order", t, func() {
+		requested := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo")},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock").AddDynamicElement("host", "host").AddStaticElement("baz")},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar")},
+		}
+		collected := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar")},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "other")},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "host0", "baz")},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo")},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "host1", "baz")},
+		}
+		Convey("they are ordered by the request they match", func() {
+			ordered := orderMetrics(requested, collected, nil)
+			var nss []string
+			for _, m := range ordered {
+				nss = append(nss, m.Namespace().String())
+			}
+			So(nss, ShouldResemble, []string{
+				"/intel/mock/foo",
+				"/intel/mock/host0/baz",
+				"/intel/mock/host1/baz",
+				"/intel/mock/bar",
+				"/intel/other",
+			})
+		})
+	})
+}
+
+func TestOrderMetricsFailures(t *testing.T) {
+	Convey("The gaps left by failed metrics are filled in request order", t, func() {
+		requested := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Version_: 1},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock").AddDynamicElement("host", "host").AddStaticElement("baz"), Version_: 2},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar"), Version_: 3},
+		}
+		collected := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar")},
+		}
+		metricErrs := []error{
+			&core.PluginCollectError{PluginKey: "collector:mock:2", Namespaces: []string{"/intel/mock/host0/baz"}, Err: "down"},
+			&core.MetricError{Namespace: "/intel/mock/foo", Err: "boom"},
+		}
+		ordered := orderMetrics(requested, collected, metricErrs)
+		So(ordered, ShouldHaveLength, 3)
+		So(ordered[0].Namespace().String(), ShouldEqual, "/intel/mock/foo")
+		So(ordered[0].Version(), ShouldEqual, 1)
+		So(ordered[0].Data(), ShouldBeNil)
+		So(ordered[0].Tags()[MetricErrorTag], ShouldEqual, metricErrs[1].Error())
+		So(ordered[1].Namespace().String(), ShouldEqual, "/intel/mock/host0/baz")
+		So(ordered[1].Version(), ShouldEqual, 2)
+		So(ordered[1].Tags()[MetricErrorTag], ShouldEqual, metricErrs[0].Error())
+		So(ordered[2].Namespace().String(), ShouldEqual, "/intel/mock/bar")
+		_, failed := ordered[2].Tags()[MetricErrorTag]
+		So(failed, ShouldBeFalse)
+	})
+	Convey("CollectMetrics fills the gap of a metric its collector failed", t, func() {
+		c := New(GetDefaultConfig(), OrderedResults(true))
+		c.Started = true
+		failing := addFakeCollector(c, "failing", &fakeCollectorClient{
+			err: plugin.NamespaceErrors{"/intel/failing/foo": "boom"},
+		})
+		ok := addFakeCollector(c, "ok", &fakeCollectorClient{})
+		metrics, errs := c.CollectMetrics([]core.Metric{failing, ok}, time.Now().Add(time.Second), "task", nil)
+		So(errs, ShouldHaveLength, 1)
+		So(metrics, ShouldHaveLength, 2)
+		So(metrics[0].Namespace().String(), ShouldEqual, "/intel/failing/foo")
+		So(metrics[0].Tags()[MetricErrorTag], ShouldEqual, errs[0].Error())
+		So(metrics[1].Namespace().String(), ShouldEqual, "/intel/ok/foo")
+	})
+}
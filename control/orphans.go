@@ -0,0 +1,150 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrOrphanDetectionUnsupported - error message when orphaned plugins
+	// cannot be identified as the platform has no /proc filesystem
+	ErrOrphanDetectionUnsupported = errors.New("orphaned plugin detection requires /proc")
+
+	// procDir is where the process information orphaned plugins are
+	// identified by is read from
+	procDir = "/proc"
+)
+
+// PluginPidDir is the PluginControlOpt which records the pid of each plugin
+// process started in a pid file in dir while the process runs.  Plugin
+// processes which outlive an unclean shutdown of the agent are reaped with
+// ReapOrphanedPlugins, which Start calls before any plugin is started.  Pids
+// are not recorded by default.
+func PluginPidDir(dir string) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.pidDir = dir
+		c.pluginManager.SetPluginPidDir(dir)
+	}
+}
+
+// ReapOrphanedPlugins kills the plugin processes recorded in the plugin pid
+// directory by agents which are no longer running, such as those left behind
+// when the agent crashed, and returns the number of processes killed.  The
+// pid files of plugins started by a running agent, including this one, are
+// left alone, as are pid files which do not record their agent.  A process is
+// only killed while its command line shows it is running the recorded plugin
+// executable, so a pid reused by another process is left alone.  The pid
+// files of the agents which are no longer running are removed.  Processes are
+// identified through /proc, and ErrOrphanDetectionUnsupported is returned on
+// platforms without it.
+func (p *pluginControl) ReapOrphanedPlugins() (int, serror.SnapError) {
+	if p.pidDir == "" {
+		return 0, nil
+	}
+	if _, err := os.Stat(filepath.Join(procDir, "self")); err != nil {
+		return 0, serror.New(ErrOrphanDetectionUnsupported, map[string]interface{}{"pid-dir": p.pidDir})
+	}
+	files, err := filepath.Glob(filepath.Join(p.pidDir, "*"+plugin.PidFileSuffix))
+	if err != nil {
+		return 0, serror.New(err, map[string]interface{}{"pid-dir": p.pidDir})
+	}
+	reaped := 0
+	for _, file := range files {
+		killed, orphaned := reapOrphanedPlugin(file)
+		if killed {
+			reaped++
+		}
+		if orphaned {
+			if err := os.Remove(file); err != nil {
+				controlLogger.WithFields(log.Fields{
+					"_block":   "reap-orphaned-plugins",
+					"pid-file": file,
+					"error":    err,
+				}).Warn("unable to remove pid file of orphaned plugin")
+			}
+		}
+	}
+	return reaped, nil
+}
+
+// reapOrphanedPlugin kills the plugin process recorded in the pid file if it
+// is still running and its agent is not.  It returns whether the process was
+// killed and whether the agent which recorded it is no longer running.
+func reapOrphanedPlugin(file string) (bool, bool) {
+	pid, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(file), plugin.PidFileSuffix))
+	if err != nil {
+		return false, false
+	}
+	record, err := ioutil.ReadFile(file)
+	if err != nil {
+		return false, false
+	}
+	lines := strings.Split(strings.TrimSpace(string(record)), "\n")
+	if len(lines) != 2 || lines[0] == "" {
+		return false, false
+	}
+	exec := []byte(lines[0])
+	agent, err := strconv.Atoi(lines[1])
+	if err != nil || processRunning(agent) {
+		return false, false
+	}
+	cmdline, err := ioutil.ReadFile(filepath.Join(procDir, strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		// the process is no longer running
+		return false, true
+	}
+	if args := bytes.SplitN(cmdline, []byte{0}, 2); !bytes.Equal(args[0], exec) {
+		return false, true
+	}
+	f := log.Fields{
+		"_block":    "reap-orphaned-plugins",
+		"pid":       pid,
+		"path":      string(exec),
+		"agent-pid": agent,
+	}
+	proc, err := os.FindProcess(pid)
+	if err == nil {
+		err = proc.Kill()
+	}
+	if err != nil {
+		controlLogger.WithFields(f).WithField("error", err).Warn("unable to kill orphaned plugin")
+		return false, true
+	}
+	controlLogger.WithFields(f).Info("killed orphaned plugin")
+	return true, true
+}
+
+// processRunning returns whether the process with the pid is running.
+func processRunning(pid int) bool {
+	_, err := os.Stat(filepath.Join(procDir, strconv.Itoa(pid)))
+	return err == nil
+}
@@ -0,0 +1,121 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package control
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// deadPid returns the pid of a process which has exited.
+func deadPid(path string) int {
+	cmd := exec.Command(path, "0")
+	So(cmd.Run(), ShouldBeNil)
+	return cmd.Process.Pid
+}
+
+func TestReapOrphanedPlugins(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not available")
+	}
+	Convey("Given a pid directory left by an unclean shutdown", t, func() {
+		dir, err := ioutil.TempDir("", "snap-pids")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		pidFile := func(pid int) string {
+			return filepath.Join(dir, strconv.Itoa(pid)+plugin.PidFileSuffix)
+		}
+		record := func(pid int, path string, agent int) {
+			So(ioutil.WriteFile(pidFile(pid), []byte(fmt.Sprintf("%s\n%d\n", path, agent)), 0600), ShouldBeNil)
+		}
+		agent := deadPid(sleep)
+
+		orphan := exec.Command(sleep, "60")
+		So(orphan.Start(), ShouldBeNil)
+		record(orphan.Process.Pid, sleep, agent)
+		// a pid reused by a process which is not the recorded plugin
+		record(os.Getpid(), "/opt/snap/plugins/snap-collector-mock", agent)
+
+		c := New(GetDefaultConfig(), PluginPidDir(dir))
+		reaped, serr := c.ReapOrphanedPlugins()
+		So(serr, ShouldBeNil)
+		So(reaped, ShouldEqual, 1)
+		So(orphan.Wait(), ShouldNotBeNil)
+		files, err := ioutil.ReadDir(dir)
+		So(err, ShouldBeNil)
+		So(files, ShouldBeEmpty)
+	})
+	Convey("The plugins of a running agent sharing the pid directory are left alone", t, func() {
+		dir, err := ioutil.TempDir("", "snap-pids")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		running := exec.Command(sleep, "60")
+		So(running.Start(), ShouldBeNil)
+		defer running.Process.Kill()
+		file := filepath.Join(dir, strconv.Itoa(running.Process.Pid)+plugin.PidFileSuffix)
+		So(ioutil.WriteFile(file, []byte(fmt.Sprintf("%s\n%d\n", sleep, os.Getpid())), 0600), ShouldBeNil)
+		// a pid file which does not record its agent
+		unknown := filepath.Join(dir, strconv.Itoa(deadPid(sleep))+plugin.PidFileSuffix)
+		So(ioutil.WriteFile(unknown, []byte(sleep), 0600), ShouldBeNil)
+
+		c := New(GetDefaultConfig(), PluginPidDir(dir))
+		reaped, serr := c.ReapOrphanedPlugins()
+		So(serr, ShouldBeNil)
+		So(reaped, ShouldEqual, 0)
+		So(processRunning(running.Process.Pid), ShouldBeTrue)
+		_, err = os.Stat(file)
+		So(err, ShouldBeNil)
+		_, err = os.Stat(unknown)
+		So(err, ShouldBeNil)
+	})
+	Convey("Reaping fails cleanly without /proc", t, func() {
+		dir, err := ioutil.TempDir("", "snap-pids")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		defer func(d string) { procDir = d }(procDir)
+		procDir = filepath.Join(dir, "proc")
+
+		c := New(GetDefaultConfig(), PluginPidDir(dir))
+		reaped, serr := c.ReapOrphanedPlugins()
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, ErrOrphanDetectionUnsupported.Error())
+		So(reaped, ShouldEqual, 0)
+	})
+	Convey("Nothing is reaped without a pid directory", t, func() {
+		c := New(GetDefaultConfig())
+		reaped, serr := c.ReapOrphanedPlugins()
+		So(serr, ShouldBeNil)
+		So(reaped, ShouldEqual, 0)
+	})
+}
@@ -0,0 +1,48 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"github.com/intelsdi-x/snap/core"
+)
+
+// PartialCollectionAllowed is the PluginControlOpt which makes CollectMetrics
+// return the metrics collected from the other collectors when collection from
+// a collector fails.  A *core.PluginCollectError naming the collector and the
+// metrics requested from it is returned alongside the metrics for each
+// collector which failed.  By default no metrics are returned when any
+// collector fails.
+func PartialCollectionAllowed(allowed bool) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.partialCollection = allowed
+	}
+}
+
+func newPluginCollectError(pluginKey string, mts []core.Metric, err error) *core.PluginCollectError {
+	namespaces := make([]string, len(mts))
+	for i, mt := range mts {
+		namespaces[i] = mt.Namespace().String()
+	}
+	return &core.PluginCollectError{
+		PluginKey:  pluginKey,
+		Namespaces: namespaces,
+		Err:        err.Error(),
+	}
+}
@@ -0,0 +1,65 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPartialCollectionAllowed(t *testing.T) {
+	Convey("The metrics of the other collectors are returned when a collector fails", t, func() {
+		c := New(GetDefaultConfig(), PartialCollectionAllowed(true))
+		c.Started = true
+		mts := []core.Metric{
+			addFakeCollector(c, "ok", &fakeCollectorClient{}),
+			addFakeCollector(c, "failing", &fakeCollectorClient{err: errors.New("collector failed")}),
+		}
+		metrics, errs := c.CollectMetrics(mts, time.Now().Add(time.Second), "task", nil)
+		So(len(metrics), ShouldEqual, 1)
+		So(metrics[0].Namespace().String(), ShouldEqual, "/intel/ok/foo")
+		So(len(errs), ShouldEqual, 1)
+		perr, ok := errs[0].(*core.PluginCollectError)
+		So(ok, ShouldBeTrue)
+		So(perr.PluginKey, ShouldEqual, "collector:failing:1")
+		So(perr.Namespaces, ShouldResemble, []string{"/intel/failing/foo"})
+		So(perr.Err, ShouldEqual, "collector failed")
+	})
+	Convey("No metrics are returned when a collector fails by default", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		mts := []core.Metric{
+			addFakeCollector(c, "ok", &fakeCollectorClient{}),
+			addFakeCollector(c, "failing", &fakeCollectorClient{err: errors.New("collector failed")}),
+		}
+		metrics, errs := c.CollectMetrics(mts, time.Now().Add(time.Second), "task", nil)
+		So(metrics, ShouldBeEmpty)
+		So(len(errs), ShouldEqual, 1)
+		_, ok := errs[0].(*core.PluginCollectError)
+		So(ok, ShouldBeFalse)
+	})
+}
@@ -0,0 +1,133 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrProcessorCycle - error message when a processor's output is routed
+	// back into its own input within a pipeline
+	ErrProcessorCycle = errors.New("processor output feeds back into its own input")
+)
+
+// ValidatePipeline rejects a pipeline of processors in which a processor's
+// output would be routed back into its own input.  Each stage lists the
+// stages it routes its output to, and a route is only followed when the
+// content types returned by a stage are accepted by the next.  Detection uses
+// the declared content types rather than the metrics the processors process,
+// and the same processor repeated along a route is not a cycle.
+func (p *pluginControl) ValidatePipeline(stages []core.PipelineStage) serror.SnapError {
+	if !p.Started {
+		return serror.New(ErrControllerNotStarted)
+	}
+	lps := make([]*loadedPlugin, len(stages))
+	for i, st := range stages {
+		lp, err := p.pluginManager.get(core.PluginKey(core.ProcessorPluginType, st.Plugin.Name(), st.Plugin.Version()))
+		if err != nil {
+			return serror.New(err, map[string]interface{}{
+				"name":    st.Plugin.Name(),
+				"version": st.Plugin.Version(),
+			})
+		}
+		lps[i] = lp
+	}
+	if cycle := processorCycle(stages, lps); cycle != nil {
+		keys := make([]string, len(cycle))
+		for i, n := range cycle {
+			keys[i] = lps[n].Key()
+		}
+		last := lps[cycle[len(cycle)-1]]
+		return serror.New(ErrProcessorCycle, map[string]interface{}{
+			"name":     last.Name(),
+			"version":  last.Version(),
+			"pipeline": strings.Join(keys, " -> "),
+		})
+	}
+	return nil
+}
+
+// processorCycle returns the positions of the stages along the first route
+// which leads from a stage back to itself, starting and ending with that
+// stage, or nil when the pipeline has no cycle.
+func processorCycle(stages []core.PipelineStage, lps []*loadedPlugin) []int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(stages))
+	var route []int
+	var visit func(int) []int
+	visit = func(n int) []int {
+		state[n] = visiting
+		route = append(route, n)
+		for _, next := range stages[n].Next {
+			if next < 0 || next >= len(stages) {
+				continue
+			}
+			if !feedsInto(lps[n].Meta.ReturnedContentTypes, lps[next].Meta.AcceptedContentTypes) {
+				continue
+			}
+			switch state[next] {
+			case visiting:
+				for i, r := range route {
+					if r == next {
+						return append(append([]int{}, route[i:]...), next)
+					}
+				}
+			case unvisited:
+				if cycle := visit(next); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		route = route[:len(route)-1]
+		state[n] = visited
+		return nil
+	}
+	for n := range stages {
+		if state[n] == unvisited {
+			if cycle := visit(n); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// feedsInto returns whether any of the returned content types is accepted.
+// snap.* on either side matches any of the snap content types.
+func feedsInto(returned, accepted []string) bool {
+	for _, r := range returned {
+		for _, a := range accepted {
+			if r == a || (a == plugin.SnapAllContentType && strings.HasPrefix(r, "snap.")) || (r == plugin.SnapAllContentType && strings.HasPrefix(a, "snap.")) {
+				return true
+			}
+		}
+	}
+	return false
+}
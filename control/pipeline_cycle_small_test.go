@@ -0,0 +1,102 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// loadProcessor loads a processor accepting and returning the content types.
+func loadProcessor(c *pluginControl, name string, accepted, returned []string) core.Plugin {
+	lp := &loadedPlugin{
+		Type: plugin.ProcessorPluginType,
+		Meta: plugin.PluginMeta{
+			Name:                 name,
+			Version:              1,
+			AcceptedContentTypes: accepted,
+			ReturnedContentTypes: returned,
+		},
+		ConfigPolicy: cpolicy.New(),
+	}
+	c.pluginManager.(*pluginManager).loadedPlugins.add(lp)
+	return lp
+}
+
+// stage returns a pipeline stage for the processor routing to the stages.
+func stage(pr core.Plugin, next ...int) core.PipelineStage {
+	return core.PipelineStage{Plugin: pr, Next: next}
+}
+
+func TestValidatePipeline(t *testing.T) {
+	Convey("A processor routed back into itself is rejected", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		pr := loadProcessor(c, "loop", []string{plugin.SnapAllContentType}, []string{plugin.SnapGOBContentType})
+		serr := c.ValidatePipeline([]core.PipelineStage{stage(pr, 0)})
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, ErrProcessorCycle.Error())
+		So(serr.Fields()["pipeline"], ShouldEqual, "processor:loop:1 -> processor:loop:1")
+	})
+	Convey("A processor routed back into itself through another is rejected", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		a := loadProcessor(c, "a", []string{plugin.SnapGOBContentType}, []string{plugin.SnapJSONContentType})
+		b := loadProcessor(c, "b", []string{plugin.SnapJSONContentType}, []string{plugin.SnapAllContentType})
+		serr := c.ValidatePipeline([]core.PipelineStage{stage(a, 1), stage(b, 0)})
+		So(serr, ShouldNotBeNil)
+		So(serr.Fields()["pipeline"], ShouldEqual, "processor:a:1 -> processor:b:1 -> processor:a:1")
+	})
+	Convey("A processor repeated along a chain passes", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		a := loadProcessor(c, "a", []string{plugin.SnapAllContentType}, []string{plugin.SnapAllContentType})
+		b := loadProcessor(c, "b", []string{plugin.SnapAllContentType}, []string{plugin.SnapAllContentType})
+		So(c.ValidatePipeline([]core.PipelineStage{stage(a, 1), stage(b, 2), stage(a)}), ShouldBeNil)
+	})
+	Convey("A route whose output is not accepted downstream passes", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		a := loadProcessor(c, "a", []string{plugin.SnapAllContentType}, []string{"influx.line"})
+		b := loadProcessor(c, "b", []string{"influx.line"}, []string{"influx.line"})
+		So(c.ValidatePipeline([]core.PipelineStage{stage(a, 1), stage(b, 0)}), ShouldBeNil)
+	})
+	Convey("A pipeline of distinct processors passes", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		a := loadProcessor(c, "a", []string{plugin.SnapAllContentType}, []string{plugin.SnapAllContentType})
+		b := loadProcessor(c, "b", []string{plugin.SnapAllContentType}, []string{plugin.SnapAllContentType})
+		So(c.ValidatePipeline([]core.PipelineStage{stage(a, 1), stage(b)}), ShouldBeNil)
+	})
+	Convey("A pipeline with a processor which is not loaded is rejected", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		serr := c.ValidatePipeline([]core.PipelineStage{stage(&loadedPlugin{Meta: plugin.PluginMeta{Name: "missing", Version: 1}})})
+		So(serr, ShouldNotBeNil)
+		So(serr.Fields()["name"], ShouldEqual, "missing")
+	})
+}
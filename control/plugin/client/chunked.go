@@ -0,0 +1,44 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"github.com/pborman/uuid"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+)
+
+// publishChunks publishes the content of args in chunks of at most
+// chunkSize bytes with publish and returns the ack of the last chunk.
+func publishChunks(publish func(plugin.PublishArgs) (*plugin.PublishAck, error), args plugin.PublishArgs, chunkSize int) (*plugin.PublishAck, error) {
+	stream := uuid.New()
+	chunks := plugin.SplitContent(args.Content, chunkSize)
+	var ack *plugin.PublishAck
+	for i, chunk := range chunks {
+		args.Content = chunk
+		args.Chunk = &plugin.ContentChunk{Stream: stream, Index: i, Last: i == len(chunks)-1}
+		var err error
+		ack, err = publish(args)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ack, nil
+}
@@ -0,0 +1,63 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPublishChunks(t *testing.T) {
+	Convey("Content is published in chunks of a stream", t, func() {
+		var sent []plugin.PublishArgs
+		publish := func(args plugin.PublishArgs) (*plugin.PublishAck, error) {
+			sent = append(sent, args)
+			return &plugin.PublishAck{Acked: args.Chunk.Last}, nil
+		}
+		args := plugin.PublishArgs{ContentType: "snap.gob", Content: []byte("metrics")}
+		ack, err := publishChunks(publish, args, 3)
+		So(err, ShouldBeNil)
+		So(ack.Acked, ShouldBeTrue)
+		So(len(sent), ShouldEqual, 3)
+		for i, s := range sent {
+			So(s.ContentType, ShouldEqual, "snap.gob")
+			So(s.Chunk.Stream, ShouldEqual, sent[0].Chunk.Stream)
+			So(s.Chunk.Index, ShouldEqual, i)
+			So(s.Chunk.Last, ShouldEqual, i == 2)
+		}
+		So(string(sent[2].Content), ShouldEqual, "s")
+	})
+	Convey("Publishing stops at the first failed chunk", t, func() {
+		calls := 0
+		publish := func(plugin.PublishArgs) (*plugin.PublishAck, error) {
+			calls++
+			return nil, errors.New("publish failed")
+		}
+		_, err := publishChunks(publish, plugin.PublishArgs{Content: []byte("metrics")}, 3)
+		So(err, ShouldNotBeNil)
+		So(calls, ShouldEqual, 1)
+	})
+}
@@ -37,10 +37,21 @@ type PluginClient interface {
 // PluginCollectorClient A client providing collector specific plugin method calls.
 type PluginCollectorClient interface {
 	PluginClient
+	// CollectMetrics returns the collected metrics.  If the collector
+	// failed to collect some of the metrics the error is a
+	// plugin.NamespaceErrors and the metrics it collected are returned.
 	CollectMetrics([]core.Metric) ([]core.Metric, error)
 	GetMetricTypes(plugin.ConfigType) ([]core.Metric, error)
 }
 
+// PluginCollectResponseCappedClient A collector client whose collections
+// fail with ErrCollectResponseTooLarge when the response of the plugin is
+// larger than a max size in bytes, before the response is decoded.  A max of
+// zero is unlimited.
+type PluginCollectResponseCappedClient interface {
+	SetMaxCollectResponseBytes(int)
+}
+
 // PluginProcessorClient A client providing processor specific plugin method calls.
 type PluginProcessorClient interface {
 	PluginClient
@@ -52,3 +63,29 @@ type PluginPublisherClient interface {
 	PluginClient
 	Publish(contentType string, content []byte, config map[string]ctypes.ConfigValue) error
 }
+
+// PluginConfigValidatorClient A client which can have the plugin validate
+// config beyond its config policy.  Plugins which do not validate their
+// config return no errors.
+type PluginConfigValidatorClient interface {
+	ValidateConfig(plugin.ConfigType) ([]string, error)
+}
+
+// PluginAckingPublisherClient A publisher client which returns the plugin's
+// acknowledgment of the published content.  The ack is nil when the plugin
+// does not acknowledge content.
+type PluginAckingPublisherClient interface {
+	PublishWithAck(contentType, contentEncoding string, content []byte, config map[string]ctypes.ConfigValue) (*plugin.PublishAck, error)
+}
+
+// PluginEncodedPublisherClient A publisher client which can send content
+// compressed with a content encoding.
+type PluginEncodedPublisherClient interface {
+	PublishEncoded(contentType, contentEncoding string, content []byte, config map[string]ctypes.ConfigValue) error
+}
+
+// PluginChunkedPublisherClient A publisher client which can send content
+// compressed with a content encoding in chunks of at most chunkSize bytes.
+type PluginChunkedPublisherClient interface {
+	PublishChunked(contentType, contentEncoding string, content []byte, chunkSize int, config map[string]ctypes.ConfigValue) (*plugin.PublishAck, error)
+}
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"golang.org/x/net/context"
 
@@ -55,18 +56,15 @@ type grpcClient struct {
 	plugin    pluginClient
 
 	pluginType plugin.PluginType
-	timeout    time.Duration
+	timeouts   Timeouts
 	conn       *grpc.ClientConn
 	encrypter  *encrypter.Encrypter
+	codec      *collectReplyCodec
 }
 
 // NewCollectorGrpcClient returns a collector gRPC Client.
-func NewCollectorGrpcClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginCollectorClient, error) {
-	address, port, err := parseAddress(address)
-	if err != nil {
-		return nil, err
-	}
-	p, err := newGrpcClient(address, int(port), timeout, plugin.CollectorPluginType)
+func NewCollectorGrpcClient(address string, timeouts Timeouts, pub *rsa.PublicKey, secure bool) (PluginCollectorClient, error) {
+	p, err := newGrpcClient(address, timeouts, plugin.CollectorPluginType)
 	if err != nil {
 		return nil, err
 	}
@@ -84,12 +82,8 @@ func NewCollectorGrpcClient(address string, timeout time.Duration, pub *rsa.Publ
 }
 
 // NewProcessorGrpcClient returns a processor gRPC Client.
-func NewProcessorGrpcClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginProcessorClient, error) {
-	address, port, err := parseAddress(address)
-	if err != nil {
-		return nil, err
-	}
-	p, err := newGrpcClient(address, int(port), timeout, plugin.ProcessorPluginType)
+func NewProcessorGrpcClient(address string, timeouts Timeouts, pub *rsa.PublicKey, secure bool) (PluginProcessorClient, error) {
+	p, err := newGrpcClient(address, timeouts, plugin.ProcessorPluginType)
 	if err != nil {
 		return nil, err
 	}
@@ -107,12 +101,8 @@ func NewProcessorGrpcClient(address string, timeout time.Duration, pub *rsa.Publ
 }
 
 // NewPublisherGrpcClient returns a publisher gRPC Client.
-func NewPublisherGrpcClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginPublisherClient, error) {
-	address, port, err := parseAddress(address)
-	if err != nil {
-		return nil, err
-	}
-	p, err := newGrpcClient(address, int(port), timeout, plugin.PublisherPluginType)
+func NewPublisherGrpcClient(address string, timeouts Timeouts, pub *rsa.PublicKey, secure bool) (PluginPublisherClient, error) {
+	p, err := newGrpcClient(address, timeouts, plugin.PublisherPluginType)
 	if err != nil {
 		return nil, err
 	}
@@ -142,14 +132,34 @@ func parseAddress(address string) (string, int64, error) {
 	return address, port, nil
 }
 
-func newGrpcClient(addr string, port int, timeout time.Duration, typ plugin.PluginType) (*grpcClient, error) {
-	conn, err := rpcutil.GetClientConnection(addr, port)
+// dialGrpc connects to a plugin listening on either a host:port pair or a
+// Unix domain socket.  The dial does not block; a positive timeout bounds
+// each attempt gRPC makes to establish the underlying connection.
+func dialGrpc(address string, timeout time.Duration, codec grpc.Codec) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{grpc.WithCodec(codec)}
+	if timeout > 0 {
+		opts = append(opts, grpc.WithTimeout(timeout))
+	}
+	if network, path := plugin.SplitListenAddress(address); network == "unix" {
+		return rpcutil.GetUnixClientConnection(path, opts...)
+	}
+	addr, port, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	return rpcutil.GetClientConnection(addr, int(port), opts...)
+}
+
+func newGrpcClient(address string, timeouts Timeouts, typ plugin.PluginType) (*grpcClient, error) {
+	codec := &collectReplyCodec{}
+	conn, err := dialGrpc(address, timeouts.Connect, codec)
 	if err != nil {
 		return nil, err
 	}
 	p := &grpcClient{
-		timeout: timeout,
-		conn:    conn,
+		timeouts: timeouts,
+		conn:     conn,
+		codec:    codec,
 	}
 
 	switch typ {
@@ -170,14 +180,26 @@ func newGrpcClient(addr string, port int, timeout time.Duration, typ plugin.Plug
 }
 
 func getContext(timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return context.Background()
+	}
 	ctxTimeout, _ := context.WithTimeout(context.Background(), timeout)
 	return ctxTimeout
 }
 
+// callError returns ErrResponseTimeout in place of the error of a call which
+// did not complete within the response timeout.
+func callError(err error) error {
+	if grpc.Code(err) == codes.DeadlineExceeded {
+		return ErrResponseTimeout
+	}
+	return err
+}
+
 func (g *grpcClient) Ping() error {
-	_, err := g.plugin.Ping(getContext(g.timeout), &common.Empty{})
+	_, err := g.plugin.Ping(getContext(g.timeouts.Response), &common.Empty{})
 	if err != nil {
-		return err
+		return callError(err)
 	}
 	return nil
 }
@@ -187,9 +209,9 @@ func (g *grpcClient) SetKey() error {
 	if err != nil {
 		return err
 	}
-	reply, err := g.plugin.SetKey(getContext(g.timeout), &rpc.SetKeyArg{Key: out})
+	reply, err := g.plugin.SetKey(getContext(g.timeouts.Response), &rpc.SetKeyArg{Key: out})
 	if err != nil {
-		return err
+		return callError(err)
 	}
 
 	if reply.Error != "" {
@@ -200,10 +222,10 @@ func (g *grpcClient) SetKey() error {
 }
 
 func (g *grpcClient) Kill(reason string) error {
-	_, err := g.plugin.Kill(getContext(g.timeout), &rpc.KillRequest{Reason: reason})
+	_, err := g.plugin.Kill(getContext(g.timeouts.Response), &rpc.KillRequest{Reason: reason})
 	g.conn.Close()
 	if err != nil {
-		return err
+		return callError(err)
 	}
 	return nil
 }
@@ -215,9 +237,9 @@ func (g *grpcClient) Publish(contentType string, content []byte, config map[stri
 		Config:      common.ToConfigMap(config),
 	}
 	// return is empty so we don't need it
-	_, err := g.publisher.Publish(getContext(g.timeout), arg)
+	_, err := g.publisher.Publish(getContext(g.timeouts.Response), arg)
 	if err != nil {
-		return err
+		return callError(err)
 	}
 	return nil
 }
@@ -228,9 +250,9 @@ func (g *grpcClient) Process(contentType string, content []byte, config map[stri
 		Content:     content,
 		Config:      common.ToConfigMap(config),
 	}
-	reply, err := g.processor.Process(getContext(g.timeout), arg)
+	reply, err := g.processor.Process(getContext(g.timeouts.Response), arg)
 	if err != nil {
-		return "", nil, err
+		return "", nil, callError(err)
 	}
 	if reply.Error != "" {
 		return "", nil, errors.New(reply.Error)
@@ -242,10 +264,14 @@ func (g *grpcClient) CollectMetrics(mts []core.Metric) ([]core.Metric, error) {
 	arg := &rpc.CollectMetricsArg{
 		Metrics: common.NewMetrics(mts),
 	}
-	reply, err := g.collector.CollectMetrics(getContext(g.timeout), arg)
+	reply, err := g.collector.CollectMetrics(getContext(g.timeouts.Response), arg)
 
 	if err != nil {
-		return nil, err
+		// the error of the codec is only carried in the description
+		if strings.Contains(grpc.ErrorDesc(err), ErrCollectResponseTooLarge.Error()) {
+			return nil, ErrCollectResponseTooLarge
+		}
+		return nil, callError(err)
 	}
 
 	if reply.Error != "" {
@@ -271,17 +297,26 @@ func (g *grpcClient) CollectMetrics(mts []core.Metric) ([]core.Metric, error) {
 		}
 		results = append(results, mt)
 	}
+	if len(reply.NamespaceErrors) > 0 {
+		return results, plugin.NamespaceErrors(reply.NamespaceErrors)
+	}
 	return results, nil
 }
 
+// SetMaxCollectResponseBytes sets the max size of the responses to
+// collections unmarshalled from the plugin.
+func (g *grpcClient) SetMaxCollectResponseBytes(max int) {
+	g.codec.setMax(max)
+}
+
 func (g *grpcClient) GetMetricTypes(config plugin.ConfigType) ([]core.Metric, error) {
 	arg := &rpc.GetMetricTypesArg{
 		Config: common.ToConfigMap(config.Table()),
 	}
-	reply, err := g.collector.GetMetricTypes(getContext(g.timeout), arg)
+	reply, err := g.collector.GetMetricTypes(getContext(g.timeouts.Response), arg)
 
 	if err != nil {
-		return nil, err
+		return nil, callError(err)
 	}
 
 	if reply.Error != "" {
@@ -293,10 +328,10 @@ func (g *grpcClient) GetMetricTypes(config plugin.ConfigType) ([]core.Metric, er
 }
 
 func (g *grpcClient) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
-	reply, err := g.plugin.GetConfigPolicy(getContext(g.timeout), &common.Empty{})
+	reply, err := g.plugin.GetConfigPolicy(getContext(g.timeouts.Response), &common.Empty{})
 
 	if err != nil {
-		return nil, err
+		return nil, callError(err)
 	}
 
 	if reply.Error != "" {
@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"sync/atomic"
 	"time"
@@ -45,17 +46,38 @@ var logger = log.WithField("_module", "client-httpjsonrpc")
 type httpJSONRPCClient struct {
 	url        string
 	id         uint64
-	timeout    time.Duration
+	timeouts   Timeouts
+	client     *http.Client
 	pluginType plugin.PluginType
 	encrypter  *encrypter.Encrypter
 	encoder    encoding.Encoder
+	// maxCollectResponseBytes caps the size of collect responses
+	maxCollectResponseBytes int
+}
+
+// newHTTPClient returns an http.Client connecting by the connect timeout.  A
+// positive response timeout bounds the whole of a call, and waiting for the
+// response headers by the connect timeout; otherwise calls are unbounded.
+func newHTTPClient(timeouts Timeouts) *http.Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		Dial:  (&net.Dialer{Timeout: timeouts.Connect}).Dial,
+	}
+	if timeouts.Response > 0 {
+		transport.ResponseHeaderTimeout = timeouts.Connect
+	}
+	return &http.Client{
+		Timeout:   timeouts.Response,
+		Transport: transport,
+	}
 }
 
 // NewCollectorHttpJSONRPCClient returns CollectorHttpJSONRPCClient
-func NewCollectorHttpJSONRPCClient(u string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginCollectorClient, error) {
+func NewCollectorHttpJSONRPCClient(u string, timeouts Timeouts, pub *rsa.PublicKey, secure bool) (PluginCollectorClient, error) {
 	hjr := &httpJSONRPCClient{
 		url:        u,
-		timeout:    timeout,
+		timeouts:   timeouts,
+		client:     newHTTPClient(timeouts),
 		pluginType: plugin.CollectorPluginType,
 		encoder:    encoding.NewJsonEncoder(),
 	}
@@ -72,10 +94,11 @@ func NewCollectorHttpJSONRPCClient(u string, timeout time.Duration, pub *rsa.Pub
 	return hjr, nil
 }
 
-func NewProcessorHttpJSONRPCClient(u string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginProcessorClient, error) {
+func NewProcessorHttpJSONRPCClient(u string, timeouts Timeouts, pub *rsa.PublicKey, secure bool) (PluginProcessorClient, error) {
 	hjr := &httpJSONRPCClient{
 		url:        u,
-		timeout:    timeout,
+		timeouts:   timeouts,
+		client:     newHTTPClient(timeouts),
 		pluginType: plugin.ProcessorPluginType,
 		encoder:    encoding.NewJsonEncoder(),
 	}
@@ -92,10 +115,11 @@ func NewProcessorHttpJSONRPCClient(u string, timeout time.Duration, pub *rsa.Pub
 	return hjr, nil
 }
 
-func NewPublisherHttpJSONRPCClient(u string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginPublisherClient, error) {
+func NewPublisherHttpJSONRPCClient(u string, timeouts Timeouts, pub *rsa.PublicKey, secure bool) (PluginPublisherClient, error) {
 	hjr := &httpJSONRPCClient{
 		url:        u,
-		timeout:    timeout,
+		timeouts:   timeouts,
+		client:     newHTTPClient(timeouts),
 		pluginType: plugin.PublisherPluginType,
 		encoder:    encoding.NewJsonEncoder(),
 	}
@@ -165,7 +189,7 @@ func (h *httpJSONRPCClient) CollectMetrics(mts []core.Metric) ([]core.Metric, er
 		return nil, err
 	}
 
-	res, err := h.call("Collector.CollectMetrics", []interface{}{out})
+	res, err := h.callCapped("Collector.CollectMetrics", []interface{}{out}, h.maxCollectResponseBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -190,6 +214,9 @@ func (h *httpJSONRPCClient) CollectMetrics(mts []core.Metric) ([]core.Metric, er
 		idx++
 	}
 
+	if len(r.NamespaceErrors) > 0 {
+		return results, r.NamespaceErrors
+	}
 	return results, nil
 }
 
@@ -241,17 +268,65 @@ func (h *httpJSONRPCClient) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
 	return cpr.Policy, nil
 }
 
+// ValidateConfig returns the problems the plugin found with the config
+func (h *httpJSONRPCClient) ValidateConfig(config plugin.ConfigType) ([]string, error) {
+	out, err := h.encoder.Encode(plugin.ValidateConfigArgs{Config: config})
+	if err != nil {
+		return nil, err
+	}
+	res, err := h.call("SessionState.ValidateConfig", []interface{}{out})
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Result) == 0 {
+		return nil, errors.New(res.Error)
+	}
+	var r plugin.ValidateConfigReply
+	err = h.encoder.Decode(res.Result, &r)
+	if err != nil {
+		return nil, err
+	}
+	return r.Errors, nil
+}
+
 func (h *httpJSONRPCClient) Publish(contentType string, content []byte, config map[string]ctypes.ConfigValue) error {
-	args := plugin.PublishArgs{ContentType: contentType, Content: content, Config: config}
+	return h.PublishEncoded(contentType, "", content, config)
+}
+
+// PublishEncoded publishes content compressed with the content encoding.
+func (h *httpJSONRPCClient) PublishEncoded(contentType, contentEncoding string, content []byte, config map[string]ctypes.ConfigValue) error {
+	_, err := h.PublishWithAck(contentType, contentEncoding, content, config)
+	return err
+}
+
+// PublishWithAck publishes content compressed with the content encoding and
+// returns the plugin's ack, which is nil if the plugin does not ack content.
+func (h *httpJSONRPCClient) PublishWithAck(contentType, contentEncoding string, content []byte, config map[string]ctypes.ConfigValue) (*plugin.PublishAck, error) {
+	return h.publish(plugin.PublishArgs{ContentType: contentType, ContentEncoding: contentEncoding, Content: content, Config: config})
+}
+
+// PublishChunked publishes content compressed with the content encoding in
+// chunks of at most chunkSize bytes and returns the plugin's ack.
+func (h *httpJSONRPCClient) PublishChunked(contentType, contentEncoding string, content []byte, chunkSize int, config map[string]ctypes.ConfigValue) (*plugin.PublishAck, error) {
+	args := plugin.PublishArgs{ContentType: contentType, ContentEncoding: contentEncoding, Content: content, Config: config}
+	return publishChunks(h.publish, args, chunkSize)
+}
+
+func (h *httpJSONRPCClient) publish(args plugin.PublishArgs) (*plugin.PublishAck, error) {
 	out, err := h.encoder.Encode(args)
 	if err != nil {
-		return nil
+		return nil, err
+	}
+	res, err := h.call("Publisher.Publish", []interface{}{out})
+	if err != nil || len(res.Result) == 0 {
+		return nil, err
 	}
-	_, err = h.call("Publisher.Publish", []interface{}{out})
+	var r plugin.PublishReply
+	err = h.encoder.Decode(res.Result, &r)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return r.Ack, nil
 }
 
 func (h *httpJSONRPCClient) Process(contentType string, content []byte, config map[string]ctypes.ConfigValue) (string, []byte, error) {
@@ -281,7 +356,20 @@ type jsonRpcResp struct {
 	Error  string `json:"error"`
 }
 
+// SetMaxCollectResponseBytes sets the max size of the responses to
+// collections read from the plugin.
+func (h *httpJSONRPCClient) SetMaxCollectResponseBytes(max int) {
+	h.maxCollectResponseBytes = max
+}
+
 func (h *httpJSONRPCClient) call(method string, args []interface{}) (*jsonRpcResp, error) {
+	return h.callCapped(method, args, 0)
+}
+
+// callCapped calls the method like call, failing with
+// ErrCollectResponseTooLarge once more than max bytes of the response have
+// been read.  A max of zero is unlimited.
+func (h *httpJSONRPCClient) callCapped(method string, args []interface{}, max int) (*jsonRpcResp, error) {
 	data, err := json.Marshal(map[string]interface{}{
 		"method": method,
 		"id":     h.id,
@@ -298,8 +386,8 @@ func (h *httpJSONRPCClient) call(method string, args []interface{}) (*jsonRpcRes
 		}).Error("error encoding request to json")
 		return nil, err
 	}
-	client := http.Client{Timeout: h.timeout}
-	resp, err := client.Post(h.url, "application/json", bytes.NewReader(data))
+	started := time.Now()
+	resp, err := h.client.Post(h.url, "application/json", bytes.NewReader(data))
 	if err != nil {
 		logger.WithFields(log.Fields{
 			"_block":  "call",
@@ -307,11 +395,15 @@ func (h *httpJSONRPCClient) call(method string, args []interface{}) (*jsonRpcRes
 			"request": string(data),
 			"error":   err,
 		}).Error("error posting request to plugin")
-		return nil, err
+		return nil, h.timeouts.timeoutError(err, started)
 	}
 	defer resp.Body.Close()
 	result := &jsonRpcResp{}
-	if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
+	body := &cappedReader{r: resp.Body, max: int64(max)}
+	if err = json.NewDecoder(body).Decode(result); err != nil {
+		if err == ErrCollectResponseTooLarge {
+			return nil, err
+		}
 		bs, _ := ioutil.ReadAll(resp.Body)
 		logger.WithFields(log.Fields{
 			"_block":      "call",
@@ -321,6 +413,9 @@ func (h *httpJSONRPCClient) call(method string, args []interface{}) (*jsonRpcRes
 			"response":    string(bs),
 			"error":       err,
 		}).Error("error decoding result")
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return nil, ErrResponseTimeout
+		}
 		return nil, err
 	}
 	atomic.AddUint64(&h.id, 1)
@@ -184,7 +184,7 @@ func TestHTTPJSONRPC(t *testing.T) {
 
 	Convey("Collector Client", t, func() {
 		session.c = true
-		c, err := NewCollectorHttpJSONRPCClient(fmt.Sprintf("http://%v", addr), 1*time.Second, &key.PublicKey, true)
+		c, err := NewCollectorHttpJSONRPCClient(fmt.Sprintf("http://%v", addr), NewTimeouts(1*time.Second), &key.PublicKey, true)
 		So(err, ShouldBeNil)
 		So(c, ShouldNotBeNil)
 		cl := c.(*httpJSONRPCClient)
@@ -281,7 +281,7 @@ func TestHTTPJSONRPC(t *testing.T) {
 
 	Convey("Processor Client", t, func() {
 		session.c = false
-		p, _ := NewProcessorHttpJSONRPCClient(fmt.Sprintf("http://%v", addr), 1*time.Second, &key.PublicKey, true)
+		p, _ := NewProcessorHttpJSONRPCClient(fmt.Sprintf("http://%v", addr), NewTimeouts(1*time.Second), &key.PublicKey, true)
 		cl := p.(*httpJSONRPCClient)
 		cl.encrypter.Key = symkey
 		So(p, ShouldNotBeNil)
@@ -321,7 +321,7 @@ func TestHTTPJSONRPC(t *testing.T) {
 
 	Convey("Publisher Client", t, func() {
 		session.c = false
-		p, _ := NewPublisherHttpJSONRPCClient(fmt.Sprintf("http://%v", addr), 1*time.Second, &key.PublicKey, true)
+		p, _ := NewPublisherHttpJSONRPCClient(fmt.Sprintf("http://%v", addr), NewTimeouts(1*time.Second), &key.PublicKey, true)
 		cl := p.(*httpJSONRPCClient)
 		cl.encrypter.Key = symkey
 		So(p, ShouldNotBeNil)
@@ -44,24 +44,54 @@ type CallsRPC interface {
 	Call(methd string, args interface{}, reply interface{}) error
 }
 
+// timedRPC is a net/rpc client whose calls fail with ErrResponseTimeout when
+// they do not complete within the timeout.  A zero timeout is unbounded.
+type timedRPC struct {
+	client  *rpc.Client
+	timeout time.Duration
+}
+
+// Call calls method on the plugin.  net/rpc cannot cancel a single call, so a
+// call which times out closes the connection, failing it and every later
+// call, rather than leaving it pending with the plugin free to write into
+// reply.
+func (t *timedRPC) Call(method string, args interface{}, reply interface{}) error {
+	if t.timeout <= 0 {
+		return t.client.Call(method, args, reply)
+	}
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+	call := t.client.Go(method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-timer.C:
+		t.client.Close()
+		<-call.Done
+		return ErrResponseTimeout
+	}
+}
+
 // Native clients use golang net/rpc for communication to a native rpc server.
 type PluginNativeClient struct {
 	connection CallsRPC
 	pluginType plugin.PluginType
 	encoder    encoding.Encoder
 	encrypter  *encrypter.Encrypter
+	// maxCollectResponseBytes caps the size of collect responses
+	maxCollectResponseBytes int
 }
 
-func NewCollectorNativeClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginCollectorClient, error) {
-	return newNativeClient(address, timeout, plugin.CollectorPluginType, pub, secure)
+func NewCollectorNativeClient(address string, timeouts Timeouts, pub *rsa.PublicKey, secure bool) (PluginCollectorClient, error) {
+	return newNativeClient(address, timeouts, plugin.CollectorPluginType, pub, secure)
 }
 
-func NewPublisherNativeClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginPublisherClient, error) {
-	return newNativeClient(address, timeout, plugin.PublisherPluginType, pub, secure)
+func NewPublisherNativeClient(address string, timeouts Timeouts, pub *rsa.PublicKey, secure bool) (PluginPublisherClient, error) {
+	return newNativeClient(address, timeouts, plugin.PublisherPluginType, pub, secure)
 }
 
-func NewProcessorNativeClient(address string, timeout time.Duration, pub *rsa.PublicKey, secure bool) (PluginProcessorClient, error) {
-	return newNativeClient(address, timeout, plugin.ProcessorPluginType, pub, secure)
+func NewProcessorNativeClient(address string, timeouts Timeouts, pub *rsa.PublicKey, secure bool) (PluginProcessorClient, error) {
+	return newNativeClient(address, timeouts, plugin.ProcessorPluginType, pub, secure)
 }
 
 func (p *PluginNativeClient) Ping() error {
@@ -93,16 +123,46 @@ func (p *PluginNativeClient) Kill(reason string) error {
 }
 
 func (p *PluginNativeClient) Publish(contentType string, content []byte, config map[string]ctypes.ConfigValue) error {
-	args := plugin.PublishArgs{ContentType: contentType, Content: content, Config: config}
+	return p.PublishEncoded(contentType, "", content, config)
+}
+
+// PublishEncoded publishes content compressed with the content encoding.
+func (p *PluginNativeClient) PublishEncoded(contentType, contentEncoding string, content []byte, config map[string]ctypes.ConfigValue) error {
+	_, err := p.PublishWithAck(contentType, contentEncoding, content, config)
+	return err
+}
+
+// PublishWithAck publishes content compressed with the content encoding and
+// returns the plugin's ack, which is nil if the plugin does not ack content.
+func (p *PluginNativeClient) PublishWithAck(contentType, contentEncoding string, content []byte, config map[string]ctypes.ConfigValue) (*plugin.PublishAck, error) {
+	return p.publish(plugin.PublishArgs{ContentType: contentType, ContentEncoding: contentEncoding, Content: content, Config: config})
+}
+
+// PublishChunked publishes content compressed with the content encoding in
+// chunks of at most chunkSize bytes and returns the plugin's ack.
+func (p *PluginNativeClient) PublishChunked(contentType, contentEncoding string, content []byte, chunkSize int, config map[string]ctypes.ConfigValue) (*plugin.PublishAck, error) {
+	args := plugin.PublishArgs{ContentType: contentType, ContentEncoding: contentEncoding, Content: content, Config: config}
+	return publishChunks(p.publish, args, chunkSize)
+}
 
+func (p *PluginNativeClient) publish(args plugin.PublishArgs) (*plugin.PublishAck, error) {
 	out, err := p.encoder.Encode(args)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var reply []byte
 	err = p.connection.Call("Publisher.Publish", out, &reply)
-	return err
+	if err != nil || len(reply) == 0 {
+		return nil, err
+	}
+
+	r := &plugin.PublishReply{}
+	err = p.encoder.Decode(reply, r)
+	if err != nil {
+		return nil, err
+	}
+	return r.Ack, nil
 }
 
 func (p *PluginNativeClient) Process(contentType string, content []byte, config map[string]ctypes.ConfigValue) (string, []byte, error) {
@@ -159,6 +219,9 @@ func (p *PluginNativeClient) CollectMetrics(mts []core.Metric) ([]core.Metric, e
 	if err != nil {
 		return nil, err
 	}
+	if p.maxCollectResponseBytes > 0 && len(reply) > p.maxCollectResponseBytes {
+		return nil, ErrCollectResponseTooLarge
+	}
 
 	r := &plugin.CollectMetricsReply{}
 	err = p.encoder.Decode(reply, r)
@@ -173,9 +236,18 @@ func (p *PluginNativeClient) CollectMetrics(mts []core.Metric) ([]core.Metric, e
 		idx++
 	}
 
+	if len(r.NamespaceErrors) > 0 {
+		return results, r.NamespaceErrors
+	}
 	return results, nil
 }
 
+// SetMaxCollectResponseBytes sets the max size of the responses to
+// collections decoded from the plugin.
+func (p *PluginNativeClient) SetMaxCollectResponseBytes(max int) {
+	p.maxCollectResponseBytes = max
+}
+
 func (p *PluginNativeClient) GetMetricTypes(config plugin.ConfigType) ([]core.Metric, error) {
 	var reply []byte
 
@@ -223,20 +295,45 @@ func (p *PluginNativeClient) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
 	return r.Policy, nil
 }
 
+// ValidateConfig returns the problems the plugin found with the config
+func (p *PluginNativeClient) ValidateConfig(config plugin.ConfigType) ([]string, error) {
+	out, err := p.encoder.Encode(plugin.ValidateConfigArgs{Config: config})
+	if err != nil {
+		return nil, err
+	}
+
+	var reply []byte
+	err = p.connection.Call("SessionState.ValidateConfig", out, &reply)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &plugin.ValidateConfigReply{}
+	err = p.encoder.Decode(reply, r)
+	if err != nil {
+		return nil, err
+	}
+	return r.Errors, nil
+}
+
 // GetType returns the string type of the plugin
 // Note: the first letter of the type will be capitalized.
 func (p *PluginNativeClient) GetType() string {
 	return upcaseInitial(p.pluginType.String())
 }
 
-func newNativeClient(address string, timeout time.Duration, t plugin.PluginType, pub *rsa.PublicKey, secure bool) (*PluginNativeClient, error) {
+func newNativeClient(address string, timeouts Timeouts, t plugin.PluginType, pub *rsa.PublicKey, secure bool) (*PluginNativeClient, error) {
 	// Attempt to dial address error on timeout or problem
-	conn, err := net.DialTimeout("tcp", address, timeout)
+	network, address := plugin.SplitListenAddress(address)
+	conn, err := net.DialTimeout(network, address, timeouts.Connect)
 	// Return nil RPCClient and err if encoutered
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return nil, ErrConnectTimeout
+	}
 	if err != nil {
 		return nil, err
 	}
-	r := rpc.NewClient(conn)
+	r := &timedRPC{client: rpc.NewClient(conn), timeout: timeouts.Response}
 	p := &PluginNativeClient{
 		connection: r,
 		pluginType: t,
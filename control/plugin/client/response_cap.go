@@ -0,0 +1,85 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/intelsdi-x/snap/control/plugin/rpc"
+)
+
+// ErrCollectResponseTooLarge is returned by a collection whose response from
+// the plugin is larger than the max collect response size of the client.
+var ErrCollectResponseTooLarge = errors.New("collect response from plugin exceeded the max size")
+
+// cappedReader reads from r, failing with ErrCollectResponseTooLarge once more
+// than max bytes have been read.  A max of zero is unlimited.
+type cappedReader struct {
+	r    io.Reader
+	max  int64
+	read int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.max <= 0 {
+		return c.r.Read(p)
+	}
+	// read at most one byte past the cap to tell it was exceeded
+	if remaining := c.max - c.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := c.r.Read(p)
+	c.read += int64(n)
+	if c.read > c.max {
+		return n, ErrCollectResponseTooLarge
+	}
+	return n, err
+}
+
+// collectReplyCodec is the protobuf codec of gRPC clients, which fails to
+// unmarshal a collect reply larger than max bytes.  A max of zero is
+// unlimited.
+type collectReplyCodec struct {
+	max int64
+}
+
+func (c *collectReplyCodec) setMax(max int) {
+	atomic.StoreInt64(&c.max, int64(max))
+}
+
+func (c *collectReplyCodec) Marshal(v interface{}) ([]byte, error) {
+	return proto.Marshal(v.(proto.Message))
+}
+
+func (c *collectReplyCodec) Unmarshal(data []byte, v interface{}) error {
+	if _, ok := v.(*rpc.CollectMetricsReply); ok {
+		if max := atomic.LoadInt64(&c.max); max > 0 && int64(len(data)) > max {
+			return ErrCollectResponseTooLarge
+		}
+	}
+	return proto.Unmarshal(data, v.(proto.Message))
+}
+
+func (c *collectReplyCodec) String() string {
+	return "proto"
+}
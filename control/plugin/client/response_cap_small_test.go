@@ -0,0 +1,124 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+# Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/encoding"
+	"github.com/intelsdi-x/snap/control/plugin/rpc"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// replyRPC replies to every call with reply.
+type replyRPC struct {
+	reply []byte
+}
+
+func (r *replyRPC) Call(method string, args interface{}, reply interface{}) error {
+	*reply.(*[]byte) = r.reply
+	return nil
+}
+
+func TestCappedReader(t *testing.T) {
+	Convey("A response within the cap is read whole", t, func() {
+		r := &cappedReader{r: strings.NewReader("response"), max: 8}
+		b, err := ioutil.ReadAll(r)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, "response")
+	})
+	Convey("A response past the cap fails once the cap is exceeded", t, func() {
+		r := &cappedReader{r: strings.NewReader("response"), max: 4}
+		b, err := ioutil.ReadAll(r)
+		So(err, ShouldEqual, ErrCollectResponseTooLarge)
+		So(len(b), ShouldBeLessThanOrEqualTo, 5)
+	})
+	Convey("A zero cap is unlimited", t, func() {
+		r := &cappedReader{r: strings.NewReader("response")}
+		b, err := ioutil.ReadAll(r)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, "response")
+	})
+}
+
+func TestCollectReplyCodec(t *testing.T) {
+	reply := &rpc.CollectMetricsReply{Error: strings.Repeat("x", 100)}
+	data, err := proto.Marshal(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Convey("A collect reply past the cap is not unmarshalled", t, func() {
+		c := &collectReplyCodec{}
+		c.setMax(10)
+		So(c.Unmarshal(data, &rpc.CollectMetricsReply{}), ShouldEqual, ErrCollectResponseTooLarge)
+	})
+	Convey("Other replies are not capped", t, func() {
+		c := &collectReplyCodec{}
+		c.setMax(10)
+		So(c.Unmarshal(data, &rpc.GetMetricTypesReply{}), ShouldNotEqual, ErrCollectResponseTooLarge)
+	})
+	Convey("A collect reply within the cap is unmarshalled", t, func() {
+		c := &collectReplyCodec{}
+		c.setMax(len(data))
+		got := &rpc.CollectMetricsReply{}
+		So(c.Unmarshal(data, got), ShouldBeNil)
+		So(got.Error, ShouldEqual, reply.Error)
+	})
+}
+
+func TestHTTPJSONRPCCollectResponseCap(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"id": 0, "result": "%s"}`, strings.Repeat("eHh4", 100))
+	}))
+	defer srv.Close()
+	h := &httpJSONRPCClient{url: srv.URL, client: http.DefaultClient, encoder: encoding.NewJsonEncoder()}
+
+	Convey("A collect response past the cap is not read further", t, func() {
+		h.SetMaxCollectResponseBytes(100)
+		_, err := h.CollectMetrics([]core.Metric{plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo")}})
+		So(err, ShouldEqual, ErrCollectResponseTooLarge)
+	})
+	Convey("Calls other than collections are not capped", t, func() {
+		h.SetMaxCollectResponseBytes(100)
+		res, err := h.call("Collector.GetMetricTypes", nil)
+		So(err, ShouldBeNil)
+		So(bytes.Count(res.Result, []byte("x")), ShouldEqual, 300)
+	})
+}
+
+func TestNativeCollectResponseCap(t *testing.T) {
+	Convey("A collect response past the cap is not decoded", t, func() {
+		p := &PluginNativeClient{connection: &replyRPC{reply: make([]byte, 200)}, encoder: encoding.NewGobEncoder()}
+		p.SetMaxCollectResponseBytes(100)
+		_, err := p.CollectMetrics([]core.Metric{plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo")}})
+		So(err, ShouldEqual, ErrCollectResponseTooLarge)
+	})
+}
@@ -0,0 +1,65 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+var (
+	// ErrConnectTimeout is returned when a plugin could not be connected
+	// to, or did not start responding, within the connect timeout.  The
+	// plugin is slow to start rather than hung mid-response.
+	ErrConnectTimeout = errors.New("timed out connecting to plugin")
+	// ErrResponseTimeout is returned when a call to a plugin did not
+	// complete within the response timeout.
+	ErrResponseTimeout = errors.New("timed out waiting for plugin response")
+)
+
+// Timeouts are the timeouts applied to the calls a client makes to a plugin.
+type Timeouts struct {
+	// Connect bounds connecting to the plugin and, where the transport
+	// allows, waiting for the first byte of its response.
+	Connect time.Duration
+	// Response bounds the whole call.
+	Response time.Duration
+}
+
+// NewTimeouts returns Timeouts using the same timeout to connect to the
+// plugin and for the whole call.
+func NewTimeouts(timeout time.Duration) Timeouts {
+	return Timeouts{Connect: timeout, Response: timeout}
+}
+
+// timeoutError returns ErrConnectTimeout or ErrResponseTimeout in place of
+// a timeout error returned by a call started at started, depending on
+// whether the response timeout had passed.  Other errors are returned
+// unchanged.
+func (t Timeouts) timeoutError(err error, started time.Time) error {
+	if ne, ok := err.(net.Error); !ok || !ne.Timeout() {
+		return err
+	}
+	if t.Response <= 0 || time.Since(started) < t.Response {
+		return ErrConnectTimeout
+	}
+	return ErrResponseTimeout
+}
@@ -0,0 +1,92 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+type Sleeper struct{}
+
+func (Sleeper) Sleep(d time.Duration, reply *int) error {
+	time.Sleep(d)
+	return nil
+}
+
+func newSleeperClient() *rpc.Client {
+	srv := rpc.NewServer()
+	srv.Register(Sleeper{})
+	srvConn, cliConn := net.Pipe()
+	go srv.ServeConn(srvConn)
+	return rpc.NewClient(cliConn)
+}
+
+func TestTimeoutError(t *testing.T) {
+	timeouts := Timeouts{Connect: time.Second, Response: time.Minute}
+	Convey("A timeout before the response timeout is a connect timeout", t, func() {
+		So(timeouts.timeoutError(timeoutErr{}, time.Now()), ShouldEqual, ErrConnectTimeout)
+	})
+	Convey("A timeout after the response timeout is a response timeout", t, func() {
+		started := time.Now().Add(-2 * time.Minute)
+		So(timeouts.timeoutError(timeoutErr{}, started), ShouldEqual, ErrResponseTimeout)
+	})
+	Convey("Other errors are returned unchanged", t, func() {
+		err := errors.New("connection refused")
+		So(timeouts.timeoutError(err, time.Now()), ShouldEqual, err)
+	})
+}
+
+func TestTimedRPC(t *testing.T) {
+	Convey("A call completing within the timeout succeeds", t, func() {
+		r := &timedRPC{client: newSleeperClient(), timeout: time.Second}
+		var reply int
+		So(r.Call("Sleeper.Sleep", time.Duration(0), &reply), ShouldBeNil)
+	})
+	Convey("A call not completing within the timeout is a response timeout", t, func() {
+		r := &timedRPC{client: newSleeperClient(), timeout: 10 * time.Millisecond}
+		var reply int
+		So(r.Call("Sleeper.Sleep", time.Second, &reply), ShouldEqual, ErrResponseTimeout)
+	})
+	Convey("A call timing out disconnects the plugin", t, func() {
+		r := &timedRPC{client: newSleeperClient(), timeout: 10 * time.Millisecond}
+		var reply int
+		So(r.Call("Sleeper.Sleep", time.Second, &reply), ShouldEqual, ErrResponseTimeout)
+		So(r.Call("Sleeper.Sleep", time.Duration(0), &reply), ShouldEqual, rpc.ErrShutdown)
+	})
+	Convey("A zero timeout leaves calls unbounded", t, func() {
+		r := &timedRPC{client: newSleeperClient()}
+		var reply int
+		So(r.Call("Sleeper.Sleep", 50*time.Millisecond, &reply), ShouldBeNil)
+	})
+}
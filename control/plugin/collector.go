@@ -19,12 +19,38 @@ limitations under the License.
 
 package plugin
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // Acts as a proxy for RPC calls to a CollectorPlugin. This helps keep the function signature simple
 // within plugins vs. having to match required RPC patterns.
 
 // Collector plugin
 type CollectorPlugin interface {
 	Plugin
+	// CollectMetrics collects the requested metrics.  A collector which
+	// collects only some of the metrics returns those it collected along
+	// with NamespaceErrors describing the metrics it failed to collect.
 	CollectMetrics([]MetricType) ([]MetricType, error)
 	GetMetricTypes(ConfigType) ([]MetricType, error)
 }
+
+// NamespaceErrors maps the namespace, as returned by Namespace.String, of
+// each metric a collector failed to collect to the reason.
+type NamespaceErrors map[string]string
+
+func (n NamespaceErrors) Error() string {
+	namespaces := make([]string, 0, len(n))
+	for ns := range n {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	msgs := make([]string, len(namespaces))
+	for i, ns := range namespaces {
+		msgs[i] = fmt.Sprintf("%s: %s", ns, n[ns])
+	}
+	return "unable to collect " + strings.Join(msgs, ", ")
+}
@@ -40,6 +40,8 @@ type CollectMetricsArgs struct {
 // Reply assigned by a Collector implementation using CollectMetrics()
 type CollectMetricsReply struct {
 	PluginMetrics []MetricType
+	// NamespaceErrors holds the metrics which could not be collected
+	NamespaceErrors NamespaceErrors
 }
 
 // GetMetricTypesArgs args passed to GetMetricTypes
@@ -91,11 +93,12 @@ func (c *collectorPluginProxy) CollectMetrics(args []byte, reply *[]byte) error
 	c.Session.Decode(args, dargs)
 
 	ms, err := c.Plugin.CollectMetrics(dargs.MetricTypes)
-	if err != nil {
+	nerrs, partial := err.(NamespaceErrors)
+	if err != nil && !partial {
 		return errors.New(fmt.Sprintf("CollectMetrics call error : %s", err.Error()))
 	}
 
-	r := CollectMetricsReply{PluginMetrics: ms}
+	r := CollectMetricsReply{PluginMetrics: ms, NamespaceErrors: nerrs}
 	*reply, err = c.Session.Encode(r)
 	if err != nil {
 		return err
@@ -114,7 +117,8 @@ func (g *gRPCCollectorProxy) CollectMetrics(ctx context.Context, arg *rpc.Collec
 	defer catchPluginPanic(g.Session.Logger())
 
 	metrics, err := g.Plugin.CollectMetrics(toPluginMetricTypes(arg.Metrics))
-	if err != nil {
+	nerrs, partial := err.(NamespaceErrors)
+	if err != nil && !partial {
 		return &rpc.CollectMetricsReply{
 			Error: err.Error(),
 		}, nil
@@ -128,7 +132,8 @@ func (g *gRPCCollectorProxy) CollectMetrics(ctx context.Context, arg *rpc.Collec
 	}
 
 	reply := &rpc.CollectMetricsReply{
-		Metrics: common.NewMetrics(coreMetrics),
+		Metrics:         common.NewMetrics(coreMetrics),
+		NamespaceErrors: nerrs,
 	}
 
 	return reply, nil
@@ -102,6 +102,14 @@ func (p *mockErrorPlugin) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
 	return &cpolicy.ConfigPolicy{}, errors.New("Error in get config policy")
 }
 
+type mockPartialPlugin struct {
+	mockPlugin
+}
+
+func (p *mockPartialPlugin) CollectMetrics(mts []MetricType) ([]MetricType, error) {
+	return mts[1:], NamespaceErrors{mts[0].Namespace().String(): "sensor offline"}
+}
+
 func TestCollectorProxy(t *testing.T) {
 	Convey("Test collector plugin proxy for get metric types ", t, func() {
 
@@ -169,6 +177,22 @@ func TestCollectorProxy(t *testing.T) {
 				err = errC.CollectMetrics(out, &reply)
 				So(err, ShouldNotBeNil)
 			})
+			Convey("Get namespace errors in Collect Metric ", func() {
+				partialC := &collectorPluginProxy{
+					Plugin:  &mockPartialPlugin{},
+					Session: mockSessionState,
+				}
+				out, err := partialC.Session.Encode(CollectMetricsArgs{MetricTypes: mockMetricType})
+				So(err, ShouldBeNil)
+				var reply []byte
+				err = partialC.CollectMetrics(out, &reply)
+				So(err, ShouldBeNil)
+				var mtr CollectMetricsReply
+				err = partialC.Session.Decode(reply, &mtr)
+				So(err, ShouldBeNil)
+				So(len(mtr.PluginMetrics), ShouldEqual, 1)
+				So(mtr.NamespaceErrors, ShouldResemble, NamespaceErrors{mockMetricType[0].Namespace().String(): "sensor offline"})
+			})
 
 		})
 
@@ -36,6 +36,16 @@ type ProcessingErrors struct {
 	mutex  *sync.Mutex
 }
 
+// KeyError is a processing error for the config key of a rule.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (k *KeyError) Error() string {
+	return k.Err.Error()
+}
+
 func NewProcessingErrors() *ProcessingErrors {
 	return &ProcessingErrors{
 		errors: []error{},
@@ -165,13 +175,13 @@ func (c *ConfigPolicyNode) Process(m map[string]ctypes.ConfigValue) (*map[string
 			// Validate versus matching data
 			e := rule.Validate(cv)
 			if e != nil {
-				pErrors.AddError(e)
+				pErrors.AddError(&KeyError{Key: key, Err: e})
 			}
 		} else {
 			// If it was required add error
 			if rule.Required() {
 				e := fmt.Errorf("required key missing (%s)", key)
-				pErrors.AddError(e)
+				pErrors.AddError(&KeyError{Key: key, Err: e})
 			} else {
 				// If default returns we should add it
 				cv := rule.Default()
@@ -23,11 +23,14 @@ import (
 	"bufio"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -46,12 +49,22 @@ const (
 	pluginResponseBad                   // plugin response received (invalid)
 )
 
+// PidFileSuffix is the suffix of the pid files recorded for plugin processes
+const PidFileSuffix = ".pid"
+
 // A plugin that is executable as a forked process on *Linux.
 type ExecutablePlugin struct {
 	cmd    *exec.Cmd
 	stdout io.Reader
 	stderr io.Reader
 	args   Arg
+	// pidDir is the directory the pid file of the plugin process is
+	// recorded in, if any
+	pidDir string
+	// stdoutWriter and stderrWriter capture the output of the plugin when
+	// set, otherwise it is written to the plugin log files
+	stdoutWriter io.Writer
+	stderrWriter io.Writer
 }
 
 // A interface representing an executable plugin.
@@ -62,6 +75,12 @@ type pluginExecutor interface {
 	ErrorResponseReader() io.Reader
 }
 
+// outputCapturer is implemented by plugin executors whose output is
+// captured by writers rather than written to the plugin log files.
+type outputCapturer interface {
+	capturedOutput() (stdout, stderr io.Writer)
+}
+
 type waitSignal int
 
 type waitSignalValue struct {
@@ -70,6 +89,30 @@ type waitSignalValue struct {
 	Error    *error
 }
 
+// RecordPid records the process id of the plugin in a pid file in dir while
+// the plugin runs.  The pid file holds the path of the plugin executable on
+// its first line and the process id of the agent on its second, so that a
+// process which outlives the agent can be identified and the plugins of
+// another agent sharing the directory are left alone.  It must be called
+// before Start.
+func (e *ExecutablePlugin) RecordPid(dir string) {
+	e.pidDir = dir
+}
+
+// CaptureOutput routes the lines the plugin writes to stdout, once it has
+// responded, and to stderr to the writers rather than the plugin log files.
+// Each line is written in a single call to Write.  Writers which are
+// io.Closers are closed when the plugin closes its output.  It must be called
+// before WaitForResponse.
+func (e *ExecutablePlugin) CaptureOutput(stdout, stderr io.Writer) {
+	e.stdoutWriter = stdout
+	e.stderrWriter = stderr
+}
+
+func (e *ExecutablePlugin) capturedOutput() (io.Writer, io.Writer) {
+	return e.stdoutWriter, e.stderrWriter
+}
+
 // Starts the plugin and returns error if one occurred. This is non blocking.
 func (e *ExecutablePlugin) Start() error {
 	err := e.cmd.Start()
@@ -81,19 +124,44 @@ func (e *ExecutablePlugin) Start() error {
 			"cmd args": e.cmd.Args,
 			"error":    err.Error(),
 		}).Error("error in starting executable plugin")
+		return err
 	}
-	return err
+	if e.pidDir != "" {
+		record := fmt.Sprintf("%s\n%d\n", e.cmd.Path, os.Getpid())
+		if err := ioutil.WriteFile(e.pidFile(), []byte(record), 0600); err != nil {
+			execLogger.WithFields(logrus.Fields{
+				"_block": "start",
+				"path":   e.cmd.Path,
+				"error":  err.Error(),
+			}).Warn("unable to record plugin pid")
+		}
+	}
+	return nil
 }
 
 // Kills the plugin and returns error if one occurred. This is blocking.
 func (e *ExecutablePlugin) Kill() error {
 	execLogger.WithField("path", e.cmd.Path).Debug("Hard killing plugin")
+	e.removePidFile()
 	return e.cmd.Process.Kill()
 }
 
 // Waits for plugin to halt. If error is returned then plugin stopped with error. If not plugin stopped safely.
 func (e *ExecutablePlugin) WaitForExit() error {
-	return e.cmd.Wait()
+	err := e.cmd.Wait()
+	e.removePidFile()
+	return err
+}
+
+// pidFile returns the path of the pid file recorded for the plugin process.
+func (e *ExecutablePlugin) pidFile() string {
+	return filepath.Join(e.pidDir, strconv.Itoa(e.cmd.Process.Pid)+PidFileSuffix)
+}
+
+func (e *ExecutablePlugin) removePidFile() {
+	if e.pidDir != "" && e.cmd.Process != nil {
+		os.Remove(e.pidFile())
+	}
 }
 
 // The STDOUT pipe for the plugin as io.Reader. Use to read from plugin process STDOUT.
@@ -183,12 +251,17 @@ func waitHandling(p pluginExecutor, timeout time.Duration, logpath string) (*Res
 	log.Debug("timeout chan start")
 	go waitForPluginTimeout(timeout, p, waitChannel)
 
+	var stdout, stderr io.Writer
+	if c, ok := p.(outputCapturer); ok {
+		stdout, stderr = c.capturedOutput()
+	}
+
 	// send response received signal to our channel on response
 	log.Debug("response chan start")
-	go waitForResponseFromPlugin(p.ResponseReader(), waitChannel, logpath)
+	go waitForResponseFromPlugin(p.ResponseReader(), waitChannel, outputLogger(stdout, logpath, ".stdout"))
 
 	// log stderr from the plugin
-	go logStdErr(p.ErrorResponseReader(), logpath)
+	go logStdErr(p.ErrorResponseReader(), outputLogger(stderr, logpath, ".stderr"))
 
 	// send killed plugin signal to our channel on kill
 	log.Debug("kill chan start")
@@ -273,11 +346,31 @@ func waitForPluginTimeout(timeout time.Duration, p pluginExecutor, waitChannel c
 	waitChannel <- waitSignalValue{Signal: pluginTimeout}
 }
 
-func waitForResponseFromPlugin(r io.Reader, waitChannel chan waitSignalValue, logpath string) {
+// outputLogger returns a logger writing the output of a plugin to w or, when
+// w is nil, to the plugin log file with the suffix.  The writer is closed
+// when the logger is closed.
+func outputLogger(w io.Writer, logpath, suffix string) *pluginOutputLogger {
+	if w != nil {
+		return &pluginOutputLogger{Logger: log.New(w, "", 0), w: w}
+	}
 	lp := strings.TrimSuffix(logpath, filepath.Ext(logpath))
-	lf, _ := os.OpenFile(lp+".stdout", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
-	defer lf.Close()
-	logger := log.New(lf, "", log.Ldate|log.Ltime)
+	lf, _ := os.OpenFile(lp+suffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	return &pluginOutputLogger{Logger: log.New(lf, "", log.Ldate|log.Ltime), w: lf}
+}
+
+type pluginOutputLogger struct {
+	*log.Logger
+	w io.Writer
+}
+
+func (l *pluginOutputLogger) Close() {
+	if c, ok := l.w.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+func waitForResponseFromPlugin(r io.Reader, waitChannel chan waitSignalValue, logger *pluginOutputLogger) {
+	defer logger.Close()
 	processedResponse := false
 	scanner := bufio.NewScanner(r)
 	resp := new(Response)
@@ -315,11 +408,8 @@ OK:
 	}
 }
 
-func logStdErr(r io.Reader, logpath string) {
-	lp := strings.TrimSuffix(logpath, filepath.Ext(logpath))
-	lf, _ := os.OpenFile(lp+".stderr", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
-	defer lf.Close()
-	logger := log.New(lf, "", log.Ldate|log.Ltime)
+func logStdErr(r io.Reader, logger *pluginOutputLogger) {
+	defer logger.Close()
 	scanner := bufio.NewScanner(r)
 OK:
 	for scanner.Scan() {
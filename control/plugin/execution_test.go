@@ -225,3 +225,32 @@ func TestWaitForPluginResponse(t *testing.T) {
 
 	})
 }
+
+// closingBuffer is a buffer recording whether it was closed.
+type closingBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closingBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestCapturedOutput(t *testing.T) {
+	Convey("Captured stderr is written to the writer a line at a time", t, func() {
+		w := &closingBuffer{}
+		logStdErr(bytes.NewBufferString("first\nsecond\n"), outputLogger(w, "/tmp/snap-mock.log", ".stderr"))
+		So(w.String(), ShouldEqual, "first\nsecond\n")
+		So(w.closed, ShouldBeTrue)
+	})
+	Convey("Captured stdout after the response is written to the writer", t, func() {
+		w := &closingBuffer{}
+		waitChannel := make(chan waitSignalValue, 1)
+		r := bytes.NewBufferString("{}\nafter response\n")
+		waitForResponseFromPlugin(r, waitChannel, outputLogger(w, "/tmp/snap-mock.log", ".stdout"))
+		So((<-waitChannel).Signal, ShouldEqual, pluginResponseOk)
+		So(w.String(), ShouldEqual, "after response\n")
+		So(w.closed, ShouldBeTrue)
+	})
+}
@@ -28,9 +28,12 @@ import (
 	"time"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/golang/protobuf/proto"
 
+	"github.com/intelsdi-x/snap/control/plugin/rpc"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/grpc/common"
 )
 
 const (
@@ -43,7 +46,7 @@ const (
 	// SnapJSON snap metrics serialized into json
 	SnapJSONContentType = "snap.json"
 	// SnapProtoBuff snap metrics serialized into protocol buffers
-	// SnapProtoBuff = "snap.pb" // TO BE IMPLEMENTED
+	SnapProtoBuffContentType = "snap.pb"
 )
 
 type ConfigType struct {
@@ -224,6 +227,18 @@ func MarshalMetricTypes(contentType string, metrics []MetricType) ([]byte, strin
 			return nil, "", err
 		}
 		return b, SnapJSONContentType, nil
+	case SnapProtoBuffContentType:
+		// Serialize into protocol buffers
+		b, err := marshalProtoBuff(metrics)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"_module": "control-plugin",
+				"block":   "marshal-content-type",
+				"error":   err.Error(),
+			}).Error("error while marshalling")
+			return nil, "", err
+		}
+		return b, SnapProtoBuffContentType, nil
 	default:
 		// We don't recognize this content type. Log and return error.
 		es := fmt.Sprintf("invalid snap content type: %s", contentType)
@@ -264,6 +279,18 @@ func UnmarshallMetricTypes(contentType string, payload []byte) ([]MetricType, er
 			return nil, err
 		}
 		return metrics, nil
+	case SnapProtoBuffContentType:
+		arg := &rpc.CollectMetricsArg{}
+		err := proto.Unmarshal(payload, arg)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"_module": "control-plugin",
+				"block":   "unmarshal-content-type",
+				"error":   err.Error(),
+			}).Error("error while unmarshalling")
+			return nil, err
+		}
+		return toPluginMetricTypes(arg.Metrics), nil
 	default:
 		// We don't recognize this content type as one we can unmarshal. Log and return error.
 		es := fmt.Sprintf("invalid snap content type for unmarshalling: %s", contentType)
@@ -276,6 +303,22 @@ func UnmarshallMetricTypes(contentType string, payload []byte) ([]MetricType, er
 	}
 }
 
+// marshalProtoBuff serializes metrics as the repeated metrics of a
+// CollectMetricsArg message.  Metrics carrying data that has no protocol
+// buffer representation are rejected.
+func marshalProtoBuff(metrics []MetricType) ([]byte, error) {
+	mts := make([]*common.Metric, len(metrics))
+	for i, m := range metrics {
+		switch m.Data_.(type) {
+		case string, float64, float32, int32, int, int64, []byte, nil:
+		default:
+			return nil, fmt.Errorf("unsupported data type %T for metric %s", m.Data_, m.Namespace().String())
+		}
+		mts[i] = common.ToMetric(m)
+	}
+	return proto.Marshal(&rpc.CollectMetricsArg{Metrics: mts})
+}
+
 // SwapMetricContentType swaps a payload with one content type to another one.
 func SwapMetricContentType(contentType, requestedContentType string, payload []byte) ([]byte, string, error) {
 	metrics, err1 := UnmarshallMetricTypes(contentType, payload)
@@ -139,6 +139,35 @@ func TestMetric(t *testing.T) {
 		})
 	})
 
+	Convey("marshall using snap.pb", t, func() {
+		m := []MetricType{
+			*NewMetricType(core.NewNamespace("foo", "bar"), time.Now(), nil, "", 1),
+			*NewMetricType(core.NewNamespace("foo", "baz"), time.Now(), nil, "", "2"),
+		}
+		a, c, e := MarshalMetricTypes("snap.pb", m)
+		So(e, ShouldBeNil)
+		So(a, ShouldNotBeNil)
+		So(len(a), ShouldBeGreaterThan, 0)
+		So(c, ShouldEqual, "snap.pb")
+
+		Convey("unmarshal snap.pb", func() {
+			m, e = UnmarshallMetricTypes("snap.pb", a)
+			So(e, ShouldBeNil)
+			So(m[0].Namespace().String(), ShouldResemble, "/foo/bar")
+			So(m[0].Data(), ShouldResemble, int64(1))
+			So(m[1].Namespace().String(), ShouldResemble, "/foo/baz")
+			So(m[1].Data(), ShouldResemble, "2")
+		})
+
+		Convey("error on unsupported data", func() {
+			m[0].AddData(true)
+			a, c, e = MarshalMetricTypes("snap.pb", m)
+			So(e, ShouldNotBeNil)
+			So(e.Error(), ShouldEqual, "unsupported data type bool for metric /foo/bar")
+			So(a, ShouldBeNil)
+		})
+	})
+
 	Convey("error on unmarshall using bad content type", t, func() {
 		m := []MetricType{
 			*NewMetricType(core.NewNamespace("foo", "bar"), time.Now(), nil, "", 1),
@@ -31,12 +31,16 @@ import (
 	"net/http"
 	"net/rpc"
 	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
 	"regexp"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	myRPC "github.com/intelsdi-x/snap/control/plugin/rpc"
+	"github.com/intelsdi-x/snap/core"
 	"google.golang.org/grpc"
 )
 
@@ -71,6 +75,9 @@ const (
 	// Using this strategy enables a running database plugin that has the same connection info between
 	// two tasks to be shared.
 	ConfigRouting
+	// LeastLoadedRouting is routing to the running instance of a plugin
+	// serving the fewest calls.
+	LeastLoadedRouting
 )
 
 // Plugin response states
@@ -89,6 +96,59 @@ const (
 	GRPC
 )
 
+// TransportType is the transport a plugin listens on for requests from snapd.
+type TransportType int
+
+const (
+	// TransportTCP listens on a loopback TCP port
+	TransportTCP TransportType = iota
+	// TransportUnix listens on a Unix domain socket.  Plugins fall back to
+	// TCP when Unix domain sockets are not supported.
+	TransportUnix
+)
+
+// Capability is a feature a plugin declares support for in its metadata.
+// Capabilities are flags and may be combined.
+type Capability int
+
+const (
+	// StreamingCapability is declared by plugins which stream metrics.
+	StreamingCapability Capability = 1 << iota
+	// CompressionCapability is declared by plugins which accept compressed
+	// content.  It is implied by accepted content encodings.
+	CompressionCapability
+	// ExclusiveCapability is declared by plugins which run a single
+	// instance.  It is implied by Exclusive.
+	ExclusiveCapability
+	// ChunkedContentCapability is declared by publishers which accept
+	// content published in chunks.  Chunked content is only supported by
+	// native and JSON-RPC publishers.
+	ChunkedContentCapability
+)
+
+// Returns string for matching Capability flags
+func (c Capability) String() string {
+	var names []string
+	for i, name := range capabilityNames {
+		if c&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// unixAddressPrefix marks a listen address as a Unix domain socket path
+const unixAddressPrefix = "unix://"
+
+// SplitListenAddress returns the network and address a plugin listen
+// address should be dialed with.
+func SplitListenAddress(addr string) (network, address string) {
+	if strings.HasPrefix(addr, unixAddressPrefix) {
+		return "unix", strings.TrimPrefix(addr, unixAddressPrefix)
+	}
+	return "tcp", addr
+}
+
 var (
 	// Timeout settings
 	// How much time must elapse before a lack of Ping results in a timeout
@@ -108,6 +168,15 @@ var (
 		"least-recently-used",
 		"sticky",
 		"config",
+		"least-loaded",
+	}
+
+	// Array matching Capability flags to a string
+	capabilityNames = [...]string{
+		"streaming",
+		"compression",
+		"exclusive",
+		"chunked-content",
 	}
 )
 
@@ -115,6 +184,14 @@ type Plugin interface {
 	GetConfigPolicy() (*cpolicy.ConfigPolicy, error)
 }
 
+// ConfigValidator is implemented by plugins which validate their config
+// beyond what can be expressed by their config policy.  ValidateConfig is
+// called when a task using the plugin is validated and returns an error for
+// each problem with the config.
+type ConfigValidator interface {
+	ValidateConfig(ConfigType) []error
+}
+
 // PluginMeta for plugin
 type PluginMeta struct {
 	Name    string
@@ -138,9 +215,55 @@ type PluginMeta struct {
 	Unsecure bool
 	// CacheTTL will override the default cache TTL for the provided plugin.
 	CacheTTL time.Duration
+	// DisableCaching results in the plugin's metrics never being cached,
+	// for plugins whose data is not idempotent, such as event counters.
+	DisableCaching bool
 	// RoutingStrategy will override the routing strategy this plugin requires.
 	// The default routing strategy round-robin.
 	RoutingStrategy RoutingStrategyType
+	// DependsOn lists the plugins which must already be loaded before this
+	// plugin can be loaded.
+	DependsOn []PluginRef
+	// AcceptedContentEncodings are the content encodings, such as gzip,
+	// snap may compress published content with.  Content encodings are only
+	// supported by native and JSON-RPC publishers.
+	AcceptedContentEncodings []string
+	// MaxConcurrentCalls is the max number of calls a running instance of
+	// the plugin is sent at once.  Plugins which are not safe to call
+	// concurrently should set this to 1.  Zero means no limit.
+	MaxConcurrentCalls int
+	// Capabilities are the features the plugin declares support for.
+	Capabilities Capability
+	// CollectionCost hints how expensive collecting from the plugin is
+	// relative to other collectors.
+	CollectionCost core.CollectionCost
+}
+
+// HasCapability returns whether the plugin supports all the capabilities,
+// whether declared or implied by its other metadata.
+func (m *PluginMeta) HasCapability(c Capability) bool {
+	caps := m.Capabilities
+	if m.Exclusive {
+		caps |= ExclusiveCapability
+	}
+	if len(m.AcceptedContentEncodings) > 0 {
+		caps |= CompressionCapability
+	}
+	return caps&c == c
+}
+
+// PluginRef identifies a plugin by type, name and version.
+// A version less than 1 matches any loaded version of the plugin.
+type PluginRef struct {
+	Type    PluginType
+	Name    string
+	Version int
+}
+
+// Key returns the reference in the {type}:{name}:{version} form used to
+// look up loaded plugins.
+func (r PluginRef) Key() string {
+	return core.PluginKey(core.PluginType(r.Type), r.Name, r.Version)
 }
 
 type metaOp func(m *PluginMeta)
@@ -180,6 +303,50 @@ func CacheTTL(t time.Duration) metaOp {
 	}
 }
 
+// DisableCaching is an option that can be be provided to the func NewPluginMeta.
+func DisableCaching(b bool) metaOp {
+	return func(m *PluginMeta) {
+		m.DisableCaching = b
+	}
+}
+
+// DependsOn is an option that can be be provided to the func NewPluginMeta.
+func DependsOn(refs ...PluginRef) metaOp {
+	return func(m *PluginMeta) {
+		m.DependsOn = append(m.DependsOn, refs...)
+	}
+}
+
+// AcceptedContentEncodings is an option that can be be provided to the func NewPluginMeta.
+func AcceptedContentEncodings(encodings ...string) metaOp {
+	return func(m *PluginMeta) {
+		m.AcceptedContentEncodings = append(m.AcceptedContentEncodings, encodings...)
+	}
+}
+
+// MaxConcurrentCalls is an option that can be be provided to the func NewPluginMeta.
+func MaxConcurrentCalls(n int) metaOp {
+	return func(m *PluginMeta) {
+		m.MaxConcurrentCalls = n
+	}
+}
+
+// CollectionCost is an option that can be be provided to the func NewPluginMeta.
+func CollectionCost(c core.CollectionCost) metaOp {
+	return func(m *PluginMeta) {
+		m.CollectionCost = c
+	}
+}
+
+// Capabilities is an option that can be be provided to the func NewPluginMeta.
+func Capabilities(caps ...Capability) metaOp {
+	return func(m *PluginMeta) {
+		for _, c := range caps {
+			m.Capabilities |= c
+		}
+	}
+}
+
 // NewPluginMeta constructs and returns a PluginMeta struct
 func NewPluginMeta(name string, version int, pluginType PluginType, acceptContentTypes, returnContentTypes []string, opts ...metaOp) *PluginMeta {
 	// An empty accepted content type default to "snap.*"
@@ -232,6 +399,10 @@ type Arg struct {
 	PingTimeoutDuration time.Duration
 
 	NoDaemon bool
+	// The transport to listen on
+	Transport TransportType
+	// The log level, such as debug or info, to log at
+	LogLevel string
 	// The listen port
 	listenPort string
 }
@@ -338,12 +509,12 @@ func Start(m *PluginMeta, c Plugin, requestString string) (error, int) {
 		}
 	}
 
-	l, err := net.Listen("tcp", "127.0.0.1:"+s.ListenPort())
+	l, err := listen(s, m.RPCType)
 	if err != nil {
 		s.Logger().Println(err.Error())
 		panic(err)
 	}
-	s.SetListenAddress(l.Addr().String())
+	defer unlinkSocket(s)
 	s.Logger().Printf("Listening %s\n", l.Addr())
 	s.Logger().Printf("Session token %s\n", s.Token())
 
@@ -397,6 +568,37 @@ func Start(m *PluginMeta, c Plugin, requestString string) (error, int) {
 	return nil, exitCode
 }
 
+// listen opens the listener the plugin serves requests on and sets the
+// session listen address.  A Unix domain socket is used when requested and
+// supported, otherwise a loopback TCP port.
+func listen(s *SessionState, rpcType RPCType) (net.Listener, error) {
+	// JSON-RPC is served over HTTP which is only dialed over TCP
+	if s.Transport == TransportUnix && runtime.GOOS != "windows" && rpcType != JSONRPC {
+		path := filepath.Join(os.TempDir(), fmt.Sprintf("snap-plugin-%d.sock", os.Getpid()))
+		os.Remove(path)
+		l, err := net.Listen("unix", path)
+		if err == nil {
+			s.SetListenAddress(unixAddressPrefix + path)
+			return l, nil
+		}
+		s.Logger().Printf("Unable to listen on %s, falling back to TCP: %v\n", path, err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:"+s.ListenPort())
+	if err != nil {
+		return nil, err
+	}
+	s.SetListenAddress(l.Addr().String())
+	return l, nil
+}
+
+// unlinkSocket removes the Unix domain socket file the plugin listened on,
+// if any, once the plugin stops.
+func unlinkSocket(s *SessionState) {
+	if network, path := SplitListenAddress(s.ListenAddress()); network == "unix" {
+		os.Remove(path)
+	}
+}
+
 func startGRPC(m *PluginMeta, c Plugin, requestString string) (error, int) {
 	s, sErr, retCode := NewSessionState(requestString, c, m)
 	if sErr != nil {
@@ -469,12 +671,12 @@ func startGRPC(m *PluginMeta, c Plugin, requestString string) (error, int) {
 		myRPC.RegisterPublisherServer(grpcServer, publishProxy)
 	}
 
-	l, err := net.Listen("tcp", "127.0.0.1:"+s.ListenPort())
+	l, err := listen(s, m.RPCType)
 	if err != nil {
 		s.Logger().Println(err.Error())
 		panic(err)
 	}
-	s.SetListenAddress(l.Addr().String())
+	defer unlinkSocket(s)
 	go func() {
 		err := grpcServer.Serve(l)
 		if err != nil {
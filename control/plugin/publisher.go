@@ -26,3 +26,17 @@ type PublisherPlugin interface {
 	Plugin
 	Publish(contentType string, content []byte, config map[string]ctypes.ConfigValue) error
 }
+
+// AckingPublisher is a publisher plugin which acknowledges the content it
+// publishes, confirming delivery to its backend.
+type AckingPublisher interface {
+	PublishWithAck(contentType string, content []byte, config map[string]ctypes.ConfigValue) (PublishAck, error)
+}
+
+// PublishAck is a publisher's acknowledgment of published content.
+type PublishAck struct {
+	// Acked is true when the publisher confirmed delivery of the content
+	Acked bool
+	// BatchID optionally identifies the batch the content was delivered in
+	BatchID string
+}
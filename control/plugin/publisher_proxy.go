@@ -20,8 +20,13 @@ limitations under the License.
 package plugin
 
 import (
+	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -30,18 +35,142 @@ import (
 	"github.com/intelsdi-x/snap/grpc/common"
 )
 
+// GzipContentEncoding is the content encoding for gzip compressed content
+const GzipContentEncoding = "gzip"
+
+// chunkStreamTTL is how long the chunks of content published in chunks are
+// held without a further chunk arriving before the content is discarded, so
+// that content whose publishing was abandoned is not held forever.
+const chunkStreamTTL = time.Minute
+
 type PublishArgs struct {
 	ContentType string
-	Content     []byte
-	Config      map[string]ctypes.ConfigValue
+	// ContentEncoding is the encoding Content is compressed with, if any
+	ContentEncoding string
+	Content         []byte
+	Config          map[string]ctypes.ConfigValue
+	// Chunk is set when Content is a chunk of content published in chunks
+	Chunk *ContentChunk
+}
+
+// ContentChunk identifies a chunk of content published in chunks.  The
+// chunks of content are published in order and the publisher publishes the
+// content once its last chunk arrives.
+type ContentChunk struct {
+	// Stream identifies the content the chunk is part of
+	Stream string
+	// Index is the position of the chunk in the content, starting at 0
+	Index int
+	// Last is true for the final chunk of the content
+	Last bool
+}
+
+// SplitContent splits content into chunks of at most size bytes.
+func SplitContent(content []byte, size int) [][]byte {
+	if size <= 0 || len(content) <= size {
+		return [][]byte{content}
+	}
+	chunks := make([][]byte, 0, (len(content)+size-1)/size)
+	for len(content) > size {
+		chunks = append(chunks, content[:size])
+		content = content[size:]
+	}
+	return append(chunks, content)
+}
+
+// chunkBuffer holds the chunks of content published in chunks until the
+// last chunk arrives.  The zero value is ready to use.
+type chunkBuffer struct {
+	sync.Mutex
+	streams map[string]*chunkStream
+}
+
+type chunkStream struct {
+	content []byte
+	next    int
+	// updated is when the last chunk of the stream arrived
+	updated time.Time
+}
+
+// add adds the chunk to its content and returns the content once the last
+// chunk is added.  done is false until then.  A chunk arriving out of order
+// discards the content, as does no chunk arriving for chunkStreamTTL.
+func (b *chunkBuffer) add(chunk *ContentChunk, content []byte) ([]byte, bool, error) {
+	b.Lock()
+	defer b.Unlock()
+	if b.streams == nil {
+		b.streams = make(map[string]*chunkStream)
+	}
+	now := time.Now()
+	for id, stream := range b.streams {
+		if now.Sub(stream.updated) >= chunkStreamTTL {
+			delete(b.streams, id)
+		}
+	}
+	stream, ok := b.streams[chunk.Stream]
+	if !ok {
+		stream = &chunkStream{}
+	}
+	if chunk.Index != stream.next {
+		delete(b.streams, chunk.Stream)
+		return nil, false, fmt.Errorf("chunk %d of stream %s received, expected chunk %d", chunk.Index, chunk.Stream, stream.next)
+	}
+	stream.content = append(stream.content, content...)
+	stream.next++
+	stream.updated = now
+	if chunk.Last {
+		delete(b.streams, chunk.Stream)
+		return stream.content, true, nil
+	}
+	b.streams[chunk.Stream] = stream
+	return nil, false, nil
+}
+
+// EncodeContent compresses content with the content encoding.
+func EncodeContent(encoding string, content []byte) ([]byte, error) {
+	switch encoding {
+	case "":
+		return content, nil
+	case GzipContentEncoding:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(content); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+}
+
+// DecodeContent decompresses content compressed with the content encoding.
+func DecodeContent(encoding string, content []byte) ([]byte, error) {
+	switch encoding {
+	case "":
+		return content, nil
+	case GzipContentEncoding:
+		r, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	}
+	return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
 }
 
 type PublishReply struct {
+	// Ack is set by publishers which acknowledge published content
+	Ack *PublishAck
 }
 
 type publisherPluginProxy struct {
 	Plugin  PublisherPlugin
 	Session Session
+	// chunks holds content published in chunks until it is complete
+	chunks chunkBuffer
 }
 
 func (p *publisherPluginProxy) Publish(args []byte, reply *[]byte) error {
@@ -54,7 +183,30 @@ func (p *publisherPluginProxy) Publish(args []byte, reply *[]byte) error {
 		return err
 	}
 
-	err = p.Plugin.Publish(dargs.ContentType, dargs.Content, dargs.Config)
+	content := dargs.Content
+	if dargs.Chunk != nil {
+		var done bool
+		content, done, err = p.chunks.add(dargs.Chunk, content)
+		if err != nil || !done {
+			return err
+		}
+	}
+
+	content, err = DecodeContent(dargs.ContentEncoding, content)
+	if err != nil {
+		return err
+	}
+
+	if ap, ok := p.Plugin.(AckingPublisher); ok {
+		ack, err := ap.PublishWithAck(dargs.ContentType, content, dargs.Config)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Publish call error: %v", err.Error()))
+		}
+		*reply, err = p.Session.Encode(PublishReply{Ack: &ack})
+		return err
+	}
+
+	err = p.Plugin.Publish(dargs.ContentType, content, dargs.Config)
 	if err != nil {
 		return errors.New(fmt.Sprintf("Publish call error: %v", err.Error()))
 	}
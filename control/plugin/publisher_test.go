@@ -23,10 +23,12 @@ package plugin
 
 import (
 	"log"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/control/plugin/encoding"
 	"github.com/intelsdi-x/snap/core/ctypes"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -40,6 +42,24 @@ func (f *MockPublisher) Publish(_ string, _ []byte, _ map[string]ctypes.ConfigVa
 	return nil
 }
 
+type mockPublisher struct{}
+
+func (f *mockPublisher) Publish(_ string, _ []byte, _ map[string]ctypes.ConfigValue) error {
+	return nil
+}
+
+func (f *mockPublisher) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
+	return cpolicy.New(), nil
+}
+
+type mockAckingPublisher struct {
+	mockPublisher
+}
+
+func (f *mockAckingPublisher) PublishWithAck(_ string, _ []byte, _ map[string]ctypes.ConfigValue) (PublishAck, error) {
+	return PublishAck{Acked: true, BatchID: "batch-1"}, nil
+}
+
 func (f *MockPublisher) GetConfigPolicy() cpolicy.ConfigPolicy {
 	return cpolicy.ConfigPolicy{}
 }
@@ -121,3 +141,117 @@ func TestStartPublisher(t *testing.T) {
 		})
 	})
 }
+
+func TestContentEncoding(t *testing.T) {
+	Convey("Content encodings", t, func() {
+		content := []byte("metrics to publish")
+		Convey("gzip content is decoded to the original content", func() {
+			encoded, err := EncodeContent(GzipContentEncoding, content)
+			So(err, ShouldBeNil)
+			decoded, err := DecodeContent(GzipContentEncoding, encoded)
+			So(err, ShouldBeNil)
+			So(decoded, ShouldResemble, content)
+		})
+		Convey("content without an encoding is unchanged", func() {
+			decoded, err := DecodeContent("", content)
+			So(err, ShouldBeNil)
+			So(decoded, ShouldResemble, content)
+		})
+		Convey("unsupported encodings return an error", func() {
+			_, err := EncodeContent("br", content)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestPublisherProxyAck(t *testing.T) {
+	Convey("Publisher plugin proxy", t, func() {
+		session := &MockSessionState{
+			Encoder:             encoding.NewGobEncoder(),
+			listenPort:          "0",
+			token:               "abcdef",
+			logger:              log.New(os.Stdout, "test: ", log.Ldate|log.Ltime|log.Lshortfile),
+			PingTimeoutDuration: time.Millisecond * 100,
+			killChan:            make(chan int),
+		}
+		args, err := session.Encode(PublishArgs{ContentType: "snap.gob", Content: []byte("metrics")})
+		So(err, ShouldBeNil)
+		Convey("replies with the ack of an acking publisher", func() {
+			p := &publisherPluginProxy{Plugin: &mockAckingPublisher{}, Session: session}
+			var reply []byte
+			So(p.Publish(args, &reply), ShouldBeNil)
+			var r PublishReply
+			So(session.Decode(reply, &r), ShouldBeNil)
+			So(r.Ack, ShouldNotBeNil)
+			So(r.Ack.Acked, ShouldBeTrue)
+			So(r.Ack.BatchID, ShouldEqual, "batch-1")
+		})
+		Convey("replies with nothing for a publisher without acks", func() {
+			p := &publisherPluginProxy{Plugin: &mockPublisher{}, Session: session}
+			var reply []byte
+			So(p.Publish(args, &reply), ShouldBeNil)
+			So(reply, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestPublisherProxyChunks(t *testing.T) {
+	Convey("Publisher plugin proxy", t, func() {
+		session := &MockSessionState{
+			Encoder:             encoding.NewGobEncoder(),
+			listenPort:          "0",
+			token:               "abcdef",
+			logger:              log.New(os.Stdout, "test: ", log.Ldate|log.Ltime|log.Lshortfile),
+			PingTimeoutDuration: time.Millisecond * 100,
+			killChan:            make(chan int),
+		}
+		publish := func(p *publisherPluginProxy, chunk []byte, c ContentChunk) ([]byte, error) {
+			args, err := session.Encode(PublishArgs{ContentType: "snap.gob", Content: chunk, Chunk: &c})
+			So(err, ShouldBeNil)
+			var reply []byte
+			err = p.Publish(args, &reply)
+			return reply, err
+		}
+		Convey("publishes chunked content once its last chunk arrives", func() {
+			p := &publisherPluginProxy{Plugin: &mockAckingPublisher{}, Session: session}
+			reply, err := publish(p, []byte("met"), ContentChunk{Stream: "s", Index: 0})
+			So(err, ShouldBeNil)
+			So(reply, ShouldBeEmpty)
+			reply, err = publish(p, []byte("rics"), ContentChunk{Stream: "s", Index: 1, Last: true})
+			So(err, ShouldBeNil)
+			var r PublishReply
+			So(session.Decode(reply, &r), ShouldBeNil)
+			So(r.Ack, ShouldNotBeNil)
+			So(p.chunks.streams, ShouldBeEmpty)
+		})
+		Convey("rejects chunks arriving out of order", func() {
+			p := &publisherPluginProxy{Plugin: &mockPublisher{}, Session: session}
+			_, err := publish(p, []byte("met"), ContentChunk{Stream: "s", Index: 0})
+			So(err, ShouldBeNil)
+			_, err = publish(p, []byte("rics"), ContentChunk{Stream: "s", Index: 2, Last: true})
+			So(err, ShouldNotBeNil)
+			So(p.chunks.streams, ShouldBeEmpty)
+		})
+		Convey("discards content no chunk has arrived for within the TTL", func() {
+			p := &publisherPluginProxy{Plugin: &mockPublisher{}, Session: session}
+			_, err := publish(p, []byte("met"), ContentChunk{Stream: "abandoned", Index: 0})
+			So(err, ShouldBeNil)
+			p.chunks.streams["abandoned"].updated = time.Now().Add(-chunkStreamTTL)
+			_, err = publish(p, []byte("met"), ContentChunk{Stream: "s", Index: 0})
+			So(err, ShouldBeNil)
+			_, ok := p.chunks.streams["abandoned"]
+			So(ok, ShouldBeFalse)
+			So(len(p.chunks.streams), ShouldEqual, 1)
+		})
+	})
+	Convey("SplitContent", t, func() {
+		Convey("splits content into chunks of at most the size", func() {
+			chunks := SplitContent([]byte("abcdefg"), 3)
+			So(chunks, ShouldResemble, [][]byte{[]byte("abc"), []byte("def"), []byte("g")})
+		})
+		Convey("returns small content as one chunk", func() {
+			So(SplitContent([]byte("abc"), 3), ShouldResemble, [][]byte{[]byte("abc")})
+			So(SplitContent([]byte("abc"), 0), ShouldResemble, [][]byte{[]byte("abc")})
+		})
+	})
+}
@@ -354,6 +354,9 @@ func (m *CollectMetricsArg) GetMetrics() []*common.Metric {
 type CollectMetricsReply struct {
 	Metrics []*common.Metric `protobuf:"bytes,1,rep,name=metrics" json:"metrics,omitempty"`
 	Error   string           `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	// namespace_errors maps the namespace of each metric which could not be
+	// collected to the reason
+	NamespaceErrors map[string]string `protobuf:"bytes,3,rep,name=namespace_errors" json:"namespace_errors,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *CollectMetricsReply) Reset()                    { *m = CollectMetricsReply{} }
@@ -368,6 +371,13 @@ func (m *CollectMetricsReply) GetMetrics() []*common.Metric {
 	return nil
 }
 
+func (m *CollectMetricsReply) GetNamespaceErrors() map[string]string {
+	if m != nil {
+		return m.NamespaceErrors
+	}
+	return nil
+}
+
 type GetMetricTypesArg struct {
 	Config *common.ConfigMap `protobuf:"bytes,1,opt,name=config" json:"config,omitempty"`
 }
@@ -1057,67 +1067,70 @@ func init() {
 }
 
 var fileDescriptor0 = []byte{
-	// 986 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xdc, 0x97, 0xdf, 0x73, 0xdb, 0x44,
-	0x10, 0xc7, 0xab, 0xc8, 0x76, 0xac, 0x95, 0xed, 0xc4, 0xd7, 0xd2, 0x31, 0x22, 0xa1, 0xad, 0x5e,
-	0xa0, 0x69, 0x6a, 0xcf, 0xa4, 0x0c, 0xc3, 0x14, 0xda, 0x81, 0xa4, 0x29, 0x30, 0x9d, 0xce, 0x64,
-	0x5c, 0x78, 0xee, 0xd8, 0xca, 0xc5, 0xd5, 0x20, 0x4b, 0x42, 0x92, 0xa1, 0x66, 0x86, 0x3f, 0x91,
-	0x3f, 0x80, 0x7f, 0x85, 0x07, 0x60, 0x6f, 0xef, 0xf4, 0xe3, 0x6c, 0x09, 0x32, 0x0c, 0x4f, 0x7d,
-	0x72, 0x6e, 0xb5, 0xfb, 0xd1, 0x7e, 0x77, 0xef, 0x6e, 0x15, 0x78, 0xbc, 0xf0, 0xb3, 0x37, 0xab,
-	0xf9, 0xd8, 0x8b, 0x96, 0x13, 0x3f, 0xcc, 0x78, 0x90, 0x5e, 0xfa, 0x0f, 0xdf, 0x4e, 0xd2, 0x70,
-	0x16, 0x4f, 0xbc, 0x28, 0xcc, 0x92, 0x28, 0x98, 0xc4, 0xc1, 0x6a, 0xe1, 0x87, 0x93, 0x24, 0xf6,
-	0xd4, 0x9f, 0xe3, 0x38, 0x89, 0xb2, 0x88, 0x99, 0x68, 0x71, 0x1e, 0xfd, 0x03, 0x60, 0x21, 0x42,
-	0xf0, 0xc1, 0x32, 0x0a, 0xd5, 0x8f, 0x8c, 0x74, 0xbf, 0x07, 0xb8, 0x48, 0x22, 0x8f, 0xa7, 0xe9,
-	0x57, 0xc9, 0x82, 0xdd, 0x04, 0xfb, 0x0c, 0x5f, 0xc5, 0xc3, 0xec, 0xbb, 0x75, 0xcc, 0x47, 0xc6,
-	0x5d, 0xe3, 0x63, 0x8b, 0xed, 0xc1, 0xae, 0x32, 0x8e, 0x76, 0xd0, 0xd0, 0x63, 0xf7, 0xa0, 0x83,
-	0x86, 0x2b, 0x7f, 0x31, 0x32, 0x71, 0x6d, 0x9f, 0x0c, 0xc7, 0x0a, 0x29, 0xad, 0x2f, 0x67, 0xb1,
-	0x7b, 0x06, 0x3d, 0x85, 0x9d, 0xf2, 0x38, 0x58, 0x5f, 0x13, 0xdc, 0x87, 0xf6, 0x79, 0x92, 0x44,
-	0x09, 0x71, 0x2d, 0xca, 0x6d, 0x35, 0x0f, 0xfc, 0xf4, 0xcd, 0xff, 0x9a, 0xdb, 0x5f, 0x06, 0xb4,
-	0xa6, 0xab, 0x80, 0xb3, 0x21, 0x58, 0x09, 0xfe, 0xbe, 0xce, 0x4a, 0x9e, 0x0d, 0xe6, 0x0f, 0x7c,
-	0x4d, 0x2c, 0x8b, 0xed, 0x43, 0x37, 0xe1, 0x3f, 0xae, 0xfc, 0x84, 0x5f, 0x12, 0xad, 0x2b, 0x72,
-	0xe0, 0x3c, 0xf5, 0x12, 0x3f, 0xce, 0xfc, 0x28, 0x1c, 0xb5, 0xc8, 0xed, 0x16, 0xf4, 0xe6, 0x51,
-	0x14, 0xbc, 0xbe, 0xe4, 0x57, 0xb3, 0x55, 0x90, 0x8d, 0xda, 0xe4, 0xfa, 0x1e, 0xf4, 0xaf, 0x82,
-	0x68, 0x96, 0x15, 0xe6, 0x0e, 0x9a, 0x8d, 0xd2, 0xbc, 0xf4, 0x43, 0x7f, 0xb9, 0x5a, 0x8e, 0x76,
-	0x37, 0xcc, 0xb3, 0xb7, 0x64, 0xee, 0x92, 0x19, 0xdf, 0x87, 0x9d, 0x2c, 0x10, 0x16, 0x1a, 0xcd,
-	0xdc, 0x98, 0x03, 0x40, 0x33, 0xaa, 0x70, 0x9b, 0x8c, 0xb7, 0x61, 0x90, 0x66, 0x89, 0x1f, 0x2e,
-	0x0a, 0x42, 0x8f, 0x0a, 0x3b, 0x02, 0xeb, 0x15, 0xcf, 0x5e, 0xf0, 0xb5, 0xa8, 0xab, 0x92, 0x2c,
-	0xf4, 0xf7, 0xdc, 0x03, 0xb0, 0xe5, 0x13, 0xd9, 0x36, 0x6c, 0x08, 0xa7, 0x86, 0x50, 0x75, 0x5c,
-	0x07, 0xac, 0x0b, 0xa4, 0xd5, 0x3e, 0x3b, 0x04, 0xfb, 0x85, 0x1f, 0x04, 0x53, 0x2c, 0x18, 0x4f,
-	0x33, 0x36, 0x80, 0xce, 0x94, 0xcf, 0x52, 0x2c, 0x52, 0x11, 0x2a, 0x1f, 0xd7, 0x84, 0xfe, 0xd6,
-	0x82, 0x5b, 0x5f, 0xf3, 0x4c, 0x76, 0xe8, 0x22, 0x0a, 0x7c, 0xaf, 0xf6, 0xf5, 0xec, 0x29, 0xd8,
-	0x54, 0xe8, 0x98, 0x5c, 0xb0, 0x49, 0x26, 0x36, 0xf8, 0xfe, 0x18, 0xb7, 0xf6, 0xb8, 0x2e, 0x7c,
-	0x7c, 0x8a, 0xce, 0x72, 0x7d, 0x8e, 0xc7, 0x67, 0xcd, 0xbe, 0x84, 0x9e, 0x2c, 0xb2, 0x02, 0x98,
-	0x04, 0x38, 0x6a, 0x06, 0x3c, 0x17, 0xde, 0x55, 0xc2, 0x33, 0x18, 0x88, 0x93, 0xb5, 0xe0, 0x49,
-	0xce, 0x68, 0x11, 0xe3, 0xb8, 0x99, 0xf1, 0xad, 0xf4, 0xaf, 0x52, 0x4e, 0xa1, 0xaf, 0xda, 0xa2,
-	0x20, 0x6d, 0x82, 0x3c, 0x68, 0x86, 0xbc, 0x22, 0xf7, 0x0a, 0xc3, 0x39, 0x85, 0xbd, 0x4d, 0x79,
-	0x95, 0x46, 0x5a, 0xec, 0x43, 0x68, 0xff, 0x34, 0x0b, 0x56, 0x9c, 0xb6, 0xb2, 0x7d, 0xb2, 0x47,
-	0xec, 0x32, 0xe2, 0xf1, 0xce, 0x67, 0x86, 0xf3, 0x0c, 0xf6, 0xb7, 0x14, 0x6a, 0x90, 0x3b, 0x3a,
-	0x64, 0x9f, 0x20, 0x95, 0x10, 0xa2, 0x7c, 0x03, 0xac, 0x46, 0xa3, 0xc6, 0xb9, 0xa7, 0x73, 0x18,
-	0x71, 0xb4, 0x20, 0x22, 0x3d, 0x87, 0xe1, 0x96, 0x50, 0x1d, 0x74, 0x57, 0x07, 0x0d, 0x09, 0x54,
-	0x8d, 0x11, 0x1c, 0xf7, 0x21, 0x74, 0x85, 0x52, 0x3a, 0xe3, 0xd5, 0x33, 0x6c, 0xd0, 0xc1, 0xc4,
-	0x2b, 0x23, 0x3f, 0x0d, 0x82, 0xd2, 0x75, 0x33, 0x80, 0xb2, 0x30, 0xec, 0x3e, 0xb4, 0xc5, 0xa5,
-	0x90, 0xa2, 0xb7, 0x68, 0x8a, 0xb3, 0x51, 0xb8, 0xb1, 0xa0, 0xa6, 0xb2, 0x07, 0x9f, 0x03, 0x94,
-	0x2b, 0x3d, 0xd1, 0x03, 0x3d, 0xd1, 0x7e, 0x41, 0x11, 0x01, 0x94, 0xe4, 0x05, 0x58, 0x54, 0xc9,
-	0xe6, 0x2c, 0xf3, 0x03, 0xbe, 0x43, 0x57, 0x81, 0x30, 0xa8, 0xc3, 0x6d, 0xe6, 0x86, 0x5c, 0x87,
-	0xb8, 0x87, 0x0c, 0xf7, 0x67, 0xb0, 0x2b, 0xbd, 0x61, 0x47, 0xba, 0x90, 0x0f, 0x36, 0x9b, 0x57,
-	0x55, 0xf2, 0x45, 0xb3, 0x92, 0x43, 0x5d, 0xc9, 0xa0, 0xc4, 0x14, 0x52, 0xa6, 0x60, 0xab, 0x66,
-	0x5e, 0x4f, 0x8c, 0xb9, 0x29, 0xc6, 0xdc, 0x14, 0x63, 0xba, 0xbf, 0x42, 0x5f, 0xdb, 0x20, 0xec,
-	0x58, 0x97, 0x73, 0xb8, 0xbd, 0x87, 0xaa, 0x82, 0x9e, 0x36, 0x0b, 0xaa, 0xdd, 0xd4, 0x95, 0xfc,
-	0x49, 0xd2, 0x04, 0x40, 0x6e, 0xab, 0xeb, 0x6d, 0x22, 0xcb, 0xfd, 0x05, 0x7a, 0xd5, 0x7d, 0xc8,
-	0x1e, 0xe8, 0xe9, 0x1e, 0x6c, 0xed, 0xd4, 0x6a, 0xb6, 0x4f, 0x9a, 0xb3, 0xad, 0x3d, 0xc7, 0x65,
-	0x6a, 0x94, 0xec, 0x27, 0x30, 0x3c, 0x8b, 0x82, 0x80, 0x7b, 0xd9, 0x4b, 0x8e, 0x4f, 0x3c, 0x1a,
-	0xe5, 0x77, 0xb0, 0xc4, 0x72, 0xa5, 0x52, 0x18, 0xe4, 0x93, 0x50, 0x3a, 0xb9, 0xe7, 0x70, 0x53,
-	0x8f, 0x92, 0x77, 0xee, 0xbf, 0xc5, 0x95, 0x97, 0xb2, 0x14, 0xfe, 0x29, 0x0c, 0xf1, 0xca, 0x92,
-	0xcf, 0xc4, 0x60, 0xa6, 0x97, 0xe3, 0x14, 0xf6, 0xe4, 0x14, 0x36, 0x9a, 0xa6, 0x30, 0xbe, 0x5e,
-	0x8f, 0xfb, 0x4f, 0xaf, 0x3f, 0xf9, 0x7d, 0x07, 0x2c, 0x25, 0x23, 0x4a, 0xc4, 0xfd, 0xac, 0x6b,
-	0x62, 0xb7, 0xa9, 0x60, 0x5b, 0xe5, 0x71, 0x46, 0x35, 0x76, 0xca, 0xc0, 0xbd, 0x21, 0x28, 0x7a,
-	0x6a, 0x8a, 0xb2, 0xa5, 0x53, 0x51, 0x6a, 0x74, 0x20, 0xe5, 0x18, 0x3a, 0x72, 0x94, 0x32, 0x79,
-	0x66, 0x8a, 0x89, 0xeb, 0xec, 0x57, 0xd6, 0xb9, 0xf7, 0x47, 0xd0, 0x12, 0xa3, 0x95, 0xf5, 0x73,
-	0xb9, 0xe7, 0xcb, 0x38, 0x5b, 0x3b, 0x32, 0xb4, 0x18, 0xba, 0xe8, 0x78, 0x04, 0x2d, 0x31, 0x48,
-	0x99, 0x84, 0x54, 0x46, 0xae, 0xf2, 0x2d, 0xa6, 0x2c, 0xfa, 0x3e, 0x81, 0xbd, 0x8d, 0x71, 0xb2,
-	0xc9, 0x7f, 0xbf, 0x71, 0xe6, 0xb8, 0x37, 0x4e, 0xfe, 0x34, 0x70, 0xde, 0xcb, 0xaf, 0x38, 0xac,
-	0xed, 0x04, 0x76, 0xd5, 0x82, 0xc9, 0x5d, 0x58, 0x7e, 0x37, 0x3a, 0xc3, 0xaa, 0xe1, 0xdd, 0x29,
-	0xc0, 0x1f, 0xa2, 0x00, 0xf2, 0x0b, 0x94, 0x27, 0x78, 0xa4, 0x77, 0xd5, 0x22, 0x2f, 0x40, 0xf1,
-	0x71, 0xea, 0xe8, 0xd4, 0x77, 0x41, 0xfc, 0xbc, 0x43, 0xff, 0x20, 0x3c, 0xfa, 0x3b, 0x00, 0x00,
-	0xff, 0xff, 0xd7, 0x48, 0xcc, 0xfe, 0x98, 0x0c, 0x00, 0x00,
+	// 1032 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x97, 0xdf, 0x6f, 0xdc, 0x44,
+	0x10, 0xc7, 0xe3, 0xf8, 0x7e, 0xc4, 0xe3, 0xbb, 0x4b, 0x6e, 0x13, 0x2a, 0x63, 0x12, 0x7a, 0xf1,
+	0x0b, 0xd7, 0xfc, 0xb8, 0x93, 0x52, 0x54, 0xa1, 0x42, 0x2b, 0x9a, 0x34, 0x0d, 0xa8, 0x2a, 0x8a,
+	0x52, 0x78, 0x8e, 0x2e, 0xce, 0xe6, 0x6a, 0xe1, 0xf3, 0xba, 0x6b, 0x1b, 0x7a, 0x48, 0xfc, 0x7b,
+	0xbc, 0xf1, 0x07, 0xf0, 0xaf, 0xf0, 0x00, 0x68, 0x67, 0xd7, 0x67, 0xef, 0xc5, 0x86, 0x08, 0xf5,
+	0xa9, 0x4f, 0xed, 0x8e, 0x77, 0x3e, 0x37, 0xdf, 0x99, 0x9d, 0x9d, 0x0d, 0x3c, 0x9e, 0x06, 0xe9,
+	0x9b, 0xec, 0x6a, 0xe4, 0xb3, 0xd9, 0x38, 0x88, 0x52, 0x1a, 0x26, 0xd7, 0xc1, 0xe1, 0xbb, 0x71,
+	0x12, 0x4d, 0xe2, 0xb1, 0xcf, 0xa2, 0x94, 0xb3, 0x70, 0x1c, 0x87, 0xd9, 0x34, 0x88, 0xc6, 0x3c,
+	0xf6, 0xd5, 0x7f, 0x47, 0x31, 0x67, 0x29, 0x23, 0x26, 0x8f, 0x7d, 0xf7, 0xe1, 0xbf, 0x00, 0xa6,
+	0xc2, 0xc5, 0x67, 0xb3, 0x19, 0x8b, 0xd4, 0x3f, 0xd2, 0xd3, 0xfb, 0x01, 0xe0, 0x9c, 0x33, 0x9f,
+	0x26, 0xc9, 0x33, 0x3e, 0x25, 0x9b, 0x60, 0x9f, 0xb0, 0x28, 0xa5, 0x51, 0xfa, 0xfd, 0x3c, 0xa6,
+	0x8e, 0x31, 0x30, 0x86, 0x16, 0x59, 0x87, 0xb6, 0x32, 0x3a, 0xab, 0x03, 0x63, 0xd8, 0x21, 0xbb,
+	0xd0, 0x3a, 0x61, 0xd1, 0x4d, 0x30, 0x75, 0xcc, 0x81, 0x31, 0xb4, 0x8f, 0xfa, 0x23, 0x85, 0x94,
+	0xd6, 0x57, 0x93, 0xd8, 0x3b, 0x81, 0x8e, 0xc2, 0x5e, 0xd0, 0x38, 0x9c, 0xdf, 0x11, 0xdc, 0x85,
+	0xe6, 0x29, 0xe7, 0x8c, 0x23, 0xd7, 0xc2, 0xd8, 0xb2, 0xab, 0x30, 0x48, 0xde, 0xbc, 0xd7, 0xd8,
+	0xfe, 0x36, 0xa0, 0x71, 0x91, 0x85, 0x94, 0xf4, 0xc1, 0xe2, 0x59, 0x48, 0x2f, 0xd3, 0x82, 0x67,
+	0x83, 0xf9, 0x23, 0x9d, 0x23, 0xcb, 0x22, 0x1b, 0xb0, 0xc6, 0xe9, 0xdb, 0x2c, 0xe0, 0xf4, 0x1a,
+	0x69, 0x6b, 0x22, 0x06, 0x4a, 0x13, 0x9f, 0x07, 0x71, 0x1a, 0xb0, 0xc8, 0x69, 0xe0, 0xb6, 0x2d,
+	0xe8, 0x5c, 0x31, 0x16, 0x5e, 0x5e, 0xd3, 0x9b, 0x49, 0x16, 0xa6, 0x4e, 0x13, 0xb7, 0x7e, 0x04,
+	0xdd, 0x9b, 0x90, 0x4d, 0xd2, 0x85, 0xb9, 0x35, 0x30, 0x86, 0x46, 0x61, 0x9e, 0x05, 0x51, 0x30,
+	0xcb, 0x66, 0x4e, 0x7b, 0xc9, 0x3c, 0x79, 0x87, 0xe6, 0x35, 0x34, 0x6f, 0x82, 0x1d, 0x44, 0x05,
+	0xc2, 0x1a, 0x18, 0x43, 0x33, 0x37, 0xe6, 0x00, 0xd0, 0x8c, 0xca, 0xdd, 0x46, 0xe3, 0x3d, 0xe8,
+	0x25, 0x29, 0x0f, 0xa2, 0xe9, 0x82, 0xd0, 0xc1, 0xc4, 0x3a, 0x60, 0xbd, 0xa6, 0xe9, 0x4b, 0x3a,
+	0x17, 0x79, 0x55, 0x92, 0x85, 0xfe, 0x8e, 0xb7, 0x0d, 0xb6, 0xfc, 0x22, 0xcb, 0xd6, 0x85, 0x26,
+	0xc5, 0x82, 0x60, 0x76, 0x3c, 0x17, 0xac, 0xf3, 0x20, 0x9a, 0x56, 0x7e, 0xdb, 0x01, 0xfb, 0x65,
+	0x10, 0x86, 0x17, 0xf4, 0x6d, 0x46, 0x93, 0x94, 0xf4, 0xa0, 0x75, 0x41, 0x27, 0x09, 0x8b, 0x0a,
+	0x57, 0xf9, 0xb9, 0xc2, 0xf5, 0xf7, 0x06, 0x6c, 0x9d, 0xd1, 0x54, 0x56, 0xe8, 0x9c, 0x85, 0x81,
+	0x5f, 0xf9, 0xf3, 0xe4, 0x29, 0xd8, 0x98, 0xe8, 0x18, 0xb7, 0x38, 0xab, 0x03, 0x73, 0x68, 0x1f,
+	0x3d, 0x18, 0xf1, 0xd8, 0x1f, 0x55, 0xb9, 0x8f, 0x8e, 0x19, 0x0b, 0xe5, 0xfa, 0x34, 0x4a, 0xf9,
+	0x9c, 0x7c, 0x0d, 0x1d, 0x99, 0x64, 0x05, 0x30, 0x11, 0xb0, 0x57, 0x0f, 0x78, 0x21, 0x76, 0x97,
+	0x09, 0xcf, 0xa1, 0x27, 0x3a, 0x6b, 0x4a, 0x79, 0xce, 0x68, 0x20, 0xe3, 0xa0, 0x9e, 0xf1, 0xad,
+	0xdc, 0x5f, 0xa6, 0x1c, 0x43, 0x57, 0x95, 0x45, 0x41, 0x9a, 0x08, 0xd9, 0xaf, 0x87, 0xbc, 0xc6,
+	0xed, 0x25, 0x86, 0x7b, 0x0c, 0xeb, 0xcb, 0xf2, 0x4a, 0x85, 0xb4, 0xc8, 0xa7, 0xd0, 0xfc, 0x69,
+	0x12, 0x66, 0x14, 0x8f, 0xb2, 0x7d, 0xb4, 0x8e, 0xec, 0xc2, 0xe3, 0xf1, 0xea, 0x17, 0x86, 0xfb,
+	0x1c, 0x36, 0x6e, 0x29, 0xd4, 0x20, 0xf7, 0x75, 0xc8, 0x06, 0x42, 0x4a, 0x2e, 0x48, 0xf9, 0x06,
+	0x48, 0x85, 0x46, 0x8d, 0xb3, 0xab, 0x73, 0x08, 0x72, 0x34, 0x27, 0x24, 0xbd, 0x80, 0xfe, 0x2d,
+	0xa1, 0x3a, 0x68, 0xa0, 0x83, 0xfa, 0x08, 0x2a, 0xfb, 0x08, 0x8e, 0x77, 0x08, 0x6b, 0x42, 0x29,
+	0xf6, 0x78, 0xb9, 0x87, 0x0d, 0x6c, 0xcc, 0x75, 0x68, 0xe7, 0xdd, 0x20, 0x28, 0x6b, 0x5e, 0x0a,
+	0x50, 0x24, 0x86, 0x3c, 0x80, 0xa6, 0xb8, 0x14, 0x12, 0xc7, 0xc0, 0xa2, 0xb8, 0x4b, 0x89, 0x1b,
+	0x09, 0x6a, 0x22, 0x6b, 0xf0, 0x25, 0x40, 0xb1, 0xd2, 0x03, 0xdd, 0xd6, 0x03, 0xed, 0x2e, 0x28,
+	0xc2, 0x01, 0x83, 0x3c, 0x07, 0x0b, 0x33, 0x59, 0x1f, 0x65, 0xde, 0xe0, 0xab, 0x78, 0x15, 0x08,
+	0x83, 0x6a, 0x6e, 0x33, 0x37, 0xe4, 0x3a, 0xc4, 0x3d, 0x64, 0x78, 0x3f, 0x83, 0x5d, 0xaa, 0x0d,
+	0xd9, 0xd3, 0x85, 0x7c, 0xb2, 0x5c, 0xbc, 0xb2, 0x92, 0xaf, 0xea, 0x95, 0xec, 0xe8, 0x4a, 0x7a,
+	0x05, 0x66, 0x21, 0xe5, 0x02, 0x6c, 0x55, 0xcc, 0xbb, 0x89, 0x31, 0x97, 0xc5, 0x98, 0xcb, 0x62,
+	0x4c, 0xef, 0x57, 0xe8, 0x6a, 0x07, 0x84, 0x1c, 0xe8, 0x72, 0x76, 0x6e, 0x9f, 0xa1, 0xb2, 0xa0,
+	0xa7, 0xf5, 0x82, 0x2a, 0x0f, 0x75, 0x29, 0x7e, 0x94, 0x34, 0x06, 0x90, 0xc7, 0xea, 0x6e, 0x87,
+	0xc8, 0xf2, 0x7e, 0x81, 0x4e, 0xf9, 0x1c, 0x92, 0x7d, 0x3d, 0xdc, 0xed, 0x5b, 0x27, 0xb5, 0x1c,
+	0xed, 0x93, 0xfa, 0x68, 0x2b, 0xfb, 0xb8, 0x08, 0x0d, 0x83, 0xfd, 0x1c, 0xfa, 0x27, 0x2c, 0x0c,
+	0xa9, 0x9f, 0xbe, 0xa2, 0x29, 0x0f, 0x7c, 0x1c, 0xe5, 0xf7, 0xa1, 0x3d, 0x93, 0x2b, 0x15, 0x42,
+	0x2f, 0x9f, 0x84, 0x72, 0x93, 0xf7, 0x9b, 0x01, 0x9b, 0xba, 0x9b, 0xbc, 0x74, 0xff, 0xcb, 0xb1,
+	0xb8, 0x95, 0xe5, 0x94, 0x3c, 0x83, 0x8d, 0x68, 0x32, 0xa3, 0x49, 0x3c, 0xf1, 0xe9, 0x25, 0x7e,
+	0x48, 0xd4, 0xcd, 0x7a, 0x88, 0xc1, 0x56, 0xfc, 0xc6, 0xe8, 0xbb, 0xdc, 0x01, 0xc7, 0xbd, 0xca,
+	0xc2, 0x23, 0xd8, 0xaa, 0xb2, 0xeb, 0xf9, 0xe8, 0x96, 0xf3, 0x61, 0xa1, 0xfc, 0x47, 0xd0, 0x3f,
+	0xa3, 0x8a, 0x2f, 0x9e, 0x06, 0x28, 0x7f, 0x17, 0x5a, 0xbe, 0x7c, 0x07, 0x18, 0x75, 0xef, 0x80,
+	0x53, 0xd8, 0xd4, 0xfd, 0xfe, 0x97, 0xfe, 0xa3, 0x3f, 0x56, 0xc1, 0x52, 0x1a, 0x19, 0x17, 0x13,
+	0x42, 0x17, 0x4c, 0xee, 0x55, 0x64, 0xe1, 0x19, 0x9f, 0xba, 0x4e, 0x5d, 0x76, 0xbc, 0x15, 0x41,
+	0xd1, 0x43, 0x53, 0x94, 0x5b, 0x3a, 0x5d, 0xa7, 0xc2, 0x9e, 0x53, 0x0e, 0xa0, 0x25, 0x87, 0x39,
+	0x91, 0x5d, 0xbb, 0x98, 0xf9, 0xee, 0x46, 0x69, 0x9d, 0xef, 0xfe, 0x0c, 0x1a, 0x62, 0xb8, 0x93,
+	0x6e, 0x2e, 0xf7, 0x74, 0x16, 0xa7, 0x73, 0x57, 0xba, 0x2e, 0xc6, 0xbe, 0xb7, 0x42, 0xf6, 0xa0,
+	0x21, 0x46, 0x39, 0x91, 0x90, 0xd2, 0xd0, 0x77, 0x7b, 0x25, 0x8b, 0xdc, 0xfb, 0x04, 0xd6, 0x97,
+	0x06, 0xda, 0x32, 0xff, 0xe3, 0xda, 0xa9, 0xe7, 0xad, 0x1c, 0xfd, 0x65, 0x80, 0xa5, 0xde, 0x91,
+	0x8c, 0x93, 0x31, 0xb4, 0xd5, 0x82, 0xc8, 0x3e, 0x28, 0x5e, 0xae, 0x6e, 0xbf, 0x6c, 0xf8, 0x70,
+	0x12, 0xf0, 0xa7, 0x48, 0x80, 0x7c, 0x03, 0x53, 0x4e, 0xf6, 0xa1, 0xad, 0x16, 0x79, 0x02, 0x16,
+	0xcf, 0x63, 0x57, 0xa7, 0x7e, 0x08, 0xe2, 0xaf, 0x5a, 0xf8, 0x27, 0xca, 0xc3, 0x7f, 0x06, 0x00,
+	0xe1, 0x3b, 0x82, 0x9e, 0x1a, 0x0d, 0x00, 0x00,
 }
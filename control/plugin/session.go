@@ -27,11 +27,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"runtime"
 	"time"
 
+	"github.com/Sirupsen/logrus"
+
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	"github.com/intelsdi-x/snap/control/plugin/encoding"
 	"github.com/intelsdi-x/snap/control/plugin/encrypter"
@@ -115,6 +119,36 @@ func (s *SessionState) GetConfigPolicy(args []byte, reply *[]byte) error {
 	return nil
 }
 
+type ValidateConfigArgs struct {
+	Config ConfigType
+}
+
+type ValidateConfigReply struct {
+	Errors []string
+}
+
+// ValidateConfig validates the config with the plugin if it implements
+// ConfigValidator
+func (s *SessionState) ValidateConfig(args []byte, reply *[]byte) error {
+	defer catchPluginPanic(s.Logger())
+
+	s.logger.Println("ValidateConfig called")
+
+	a := &ValidateConfigArgs{Config: ConfigType{ConfigDataNode: cdata.NewNode()}}
+	if err := s.Decode(args, a); err != nil {
+		return err
+	}
+	r := ValidateConfigReply{}
+	if v, ok := s.plugin.(ConfigValidator); ok {
+		for _, e := range v.ValidateConfig(a.Config) {
+			r.Errors = append(r.Errors, e.Error())
+		}
+	}
+	var err error
+	*reply, err = s.Encode(r)
+	return err
+}
+
 // Ping returns nothing in normal operation
 func (s *SessionState) Ping(arg []byte, reply *[]byte) error {
 	// For now we return nil. We can return an error if we are shutting
@@ -246,6 +280,18 @@ func NewSessionState(pluginArgsMsg string, plugin Plugin, meta *PluginMeta) (*Se
 		pluginArg.listenPort = "0"
 	}
 
+	// Apply the log level requested for this plugin to logrus and to the
+	// standard log package plugins log through
+	level := logrus.InfoLevel
+	if pluginArg.LogLevel != "" {
+		level, err = logrus.ParseLevel(pluginArg.LogLevel)
+		if err != nil {
+			return nil, err, 2
+		}
+		logrus.SetLevel(level)
+		log.SetOutput(logOutput(os.Stderr, level))
+	}
+
 	// If no PingTimeoutDuration was provided we need to set it
 	if pluginArg.PingTimeoutDuration == 0 {
 		pluginArg.PingTimeoutDuration = PingTimeoutDurationDefault
@@ -271,7 +317,7 @@ func NewSessionState(pluginArgsMsg string, plugin Plugin, meta *PluginMeta) (*Se
 	if err != nil {
 		return nil, errors.New(fmt.Sprintf("error opening log file: %v", err)), 3
 	}
-	logger := log.New(lf, ">>>", log.Ldate|log.Ltime)
+	logger := log.New(logOutput(lf, level), ">>>", log.Ldate|log.Ltime)
 
 	var enc encoding.Encoder
 	switch meta.RPCType {
@@ -306,6 +352,16 @@ func NewSessionState(pluginArgsMsg string, plugin Plugin, meta *PluginMeta) (*Se
 	return ss, nil, 0
 }
 
+// logOutput returns the writer for lines logged through the standard log
+// package at the log level.  Those lines have no level of their own and are
+// logged at the info level, so they are discarded at less verbose levels.
+func logOutput(w io.Writer, level logrus.Level) io.Writer {
+	if level < logrus.InfoLevel {
+		return ioutil.Discard
+	}
+	return w
+}
+
 func init() {
 	gob.RegisterName("conf_value_string", *(&ctypes.ConfigValueStr{}))
 	gob.RegisterName("conf_value_int", *(&ctypes.ConfigValueInt{}))
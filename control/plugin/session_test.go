@@ -24,13 +24,18 @@ package plugin
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	"github.com/intelsdi-x/snap/control/plugin/encoding"
+	"github.com/intelsdi-x/snap/core/cdata"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -182,6 +187,32 @@ func TestSessionState(t *testing.T) {
 			So(err, ShouldBeNil)
 			So(sess, ShouldNotBeNil)
 		})
+		Convey("InitSessionState with a log level", func() {
+			dir, err := ioutil.TempDir("", "snap-plugin-log")
+			So(err, ShouldBeNil)
+			defer os.RemoveAll(dir)
+			defer log.SetOutput(os.Stderr)
+			defer logrus.SetLevel(logrus.GetLevel())
+			m := PluginMeta{
+				RPCType:  JSONRPC,
+				Type:     CollectorPluginType,
+				Unsecure: true,
+			}
+			logged := func(level string) string {
+				path := filepath.Join(dir, level+".log")
+				args := fmt.Sprintf(`{"PluginLogPath": %q, "LogLevel": %q}`, path, level)
+				sess, err, rc := NewSessionState(args, &MockPlugin{Meta: m}, &m)
+				So(rc, ShouldEqual, 0)
+				So(err, ShouldBeNil)
+				sess.Logger().Println("collecting")
+				b, err := ioutil.ReadFile(path)
+				So(err, ShouldBeNil)
+				return string(b)
+			}
+			So(logged("debug"), ShouldContainSubstring, "collecting")
+			So(logged("warn"), ShouldBeEmpty)
+			So(logrus.GetLevel(), ShouldEqual, logrus.WarnLevel)
+		})
 		Convey("heartbeatWatch timeout expired", func() {
 			PingTimeoutLimit = 1
 			ss.LastPing = now.Truncate(time.Minute)
@@ -250,3 +281,43 @@ func TestGetConfigPolicy(t *testing.T) {
 		So(err.Error(), ShouldResemble, "GetConfigPolicy call error : Error in get config policy")
 	})
 }
+
+type mockValidatingPlugin struct {
+	mockPlugin
+}
+
+func (p *mockValidatingPlugin) ValidateConfig(cfg ConfigType) []error {
+	if _, ok := cfg.Table()["endpoint"]; !ok {
+		return []error{errors.New("endpoint is unreachable")}
+	}
+	return nil
+}
+
+func TestValidateConfig(t *testing.T) {
+	Convey("SessionState.ValidateConfig", t, func() {
+		ss := &SessionState{
+			Arg:     &Arg{PingTimeoutDuration: 500 * time.Millisecond},
+			Encoder: encoding.NewGobEncoder(),
+		}
+		ss.logger = log.New(os.Stdout, ">>>", log.Ldate|log.Ltime)
+		args, err := ss.Encode(ValidateConfigArgs{Config: ConfigType{ConfigDataNode: cdata.NewNode()}})
+		So(err, ShouldBeNil)
+
+		Convey("returns the errors from a plugin which validates its config", func() {
+			ss.plugin = &mockValidatingPlugin{}
+			var reply []byte
+			So(ss.ValidateConfig(args, &reply), ShouldBeNil)
+			var r ValidateConfigReply
+			So(ss.Decode(reply, &r), ShouldBeNil)
+			So(r.Errors, ShouldResemble, []string{"endpoint is unreachable"})
+		})
+		Convey("returns no errors from a plugin which does not validate its config", func() {
+			ss.plugin = &mockPlugin{}
+			var reply []byte
+			So(ss.ValidateConfig(args, &reply), ShouldBeNil)
+			var r ValidateConfigReply
+			So(ss.Decode(reply, &r), ShouldBeNil)
+			So(r.Errors, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,93 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+# Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package plugin
+
+import (
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newTransportSession(t TransportType) *SessionState {
+	return &SessionState{
+		Arg:    &Arg{Transport: t},
+		logger: log.New(ioutil.Discard, "", 0),
+	}
+}
+
+func TestSplitListenAddress(t *testing.T) {
+	Convey("A Unix domain socket address is dialed over unix", t, func() {
+		network, address := SplitListenAddress("unix:///tmp/snap-plugin-1.sock")
+		So(network, ShouldEqual, "unix")
+		So(address, ShouldEqual, "/tmp/snap-plugin-1.sock")
+	})
+	Convey("Any other address is dialed over tcp", t, func() {
+		network, address := SplitListenAddress("127.0.0.1:8181")
+		So(network, ShouldEqual, "tcp")
+		So(address, ShouldEqual, "127.0.0.1:8181")
+	})
+}
+
+func TestListenTransport(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix domain sockets are not supported")
+	}
+	Convey("A plugin listens on a Unix domain socket when requested", t, func() {
+		s := newTransportSession(TransportUnix)
+		l, err := listen(s, GRPC)
+		So(err, ShouldBeNil)
+		defer l.Close()
+		So(strings.HasPrefix(s.ListenAddress(), unixAddressPrefix), ShouldBeTrue)
+		network, path := SplitListenAddress(s.ListenAddress())
+		conn, err := net.Dial(network, path)
+		So(err, ShouldBeNil)
+		conn.Close()
+
+		Convey("and unlinks the socket file once it stops", func() {
+			unlinkSocket(s)
+			_, err := os.Stat(path)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+	})
+	Convey("A JSON-RPC plugin listens on TCP", t, func() {
+		s := newTransportSession(TransportUnix)
+		l, err := listen(s, JSONRPC)
+		So(err, ShouldBeNil)
+		defer l.Close()
+		network, _ := SplitListenAddress(s.ListenAddress())
+		So(network, ShouldEqual, "tcp")
+		// unlinking leaves nothing to remove for TCP listeners
+		unlinkSocket(s)
+	})
+	Convey("A plugin listens on TCP by default", t, func() {
+		s := newTransportSession(TransportTCP)
+		l, err := listen(s, GRPC)
+		So(err, ShouldBeNil)
+		defer l.Close()
+		So(s.ListenAddress(), ShouldEqual, l.Addr().String())
+		So(strings.HasPrefix(s.ListenAddress(), "127.0.0.1:"), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,75 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sync"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// pluginCaching holds whether the metrics of plugins are cached where it was
+// set with SetPluginCaching rather than declared in the plugin's metadata.
+type pluginCaching struct {
+	sync.RWMutex
+	plugins map[string]bool
+}
+
+func newPluginCaching() *pluginCaching {
+	return &pluginCaching{
+		plugins: make(map[string]bool),
+	}
+}
+
+// SetPluginCaching sets whether the metrics collected from the plugin
+// identified by its {type}:{name}:{version} key are cached, overriding the
+// DisableCaching metadata of the plugin.  Caching is enabled for plugins by
+// default, subject to the CacheExpiration option.
+func (p *pluginControl) SetPluginCaching(key string, enabled bool) error {
+	if _, _, _, err := core.ParsePluginKey(key); err != nil {
+		return serror.New(err, map[string]interface{}{"plugin-key": key})
+	}
+	c := p.pluginRunner.AvailablePlugins().caching
+	c.Lock()
+	defer c.Unlock()
+	c.plugins[key] = enabled
+	return nil
+}
+
+// enabled returns whether the metrics collected from the pool of the plugin
+// identified by the key are cached.
+func (c *pluginCaching) enabled(key string, pool strategy.Pool) bool {
+	c.RLock()
+	enabled, ok := c.plugins[key]
+	c.RUnlock()
+	if ok {
+		return enabled
+	}
+	pool.RLock()
+	defer pool.RUnlock()
+	for _, p := range pool.Plugins() {
+		if ap, ok := p.(*availablePlugin); ok && ap.meta.DisableCaching {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// collectTwice collects the metric from its collector twice and returns the
+// number of times the collector was called.
+func collectTwice(c *pluginControl, cli *fakeCollectorClient, mt core.Metric) int {
+	aps := c.pluginRunner.AvailablePlugins()
+	for i := 0; i < 2; i++ {
+		mts, err := aps.collectMetrics("collector:cached:1", []core.Metric{mt}, "task")
+		So(err, ShouldBeNil)
+		So(len(mts), ShouldEqual, 1)
+	}
+	return cli.calls
+}
+
+func TestPluginCaching(t *testing.T) {
+	Convey("Collected metrics are cached by default", t, func() {
+		c := New(GetDefaultConfig())
+		cli := &fakeCollectorClient{}
+		mt := addFakeCollector(c, "cached", cli)
+		So(collectTwice(c, cli, mt), ShouldEqual, 1)
+	})
+	Convey("Metrics of a plugin whose metadata disables caching are not cached", t, func() {
+		c := New(GetDefaultConfig())
+		cli := &fakeCollectorClient{}
+		mt := addFakeCollector(c, "cached", cli)
+		pool, err := c.pluginRunner.AvailablePlugins().getPool("collector:cached:1")
+		So(err, ShouldBeNil)
+		for _, ap := range pool.Plugins() {
+			ap.(*availablePlugin).meta.DisableCaching = true
+		}
+		So(collectTwice(c, cli, mt), ShouldEqual, 2)
+	})
+	Convey("Caching can be disabled for a plugin", t, func() {
+		c := New(GetDefaultConfig())
+		cli := &fakeCollectorClient{}
+		mt := addFakeCollector(c, "cached", cli)
+		So(c.SetPluginCaching("collector:cached:1", false), ShouldBeNil)
+		So(collectTwice(c, cli, mt), ShouldEqual, 2)
+	})
+	Convey("Setting caching for a bad key fails", t, func() {
+		c := New(GetDefaultConfig())
+		So(c.SetPluginCaching("cached", false), ShouldNotBeNil)
+	})
+}
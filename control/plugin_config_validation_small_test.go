@@ -0,0 +1,92 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// validatingClient is a collector which rejects config with the problems.
+type validatingClient struct {
+	fakeCollectorClient
+	problems []string
+}
+
+func (c *validatingClient) ValidateConfig(plugin.ConfigType) ([]string, error) {
+	return c.problems, nil
+}
+
+// requestedMetric is a core.RequestedMetric with a config.
+type requestedMetric struct {
+	core.Metric
+	config *cdata.ConfigDataNode
+}
+
+func (r requestedMetric) Config() *cdata.ConfigDataNode { return r.config }
+
+// addValidatingCollector catalogs a metric of a collector with the name and
+// runs an instance of it using the client.
+func addValidatingCollector(c *pluginControl, name string, cli *validatingClient) core.Metric {
+	lp := &loadedPlugin{
+		Type:         plugin.CollectorPluginType,
+		Meta:         plugin.PluginMeta{Name: name, Version: 1},
+		ConfigPolicy: cpolicy.New(),
+	}
+	mt := plugin.MetricType{Namespace_: core.NewNamespace("intel", name, "foo"), Version_: 1}
+	So(c.metricCatalog.AddLoadedMetricType(lp, mt), ShouldBeNil)
+	addFakePlugin(c, plugin.CollectorPluginType, name, cli)
+	return mt
+}
+
+func TestMetricConfigValidatedByPlugin(t *testing.T) {
+	Convey("A metric subscription is rejected when the plugin rejects its config", t, func() {
+		c := New(GetDefaultConfig())
+		mt := addValidatingCollector(c, "picky", &validatingClient{problems: []string{"bad host"}})
+		errs := c.ValidateDeps([]core.Metric{requestedMetric{mt, cdata.NewNode()}}, nil)
+		So(len(errs), ShouldEqual, 1)
+		So(errs[0].Error(), ShouldContainSubstring, "bad host")
+	})
+	Convey("A metric subscription is valid when the plugin accepts its config", t, func() {
+		c := New(GetDefaultConfig())
+		mt := addValidatingCollector(c, "easy", &validatingClient{})
+		errs := c.ValidateDeps([]core.Metric{requestedMetric{mt, cdata.NewNode()}}, nil)
+		So(errs, ShouldBeEmpty)
+	})
+	Convey("A metric subscription is valid when the plugin has no running instances", t, func() {
+		c := New(GetDefaultConfig())
+		lp := &loadedPlugin{
+			Type:         plugin.CollectorPluginType,
+			Meta:         plugin.PluginMeta{Name: "idle", Version: 1},
+			ConfigPolicy: cpolicy.New(),
+		}
+		mt := plugin.MetricType{Namespace_: core.NewNamespace("intel", "idle", "foo"), Version_: 1}
+		So(c.metricCatalog.AddLoadedMetricType(lp, mt), ShouldBeNil)
+		errs := c.ValidateDeps([]core.Metric{requestedMetric{mt, cdata.NewNode()}}, nil)
+		So(errs, ShouldBeEmpty)
+	})
+}
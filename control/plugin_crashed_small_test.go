@@ -0,0 +1,95 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/intelsdi-x/gomit"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core/control_event"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// recordingEmitter records the events emitted.
+type recordingEmitter struct {
+	events []gomit.EventBody
+}
+
+func (r *recordingEmitter) Emit(e gomit.EventBody) (int, error) {
+	r.events = append(r.events, e)
+	return 0, nil
+}
+
+func (r *recordingEmitter) crashes() []*control_event.PluginCrashedEvent {
+	var crashes []*control_event.PluginCrashedEvent
+	for _, e := range r.events {
+		if c, ok := e.(*control_event.PluginCrashedEvent); ok {
+			crashes = append(crashes, c)
+		}
+	}
+	return crashes
+}
+
+// unreachableClient fails every ping with err.
+type unreachableClient struct {
+	failingClient
+}
+
+func (c *unreachableClient) Ping() error { return c.err }
+
+func TestPluginCrashedEvent(t *testing.T) {
+	Convey("Given a plugin whose health checks fail", t, func() {
+		emitter := &recordingEmitter{}
+		ap := &availablePlugin{
+			name:       "mock",
+			version:    2,
+			key:        "collector:mock:2",
+			pluginType: plugin.CollectorPluginType,
+			client:     &unreachableClient{failingClient{err: errors.New("connection is shut down")}},
+			healthChan: make(chan error, 1),
+			emitter:    emitter,
+		}
+
+		Convey("no crash is emitted before the failure limit", func() {
+			for i := 1; i < DefaultHealthCheckFailureLimit; i++ {
+				ap.CheckHealth()
+			}
+			So(emitter.crashes(), ShouldBeEmpty)
+		})
+		Convey("a crash is emitted once when the plugin is found dead", func() {
+			for i := 0; i < DefaultHealthCheckFailureLimit+2; i++ {
+				ap.CheckHealth()
+			}
+			crashes := emitter.crashes()
+			So(len(crashes), ShouldEqual, 1)
+			So(crashes[0].Name, ShouldEqual, "mock")
+			So(crashes[0].Version, ShouldEqual, 2)
+			So(crashes[0].Type, ShouldEqual, int(plugin.CollectorPluginType))
+			So(crashes[0].Key, ShouldEqual, "collector:mock:2")
+			So(crashes[0].Error, ShouldEqual, "connection is shut down")
+		})
+	})
+}
@@ -44,6 +44,7 @@ import (
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/cdata"
 	"github.com/intelsdi-x/snap/core/serror"
+	"golang.org/x/net/context"
 )
 
 const (
@@ -64,6 +65,8 @@ var (
 	ErrPluginAlreadyLoaded = errors.New("plugin is already loaded")
 	// ErrPluginNotInLoadedState - error message when a plugin must ne in a loaded state
 	ErrPluginNotInLoadedState = errors.New("Plugin must be in a LoadedState")
+	// ErrPluginDependencyNotLoaded - error message when a plugin this plugin depends on is not loaded
+	ErrPluginDependencyNotLoaded = errors.New("plugin dependency is not loaded")
 
 	pmLogger = log.WithField("_module", "control-plugin-mgr")
 )
@@ -166,6 +169,9 @@ type pluginDetails struct {
 	Path         string
 	Signed       bool
 	Signature    []byte
+	// LogLevel is the log level passed to instances of the plugin when
+	// they are started
+	LogLevel string
 }
 
 type loadedPlugin struct {
@@ -191,7 +197,7 @@ func (lp *loadedPlugin) PluginPath() string {
 
 // Key returns plugin type, name and version
 func (lp *loadedPlugin) Key() string {
-	return fmt.Sprintf("%s:%s:%d", lp.TypeName(), lp.Name(), lp.Version())
+	return core.PluginKey(core.PluginType(lp.Type), lp.Name(), lp.Version())
 }
 
 // Version returns plugin version
@@ -235,6 +241,14 @@ type pluginManager struct {
 	loadedPlugins *loadedPlugins
 	logPath       string
 	pluginConfig  *pluginConfig
+	transport     plugin.TransportType
+	// pidDir is the directory the pids of plugin processes are recorded in
+	pidDir string
+	// clientTimeouts are the timeouts of the clients calling plugins
+	clientTimeouts client.Timeouts
+	// output captures the output of plugin processes, which is written to
+	// the plugin log files when nil
+	output *pluginOutput
 }
 
 func newPluginManager(opts ...pluginManagerOpt) *pluginManager {
@@ -243,9 +257,11 @@ func newPluginManager(opts ...pluginManagerOpt) *pluginManager {
 		logPath = `c:\temp`
 	}
 	p := &pluginManager{
-		loadedPlugins: newLoadedPlugins(),
-		logPath:       logPath,
-		pluginConfig:  newPluginConfig(),
+		loadedPlugins:  newLoadedPlugins(),
+		logPath:        logPath,
+		pluginConfig:   newPluginConfig(),
+		transport:      plugin.TransportUnix,
+		clientTimeouts: client.Timeouts{Connect: DefaultClientTimeout},
 	}
 
 	for _, opt := range opts {
@@ -269,6 +285,51 @@ func (p *pluginManager) SetPluginConfig(cf *pluginConfig) {
 	p.pluginConfig = cf
 }
 
+// SetPluginTransport sets the transport plugins are asked to listen on
+func (p *pluginManager) SetPluginTransport(t plugin.TransportType) {
+	p.transport = t
+}
+
+// SetPluginPidDir sets the directory the pids of plugin processes started
+// after this call are recorded in.  An empty dir records no pids.
+func (p *pluginManager) SetPluginPidDir(dir string) {
+	p.pidDir = dir
+}
+
+// SetPluginClientTimeouts sets the timeouts of the clients calling plugins
+// loaded or inspected after this call.
+func (p *pluginManager) SetPluginClientTimeouts(t client.Timeouts) {
+	p.clientTimeouts = t
+}
+
+// SetPluginOutput sets where the output of plugin processes started after
+// this call is captured to.  A nil output writes it to the plugin log files.
+func (p *pluginManager) SetPluginOutput(o *pluginOutput) {
+	p.output = o
+}
+
+// PluginOutput returns where the output of plugin processes is captured to.
+func (p *pluginManager) PluginOutput() *pluginOutput {
+	return p.output
+}
+
+// newExecutablePlugin returns the executable plugin for the plugin details,
+// recording its pid in the pid directory if one is set and capturing its
+// output if plugin output is captured.
+func (p *pluginManager) newExecutablePlugin(details *pluginDetails) (*plugin.ExecutablePlugin, error) {
+	ePlugin, err := plugin.NewExecutablePlugin(p.GenerateArgs(details), path.Join(details.ExecPath, details.Exec))
+	if err != nil {
+		return nil, err
+	}
+	if p.pidDir != "" {
+		ePlugin.RecordPid(p.pidDir)
+	}
+	if p.output != nil {
+		ePlugin.CaptureOutput(p.output.streams(p.outputKey(details)))
+	}
+	return ePlugin, nil
+}
+
 // SetMetricCatalog sets metric catalog
 func (p *pluginManager) SetMetricCatalog(mc catalogsMetrics) {
 	p.metricCatalog = mc
@@ -277,6 +338,16 @@ func (p *pluginManager) SetMetricCatalog(mc catalogsMetrics) {
 // LoadPlugin is the method for loading a plugin and
 // saving plugin into the LoadedPlugins array
 func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter) (*loadedPlugin, serror.SnapError) {
+	return p.LoadPluginWithContext(context.Background(), details, emitter)
+}
+
+// LoadPluginWithContext loads the plugin, killing the plugin process and
+// returning the context's error if the context is done before the load
+// completes.  Metrics the plugin added to the metric catalog are removed.
+func (p *pluginManager) LoadPluginWithContext(ctx context.Context, details *pluginDetails, emitter gomit.Emitter) (lp *loadedPlugin, serr serror.SnapError) {
+	if err := ctx.Err(); err != nil {
+		return nil, serror.New(err)
+	}
 	lPlugin := new(loadedPlugin)
 	lPlugin.Details = details
 	lPlugin.State = DetectedState
@@ -285,7 +356,95 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 		"_block": "load-plugin",
 		"path":   filepath.Base(lPlugin.Details.Exec),
 	}).Info("plugin load called")
-	ePlugin, err := plugin.NewExecutablePlugin(p.GenerateArgs(lPlugin.Details.Exec), path.Join(lPlugin.Details.ExecPath, lPlugin.Details.Exec))
+
+	defer func() {
+		if serr != nil && ctx.Err() != nil {
+			if p.metricCatalog != nil {
+				p.metricCatalog.RmUnloadedPluginMetrics(lPlugin)
+			}
+			serr = serror.New(ctx.Err())
+		}
+	}()
+
+	info, serr := p.readPluginInfo(ctx, details, emitter)
+	if serr != nil {
+		return nil, serr
+	}
+	resp := info.resp
+
+	if serr := p.checkDependencies(resp); serr != nil {
+		pmLogger.WithFields(log.Fields{
+			"_block": "load-plugin",
+			"error":  serr.Error(),
+		}).WithFields(serr.Fields()).Error("load plugin error while checking plugin dependencies")
+		return nil, serr
+	}
+
+	lPlugin.ConfigPolicy = info.configPolicy
+
+	// Add metric types to metric catalog
+	for _, nmt := range info.metricTypes {
+		if err := p.metricCatalog.AddLoadedMetricType(lPlugin, nmt); err != nil {
+			pmLogger.WithFields(log.Fields{
+				"_block":           "load-plugin",
+				"plugin-name":      resp.Meta.Name,
+				"plugin-version":   resp.Meta.Version,
+				"plugin-type":      resp.Meta.Type.String(),
+				"plugin-path":      filepath.Base(lPlugin.Details.ExecPath),
+				"metric-namespace": nmt.Namespace(),
+				"metric-version":   nmt.Version(),
+				"error":            err.Error(),
+			}).Error("error adding loaded metric type")
+			return nil, serror.New(err)
+		}
+	}
+
+	lPlugin.Meta = resp.Meta
+	lPlugin.Type = resp.Type
+	lPlugin.Token = resp.Token
+	lPlugin.LoadedTime = time.Now()
+	lPlugin.State = LoadedState
+
+	if err := ctx.Err(); err != nil {
+		return nil, serror.New(err)
+	}
+	aErr := p.loadedPlugins.add(lPlugin)
+	if aErr != nil {
+		pmLogger.WithFields(log.Fields{
+			"_block": "load-plugin",
+			"error":  aErr,
+		}).Error("load plugin error while adding loaded plugin to load plugins collection")
+		return nil, setLoadErrorCode(aErr, LoadErrorAlreadyLoaded)
+	}
+
+	return lPlugin, nil
+}
+
+// InspectPlugin starts the plugin just long enough to read its metadata and,
+// for collectors, the metric types it advertises.  Nothing is added to the
+// loaded plugins or the metric catalog.
+func (p *pluginManager) InspectPlugin(details *pluginDetails) (*plugin.PluginMeta, []core.Metric, serror.SnapError) {
+	info, serr := p.readPluginInfo(context.Background(), details, nil)
+	if serr != nil {
+		return nil, nil, serr
+	}
+	meta := info.resp.Meta
+	return &meta, info.metricTypes, nil
+}
+
+// pluginInfo is what a plugin started by readPluginInfo reports about itself.
+type pluginInfo struct {
+	resp         *plugin.Response
+	configPolicy *cpolicy.ConfigPolicy
+	// metricTypes are the metric types advertised by a collector
+	metricTypes []core.Metric
+}
+
+// readPluginInfo starts the plugin and reads its metadata, its config policy
+// and, for collectors, the metric types it advertises before killing it
+// again.  The plugin process is killed early if the context is done first.
+func (p *pluginManager) readPluginInfo(ctx context.Context, details *pluginDetails, emitter gomit.Emitter) (*pluginInfo, serror.SnapError) {
+	ePlugin, err := p.newExecutablePlugin(details)
 
 	if err != nil {
 		pmLogger.WithFields(log.Fields{
@@ -304,6 +463,16 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 		return nil, serror.New(err)
 	}
 
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ePlugin.Kill()
+		case <-done:
+		}
+	}()
+
 	var resp *plugin.Response
 	resp, err = ePlugin.WaitForResponse(time.Second * 3)
 
@@ -312,16 +481,26 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 			"_block": "load-plugin",
 			"error":  err.Error(),
 		}).Error("load plugin error while waiting for response from plugin")
-		return nil, serror.New(err)
+		return nil, newLoadError(LoadErrorHandshakeFailed, err)
+	}
+
+	if serr := validatePluginMeta(resp); serr != nil {
+		pmLogger.WithFields(log.Fields{
+			"_block": "load-plugin",
+			"error":  serr.Error(),
+		}).Error("load plugin error while validating plugin metadata")
+		ePlugin.Kill()
+		return nil, serr
 	}
 
-	ap, err := newAvailablePlugin(resp, emitter, ePlugin)
+	info := &pluginInfo{resp: resp}
+	ap, err := newAvailablePlugin(resp, emitter, ePlugin, p.clientTimeouts)
 	if err != nil {
 		pmLogger.WithFields(log.Fields{
 			"_block": "load-plugin",
 			"error":  err.Error(),
 		}).Error("load plugin error while creating available plugin")
-		return nil, serror.New(err)
+		return nil, newLoadError(availablePluginErrorCode(err), err)
 	}
 
 	if resp.Meta.Unsecure {
@@ -335,10 +514,10 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 			"_block": "load-plugin",
 			"error":  err.Error(),
 		}).Error("load plugin error while pinging the plugin")
-		return nil, serror.New(err)
+		return nil, newLoadError(LoadErrorHandshakeFailed, err)
 	}
 
-	// Get the ConfigPolicy and add it to the loaded plugin
+	// Get the ConfigPolicy
 	c, ok := ap.client.(plugin.Plugin)
 	if !ok {
 		return nil, serror.New(errors.New("missing GetConfigPolicy function"))
@@ -355,15 +534,15 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 		}).Error("error in getting config policy")
 		return nil, serror.New(err)
 	}
-	lPlugin.ConfigPolicy = cp
+	info.configPolicy = cp
 
 	if resp.Type == plugin.CollectorPluginType {
 		cfgNode := p.pluginConfig.getPluginConfigDataNode(core.PluginType(resp.Type), resp.Meta.Name, resp.Meta.Version)
 
-		if lPlugin.ConfigPolicy != nil {
+		if info.configPolicy != nil {
 			// Get plugin config defaults
 			defaults := cdata.NewNode()
-			cpolicies := lPlugin.ConfigPolicy.GetAll()
+			cpolicies := info.configPolicy.GetAll()
 			for _, cpolicy := range cpolicies {
 				_, errs := cpolicy.AddDefaults(defaults.Table())
 				if len(errs.Errors()) > 0 {
@@ -394,7 +573,7 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 				}).Error("error in getting config policy")
 				return nil, serror.New(err)
 			}
-			lPlugin.ConfigPolicy = cp
+			info.configPolicy = cp
 		}
 
 		colClient := ap.client.(client.PluginCollectorClient)
@@ -413,7 +592,6 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 			return nil, serror.New(err)
 		}
 
-		// Add metric types to metric catalog
 		for _, nmt := range metricTypes {
 			// If the version is 0 default it to the plugin version
 			// This honors the plugins explicit version but falls back
@@ -440,7 +618,7 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 					"plugin-name":      resp.Meta.Name,
 					"plugin-version":   resp.Meta.Version,
 					"plugin-type":      resp.Meta.Type.String(),
-					"plugin-path":      filepath.Base(lPlugin.Details.ExecPath),
+					"plugin-path":      filepath.Base(details.ExecPath),
 					"metric-namespace": nmt.Namespace(),
 					"metric-version":   nmt.Version(),
 					"error":            err.Error(),
@@ -451,19 +629,7 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 			//Add standard tags
 			nmt = addStandardAndWorkflowTags(nmt, nil)
 
-			if err := p.metricCatalog.AddLoadedMetricType(lPlugin, nmt); err != nil {
-				pmLogger.WithFields(log.Fields{
-					"_block":           "load-plugin",
-					"plugin-name":      resp.Meta.Name,
-					"plugin-version":   resp.Meta.Version,
-					"plugin-type":      resp.Meta.Type.String(),
-					"plugin-path":      filepath.Base(lPlugin.Details.ExecPath),
-					"metric-namespace": nmt.Namespace(),
-					"metric-version":   nmt.Version(),
-					"error":            err.Error(),
-				}).Error("error adding loaded metric type")
-				return nil, serror.New(err)
-			}
+			info.metricTypes = append(info.metricTypes, nmt)
 		}
 	}
 
@@ -488,28 +654,13 @@ func (p *pluginManager) LoadPlugin(details *pluginDetails, emitter gomit.Emitter
 		return nil, serror.New(e)
 	}
 
-	lPlugin.Meta = resp.Meta
-	lPlugin.Type = resp.Type
-	lPlugin.Token = resp.Token
-	lPlugin.LoadedTime = time.Now()
-	lPlugin.State = LoadedState
-
-	aErr := p.loadedPlugins.add(lPlugin)
-	if aErr != nil {
-		pmLogger.WithFields(log.Fields{
-			"_block": "load-plugin",
-			"error":  aErr,
-		}).Error("load plugin error while adding loaded plugin to load plugins collection")
-		return nil, aErr
-	}
-
-	return lPlugin, nil
+	return info, nil
 }
 
 // UnloadPlugin unloads a plugin from the LoadedPlugins table
 func (p *pluginManager) UnloadPlugin(pl core.Plugin) (*loadedPlugin, serror.SnapError) {
 
-	plugin, err := p.loadedPlugins.get(fmt.Sprintf("%s:%s:%d", pl.TypeName(), pl.Name(), pl.Version()))
+	plugin, err := p.loadedPlugins.get(pluginKey(pl))
 	if err != nil {
 		se := serror.New(ErrPluginNotFound, map[string]interface{}{
 			"plugin-name":    pl.Name(),
@@ -567,10 +718,30 @@ func (p *pluginManager) UnloadPlugin(pl core.Plugin) (*loadedPlugin, serror.Snap
 	return plugin, nil
 }
 
+// restorePlugin adds a plugin unloaded by a reload which failed back to the
+// loaded plugins.
+func (p *pluginManager) restorePlugin(lp *loadedPlugin) serror.SnapError {
+	return p.loadedPlugins.add(lp)
+}
+
 // GenerateArgs generates the cli args to send when stating a plugin
-func (p *pluginManager) GenerateArgs(pluginPath string) plugin.Arg {
-	pluginLog := filepath.Join(p.logPath, filepath.Base(pluginPath)) + ".log"
-	return plugin.NewArg(pluginLog)
+func (p *pluginManager) GenerateArgs(details *pluginDetails) plugin.Arg {
+	pluginLog := filepath.Join(p.logPath, filepath.Base(details.Exec)) + ".log"
+	arg := plugin.NewArg(pluginLog)
+	arg.Transport = p.transport
+	arg.LogLevel = details.LogLevel
+	return arg
+}
+
+// SetPluginLogLevel sets the log level passed to instances of the loaded
+// plugin started after this call
+func (p *pluginManager) SetPluginLogLevel(key string, level string) error {
+	lp, err := p.get(key)
+	if err != nil {
+		return err
+	}
+	lp.Details.LogLevel = level
+	return nil
 }
 
 func (p *pluginManager) teardown() {
@@ -587,12 +758,79 @@ func (p *pluginManager) teardown() {
 	}
 }
 
+// validatePluginMeta returns an error listing the required metadata missing
+// from the responding plugin.
+func validatePluginMeta(resp *plugin.Response) serror.SnapError {
+	var missing []string
+	if resp.Meta.Name == "" {
+		missing = append(missing, "name")
+	}
+	if resp.Meta.Version < 1 {
+		missing = append(missing, "version")
+	}
+	if (resp.Type == plugin.ProcessorPluginType || resp.Type == plugin.PublisherPluginType) && len(resp.Meta.AcceptedContentTypes) == 0 {
+		missing = append(missing, "accepted content types")
+	}
+	if resp.Type == plugin.ProcessorPluginType && len(resp.Meta.ReturnedContentTypes) == 0 {
+		missing = append(missing, "returned content types")
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return serror.New(fmt.Errorf("plugin metadata is incomplete, missing: %s", strings.Join(missing, ", ")), map[string]interface{}{
+		"plugin-name":    resp.Meta.Name,
+		"plugin-version": resp.Meta.Version,
+		"plugin-type":    resp.Type.String(),
+	})
+}
+
+// checkDependencies returns an error if a plugin the responding plugin
+// depends on is not loaded.
+func (p *pluginManager) checkDependencies(resp *plugin.Response) serror.SnapError {
+	for _, dep := range resp.Meta.DependsOn {
+		if _, err := p.loadedPlugins.get(dep.Key()); err != nil {
+			return dependencyNotLoadedError(&resp.Meta, dep)
+		}
+	}
+	return nil
+}
+
+// dependencyNotLoadedError returns the error of a plugin which can not be
+// loaded as the plugin it depends on is not loaded.
+func dependencyNotLoadedError(meta *plugin.PluginMeta, dep plugin.PluginRef) serror.SnapError {
+	se := serror.New(ErrPluginDependencyNotLoaded)
+	se.SetFields(map[string]interface{}{
+		"plugin":         core.PluginKey(core.PluginType(meta.Type), meta.Name, meta.Version),
+		"dependency":     dep.Key(),
+		"plugin-name":    meta.Name,
+		"plugin-version": meta.Version,
+		"plugin-type":    meta.Type.String(),
+	})
+	return se
+}
+
 func (p *pluginManager) get(key string) (*loadedPlugin, error) {
 	return p.loadedPlugins.get(key)
 }
 
+// all returns a copy of the table of loaded plugins so that it can be
+// iterated while plugins are loaded and unloaded.
 func (p *pluginManager) all() map[string]*loadedPlugin {
 	p.loadedPlugins.RLock()
 	defer p.loadedPlugins.RUnlock()
-	return p.loadedPlugins.table
+	table := make(map[string]*loadedPlugin, len(p.loadedPlugins.table))
+	for key, lp := range p.loadedPlugins.table {
+		table[key] = lp
+	}
+	return table
+}
+
+// setSigned sets whether the loaded plugin is signed, under the loaded
+// plugins lock, and returns whether it was signed.
+func (p *pluginManager) setSigned(lp *loadedPlugin, signed bool) bool {
+	p.loadedPlugins.Lock()
+	defer p.loadedPlugins.Unlock()
+	wasSigned := lp.Details.Signed
+	lp.Details.Signed = signed
+	return wasSigned
 }
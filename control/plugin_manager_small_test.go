@@ -0,0 +1,86 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidatePluginMeta(t *testing.T) {
+	Convey("validatePluginMeta", t, func() {
+		Convey("accepts complete metadata", func() {
+			resp := &plugin.Response{
+				Type: plugin.ProcessorPluginType,
+				Meta: *plugin.NewPluginMeta("mock", 1, plugin.ProcessorPluginType, []string{"snap.gob"}, []string{"snap.gob"}),
+			}
+			So(validatePluginMeta(resp), ShouldBeNil)
+		})
+		Convey("rejects a missing name", func() {
+			resp := &plugin.Response{
+				Type: plugin.CollectorPluginType,
+				Meta: plugin.PluginMeta{Version: 1},
+			}
+			serr := validatePluginMeta(resp)
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, "plugin metadata is incomplete, missing: name")
+		})
+		Convey("rejects a missing version", func() {
+			resp := &plugin.Response{
+				Type: plugin.CollectorPluginType,
+				Meta: plugin.PluginMeta{Name: "mock"},
+			}
+			serr := validatePluginMeta(resp)
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, "plugin metadata is incomplete, missing: version")
+		})
+		Convey("rejects a publisher without accepted content types", func() {
+			resp := &plugin.Response{
+				Type: plugin.PublisherPluginType,
+				Meta: plugin.PluginMeta{Name: "mock", Version: 1},
+			}
+			serr := validatePluginMeta(resp)
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, "plugin metadata is incomplete, missing: accepted content types")
+		})
+		Convey("rejects a processor without returned content types", func() {
+			resp := &plugin.Response{
+				Type: plugin.ProcessorPluginType,
+				Meta: plugin.PluginMeta{Name: "mock", Version: 1, AcceptedContentTypes: []string{"snap.gob"}},
+			}
+			serr := validatePluginMeta(resp)
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, "plugin metadata is incomplete, missing: returned content types")
+		})
+		Convey("lists every missing field", func() {
+			resp := &plugin.Response{
+				Type: plugin.ProcessorPluginType,
+			}
+			serr := validatePluginMeta(resp)
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, "plugin metadata is incomplete, missing: name, version, accepted content types, returned content types")
+		})
+	})
+}
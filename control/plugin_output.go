@@ -0,0 +1,160 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// PluginOutputBufferSize is the number of lines of output buffered for each
+// plugin output stream when plugin output is captured.  Lines written while
+// the buffer is full are dropped rather than blocking the plugin.
+var PluginOutputBufferSize = 1000
+
+// CapturePluginOutput is the PluginControlOpt which captures the stdout and
+// stderr of plugin processes and logs each line with the control logger,
+// with the plugin_key field identifying the plugin, rather than writing it
+// to the plugin log files.  Output is not captured by default.
+func CapturePluginOutput(enabled bool) PluginControlOpt {
+	return func(c *pluginControl) {
+		if enabled {
+			c.pluginManager.SetPluginOutput(newPluginOutput(nil))
+			return
+		}
+		c.pluginManager.SetPluginOutput(nil)
+	}
+}
+
+// SetPluginLogWriter captures the stdout and stderr of plugin processes
+// started after this call and writes each line to w prefixed with the key
+// of the plugin and the stream it was written to.  A nil w logs the lines
+// with the control logger as CapturePluginOutput does.
+func (p *pluginControl) SetPluginLogWriter(w io.Writer) {
+	p.pluginManager.SetPluginOutput(newPluginOutput(w))
+}
+
+// DroppedPluginOutput returns the number of lines of captured plugin output
+// dropped because the plugin wrote faster than its output was routed.
+func (p *pluginControl) DroppedPluginOutput() uint64 {
+	if o := p.pluginManager.PluginOutput(); o != nil {
+		return atomic.LoadUint64(&o.dropped)
+	}
+	return 0
+}
+
+// pluginOutput routes the captured output of plugins to the writer, or to
+// the control logger when the writer is nil.
+type pluginOutput struct {
+	// dropped counts the lines dropped because a stream's buffer was full
+	dropped uint64
+	sync.Mutex
+	w io.Writer
+}
+
+func newPluginOutput(w io.Writer) *pluginOutput {
+	return &pluginOutput{w: w}
+}
+
+// streams returns the writers capturing the stdout and stderr of the plugin
+// with the key.
+func (o *pluginOutput) streams(key string) (stdout, stderr io.Writer) {
+	return o.newStream(key, "stdout"), o.newStream(key, "stderr")
+}
+
+func (o *pluginOutput) newStream(key, name string) *outputStream {
+	return &outputStream{
+		key:    key,
+		name:   name,
+		lines:  make(chan string, PluginOutputBufferSize),
+		output: o,
+	}
+}
+
+func (o *pluginOutput) write(key, stream, line string) {
+	if o.w == nil {
+		controlLogger.WithFields(log.Fields{
+			"_block":     "plugin-output",
+			"plugin_key": key,
+			"stream":     stream,
+		}).Info(line)
+		return
+	}
+	o.Lock()
+	defer o.Unlock()
+	fmt.Fprintf(o.w, "%s %s: %s\n", key, stream, line)
+}
+
+// outputStream buffers the lines written to one output stream of a plugin
+// until they are routed.  Lines are routed by a goroutine started by the
+// first write, and written and closed from a single goroutine.
+type outputStream struct {
+	key     string
+	name    string
+	lines   chan string
+	output  *pluginOutput
+	routing bool
+}
+
+// Write buffers the line, dropping it if the buffer is full, so that a
+// plugin writing a lot of output is never blocked.
+func (s *outputStream) Write(p []byte) (int, error) {
+	if !s.routing {
+		s.routing = true
+		go s.route()
+	}
+	select {
+	case s.lines <- strings.TrimSuffix(string(p), "\n"):
+	default:
+		atomic.AddUint64(&s.output.dropped, 1)
+	}
+	return len(p), nil
+}
+
+// Close stops the stream once its buffered lines are routed.
+func (s *outputStream) Close() error {
+	close(s.lines)
+	return nil
+}
+
+func (s *outputStream) route() {
+	for line := range s.lines {
+		s.output.write(s.key, s.name, line)
+	}
+}
+
+// outputKey returns the key identifying the plugin with the details in its
+// captured output, which is the executable name until the plugin is loaded.
+func (p *pluginManager) outputKey(details *pluginDetails) string {
+	p.loadedPlugins.RLock()
+	defer p.loadedPlugins.RUnlock()
+	for key, lp := range p.loadedPlugins.table {
+		if lp.Details == details {
+			return key
+		}
+	}
+	return filepath.Base(details.Exec)
+}
@@ -0,0 +1,121 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// blockingWriter records the lines written to it.  When writing is set the
+// first write blocks until unblock is closed.
+type blockingWriter struct {
+	sync.Mutex
+	buf     bytes.Buffer
+	writing chan struct{}
+	unblock chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	if w.writing != nil {
+		select {
+		case <-w.unblock:
+		default:
+			w.writing <- struct{}{}
+			<-w.unblock
+		}
+	}
+	w.Lock()
+	defer w.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) String() string {
+	w.Lock()
+	defer w.Unlock()
+	return w.buf.String()
+}
+
+// eventually returns whether cond becomes true within a second.
+func eventually(cond func() bool) bool {
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestPluginOutput(t *testing.T) {
+	Convey("Captured plugin output is written to the plugin log writer", t, func() {
+		c := New(GetDefaultConfig())
+		w := &blockingWriter{}
+		c.SetPluginLogWriter(w)
+		stdout, stderr := c.pluginManager.PluginOutput().streams("collector:mock:1")
+		stdout.Write([]byte("out line\n"))
+		stderr.Write([]byte("err line\n"))
+		So(eventually(func() bool {
+			return strings.Contains(w.String(), "collector:mock:1 stdout: out line\n") &&
+				strings.Contains(w.String(), "collector:mock:1 stderr: err line\n")
+		}), ShouldBeTrue)
+		So(c.DroppedPluginOutput(), ShouldEqual, 0)
+	})
+	Convey("Output written while the buffer is full is dropped", t, func() {
+		size := PluginOutputBufferSize
+		PluginOutputBufferSize = 1
+		defer func() { PluginOutputBufferSize = size }()
+		c := New(GetDefaultConfig())
+		w := &blockingWriter{writing: make(chan struct{}), unblock: make(chan struct{})}
+		c.SetPluginLogWriter(w)
+		stdout, _ := c.pluginManager.PluginOutput().streams("collector:mock:1")
+		stdout.Write([]byte("routing\n"))
+		<-w.writing
+		stdout.Write([]byte("buffered\n"))
+		stdout.Write([]byte("dropped\n"))
+		So(c.DroppedPluginOutput(), ShouldEqual, 1)
+		close(w.unblock)
+		So(eventually(func() bool { return strings.Contains(w.String(), "buffered") }), ShouldBeTrue)
+		So(strings.Contains(w.String(), "dropped"), ShouldBeFalse)
+	})
+	Convey("Plugin output is not captured by default", t, func() {
+		c := New(GetDefaultConfig())
+		So(c.pluginManager.PluginOutput(), ShouldBeNil)
+		So(c.DroppedPluginOutput(), ShouldEqual, 0)
+		c = New(GetDefaultConfig(), CapturePluginOutput(true))
+		So(c.pluginManager.PluginOutput(), ShouldNotBeNil)
+	})
+	Convey("Captured output is keyed by the loaded plugin with the details", t, func() {
+		c := New(GetDefaultConfig())
+		pm := c.pluginManager.(*pluginManager)
+		lp := newCollector("mock")
+		lp.Details = &pluginDetails{Exec: "snap-plugin-collector-mock"}
+		pm.loadedPlugins.add(lp)
+		So(pm.outputKey(lp.Details), ShouldEqual, "collector:mock:1")
+		So(pm.outputKey(&pluginDetails{Exec: "snap-plugin-collector-mock"}), ShouldEqual, "snap-plugin-collector-mock")
+	})
+}
@@ -0,0 +1,103 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrPluginOutsideRoot - error message when the path of a plugin to load
+	// is outside the root set with SetPluginRoot
+	ErrPluginOutsideRoot = errors.New("Plugin path is outside the plugin root")
+)
+
+// SetPluginRoot restricts the plugins which can be loaded to those within
+// dir, once symlinks are resolved.  An empty dir removes the restriction.
+func (p *pluginControl) SetPluginRoot(dir string) error {
+	if dir == "" {
+		p.pluginRoot = ""
+		return nil
+	}
+	root, err := normalizePluginPath(dir)
+	if err != nil {
+		return serror.New(err, map[string]interface{}{"plugin-root": dir})
+	}
+	p.pluginRoot = root
+	return nil
+}
+
+// normalizePluginPath returns the absolute path of the file at path with
+// symlinks resolved.
+func normalizePluginPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// resolvePluginPath replaces the path of the requested plugin with its
+// normalized path, failing if that is outside the plugin root.
+func (p *pluginControl) resolvePluginPath(rp *core.RequestedPlugin) serror.SnapError {
+	f := map[string]interface{}{"path": rp.Path()}
+	resolved, err := normalizePluginPath(rp.Path())
+	if err != nil {
+		return serror.New(err, f)
+	}
+	if p.pluginRoot != "" && !withinDir(p.pluginRoot, resolved) {
+		f["plugin-root"] = p.pluginRoot
+		return serror.New(ErrPluginOutsideRoot, f)
+	}
+	rp.SetPath(resolved)
+	return nil
+}
+
+// withinDir returns whether path is within the directory dir.  Both are
+// expected to be normalized.
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// signatureFile returns the signature file of the plugin at path, which is
+// the .asc file beside it or, for a symlink, beside the file it links to.
+// An empty string is returned if neither exists.
+func signatureFile(path string) string {
+	candidates := []string{path + ".asc"}
+	if resolved, err := normalizePluginPath(path); err == nil {
+		candidates = append(candidates, resolved+".asc")
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c
+		}
+	}
+	return ""
+}
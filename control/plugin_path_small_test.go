@@ -0,0 +1,123 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// pluginPathDir creates a directory holding a plugin file, its signature
+// file and a symlink to the plugin in another directory.
+func pluginPathDir() (root, pluginPath, linkPath string) {
+	dir, err := ioutil.TempDir("", "plugin-path")
+	So(err, ShouldBeNil)
+	dir, err = filepath.EvalSymlinks(dir)
+	So(err, ShouldBeNil)
+	root = filepath.Join(dir, "root")
+	So(os.Mkdir(root, 0755), ShouldBeNil)
+	So(os.Mkdir(filepath.Join(dir, "links"), 0755), ShouldBeNil)
+	pluginPath = filepath.Join(root, "snap-plugin-collector-mock")
+	So(ioutil.WriteFile(pluginPath, []byte("plugin"), 0755), ShouldBeNil)
+	So(ioutil.WriteFile(pluginPath+".asc", []byte("signature"), 0644), ShouldBeNil)
+	linkPath = filepath.Join(dir, "links", "mock")
+	So(os.Symlink(pluginPath, linkPath), ShouldBeNil)
+	return root, pluginPath, linkPath
+}
+
+func TestPluginPath(t *testing.T) {
+	Convey("A symlinked plugin path is resolved", t, func() {
+		root, pluginPath, linkPath := pluginPathDir()
+		defer os.RemoveAll(filepath.Dir(root))
+		c := New(GetDefaultConfig())
+		rp, err := core.NewRequestedPlugin(linkPath)
+		So(err, ShouldBeNil)
+		So(c.resolvePluginPath(rp), ShouldBeNil)
+		So(rp.Path(), ShouldEqual, pluginPath)
+	})
+	Convey("A relative plugin path with traversal is made absolute", t, func() {
+		root, pluginPath, _ := pluginPathDir()
+		defer os.RemoveAll(filepath.Dir(root))
+		wd, err := os.Getwd()
+		So(err, ShouldBeNil)
+		defer os.Chdir(wd)
+		So(os.Chdir(filepath.Join(filepath.Dir(root), "links")), ShouldBeNil)
+		c := New(GetDefaultConfig())
+		rp, err := core.NewRequestedPlugin("../root/snap-plugin-collector-mock")
+		So(err, ShouldBeNil)
+		So(c.resolvePluginPath(rp), ShouldBeNil)
+		So(rp.Path(), ShouldEqual, pluginPath)
+	})
+	Convey("A plugin within the plugin root can be resolved", t, func() {
+		root, pluginPath, linkPath := pluginPathDir()
+		defer os.RemoveAll(filepath.Dir(root))
+		c := New(GetDefaultConfig())
+		So(c.SetPluginRoot(root), ShouldBeNil)
+		rp, err := core.NewRequestedPlugin(linkPath)
+		So(err, ShouldBeNil)
+		So(c.resolvePluginPath(rp), ShouldBeNil)
+		So(rp.Path(), ShouldEqual, pluginPath)
+	})
+	Convey("A plugin outside the plugin root fails to load", t, func() {
+		root, pluginPath, _ := pluginPathDir()
+		defer os.RemoveAll(filepath.Dir(root))
+		c := New(GetDefaultConfig())
+		So(c.SetPluginRoot(filepath.Dir(root)+"/links"), ShouldBeNil)
+		rp, err := core.NewRequestedPlugin(pluginPath)
+		So(err, ShouldBeNil)
+		_, serr := c.Load(rp)
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, ErrPluginOutsideRoot.Error())
+	})
+	Convey("Clearing the plugin root removes the restriction", t, func() {
+		root, _, linkPath := pluginPathDir()
+		defer os.RemoveAll(filepath.Dir(root))
+		c := New(GetDefaultConfig())
+		So(c.SetPluginRoot(filepath.Join(root, "missing")), ShouldNotBeNil)
+		So(c.SetPluginRoot(filepath.Dir(root)+"/links"), ShouldBeNil)
+		So(c.SetPluginRoot(""), ShouldBeNil)
+		rp, err := core.NewRequestedPlugin(linkPath)
+		So(err, ShouldBeNil)
+		So(c.resolvePluginPath(rp), ShouldBeNil)
+	})
+	Convey("The signature file of a symlinked plugin is found beside its target", t, func() {
+		root, pluginPath, linkPath := pluginPathDir()
+		defer os.RemoveAll(filepath.Dir(root))
+		So(signatureFile(pluginPath), ShouldEqual, pluginPath+".asc")
+		So(signatureFile(linkPath), ShouldEqual, pluginPath+".asc")
+		So(os.Remove(pluginPath+".asc"), ShouldBeNil)
+		So(signatureFile(linkPath), ShouldEqual, "")
+	})
+	Convey("withinDir rejects paths escaping the directory", t, func() {
+		So(withinDir("/opt/plugins", "/opt/plugins/mock"), ShouldBeTrue)
+		So(withinDir("/opt/plugins", "/opt/plugins/sub/mock"), ShouldBeTrue)
+		So(withinDir("/opt/plugins", "/opt/mock"), ShouldBeFalse)
+		So(withinDir("/opt/plugins", "/opt/plugins-other/mock"), ShouldBeFalse)
+		So(withinDir("/opt/plugins", "/opt/..plugins/mock"), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,61 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sort"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// PluginSubscriptions returns the IDs of the tasks subscribed to the pool of
+// the plugin identified by its {type}:{name}:{version} key, in order.  There
+// are no subscriptions when no instance of the plugin is running.
+func (p *pluginControl) PluginSubscriptions(key string) ([]string, error) {
+	if _, _, _, err := core.ParsePluginKey(key); err != nil {
+		return nil, serror.New(err, map[string]interface{}{"plugin-key": key})
+	}
+	aps := p.pluginRunner.AvailablePlugins()
+	aps.RLock()
+	pool, ok := aps.table[key]
+	aps.RUnlock()
+	taskIDs := []string{}
+	if !ok {
+		return taskIDs, nil
+	}
+	for _, sub := range pool.Subscriptions() {
+		taskIDs = append(taskIDs, sub.TaskID)
+	}
+	sort.Strings(taskIDs)
+	return taskIDs, nil
+}
+
+// SubscriptionCount returns the number of tasks subscribed to the pool of the
+// plugin identified by its {type}:{name}:{version} key.
+func (p *pluginControl) SubscriptionCount(key string) int {
+	aps := p.pluginRunner.AvailablePlugins()
+	aps.RLock()
+	defer aps.RUnlock()
+	if pool, ok := aps.table[key]; ok {
+		return pool.SubscriptionCount()
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPluginSubscriptions(t *testing.T) {
+	Convey("Given a running collector with subscriptions", t, func() {
+		c := New(GetDefaultConfig())
+		addFakeCollector(c, "mock", &fakeCollectorClient{})
+		pool, err := c.pluginRunner.AvailablePlugins().getPool("collector:mock:1")
+		So(err, ShouldBeNil)
+		pool.Subscribe("task-b", strategy.BoundSubscriptionType)
+		pool.Subscribe("task-a", strategy.UnboundSubscriptionType)
+
+		Convey("the subscribed tasks are returned in order", func() {
+			taskIDs, err := c.PluginSubscriptions("collector:mock:1")
+			So(err, ShouldBeNil)
+			So(taskIDs, ShouldResemble, []string{"task-a", "task-b"})
+			So(c.SubscriptionCount("collector:mock:1"), ShouldEqual, 2)
+		})
+		Convey("a plugin which is not running has no subscriptions", func() {
+			taskIDs, err := c.PluginSubscriptions("collector:other:1")
+			So(err, ShouldBeNil)
+			So(taskIDs, ShouldBeEmpty)
+			So(c.SubscriptionCount("collector:other:1"), ShouldEqual, 0)
+		})
+		Convey("a malformed key is an error", func() {
+			_, err := c.PluginSubscriptions("mock")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,47 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sort"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// ListPluginVersions returns the loaded versions of the plugin of the given
+// type and name in ascending order.  The slice is empty if no version of the
+// plugin is loaded.
+func (p *pluginControl) ListPluginVersions(t core.PluginType, name string) ([]int, error) {
+	if t < core.CollectorPluginType || t > core.PublisherPluginType {
+		return nil, serror.New(strategy.ErrBadType, map[string]interface{}{
+			"plugin-name": name,
+		})
+	}
+	versions := []int{}
+	for _, lp := range p.pluginManager.all() {
+		if core.PluginType(lp.Type) == t && lp.Name() == name {
+			versions = append(versions, lp.Version())
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
@@ -0,0 +1,68 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestListPluginVersions(t *testing.T) {
+	Convey("Given loaded versions of a plugin", t, func() {
+		c := New(GetDefaultConfig())
+		pm := c.pluginManager.(*pluginManager)
+		for _, v := range []int{3, 1, 2} {
+			lp := newCollector("mock")
+			lp.Meta.Version = v
+			So(pm.loadedPlugins.add(lp), ShouldBeNil)
+		}
+		So(pm.loadedPlugins.add(newCollector("other")), ShouldBeNil)
+		So(pm.loadedPlugins.add(&loadedPlugin{
+			Type: plugin.PublisherPluginType,
+			Meta: plugin.PluginMeta{Name: "mock", Version: 7},
+		}), ShouldBeNil)
+
+		Convey("the versions of the plugin are returned in ascending order", func() {
+			versions, err := c.ListPluginVersions(core.CollectorPluginType, "mock")
+			So(err, ShouldBeNil)
+			So(versions, ShouldResemble, []int{1, 2, 3})
+
+			versions, err = c.ListPluginVersions(core.PublisherPluginType, "mock")
+			So(err, ShouldBeNil)
+			So(versions, ShouldResemble, []int{7})
+		})
+		Convey("a plugin which is not loaded has no versions", func() {
+			versions, err := c.ListPluginVersions(core.ProcessorPluginType, "mock")
+			So(err, ShouldBeNil)
+			So(versions, ShouldNotBeNil)
+			So(versions, ShouldBeEmpty)
+		})
+		Convey("an unknown plugin type is an error", func() {
+			_, err := c.ListPluginVersions(core.PluginType(9), "mock")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
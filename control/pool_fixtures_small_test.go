@@ -0,0 +1,73 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/control/strategy"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// nopExecutablePlugin stands in for the process of a running plugin.
+type nopExecutablePlugin struct{}
+
+func (nopExecutablePlugin) Start() error                                            { return nil }
+func (nopExecutablePlugin) Kill() error                                             { return nil }
+func (nopExecutablePlugin) WaitForResponse(time.Duration) (*plugin.Response, error) { return nil, nil }
+
+// newFakeAvailablePlugin returns a running instance of the plugin talking
+// through the client.
+func newFakeAvailablePlugin(typ plugin.PluginType, name string, version int, cli client.PluginClient) *availablePlugin {
+	return &availablePlugin{
+		name:       name,
+		version:    version,
+		pluginType: typ,
+		client:     cli,
+		ePlugin:    nopExecutablePlugin{},
+		healthChan: make(chan error, 1),
+	}
+}
+
+// newTestPool returns a pool for the plugin key holding the plugins.
+func newTestPool(key string, plugins ...strategy.AvailablePlugin) strategy.Pool {
+	pool, err := strategy.NewPool(key, plugins...)
+	So(err, ShouldBeNil)
+	return pool
+}
+
+// setPool makes the pool the one available for the plugin key.
+func setPool(aps *availablePlugins, key string, pool strategy.Pool) {
+	aps.Lock()
+	aps.table[key] = pool
+	aps.Unlock()
+}
+
+// addPool makes a pool holding the plugins available for the plugin key.
+func addPool(aps *availablePlugins, key string, plugins ...strategy.AvailablePlugin) strategy.Pool {
+	pool := newTestPool(key, plugins...)
+	setPool(aps, key, pool)
+	return pool
+}
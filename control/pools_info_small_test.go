@@ -0,0 +1,70 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+# Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package control
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPoolsInfo(t *testing.T) {
+	Convey("Pools are described ordered by key", t, func() {
+		c := New(GetDefaultConfig())
+		addFakeCollector(c, "foo", &fakeCollectorClient{})
+		addFakeCollector(c, "bar", &fakeCollectorClient{})
+		pool, err := c.pluginRunner.AvailablePlugins().getPool("collector:foo:1")
+		So(err, ShouldBeNil)
+		pool.Subscribe("task", strategy.BoundSubscriptionType)
+		pool.IncRestartCount()
+
+		pools := c.PoolsInfo()
+		So(len(pools), ShouldEqual, 2)
+		So(pools[0].Key, ShouldEqual, "collector:bar:1")
+		So(pools[0].RestartCount, ShouldEqual, 0)
+		So(pools[1].Key, ShouldEqual, "collector:foo:1")
+		So(pools[1].Strategy, ShouldEqual, pool.Strategy().String())
+		So(pools[1].SubscriptionCount, ShouldEqual, 1)
+		So(pools[1].RestartCount, ShouldEqual, 1)
+		So(len(pools[1].Members), ShouldEqual, 1)
+	})
+	Convey("Pools are described while plugins restart", t, func() {
+		c := New(GetDefaultConfig())
+		addFakeCollector(c, "foo", &fakeCollectorClient{})
+		pool, err := c.pluginRunner.AvailablePlugins().getPool("collector:foo:1")
+		So(err, ShouldBeNil)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				pool.IncRestartCount()
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			c.PoolsInfo()
+		}
+		wg.Wait()
+		So(c.PoolsInfo()[0].RestartCount, ShouldEqual, 100)
+	})
+}
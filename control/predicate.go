@@ -0,0 +1,122 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+const (
+	// CollectIfMetricConfigKey is the metric config key naming the metric,
+	// as a namespace string such as /intel/mock/foo, a metric's collection
+	// depends on
+	CollectIfMetricConfigKey = "snap.collect_if.metric"
+	// CollectIfAboveConfigKey is the metric config key holding the value the
+	// metric named by CollectIfMetricConfigKey must be above, in the same
+	// collection, for the metric to be collected
+	CollectIfAboveConfigKey = "snap.collect_if.above"
+)
+
+// collectPredicate is a condition on the value of another metric collected
+// in the same call which must hold for a metric to be collected.
+type collectPredicate struct {
+	namespace string
+	above     float64
+}
+
+// predicateFor returns the collect predicate declared in the metric's config.
+func predicateFor(mt core.Metric) (collectPredicate, bool) {
+	if mt.Config() == nil {
+		return collectPredicate{}, false
+	}
+	table := mt.Config().Table()
+	ns, ok := table[CollectIfMetricConfigKey].(ctypes.ConfigValueStr)
+	if !ok {
+		return collectPredicate{}, false
+	}
+	pred := collectPredicate{namespace: ns.Value}
+	switch v := table[CollectIfAboveConfigKey].(type) {
+	case ctypes.ConfigValueFloat:
+		pred.above = v.Value
+	case ctypes.ConfigValueInt:
+		pred.above = float64(v.Value)
+	}
+	return pred, true
+}
+
+// splitConditionalMetrics separates the metrics with a collect predicate from
+// those which are always collected.
+func splitConditionalMetrics(mts []core.Metric) (ready, pending []core.Metric) {
+	for _, mt := range mts {
+		if _, ok := predicateFor(mt); ok {
+			pending = append(pending, mt)
+			continue
+		}
+		ready = append(ready, mt)
+	}
+	return ready, pending
+}
+
+// dueConditionalMetrics evaluates the predicates of the pending metrics
+// against the collected metrics.  It returns the metrics whose predicate
+// holds and the metrics whose predicate depends on a metric not yet
+// collected.  Metrics whose predicate does not hold are dropped.
+func dueConditionalMetrics(pending, collected []core.Metric) (due, waiting []core.Metric) {
+	values := make(map[string]interface{}, len(collected))
+	for _, m := range collected {
+		values[m.Namespace().String()] = m.Data()
+	}
+	for _, mt := range pending {
+		pred, _ := predicateFor(mt)
+		data, ok := values[pred.namespace]
+		if !ok {
+			waiting = append(waiting, mt)
+			continue
+		}
+		if v, ok := toFloat64(data); ok && v > pred.above {
+			due = append(due, mt)
+		}
+	}
+	return due, waiting
+}
+
+// toFloat64 converts numeric metric data to a float64.
+func toFloat64(data interface{}) (float64, bool) {
+	switch v := data.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,106 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConditionalMetrics(t *testing.T) {
+	Convey("Given a metric collected only when another is above a threshold", t, func() {
+		load := plugin.MetricType{Namespace_: core.NewNamespace("intel", "cpu", "load")}
+		cfg := cdata.NewNode()
+		cfg.AddItem(CollectIfMetricConfigKey, ctypes.ConfigValueStr{Value: load.Namespace().String()})
+		cfg.AddItem(CollectIfAboveConfigKey, ctypes.ConfigValueFloat{Value: 0.5})
+		detail := plugin.MetricType{Namespace_: core.NewNamespace("intel", "cpu", "detail"), Config_: cfg}
+
+		ready, pending := splitConditionalMetrics([]core.Metric{detail, load})
+		So(len(ready), ShouldEqual, 1)
+		So(len(pending), ShouldEqual, 1)
+
+		Convey("it waits while its input has not been collected", func() {
+			due, waiting := dueConditionalMetrics(pending, nil)
+			So(due, ShouldBeEmpty)
+			So(len(waiting), ShouldEqual, 1)
+		})
+		Convey("it is due when the predicate holds", func() {
+			load.Data_ = 0.9
+			due, waiting := dueConditionalMetrics(pending, []core.Metric{load})
+			So(len(due), ShouldEqual, 1)
+			So(waiting, ShouldBeEmpty)
+		})
+		Convey("it is dropped when the predicate does not hold", func() {
+			load.Data_ = int64(0)
+			due, waiting := dueConditionalMetrics(pending, []core.Metric{load})
+			So(due, ShouldBeEmpty)
+			So(waiting, ShouldBeEmpty)
+		})
+	})
+}
+
+// valueCollectorClient collects every metric with the value.
+type valueCollectorClient struct {
+	fakeCollectorClient
+	value float64
+}
+
+func (c *valueCollectorClient) CollectMetrics(mts []core.Metric) ([]core.Metric, error) {
+	out := make([]core.Metric, len(mts))
+	for i, mt := range mts {
+		out[i] = plugin.MetricType{Namespace_: mt.Namespace(), Version_: mt.Version(), Data_: c.value}
+	}
+	return out, nil
+}
+
+func TestConditionalMetricConfigKeys(t *testing.T) {
+	Convey("The predicate config keys are not passed to the plugin", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		load := addFakeCollector(c, "cpu", &valueCollectorClient{value: 0.9})
+		cli := &configRecordingClient{}
+		detail := addConfigurableCollector(c, cli).(plugin.MetricType)
+		cfg := configWithLimit(5)
+		cfg.AddItem(CollectIfMetricConfigKey, ctypes.ConfigValueStr{Value: load.Namespace().String()})
+		cfg.AddItem(CollectIfAboveConfigKey, ctypes.ConfigValueFloat{Value: 0.5})
+		detail.Config_ = cfg
+
+		metrics, errs := c.CollectMetrics([]core.Metric{detail, load}, time.Now().Add(time.Second), "task", nil)
+		So(errs, ShouldBeEmpty)
+		So(metrics, ShouldHaveLength, 2)
+		So(cli.config, ShouldNotBeNil)
+		So(cli.config.Table()["limit"], ShouldResemble, ctypes.ConfigValueInt{Value: 5})
+		_, ok := cli.config.Table()[CollectIfMetricConfigKey]
+		So(ok, ShouldBeFalse)
+		_, ok = cli.config.Table()[CollectIfAboveConfigKey]
+		So(ok, ShouldBeFalse)
+		// the metric's own config still holds its predicate
+		So(cfg.Table(), ShouldContainKey, CollectIfMetricConfigKey)
+	})
+}
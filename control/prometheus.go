@@ -0,0 +1,183 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The names of the metrics exposed by PrometheusHandler.  They are kept
+// stable so scrapers and dashboards built on them keep working.
+const (
+	// PrometheusLoadedPlugins is a gauge of the plugins loaded, labelled
+	// by plugin type.
+	PrometheusLoadedPlugins = "snap_control_loaded_plugins"
+	// PrometheusPoolSize is a gauge of the running plugins in each pool,
+	// labelled by plugin key.
+	PrometheusPoolSize = "snap_control_pool_size"
+	// PrometheusPoolSubscriptions is a gauge of the task subscriptions to
+	// each pool, labelled by plugin key.
+	PrometheusPoolSubscriptions = "snap_control_pool_subscriptions"
+	// PrometheusCollectDuration is a histogram of the time collectors take
+	// to respond to a collection, in seconds, labelled by plugin key.
+	PrometheusCollectDuration = "snap_control_collect_duration_seconds"
+	// PrometheusSpawnFailures is a counter of the plugin instances which
+	// failed to start.
+	PrometheusSpawnFailures = "snap_control_plugin_spawn_failures_total"
+)
+
+// CollectDurationBuckets are the upper bounds, in seconds, of the buckets
+// of the PrometheusCollectDuration histogram.
+var CollectDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// controlTelemetry records the control self-telemetry which is not read off
+// the plugin manager and pools when it is exposed.
+type controlTelemetry struct {
+	mutex           sync.Mutex
+	collectDuration map[string]*durationHistogram
+	spawnFailures   uint64
+}
+
+type durationHistogram struct {
+	// buckets holds the count of observations in each bucket, which are
+	// made cumulative when exposed
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+func newControlTelemetry() *controlTelemetry {
+	return &controlTelemetry{
+		collectDuration: map[string]*durationHistogram{},
+	}
+}
+
+// observeCollect records a collection from the plugin identified by the
+// plugin key which took d.
+func (t *controlTelemetry) observeCollect(pluginKey string, d time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	h, ok := t.collectDuration[pluginKey]
+	if !ok {
+		h = &durationHistogram{buckets: make([]uint64, len(CollectDurationBuckets))}
+		t.collectDuration[pluginKey] = h
+	}
+	secs := d.Seconds()
+	if i := sort.SearchFloat64s(CollectDurationBuckets, secs); i < len(h.buckets) {
+		h.buckets[i]++
+	}
+	h.count++
+	h.sum += secs
+}
+
+// spawnFailed records a plugin instance failing to start.
+func (t *controlTelemetry) spawnFailed() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.spawnFailures++
+}
+
+// PrometheusHandler returns an http.Handler exposing the control
+// self-telemetry in the Prometheus text exposition format: the loaded
+// plugins, the size of and subscriptions to each pool, how long collections
+// take and how many plugin instances failed to start.
+func (p *pluginControl) PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write(p.prometheusMetrics())
+	})
+}
+
+// prometheusMetrics returns the control self-telemetry in the Prometheus
+// text exposition format.
+func (p *pluginControl) prometheusMetrics() []byte {
+	var buf bytes.Buffer
+
+	loaded := map[string]int{}
+	for _, lp := range p.pluginManager.all() {
+		loaded[lp.TypeName()]++
+	}
+	writePrometheusHeader(&buf, PrometheusLoadedPlugins, "gauge", "Plugins loaded by type.")
+	for _, typ := range sortedKeys(loaded) {
+		fmt.Fprintf(&buf, "%s{type=%q} %d\n", PrometheusLoadedPlugins, typ, loaded[typ])
+	}
+
+	aps := p.pluginRunner.AvailablePlugins()
+	sizes := map[string]int{}
+	subs := map[string]int{}
+	aps.RLock()
+	for key, pool := range aps.table {
+		sizes[key] = pool.Count()
+		subs[key] = pool.SubscriptionCount()
+	}
+	aps.RUnlock()
+	writePrometheusHeader(&buf, PrometheusPoolSize, "gauge", "Running plugins in each pool.")
+	for _, key := range sortedKeys(sizes) {
+		fmt.Fprintf(&buf, "%s{plugin=%q} %d\n", PrometheusPoolSize, key, sizes[key])
+	}
+	writePrometheusHeader(&buf, PrometheusPoolSubscriptions, "gauge", "Task subscriptions to each pool.")
+	for _, key := range sortedKeys(subs) {
+		fmt.Fprintf(&buf, "%s{plugin=%q} %d\n", PrometheusPoolSubscriptions, key, subs[key])
+	}
+
+	t := aps.telemetry
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	writePrometheusHeader(&buf, PrometheusCollectDuration, "histogram", "Time collectors take to respond to a collection in seconds.")
+	keys := make([]string, 0, len(t.collectDuration))
+	for key := range t.collectDuration {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		h := t.collectDuration[key]
+		var cumulative uint64
+		for i, le := range CollectDurationBuckets {
+			cumulative += h.buckets[i]
+			fmt.Fprintf(&buf, "%s_bucket{plugin=%q,le=%q} %d\n", PrometheusCollectDuration, key, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&buf, "%s_bucket{plugin=%q,le=\"+Inf\"} %d\n", PrometheusCollectDuration, key, h.count)
+		fmt.Fprintf(&buf, "%s_sum{plugin=%q} %s\n", PrometheusCollectDuration, key, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&buf, "%s_count{plugin=%q} %d\n", PrometheusCollectDuration, key, h.count)
+	}
+	writePrometheusHeader(&buf, PrometheusSpawnFailures, "counter", "Plugin instances which failed to start.")
+	fmt.Fprintf(&buf, "%s %d\n", PrometheusSpawnFailures, t.spawnFailures)
+
+	return buf.Bytes()
+}
+
+func writePrometheusHeader(buf *bytes.Buffer, name, typ, help string) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,66 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPrometheusHandler(t *testing.T) {
+	Convey("The control self-telemetry is exposed in the Prometheus format", t, func() {
+		c := New(GetDefaultConfig())
+		addRecordedPool(c, &stopRecorder{}, plugin.CollectorPluginType, "col")
+		tel := c.pluginRunner.AvailablePlugins().telemetry
+		tel.observeCollect("collector:col:1", 20*time.Millisecond)
+		tel.observeCollect("collector:col:1", 20*time.Second)
+		tel.spawnFailed()
+
+		req, err := http.NewRequest("GET", "/metrics", nil)
+		So(err, ShouldBeNil)
+		w := httptest.NewRecorder()
+		c.PrometheusHandler().ServeHTTP(w, req)
+		So(w.Header().Get("Content-Type"), ShouldStartWith, "text/plain")
+		body := w.Body.String()
+		for _, line := range []string{
+			"# TYPE snap_control_pool_size gauge",
+			`snap_control_pool_size{plugin="collector:col:1"} 1`,
+			`snap_control_pool_subscriptions{plugin="collector:col:1"} 0`,
+			"# TYPE snap_control_collect_duration_seconds histogram",
+			`snap_control_collect_duration_seconds_bucket{plugin="collector:col:1",le="0.01"} 0`,
+			`snap_control_collect_duration_seconds_bucket{plugin="collector:col:1",le="0.025"} 1`,
+			`snap_control_collect_duration_seconds_bucket{plugin="collector:col:1",le="10"} 1`,
+			`snap_control_collect_duration_seconds_bucket{plugin="collector:col:1",le="+Inf"} 2`,
+			`snap_control_collect_duration_seconds_count{plugin="collector:col:1"} 2`,
+			"snap_control_plugin_spawn_failures_total 1",
+		} {
+			So(strings.Contains(body, line+"\n"), ShouldBeTrue)
+		}
+	})
+}
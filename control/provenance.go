@@ -0,0 +1,76 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"strconv"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+)
+
+// ProvenancePluginTag is the tag added to collected metrics recording the
+// name of the plugin the metric was collected from when provenance tags are
+// enabled.  The version of the plugin is recorded in CollectedVersionTag.
+const ProvenancePluginTag = "plugin_name"
+
+// ProvenanceTags is the PluginControlOpt which tags each metric returned by
+// CollectMetrics with the name and version of the plugin it was collected
+// from, so the collector version which produced the data can be traced.
+// Metrics are not tagged by default.
+func ProvenanceTags(enabled bool) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.provenanceTags = enabled
+	}
+}
+
+// tagProvenance returns the metrics collected from the plugin identified by
+// the plugin key tagged with the plugin name and version when provenance tags
+// are enabled.
+func (p *pluginControl) tagProvenance(pluginKey string, mts []core.Metric) []core.Metric {
+	if !p.provenanceTags || len(mts) == 0 {
+		return mts
+	}
+	_, name, version, err := core.ParsePluginKey(pluginKey)
+	if err != nil {
+		return mts
+	}
+	out := make([]core.Metric, len(mts))
+	for i, m := range mts {
+		tags := make(map[string]string, len(m.Tags())+2)
+		for k, v := range m.Tags() {
+			tags[k] = v
+		}
+		tags[ProvenancePluginTag] = name
+		tags[CollectedVersionTag] = strconv.Itoa(version)
+		out[i] = plugin.MetricType{
+			Namespace_:          m.Namespace(),
+			Version_:            m.Version(),
+			LastAdvertisedTime_: m.LastAdvertisedTime(),
+			Config_:             m.Config(),
+			Data_:               m.Data(),
+			Tags_:               tags,
+			Description_:        m.Description(),
+			Unit_:               m.Unit(),
+			Timestamp_:          m.Timestamp(),
+		}
+	}
+	return out
+}
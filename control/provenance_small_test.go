@@ -0,0 +1,56 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProvenanceTags(t *testing.T) {
+	collected := []core.Metric{
+		plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Tags_: map[string]string{"dc": "east"}},
+		plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar")},
+	}
+	Convey("Collected metrics are tagged with the plugin they were collected from", t, func() {
+		c := New(GetDefaultConfig(), ProvenanceTags(true))
+		mts := c.tagProvenance("collector:mock:2", collected)
+		So(len(mts), ShouldEqual, 2)
+		for _, m := range mts {
+			So(m.Tags()[ProvenancePluginTag], ShouldEqual, "mock")
+			So(m.Tags()[CollectedVersionTag], ShouldEqual, "2")
+		}
+		So(mts[0].Tags()["dc"], ShouldEqual, "east")
+		So(collected[0].Tags()[ProvenancePluginTag], ShouldEqual, "")
+	})
+	Convey("Collected metrics are not tagged by default", t, func() {
+		c := New(GetDefaultConfig())
+		mts := c.tagProvenance("collector:mock:2", collected)
+		So(mts[0].Tags()[ProvenancePluginTag], ShouldEqual, "")
+		So(mts[1].Tags(), ShouldBeNil)
+	})
+}
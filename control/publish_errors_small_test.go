@@ -0,0 +1,82 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/client"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// failingClient fails every publish and process with err.
+type failingClient struct {
+	err error
+}
+
+func (c *failingClient) SetKey() error     { return nil }
+func (c *failingClient) Ping() error       { return nil }
+func (c *failingClient) Kill(string) error { return nil }
+func (c *failingClient) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
+	return nil, nil
+}
+func (c *failingClient) Publish(string, []byte, map[string]ctypes.ConfigValue) error {
+	return c.err
+}
+func (c *failingClient) Process(string, []byte, map[string]ctypes.ConfigValue) (string, []byte, error) {
+	return "", nil, c.err
+}
+
+// addFakePlugin runs an instance of the plugin with the type and name using
+// the client.
+func addFakePlugin(c *pluginControl, typ plugin.PluginType, name string, cli client.PluginClient) {
+	ap := newFakeAvailablePlugin(typ, name, 1, cli)
+	addPool(c.pluginRunner.AvailablePlugins(), core.PluginKey(core.PluginType(typ), name, 1), ap)
+}
+
+func TestPublishAndProcessErrors(t *testing.T) {
+	failed := errors.New("plugin failed")
+	Convey("A failing publish is returned by PublishMetrics", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		addFakePlugin(c, plugin.PublisherPluginType, "failing", &failingClient{err: failed})
+		errs := c.PublishMetrics(plugin.SnapGOBContentType, []byte("metrics"), "failing", 1, nil, "task")
+		So(len(errs), ShouldEqual, 1)
+		So(errs[0], ShouldEqual, failed)
+	})
+	Convey("A failing process is returned by ProcessMetrics", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		addFakePlugin(c, plugin.ProcessorPluginType, "failing", &failingClient{err: failed})
+		ct, content, errs := c.ProcessMetrics(plugin.SnapGOBContentType, []byte("metrics"), "failing", 1, nil, "task")
+		So(len(errs), ShouldEqual, 1)
+		So(errs[0], ShouldEqual, failed)
+		So(ct, ShouldEqual, "")
+		So(content, ShouldBeNil)
+	})
+}
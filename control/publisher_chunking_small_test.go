@@ -0,0 +1,94 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeChunkedPublisherClient records whether content was published in one
+// call or in chunks.
+type fakeChunkedPublisherClient struct {
+	published []byte
+	chunkSize int
+}
+
+func (c *fakeChunkedPublisherClient) SetKey() error     { return nil }
+func (c *fakeChunkedPublisherClient) Ping() error       { return nil }
+func (c *fakeChunkedPublisherClient) Kill(string) error { return nil }
+func (c *fakeChunkedPublisherClient) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
+	return nil, nil
+}
+func (c *fakeChunkedPublisherClient) Publish(_ string, content []byte, _ map[string]ctypes.ConfigValue) error {
+	c.published = content
+	return nil
+}
+func (c *fakeChunkedPublisherClient) PublishChunked(_, _ string, content []byte, chunkSize int, _ map[string]ctypes.ConfigValue) (*plugin.PublishAck, error) {
+	c.published = content
+	c.chunkSize = chunkSize
+	return &plugin.PublishAck{Acked: true}, nil
+}
+
+// addFakePublisher runs an instance of a publisher with the name and
+// capabilities using the client.
+func addFakePublisher(c *pluginControl, name string, caps plugin.Capability, cli *fakeChunkedPublisherClient) {
+	ap := newFakeAvailablePlugin(plugin.PublisherPluginType, name, 1, cli)
+	ap.meta = plugin.PluginMeta{Name: name, Version: 1, Capabilities: caps}
+	addPool(c.pluginRunner.AvailablePlugins(), "publisher:"+name+":1", ap)
+}
+
+func TestPublisherChunkSize(t *testing.T) {
+	content := []byte("metrics")
+	Convey("Content is published in chunks to publishers accepting chunked content", t, func() {
+		c := New(GetDefaultConfig(), PublisherChunkSize(3))
+		cli := &fakeChunkedPublisherClient{}
+		addFakePublisher(c, "chunked", plugin.ChunkedContentCapability, cli)
+		ack, errs := c.pluginRunner.AvailablePlugins().publishMetrics("snap.gob", content, "chunked", 1, nil, "task")
+		So(errs, ShouldBeEmpty)
+		So(ack, ShouldNotBeNil)
+		So(cli.chunkSize, ShouldEqual, 3)
+		So(cli.published, ShouldResemble, content)
+	})
+	Convey("Content is published in one call to publishers without the capability", t, func() {
+		c := New(GetDefaultConfig(), PublisherChunkSize(3))
+		cli := &fakeChunkedPublisherClient{}
+		addFakePublisher(c, "single", 0, cli)
+		_, errs := c.pluginRunner.AvailablePlugins().publishMetrics("snap.gob", content, "single", 1, nil, "task")
+		So(errs, ShouldBeEmpty)
+		So(cli.chunkSize, ShouldEqual, 0)
+		So(cli.published, ShouldResemble, content)
+	})
+	Convey("Content is published in one call when chunking is disabled", t, func() {
+		c := New(GetDefaultConfig())
+		cli := &fakeChunkedPublisherClient{}
+		addFakePublisher(c, "chunked", plugin.ChunkedContentCapability, cli)
+		_, errs := c.pluginRunner.AvailablePlugins().publishMetrics("snap.gob", content, "chunked", 1, nil, "task")
+		So(errs, ShouldBeEmpty)
+		So(cli.chunkSize, ShouldEqual, 0)
+	})
+}
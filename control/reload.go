@@ -0,0 +1,170 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrPluginNotReloadable is returned by Reload for plugins uploaded
+	// through the REST API, whose binary is removed when they are unloaded.
+	ErrPluginNotReloadable = errors.New("Only plugins loaded from a path can be reloaded")
+)
+
+// Reload loads the binary at the path the plugin identified by its
+// {type}:{name}:{version} key was loaded from and swaps it in for the plugin
+// as SwapPlugins does, returning the plugin loaded.  A binary rebuilt in place
+// which reports the version of the plugin it replaces is reloaded by
+// unloading the plugin first.  The plugin is loaded back if the binary fails
+// to load or is incompatible with it, and its running instances are
+// restarted once the binary is loaded.
+func (p *pluginControl) Reload(key string) (core.CatalogedPlugin, serror.SnapError) {
+	f := map[string]interface{}{"plugin-key": key}
+	if _, _, _, err := core.ParsePluginKey(key); err != nil {
+		return nil, serror.New(err, f)
+	}
+	out, err := p.pluginManager.get(key)
+	if err != nil {
+		return nil, serror.New(err, f)
+	}
+	if out.Details == nil || !out.Details.IsAutoLoaded {
+		return nil, serror.New(ErrPluginNotReloadable, f)
+	}
+	f["plugin-path"] = out.Details.Path
+	in, err := core.NewRequestedPlugin(out.Details.Path)
+	if err != nil {
+		return nil, serror.New(err, f)
+	}
+	in.SetSignature(out.Details.Signature)
+	details, serr := p.returnPluginDetails(in)
+	if serr != nil {
+		return nil, serr
+	}
+	if details.IsPackage {
+		defer os.RemoveAll(filepath.Dir(details.ExecPath))
+	}
+	meta, _, serr := p.pluginManager.InspectPlugin(details)
+	if serr != nil {
+		return nil, serr
+	}
+	var lp *loadedPlugin
+	if meta.Type == out.Type && meta.Name == out.Name() && meta.Version == out.Version() {
+		lp, serr = p.reloadInPlace(details, out)
+	} else {
+		lp, serr = p.swapPlugins(in, out, false)
+	}
+	if serr != nil {
+		return nil, serr
+	}
+	return lp, nil
+}
+
+// reloadInPlace unloads out and loads the binary of details, which reports
+// the version of out, in its place.  out and its metrics are loaded back if the
+// binary fails to load or is incompatible with out.
+func (p *pluginControl) reloadInPlace(details *pluginDetails, out *loadedPlugin) (*loadedPlugin, serror.SnapError) {
+	if !p.Started {
+		return nil, serror.New(ErrControllerNotStarted)
+	}
+	namespaces := p.pluginNamespaces(out)
+	var metrics []*metricType
+	p.metricCatalog.Walk(func(mt *metricType) bool {
+		if mt.Plugin == out {
+			metrics = append(metrics, mt)
+		}
+		return true
+	})
+	if _, serr := p.pluginManager.UnloadPlugin(out); serr != nil {
+		return nil, serr
+	}
+	lp, serr := p.pluginManager.LoadPlugin(details, p.eventManager)
+	if serr == nil {
+		if serr = p.compatibility(lp, out, namespaces); serr != nil {
+			if _, err := p.pluginManager.UnloadPlugin(lp); err != nil {
+				return nil, serror.New(errors.New("Failed to rollback after error"), map[string]interface{}{
+					"original-unload-error": serr.Error(),
+					"rollback-unload-error": err.Error(),
+				})
+			}
+		}
+	}
+	if serr != nil {
+		if err := p.pluginManager.restorePlugin(out); err != nil {
+			return nil, serror.New(errors.New("Failed to rollback after error"), map[string]interface{}{
+				"original-load-error": serr.Error(),
+				"rollback-load-error": err.Error(),
+			})
+		}
+		for _, mt := range metrics {
+			p.metricCatalog.Add(mt)
+		}
+		return nil, serr
+	}
+	p.restartPool(lp)
+	p.resubscribeSubtrees()
+	p.saveState()
+
+	defer p.eventManager.Emit(&control_event.SwapPluginsEvent{
+		LoadedPluginName:      lp.Meta.Name,
+		LoadedPluginVersion:   lp.Meta.Version,
+		UnloadedPluginName:    out.Meta.Name,
+		UnloadedPluginVersion: out.Meta.Version,
+		PluginType:            int(lp.Meta.Type),
+	})
+	return lp, nil
+}
+
+// restartPool replaces the running instances of the plugin reloaded in place
+// with instances of the plugin loaded.  An instance is left running if its
+// replacement fails to start.
+func (p *pluginControl) restartPool(lp *loadedPlugin) {
+	pool, err := p.pluginRunner.AvailablePlugins().getPool(lp.Key())
+	if err != nil || pool == nil {
+		return
+	}
+	pool.RLock()
+	var running []strategy.AvailablePlugin
+	for _, ap := range pool.Plugins() {
+		running = append(running, ap)
+	}
+	pool.RUnlock()
+	for _, ap := range running {
+		if _, err := p.pluginRunner.spawnPlugin(lp.Details); err != nil {
+			controlLogger.WithFields(log.Fields{
+				"_block":     "reload",
+				"plugin-key": lp.Key(),
+				"error":      err,
+			}).Error("unable to restart reloaded plugin")
+			continue
+		}
+		ap.Stop("plugin reloaded")
+		pool.Kill(ap.ID(), "plugin reloaded")
+	}
+}
@@ -0,0 +1,206 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/intelsdi-x/gomit"
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// rebuiltManager loads the mock collector rebuilt with the version and
+// metric namespaces given, or fails to load it.
+type rebuiltManager struct {
+	*pluginManager
+	version    int
+	namespaces []core.Namespace
+	failLoad   bool
+}
+
+func (m *rebuiltManager) InspectPlugin(*pluginDetails) (*plugin.PluginMeta, []core.Metric, serror.SnapError) {
+	return &plugin.PluginMeta{Name: "mock", Version: m.version, Type: plugin.CollectorPluginType}, nil, nil
+}
+
+func (m *rebuiltManager) LoadPlugin(details *pluginDetails, _ gomit.Emitter) (*loadedPlugin, serror.SnapError) {
+	if m.failLoad {
+		return nil, serror.New(errors.New("load failed"))
+	}
+	lp := newCollector("mock")
+	lp.Meta.Version = m.version
+	lp.State = LoadedState
+	lp.Details = details
+	if err := m.loadedPlugins.add(lp); err != nil {
+		return nil, err
+	}
+	for _, ns := range m.namespaces {
+		mt := plugin.MetricType{Namespace_: ns, Version_: m.version}
+		if err := m.metricCatalog.AddLoadedMetricType(lp, mt); err != nil {
+			return nil, serror.New(err)
+		}
+	}
+	return lp, nil
+}
+
+// reloadableCollector loads the mock collector from a binary on disk, running
+// an instance of it, and returns the plugin, the manager loading its
+// rebuilt binary and the runner of its instances.
+func reloadableCollector(c *pluginControl, dir string) (*loadedPlugin, *rebuiltManager, *spawningRunner) {
+	c.Started = true
+	r := &spawningRunner{runner: c.pluginRunner.(*runner), recorder: &stopRecorder{}}
+	c.pluginRunner = r
+	out := loadRunnablePlugin(c, dir, plugin.CollectorPluginType, "mock")
+	out.Details.IsAutoLoaded = true
+	catalogMetric(c, out)
+	_, err := r.spawnPlugin(out.Details)
+	So(err, ShouldBeNil)
+	pm := c.pluginManager.(*pluginManager)
+	m := &rebuiltManager{pluginManager: pm, version: 1, namespaces: []core.Namespace{core.NewNamespace("intel", "mock", "foo")}}
+	c.pluginManager = m
+	return out, m, r
+}
+
+func catalogedPlugins(c *pluginControl, ns core.Namespace) []*loadedPlugin {
+	mts, err := c.metricCatalog.GetVersions(ns)
+	So(err, ShouldBeNil)
+	plugins := make([]*loadedPlugin, len(mts))
+	for i, mt := range mts {
+		plugins[i] = mt.Plugin
+	}
+	return plugins
+}
+
+func TestReload(t *testing.T) {
+	Convey("Given a loaded collector", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		lp := newCollector("mock")
+		lp.State = LoadedState
+		lp.Details = &pluginDetails{Path: "/nonexistent/snap-collector-mock"}
+		So(c.pluginManager.(*pluginManager).loadedPlugins.add(lp), ShouldBeNil)
+
+		Convey("a malformed key is an error", func() {
+			_, serr := c.Reload("collector:mock")
+			So(serr, ShouldNotBeNil)
+			So(serr.Fields()["plugin-key"], ShouldEqual, "collector:mock")
+		})
+		Convey("a plugin which is not loaded is an error", func() {
+			_, serr := c.Reload("collector:mock:2")
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, ErrPluginNotFound.Error())
+		})
+		Convey("a plugin uploaded through the REST API is not reloaded", func() {
+			_, serr := c.Reload("collector:mock:1")
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, ErrPluginNotReloadable.Error())
+		})
+		Convey("a plugin whose binary is gone is not reloaded", func() {
+			lp.Details.IsAutoLoaded = true
+			_, serr := c.Reload("collector:mock:1")
+			So(serr, ShouldNotBeNil)
+			So(serr.Fields()["plugin-path"], ShouldEqual, "/nonexistent/snap-collector-mock")
+			_, err := c.pluginManager.get("collector:mock:1")
+			So(err, ShouldBeNil)
+		})
+	})
+}
+
+func TestReloadRebuiltPlugin(t *testing.T) {
+	foo := core.NewNamespace("intel", "mock", "foo")
+	Convey("A plugin rebuilt with a new version is swapped in", t, func() {
+		dir, err := ioutil.TempDir("", "snap-reload")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		c := New(GetDefaultConfig())
+		out, m, _ := reloadableCollector(c, dir)
+		m.version = 2
+
+		lp, serr := c.Reload(out.Key())
+		So(serr, ShouldBeNil)
+		So(lp.Version(), ShouldEqual, 2)
+		_, err = c.pluginManager.get(out.Key())
+		So(err, ShouldEqual, ErrPluginNotFound)
+	})
+	Convey("A plugin rebuilt with the same version is reloaded in place", t, func() {
+		dir, err := ioutil.TempDir("", "snap-reload")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		c := New(GetDefaultConfig())
+		out, _, r := reloadableCollector(c, dir)
+
+		lp, serr := c.Reload(out.Key())
+		So(serr, ShouldBeNil)
+		loaded, err := c.pluginManager.get(out.Key())
+		So(err, ShouldBeNil)
+		So(loaded, ShouldEqual, lp)
+		So(loaded, ShouldNotEqual, out)
+		So(catalogedPlugins(c, foo), ShouldResemble, []*loadedPlugin{loaded})
+		// the instance of the previous binary is replaced
+		So(r.recorder.stopped, ShouldResemble, []string{"mock"})
+		pool, err := c.pluginRunner.AvailablePlugins().getPool(out.Key())
+		So(err, ShouldBeNil)
+		So(pool.Count(), ShouldEqual, 1)
+	})
+	Convey("A plugin rebuilt with the same version which fails to load is loaded back", t, func() {
+		dir, err := ioutil.TempDir("", "snap-reload")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		c := New(GetDefaultConfig())
+		out, m, r := reloadableCollector(c, dir)
+		m.failLoad = true
+
+		_, serr := c.Reload(out.Key())
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, "load failed")
+		loaded, err := c.pluginManager.get(out.Key())
+		So(err, ShouldBeNil)
+		So(loaded, ShouldEqual, out)
+		So(catalogedPlugins(c, foo), ShouldResemble, []*loadedPlugin{out})
+		So(r.recorder.stopped, ShouldBeEmpty)
+	})
+	Convey("A plugin rebuilt with the same version without its metrics is loaded back", t, func() {
+		dir, err := ioutil.TempDir("", "snap-reload")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		c := New(GetDefaultConfig())
+		out, m, _ := reloadableCollector(c, dir)
+		m.namespaces = []core.Namespace{core.NewNamespace("intel", "mock", "bar")}
+
+		_, serr := c.Reload(out.Key())
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, ErrSwapIncompatible.Error())
+		So(serr.Fields()["missing-namespaces"], ShouldResemble, []string{"/intel/mock/foo"})
+		loaded, err := c.pluginManager.get(out.Key())
+		So(err, ShouldBeNil)
+		So(loaded, ShouldEqual, out)
+		So(catalogedPlugins(c, foo), ShouldResemble, []*loadedPlugin{out})
+		_, err = c.metricCatalog.GetVersions(core.NewNamespace("intel", "mock", "bar"))
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/intelsdi-x/gomit"
 
 	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/client"
 	"github.com/intelsdi-x/snap/control/strategy"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/control_event"
@@ -74,12 +75,19 @@ type runner struct {
 	availablePlugins *availablePlugins
 	metricCatalog    catalogsMetrics
 	pluginManager    managesPlugins
+	clientTimeouts   client.Timeouts
+	// maxCollectResponseBytes caps the size of the collect responses read
+	// by the clients of collectors
+	maxCollectResponseBytes int
+	restarts                *deadPluginRestarts
 }
 
 func newRunner() *runner {
 	r := &runner{
 		monitor:          newMonitor(),
 		availablePlugins: newAvailablePlugins(),
+		clientTimeouts:   client.Timeouts{Connect: DefaultClientTimeout},
+		restarts:         newDeadPluginRestarts(),
 	}
 	return r
 }
@@ -90,12 +98,25 @@ func (r *runner) SetMetricCatalog(c catalogsMetrics) {
 
 func (r *runner) SetEmitter(e gomit.Emitter) {
 	r.emitter = e
+	r.monitor.emitter = e
 }
 
 func (r *runner) SetPluginManager(m managesPlugins) {
 	r.pluginManager = m
 }
 
+// SetClientTimeouts sets the timeouts of the clients calling plugins started
+// after this call.
+func (r *runner) SetClientTimeouts(t client.Timeouts) {
+	r.clientTimeouts = t
+}
+
+// SetMaxCollectResponseBytes sets the max size in bytes of the collect
+// responses read by the clients of plugins started after this call.
+func (r *runner) SetMaxCollectResponseBytes(max int) {
+	r.maxCollectResponseBytes = max
+}
+
 func (r *runner) AvailablePlugins() *availablePlugins {
 	return r.availablePlugins
 }
@@ -129,6 +150,7 @@ func (r *runner) Start() error {
 
 	// Start the monitor
 	r.monitor.Start(r.availablePlugins)
+	r.restarts.start()
 	runnerLog.WithFields(log.Fields{
 		"_block": "start",
 	}).Debug("started")
@@ -141,6 +163,7 @@ func (r *runner) Stop() []error {
 
 	// Stop the monitor
 	r.monitor.Stop()
+	r.restarts.stop()
 
 	// TODO: Actually stop the plugins
 
@@ -198,10 +221,13 @@ func (r *runner) startPlugin(p executablePlugin) (*availablePlugin, error) {
 	}
 
 	// build availablePlugin
-	ap, err := newAvailablePlugin(resp, r.emitter, p)
+	ap, err := newAvailablePlugin(resp, r.emitter, p, r.clientTimeouts)
 	if err != nil {
 		return nil, err
 	}
+	if c, ok := ap.client.(client.PluginCollectResponseCappedClient); ok {
+		c.SetMaxCollectResponseBytes(r.maxCollectResponseBytes)
+	}
 
 	if resp.Meta.Unsecure {
 		err = ap.client.Ping()
@@ -258,31 +284,13 @@ func (r *runner) HandleGomitEvent(e gomit.Event) {
 		}
 
 		if pool.Eligible() {
+			if r.restarts.healthySince(v.Key, r.monitor.restartResetPeriod) {
+				pool.ResetRestartCount()
+			}
 			if pool.RestartCount() < MaxPluginRestartCount {
-				e := r.restartPlugin(v.Key)
-				if e != nil {
-					runnerLog.WithFields(log.Fields{
-						"_block":  "handle-events",
-						"aplugin": v.String,
-					}).Error(e.Error())
-					return
+				if r.restarts.begin(v.Key) {
+					go r.restartDeadPlugin(v, pool, r.monitor.restartDelay(pool.RestartCount()))
 				}
-				pool.IncRestartCount()
-
-				runnerLog.WithFields(log.Fields{
-					"_block":        "handle-events",
-					"event":         v.Name,
-					"aplugin":       v.Version,
-					"restart_count": pool.RestartCount(),
-				}).Warning("plugin restarted")
-
-				r.emitter.Emit(&control_event.RestartedAvailablePluginEvent{
-					Id:      v.Id,
-					Name:    v.Name,
-					Version: v.Version,
-					Key:     v.Key,
-					Type:    v.Type,
-				})
 			} else {
 				r.emitter.Emit(&control_event.MaxPluginRestartsExceededEvent{
 					Id:      v.Id,
@@ -302,7 +310,7 @@ func (r *runner) HandleGomitEvent(e gomit.Event) {
 			"plugin-type":    core.PluginType(v.PluginType).String(),
 		}).Debug("handling plugin unsubscription event")
 
-		err := r.handleUnsubscription(core.PluginType(v.PluginType).String(), v.PluginName, v.PluginVersion, v.TaskId)
+		err := r.handleUnsubscription(core.PluginType(v.PluginType), v.PluginName, v.PluginVersion, v.TaskId)
 		if err != nil {
 			return
 		}
@@ -326,7 +334,7 @@ func (r *runner) HandleGomitEvent(e gomit.Event) {
 		}
 		// Check for the highest lower version plugin and move subscriptions that
 		// are not bound to a plugin version to this pool.
-		plugin, err := r.pluginManager.get(fmt.Sprintf("%s:%s:%d", core.PluginType(v.Type).String(), v.Name, -1))
+		plugin, err := r.pluginManager.get(core.PluginKey(core.PluginType(v.Type), v.Name, -1))
 		if err != nil {
 			return
 		}
@@ -435,7 +443,7 @@ func (r *runner) HandleGomitEvent(e gomit.Event) {
 			}).Info("No previous pool found for loaded plugin")
 			return
 		}
-		plugin, err := r.pluginManager.get(fmt.Sprintf("%s:%s:%d", core.PluginType(v.Type).String(), v.Name, v.Version))
+		plugin, err := r.pluginManager.get(core.PluginKey(core.PluginType(v.Type), v.Name, v.Version))
 		if err != nil {
 			return
 		}
@@ -502,26 +510,34 @@ func (r *runner) HandleGomitEvent(e gomit.Event) {
 }
 
 func (r *runner) runPlugin(details *pluginDetails) error {
+	_, err := r.spawnPlugin(details)
+	return err
+}
+
+// spawnPlugin starts a new instance of the plugin and returns the available
+// plugin added to its pool.
+func (r *runner) spawnPlugin(details *pluginDetails) (*availablePlugin, error) {
 	if details.IsPackage {
 		f, err := os.Open(details.Path)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		defer f.Close()
 		tempPath, err := aci.Extract(f)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		details.ExecPath = path.Join(tempPath, "rootfs")
 	}
-	ePlugin, err := plugin.NewExecutablePlugin(r.pluginManager.GenerateArgs(details.Exec), path.Join(details.ExecPath, details.Exec))
+	ePlugin, err := r.pluginManager.newExecutablePlugin(details)
 	if err != nil {
 		runnerLog.WithFields(log.Fields{
 			"_block": "run-plugin",
 			"path":   path.Join(details.ExecPath, details.Exec),
 			"error":  err,
 		}).Error("error creating executable plugin")
-		return err
+		r.availablePlugins.telemetry.spawnFailed()
+		return nil, err
 	}
 	ap, err := r.startPlugin(ePlugin)
 	if err != nil {
@@ -530,24 +546,25 @@ func (r *runner) runPlugin(details *pluginDetails) error {
 			"path":   path.Join(details.ExecPath, details.Exec),
 			"error":  err,
 		}).Error("error starting new plugin")
-		return err
+		r.availablePlugins.telemetry.spawnFailed()
+		return nil, err
 	}
 	ap.exec = details.Exec
 	ap.execPath = details.ExecPath
 	if details.IsPackage {
 		ap.fromPackage = true
 	}
-	return nil
+	return ap, nil
 }
 
-func (r *runner) handleUnsubscription(pType, pName string, pVersion int, taskID string) error {
-	pool, err := r.availablePlugins.getPool(fmt.Sprintf("%s:%s:%d", pType, pName, pVersion))
+func (r *runner) handleUnsubscription(pType core.PluginType, pName string, pVersion int, taskID string) error {
+	pool, err := r.availablePlugins.getPool(core.PluginKey(pType, pName, pVersion))
 	if err != nil {
 		runnerLog.WithFields(log.Fields{
 			"_block":         "handle-unsubscription",
 			"plugin-name":    pName,
 			"plugin-version": pVersion,
-			"plugin-type":    pType,
+			"plugin-type":    pType.String(),
 		}).Error("error retrieving pool")
 		return errors.New("error retrieving pool")
 	}
@@ -556,7 +573,7 @@ func (r *runner) handleUnsubscription(pType, pName string, pVersion int, taskID
 			"_block":         "handle-unsubscription",
 			"plugin-name":    pName,
 			"plugin-version": pVersion,
-			"plugin-type":    pType,
+			"plugin-type":    pType.String(),
 		}).Error("pool not found")
 		return errors.New("pool not found")
 	}
@@ -565,12 +582,57 @@ func (r *runner) handleUnsubscription(pType, pName string, pVersion int, taskID
 			"_block":                  "handle-unsubscription",
 			"pool-count":              pool.Count(),
 			"pool-subscription-count": pool.SubscriptionCount(),
-		}).Debug(fmt.Sprintf("killing an available plugin in pool  %s:%s:%d", pType, pName, pVersion))
+		}).Debug(fmt.Sprintf("killing an available plugin in pool  %s", core.PluginKey(pType, pName, pVersion)))
 		pool.SelectAndKill(taskID, "unsubscription event")
 	}
 	return nil
 }
 
+// restartDeadPlugin starts a plugin in the pool of the dead available plugin
+// after the backoff.  The plugin is not restarted if the pool no longer needs
+// it when the backoff has passed, or if the runner is stopped first.
+func (r *runner) restartDeadPlugin(v *control_event.DeadAvailablePluginEvent, pool strategy.Pool, backoff time.Duration) {
+	restarted := false
+	defer func() { r.restarts.end(v.Key, restarted) }()
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-r.restarts.stopped():
+		return
+	}
+	if !pool.Eligible() {
+		return
+	}
+	if err := r.restartPlugin(v.Key); err != nil {
+		runnerLog.WithFields(log.Fields{
+			"_block":  "handle-events",
+			"aplugin": v.String,
+		}).Error(err.Error())
+		return
+	}
+	restarted = true
+	pool.IncRestartCount()
+
+	runnerLog.WithFields(log.Fields{
+		"_block":        "handle-events",
+		"event":         v.Name,
+		"aplugin":       v.Version,
+		"restart_count": pool.RestartCount(),
+		"backoff":       backoff,
+	}).Warning("plugin restarted")
+
+	r.emitter.Emit(&control_event.RestartedAvailablePluginEvent{
+		Id:           v.Id,
+		Name:         v.Name,
+		Version:      v.Version,
+		Key:          v.Key,
+		Type:         v.Type,
+		RestartCount: pool.RestartCount(),
+		Backoff:      backoff,
+	})
+}
+
 func (r *runner) restartPlugin(key string) error {
 	lp, err := r.pluginManager.get(key)
 	if err != nil {
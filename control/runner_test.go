@@ -411,7 +411,7 @@ func TestRunnerPluginRunning(t *testing.T) {
 						So(e, ShouldBeNil)
 						ap.client = new(MockHealthyPluginCollectorClient)
 						ap.CheckHealth()
-						So(ap.failedHealthChecks, ShouldEqual, 0)
+						So(ap.healthCheckFailures(), ShouldEqual, 0)
 					})
 
 					Convey("healthcheck on unhealthy plugin increments failedHealthChecks", func() {
@@ -430,7 +430,7 @@ func TestRunnerPluginRunning(t *testing.T) {
 						So(e, ShouldBeNil)
 						ap.client = new(MockUnhealthyPluginCollectorClient)
 						ap.CheckHealth()
-						So(ap.failedHealthChecks, ShouldEqual, 1)
+						So(ap.healthCheckFailures(), ShouldEqual, 1)
 					})
 
 					Convey("successful healthcheck resets failedHealthChecks", func() {
@@ -450,10 +450,10 @@ func TestRunnerPluginRunning(t *testing.T) {
 						ap.client = new(MockUnhealthyPluginCollectorClient)
 						ap.CheckHealth()
 						ap.CheckHealth()
-						So(ap.failedHealthChecks, ShouldEqual, 2)
+						So(ap.healthCheckFailures(), ShouldEqual, 2)
 						ap.client = new(MockHealthyPluginCollectorClient)
 						ap.CheckHealth()
-						So(ap.failedHealthChecks, ShouldEqual, 0)
+						So(ap.healthCheckFailures(), ShouldEqual, 0)
 					})
 
 					Convey("three consecutive failedHealthChecks disables the plugin", func() {
@@ -474,7 +474,7 @@ func TestRunnerPluginRunning(t *testing.T) {
 						ap.CheckHealth()
 						ap.CheckHealth()
 						ap.CheckHealth()
-						So(ap.failedHealthChecks, ShouldEqual, 3)
+						So(ap.healthCheckFailures(), ShouldEqual, 3)
 					})
 
 					Convey("should return error for WaitForResponse error", func() {
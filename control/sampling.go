@@ -0,0 +1,81 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"hash/fnv"
+	"math"
+
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// SampleRateConfigKey is the metric config key holding the fraction, between
+// 0 and 1, of the metrics returned for the subscription which are kept.
+// Metrics are sampled by namespace so the same metrics are kept on every
+// collection.
+const SampleRateConfigKey = "snap.sample_rate"
+
+// sampleRatePolicy validates the sample rate in a metric's config.
+var sampleRatePolicy = newSampleRatePolicy()
+
+func newSampleRatePolicy() *cpolicy.ConfigPolicyNode {
+	rule, _ := cpolicy.NewFloatRule(SampleRateConfigKey, false)
+	rule.SetMinimum(0)
+	rule.SetMaximum(1)
+	node := cpolicy.NewPolicyNode()
+	node.Add(rule)
+	return node
+}
+
+// sampleMetrics drops the metrics not selected by the sample rate in their
+// config.
+func sampleMetrics(mts []core.Metric) []core.Metric {
+	sampled := mts[:0]
+	for _, m := range mts {
+		if rate, ok := sampleRate(m); !ok || keepSample(m.Namespace().String(), rate) {
+			sampled = append(sampled, m)
+		}
+	}
+	return sampled
+}
+
+// sampleRate returns the sample rate in the metric's config.
+func sampleRate(m core.Metric) (float64, bool) {
+	if m.Config() == nil {
+		return 0, false
+	}
+	v, ok := m.Config().Table()[SampleRateConfigKey].(ctypes.ConfigValueFloat)
+	if !ok {
+		return 0, false
+	}
+	return v.Value, true
+}
+
+// keepSample deterministically selects the fraction rate of namespaces.
+func keepSample(ns string, rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(ns))
+	return float64(h.Sum32())/float64(math.MaxUint32) < rate
+}
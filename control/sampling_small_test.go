@@ -0,0 +1,67 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSampleMetrics(t *testing.T) {
+	Convey("Given metrics collected with a sample rate", t, func() {
+		cfg := cdata.NewNode()
+		cfg.AddItem(SampleRateConfigKey, ctypes.ConfigValueFloat{Value: 0.5})
+		var mts []core.Metric
+		for i := 0; i < 1000; i++ {
+			mts = append(mts, plugin.MetricType{
+				Namespace_: core.NewNamespace("intel", "mock", fmt.Sprintf("series%d", i)),
+				Config_:    cfg,
+			})
+		}
+		unsampled := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "unsampled")}
+
+		Convey("about the fraction of metrics is kept", func() {
+			sampled := sampleMetrics(append([]core.Metric{}, mts...))
+			So(len(sampled), ShouldBeBetween, 400, 600)
+		})
+		Convey("the same metrics are kept on every collection", func() {
+			first := sampleMetrics(append([]core.Metric{}, mts...))
+			second := sampleMetrics(append([]core.Metric{}, mts...))
+			So(second, ShouldResemble, first)
+		})
+		Convey("metrics without a sample rate are kept", func() {
+			So(len(sampleMetrics([]core.Metric{unsampled})), ShouldEqual, 1)
+		})
+		Convey("a sample rate above 1 is rejected", func() {
+			table := map[string]ctypes.ConfigValue{SampleRateConfigKey: ctypes.ConfigValueFloat{Value: 2}}
+			_, errs := sampleRatePolicy.Process(table)
+			So(errs.HasErrors(), ShouldBeTrue)
+		})
+	})
+}
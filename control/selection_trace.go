@@ -0,0 +1,164 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+)
+
+// DefaultSelectionTraceSize is the number of recent plugin selections kept
+// when selection tracing is enabled
+var DefaultSelectionTraceSize = 100
+
+// SelectionTrace records the plugin a pool's routing strategy selected to
+// serve a call.
+type SelectionTrace struct {
+	Time time.Time
+	// PluginKey is the {type}:{name}:{version} key of the pool
+	PluginKey string
+	TaskID    string
+	// Strategy is the name of the routing strategy which made the selection
+	Strategy string
+	// Candidates are the running plugins the strategy selected from
+	Candidates []SelectionCandidate
+	// Selected is the ID of the selected plugin
+	Selected uint32
+}
+
+// SelectionCandidate is a running plugin a routing strategy could select,
+// with its stats at the time of the selection.
+type SelectionCandidate struct {
+	ID       uint32
+	HitCount int
+	LastHit  time.Time
+	// ActiveCalls is the number of calls the plugin was serving
+	ActiveCalls int
+}
+
+type byCandidateID []SelectionCandidate
+
+func (b byCandidateID) Len() int           { return len(b) }
+func (b byCandidateID) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byCandidateID) Less(i, j int) bool { return b[i].ID < b[j].ID }
+
+// SelectionTracing is the PluginControlOpt which records the plugin selected
+// by the routing strategy for each call to a plugin pool.  The most recent
+// selections are returned by RecentSelections.  Selections are not traced by
+// default.
+func SelectionTracing(enabled bool) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.pluginRunner.AvailablePlugins().selectionTrace = nil
+		if enabled {
+			c.pluginRunner.AvailablePlugins().selectionTrace = newSelectionTraceBuffer(DefaultSelectionTraceSize)
+		}
+	}
+}
+
+// RecentSelections returns the most recent plugin selections, oldest first.
+// No selections are returned unless the SelectionTracing option is enabled.
+func (p *pluginControl) RecentSelections() []SelectionTrace {
+	t := p.pluginRunner.AvailablePlugins().selectionTrace
+	if t == nil {
+		return nil
+	}
+	return t.recent()
+}
+
+// selectionTraceBuffer keeps the most recent selection traces in a ring
+// buffer.
+type selectionTraceBuffer struct {
+	sync.RWMutex
+	traces []SelectionTrace
+	next   int
+	full   bool
+}
+
+func newSelectionTraceBuffer(size int) *selectionTraceBuffer {
+	return &selectionTraceBuffer{
+		traces: make([]SelectionTrace, size),
+	}
+}
+
+// record adds the trace, replacing the oldest trace once the buffer is full.
+func (b *selectionTraceBuffer) record(t SelectionTrace) {
+	b.Lock()
+	defer b.Unlock()
+	if len(b.traces) == 0 {
+		return
+	}
+	b.traces[b.next] = t
+	b.next = (b.next + 1) % len(b.traces)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// recent returns the buffered traces, oldest first.
+func (b *selectionTraceBuffer) recent() []SelectionTrace {
+	b.RLock()
+	defer b.RUnlock()
+	traces := append([]SelectionTrace{}, b.traces[:b.next]...)
+	if b.full {
+		traces = append(append([]SelectionTrace{}, b.traces[b.next:]...), traces...)
+	}
+	return traces
+}
+
+// traceSelection records the selection of the plugin reserved from the pool,
+// along with the candidates it was selected from.  The pool must be read
+// locked by the caller.
+func (ap *availablePlugins) traceSelection(pool strategy.Pool, key, taskID string, reserved *availablePlugin) {
+	t := SelectionTrace{
+		Time:      time.Now(),
+		PluginKey: key,
+		TaskID:    taskID,
+		Strategy:  pool.Strategy().String(),
+		Selected:  reserved.ID(),
+	}
+	for _, p := range pool.Plugins() {
+		c := SelectionCandidate{
+			ID:       p.ID(),
+			HitCount: p.HitCount(),
+			LastHit:  p.LastHit(),
+		}
+		if a, ok := p.(*availablePlugin); ok {
+			c.ActiveCalls = int(atomic.LoadInt32(&a.active))
+		}
+		t.Candidates = append(t.Candidates, c)
+	}
+	sort.Sort(byCandidateID(t.Candidates))
+	ap.selectionTrace.record(t)
+	log.WithFields(log.Fields{
+		"_module":    "control",
+		"_block":     "select-ap",
+		"pool-key":   key,
+		"task-id":    taskID,
+		"strategy":   t.Strategy,
+		"candidates": len(t.Candidates),
+		"selected":   t.Selected,
+	}).Debug("plugin selected")
+}
@@ -0,0 +1,69 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSelectionTrace(t *testing.T) {
+	Convey("Given a pool of two plugins", t, func() {
+		a := &availablePlugin{name: "mock", version: 1}
+		b := &availablePlugin{name: "mock", version: 1}
+		pool := newTestPool("collector:mock:1", a, b)
+
+		Convey("selections are recorded when tracing is enabled", func() {
+			c := New(GetDefaultConfig(), SelectionTracing(true))
+			aps := c.pluginRunner.AvailablePlugins()
+			selected, serr := aps.selectAP(pool, "collector:mock:1", "task", nil)
+			So(serr, ShouldBeNil)
+			traces := c.RecentSelections()
+			So(len(traces), ShouldEqual, 1)
+			So(traces[0].PluginKey, ShouldEqual, "collector:mock:1")
+			So(traces[0].TaskID, ShouldEqual, "task")
+			So(traces[0].Strategy, ShouldEqual, pool.Strategy().String())
+			So(traces[0].Selected, ShouldEqual, selected.ID())
+			So(len(traces[0].Candidates), ShouldEqual, 2)
+			So(traces[0].Candidates[0].ID, ShouldBeLessThan, traces[0].Candidates[1].ID)
+			selected.release()
+		})
+		Convey("selections are not recorded by default", func() {
+			c := New(GetDefaultConfig())
+			selected, serr := c.pluginRunner.AvailablePlugins().selectAP(pool, "collector:mock:1", "task", nil)
+			So(serr, ShouldBeNil)
+			So(c.RecentSelections(), ShouldBeEmpty)
+			selected.release()
+		})
+	})
+	Convey("The selection trace buffer keeps the most recent traces", t, func() {
+		b := newSelectionTraceBuffer(2)
+		for _, task := range []string{"a", "b", "c"} {
+			b.record(SelectionTrace{TaskID: task})
+		}
+		traces := b.recent()
+		So(len(traces), ShouldEqual, 2)
+		So(traces[0].TaskID, ShouldEqual, "b")
+		So(traces[1].TaskID, ShouldEqual, "c")
+	})
+}
@@ -0,0 +1,89 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+)
+
+// DefaultShutdownOrder is the order Stop stops running plugins in by type:
+// publishers, then processors, then collectors.
+var DefaultShutdownOrder = []core.PluginType{
+	core.PublisherPluginType,
+	core.ProcessorPluginType,
+	core.CollectorPluginType,
+}
+
+// ShutdownOrder is the PluginControlOpt which sets the order Stop stops
+// running plugins in by type, for topologies where DefaultShutdownOrder does
+// not suit.  Every running plugin of a type is stopped before any plugin of
+// the next type, and plugins of types missing from the order are stopped
+// last.
+func ShutdownOrder(order ...core.PluginType) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.shutdownOrder = order
+	}
+}
+
+// stopRunningPlugins stops the running plugins in the shutdown order and
+// removes them from their pools.
+func (p *pluginControl) stopRunningPlugins() {
+	order := p.shutdownOrder
+	if order == nil {
+		order = DefaultShutdownOrder
+	}
+	byType := map[core.PluginType][]strategy.Pool{}
+	for key, pool := range p.pluginRunner.AvailablePlugins().pools() {
+		typ, _, _, err := core.ParsePluginKey(key)
+		if err != nil {
+			continue
+		}
+		byType[typ] = append(byType[typ], pool)
+	}
+	for _, typ := range order {
+		stopPools(byType[typ])
+		delete(byType, typ)
+	}
+	for _, pools := range byType {
+		stopPools(pools)
+	}
+}
+
+// stopPools asks each plugin in the pools to stop before killing it.
+func stopPools(pools []strategy.Pool) {
+	for _, pool := range pools {
+		pool.RLock()
+		aps := pool.Plugins().Values()
+		pool.RUnlock()
+		for _, ap := range aps {
+			if err := ap.Stop("control stopped"); err != nil {
+				controlLogger.WithFields(log.Fields{
+					"_block": "stop",
+					"plugin": ap.String(),
+					"error":  err.Error(),
+				}).Debug("error stopping plugin")
+			}
+			pool.Kill(ap.ID(), "control stopped")
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// stopRecorder records the order plugins are asked to stop in.
+type stopRecorder struct {
+	stopped []string
+}
+
+type recordingClient struct {
+	name     string
+	recorder *stopRecorder
+}
+
+func (c *recordingClient) SetKey() error { return nil }
+func (c *recordingClient) Ping() error   { return nil }
+func (c *recordingClient) Kill(string) error {
+	c.recorder.stopped = append(c.recorder.stopped, c.name)
+	return nil
+}
+func (c *recordingClient) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) { return nil, nil }
+
+func addRecordedPool(c *pluginControl, r *stopRecorder, typ plugin.PluginType, name string) {
+	ap := newFakeAvailablePlugin(typ, name, 1, &recordingClient{name: name, recorder: r})
+	addPool(c.pluginRunner.AvailablePlugins(), core.PluginKey(core.PluginType(typ), name, 1), ap)
+}
+
+func TestShutdownOrder(t *testing.T) {
+	Convey("Running plugins are stopped publishers, processors then collectors", t, func() {
+		c := New(GetDefaultConfig())
+		r := &stopRecorder{}
+		addRecordedPool(c, r, plugin.CollectorPluginType, "col")
+		addRecordedPool(c, r, plugin.PublisherPluginType, "pub")
+		addRecordedPool(c, r, plugin.ProcessorPluginType, "proc")
+		c.stopRunningPlugins()
+		So(r.stopped, ShouldResemble, []string{"pub", "proc", "col"})
+		for _, pool := range c.pluginRunner.AvailablePlugins().pools() {
+			So(pool.Count(), ShouldEqual, 0)
+		}
+	})
+	Convey("The shutdown order can be overridden", t, func() {
+		c := New(GetDefaultConfig(), ShutdownOrder(core.CollectorPluginType))
+		r := &stopRecorder{}
+		addRecordedPool(c, r, plugin.PublisherPluginType, "pub")
+		addRecordedPool(c, r, plugin.CollectorPluginType, "col")
+		c.stopRunningPlugins()
+		So(r.stopped, ShouldResemble, []string{"col", "pub"})
+	})
+}
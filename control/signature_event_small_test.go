@@ -0,0 +1,153 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeSigningManager fails validation with err.
+type fakeSigningManager struct {
+	err error
+}
+
+func (s *fakeSigningManager) ValidateSignature([]string, string, []byte) error {
+	return s.err
+}
+
+// signatureControl returns a control with the plugin trust level whose
+// signatures fail validation with err.
+func signatureControl(trust int, err error) *pluginControl {
+	c := New(GetDefaultConfig())
+	c.signingManager = &fakeSigningManager{err: err}
+	c.SetPluginTrustLevel(trust)
+	c.SetKeyringFile("/etc/snap/keyring.gpg")
+	return c
+}
+
+func signedPlugin(signature []byte) *core.RequestedPlugin {
+	rp := &core.RequestedPlugin{}
+	rp.SetPath("/opt/snap/plugins/snap-plugin-collector-mock1")
+	rp.SetSignature(signature)
+	return rp
+}
+
+func TestSignatureOutcome(t *testing.T) {
+	Convey("A signature which validates is valid", t, func() {
+		c := signatureControl(PluginTrustEnabled, nil)
+		outcome, err := c.signatureOutcome(signedPlugin([]byte("sig")))
+		So(err, ShouldBeNil)
+		So(outcome, ShouldEqual, control_event.SignatureValid)
+	})
+	Convey("A signature which does not validate is invalid", t, func() {
+		c := signatureControl(PluginTrustWarn, errors.New("bad signature"))
+		outcome, err := c.signatureOutcome(signedPlugin([]byte("sig")))
+		So(err, ShouldNotBeNil)
+		So(outcome, ShouldEqual, control_event.SignatureInvalid)
+	})
+	Convey("Validation is skipped when plugin trust is disabled", t, func() {
+		c := signatureControl(PluginTrustDisabled, errors.New("bad signature"))
+		outcome, err := c.signatureOutcome(signedPlugin([]byte("sig")))
+		So(err, ShouldBeNil)
+		So(outcome, ShouldEqual, control_event.SignatureSkipped)
+	})
+	Convey("Validation of an unsigned plugin is skipped in warn mode", t, func() {
+		c := signatureControl(PluginTrustWarn, errors.New("bad signature"))
+		outcome, err := c.signatureOutcome(signedPlugin(nil))
+		So(err, ShouldBeNil)
+		So(outcome, ShouldEqual, control_event.SignatureSkipped)
+	})
+	Convey("The event records the trust decision", t, func() {
+		c := signatureControl(PluginTrustWarn, nil)
+		e := c.signatureValidationEvent("/opt/snap/plugins/mock", control_event.SignatureInvalid, errors.New("bad signature"))
+		So(e.Path, ShouldEqual, "/opt/snap/plugins/mock")
+		So(e.Outcome, ShouldEqual, control_event.SignatureInvalid)
+		So(e.Keyrings, ShouldResemble, []string{"/etc/snap/keyring.gpg"})
+		So(e.TrustLevel, ShouldEqual, PluginTrustWarn)
+		So(e.Error, ShouldEqual, "bad signature")
+	})
+}
+
+// loadSignedPlugin loads a plugin which was signed when loaded.
+func loadSignedPlugin(c *pluginControl) *loadedPlugin {
+	lp := &loadedPlugin{
+		Type:  plugin.CollectorPluginType,
+		Meta:  plugin.PluginMeta{Name: "mock", Version: 1},
+		State: LoadedState,
+		Details: &pluginDetails{
+			Path:         "/opt/snap/plugins/snap-plugin-collector-mock1",
+			Signature:    []byte("sig"),
+			Signed:       true,
+			IsAutoLoaded: true,
+		},
+		ConfigPolicy: cpolicy.New(),
+	}
+	So(c.pluginManager.(*pluginManager).loadedPlugins.add(lp), ShouldBeNil)
+	return lp
+}
+
+func TestRevalidateSignatures(t *testing.T) {
+	Convey("A plugin whose signature still validates stays signed", t, func() {
+		c := signatureControl(PluginTrustEnabled, nil)
+		c.Started = true
+		lp := loadSignedPlugin(c)
+		So(c.RevalidateSignatures(true), ShouldBeEmpty)
+		So(lp.IsSigned(), ShouldBeTrue)
+	})
+	Convey("A plugin whose signature no longer validates is marked unsigned and kept loaded", t, func() {
+		c := signatureControl(PluginTrustEnabled, errors.New("bad signature"))
+		c.Started = true
+		lp := loadSignedPlugin(c)
+		serrs := c.RevalidateSignatures(false)
+		So(serrs, ShouldHaveLength, 1)
+		So(serrs[0].Fields()["plugin-name"], ShouldEqual, "mock")
+		So(lp.IsSigned(), ShouldBeFalse)
+		_, err := c.pluginManager.get(lp.Key())
+		So(err, ShouldBeNil)
+	})
+	Convey("A plugin whose signature no longer validates is unloaded when untrusted plugins are unloaded", t, func() {
+		c := signatureControl(PluginTrustEnabled, errors.New("bad signature"))
+		c.Started = true
+		lp := loadSignedPlugin(c)
+		serrs := c.RevalidateSignatures(true)
+		So(serrs, ShouldHaveLength, 1)
+		So(lp.IsSigned(), ShouldBeFalse)
+		_, err := c.pluginManager.get(lp.Key())
+		So(err, ShouldNotBeNil)
+	})
+	Convey("A plugin is kept loaded when plugin trust is only warned about", t, func() {
+		c := signatureControl(PluginTrustWarn, errors.New("bad signature"))
+		c.Started = true
+		lp := loadSignedPlugin(c)
+		So(c.RevalidateSignatures(true), ShouldHaveLength, 1)
+		_, err := c.pluginManager.get(lp.Key())
+		So(err, ShouldBeNil)
+	})
+}
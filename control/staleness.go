@@ -0,0 +1,92 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sync"
+	"time"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/control_event"
+)
+
+// stalenessTracker records when each metric collected for a task was last
+// returned so metrics which stop being returned can be reported stale.
+type stalenessTracker struct {
+	sync.Mutex
+	window time.Duration
+	// tasks maps a task ID to the metrics collected for it
+	tasks map[string]map[string]*seenMetric
+}
+
+type seenMetric struct {
+	lastSeen time.Time
+	stale    bool
+}
+
+func newStalenessTracker(window time.Duration) *stalenessTracker {
+	return &stalenessTracker{
+		window: window,
+		tasks:  make(map[string]map[string]*seenMetric),
+	}
+}
+
+// collected records the metrics returned by a collection for the task and
+// returns an event for each previously returned metric which has now not
+// been returned within the staleness window.  A metric is reported stale
+// once until it is returned again.
+func (s *stalenessTracker) collected(taskID string, mts []core.Metric, now time.Time) []*control_event.MetricStaleEvent {
+	s.Lock()
+	defer s.Unlock()
+	seen, ok := s.tasks[taskID]
+	if !ok {
+		seen = make(map[string]*seenMetric)
+		s.tasks[taskID] = seen
+	}
+	for _, mt := range mts {
+		ns := mt.Namespace().String()
+		if sm, ok := seen[ns]; ok {
+			sm.lastSeen = now
+			sm.stale = false
+			continue
+		}
+		seen[ns] = &seenMetric{lastSeen: now}
+	}
+	var events []*control_event.MetricStaleEvent
+	for ns, sm := range seen {
+		if sm.stale || now.Sub(sm.lastSeen) <= s.window {
+			continue
+		}
+		sm.stale = true
+		events = append(events, &control_event.MetricStaleEvent{
+			TaskId:          taskID,
+			MetricNamespace: ns,
+			LastSeen:        sm.lastSeen,
+		})
+	}
+	return events
+}
+
+// forget stops tracking the metrics collected for the task.
+func (s *stalenessTracker) forget(taskID string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.tasks, taskID)
+}
@@ -0,0 +1,56 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStalenessTracker(t *testing.T) {
+	Convey("Given metrics collected for a task", t, func() {
+		now := time.Now()
+		sda := plugin.MetricType{Namespace_: core.NewNamespace("intel", "disk", "sda")}
+		sdb := plugin.MetricType{Namespace_: core.NewNamespace("intel", "disk", "sdb")}
+		s := newStalenessTracker(time.Minute)
+		So(s.collected("task", []core.Metric{sda, sdb}, now), ShouldBeEmpty)
+
+		Convey("a metric missing for less than the window is not stale", func() {
+			So(s.collected("task", []core.Metric{sda}, now.Add(30*time.Second)), ShouldBeEmpty)
+		})
+		Convey("a metric missing for longer than the window is reported once", func() {
+			events := s.collected("task", []core.Metric{sda}, now.Add(2*time.Minute))
+			So(len(events), ShouldEqual, 1)
+			So(events[0].MetricNamespace, ShouldEqual, sdb.Namespace().String())
+			So(s.collected("task", []core.Metric{sda}, now.Add(3*time.Minute)), ShouldBeEmpty)
+		})
+		Convey("a forgotten task is no longer tracked", func() {
+			s.forget("task")
+			So(s.collected("task", []core.Metric{sda}, now.Add(2*time.Minute)), ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,138 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// persistedPlugin is the persisted record of a loaded plugin.
+type persistedPlugin struct {
+	Path      string `json:"path"`
+	Signature []byte `json:"signature,omitempty"`
+	Signed    bool   `json:"signed"`
+}
+
+// StateFile is the PluginControlOpt which persists the path and signature of
+// each loaded plugin to the file so they can be reloaded with RestoreState
+// after a restart.  An empty path disables persistence.
+func StateFile(path string) PluginControlOpt {
+	return func(c *pluginControl) {
+		c.stateFile = path
+	}
+}
+
+// saveState writes the loaded plugins to the state file.  The file is
+// replaced atomically so a crash never leaves it partially written.
+func (p *pluginControl) saveState() {
+	if p.stateFile == "" {
+		return
+	}
+	f := log.Fields{
+		"_block":     "save-state",
+		"state-file": p.stateFile,
+	}
+	var state []persistedPlugin
+	for _, lp := range p.pluginManager.all() {
+		if lp.Details == nil || lp.Details.Path == "" {
+			continue
+		}
+		state = append(state, persistedPlugin{
+			Path:      lp.Details.Path,
+			Signature: lp.Details.Signature,
+			Signed:    lp.Details.Signed,
+		})
+	}
+	sort.Sort(byPersistedPath(state))
+	b, err := json.Marshal(state)
+	if err != nil {
+		controlLogger.WithFields(f).Error(err)
+		return
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(p.stateFile), filepath.Base(p.stateFile))
+	if err != nil {
+		controlLogger.WithFields(f).Error(err)
+		return
+	}
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), p.stateFile)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		controlLogger.WithFields(f).Error(err)
+	}
+}
+
+// RestoreState loads the plugins recorded in the state file.  Plugins whose
+// file no longer exists are skipped with a warning.
+func (p *pluginControl) RestoreState() ([]core.CatalogedPlugin, []serror.SnapError) {
+	if p.stateFile == "" {
+		return nil, nil
+	}
+	f := map[string]interface{}{
+		"_block":     "restore-state",
+		"state-file": p.stateFile,
+	}
+	b, err := ioutil.ReadFile(p.stateFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []serror.SnapError{serror.New(err, f)}
+	}
+	var state []persistedPlugin
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, []serror.SnapError{serror.New(err, f)}
+	}
+	var rps []*core.RequestedPlugin
+	for _, pp := range state {
+		if _, err := os.Stat(pp.Path); err != nil {
+			controlLogger.WithFields(f).WithField("path", pp.Path).Warning("skipping plugin which no longer exists")
+			continue
+		}
+		rp, err := core.NewRequestedPlugin(pp.Path)
+		if err != nil {
+			controlLogger.WithFields(f).WithField("path", pp.Path).Warning(err)
+			continue
+		}
+		rp.SetSignature(pp.Signature)
+		rps = append(rps, rp)
+	}
+	return p.loadInDependencyOrder(rps)
+}
+
+type byPersistedPath []persistedPlugin
+
+func (b byPersistedPath) Len() int           { return len(b) }
+func (b byPersistedPath) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byPersistedPath) Less(i, j int) bool { return b[i].Path < b[j].Path }
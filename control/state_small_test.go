@@ -0,0 +1,73 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPluginState(t *testing.T) {
+	Convey("Given control with a state file", t, func() {
+		dir, err := ioutil.TempDir("", "snap-state")
+		So(err, ShouldBeNil)
+		pm := newPluginManager()
+		c := &pluginControl{pluginManager: pm, stateFile: filepath.Join(dir, "state.json")}
+		pm.loadedPlugins.add(&loadedPlugin{
+			Type:    plugin.CollectorPluginType,
+			Meta:    plugin.PluginMeta{Name: "missing", Version: 1},
+			Details: &pluginDetails{Path: filepath.Join(dir, "snap-collector-missing"), Signed: true, Signature: []byte("sig")},
+		})
+
+		Convey("loaded plugins are persisted", func() {
+			c.saveState()
+			b, err := ioutil.ReadFile(c.stateFile)
+			So(err, ShouldBeNil)
+			var state []persistedPlugin
+			So(json.Unmarshal(b, &state), ShouldBeNil)
+			So(state, ShouldResemble, []persistedPlugin{{
+				Path:      filepath.Join(dir, "snap-collector-missing"),
+				Signature: []byte("sig"),
+				Signed:    true,
+			}})
+
+			Convey("and plugins which no longer exist are skipped on restore", func() {
+				loaded, serrs := c.RestoreState()
+				So(loaded, ShouldBeEmpty)
+				So(serrs, ShouldBeEmpty)
+			})
+		})
+		Convey("restoring without a state file loads nothing", func() {
+			loaded, serrs := c.RestoreState()
+			So(loaded, ShouldBeEmpty)
+			So(serrs, ShouldBeEmpty)
+		})
+		os.RemoveAll(dir)
+	})
+}
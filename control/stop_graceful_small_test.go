@@ -0,0 +1,92 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStopGraceful(t *testing.T) {
+	Convey("StopGraceful waits for calls in flight to return", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		inFlight := c.pluginRunner.AvailablePlugins().inFlight
+		id := inFlight.start("collector:mock:1", &availablePlugin{}, "collect", "task")
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			inFlight.done(id)
+		}()
+		started := time.Now()
+		c.StopGraceful(5 * time.Second)
+		So(time.Since(started), ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+		So(time.Since(started), ShouldBeLessThan, 5*time.Second)
+		So(c.Started, ShouldBeFalse)
+		So(c.InFlightCalls(), ShouldBeEmpty)
+	})
+	Convey("StopGraceful stops once the timeout elapses", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		c.pluginRunner.AvailablePlugins().inFlight.start("collector:mock:1", &availablePlugin{}, "collect", "task")
+		started := time.Now()
+		c.StopGraceful(50 * time.Millisecond)
+		So(time.Since(started), ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+		So(c.Started, ShouldBeFalse)
+		So(len(c.InFlightCalls()), ShouldEqual, 1)
+	})
+	Convey("Plugins are still called while StopGraceful waits", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		addFakePlugin(c, plugin.PublisherPluginType, "draining", &failingClient{})
+		inFlight := c.pluginRunner.AvailablePlugins().inFlight
+		id := inFlight.start("collector:mock:1", &availablePlugin{}, "collect", "task")
+		stopped := make(chan struct{})
+		go func() {
+			c.StopGraceful(5 * time.Second)
+			close(stopped)
+		}()
+		for !c.isDraining() {
+			time.Sleep(time.Millisecond)
+		}
+		So(c.Started, ShouldBeTrue)
+		errs := c.PublishMetrics(plugin.SnapGOBContentType, []byte("metrics"), "draining", 1, nil, "task")
+		So(errs, ShouldBeEmpty)
+		serrs := c.SubscribeDeps("task", nil, nil)
+		So(len(serrs), ShouldEqual, 1)
+		So(serrs[0].Error(), ShouldEqual, ErrControllerDraining.Error())
+		inFlight.done(id)
+		<-stopped
+		So(c.Started, ShouldBeFalse)
+		So(c.isDraining(), ShouldBeFalse)
+	})
+	Convey("StopGraceful stops at once without calls in flight", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		started := time.Now()
+		c.StopGraceful(5 * time.Second)
+		So(time.Since(started), ShouldBeLessThan, 5*time.Second)
+	})
+}
@@ -127,3 +127,27 @@ func TestUpdateCache(t *testing.T) {
 		})
 	})
 }
+
+func TestSetCacheTTL(t *testing.T) {
+	mts := []core.Metric{
+		fixtures.MockMetricType{Namespace_: core.NewNamespace("foo", "bar")},
+	}
+	Convey("Setting the cache TTL of a strategy", t, func() {
+		for _, rc := range []RoutingAndCaching{
+			NewLRU(time.Minute),
+			NewLeastLoaded(time.Minute),
+			NewSticky(time.Minute),
+			NewConfigBased(time.Minute),
+		} {
+			rc.UpdateCache(mts, "task")
+			rc.SetCacheTTL(0)
+			ttl, err := rc.CacheTTL("task")
+			So(err, ShouldBeNil)
+			So(ttl, ShouldEqual, 0)
+			// the entry cached before the TTL was set has expired
+			toCollect, fromCache := rc.CheckCache(mts, "task")
+			So(len(toCollect), ShouldEqual, 1)
+			So(fromCache, ShouldBeEmpty)
+		}
+	})
+}
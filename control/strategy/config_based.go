@@ -91,11 +91,24 @@ func (cb *configBased) String() string {
 	return "config-based"
 }
 
+// Interchangeable returns false as calls are routed to a particular plugin.
+func (cb *configBased) Interchangeable() bool {
+	return false
+}
+
 // CacheTTL returns the TTL for the cache.
 func (cb *configBased) CacheTTL(id string) (time.Duration, error) {
 	return cb.cacheTTL, nil
 }
 
+// SetCacheTTL sets the TTL for the caches.
+func (cb *configBased) SetCacheTTL(ttl time.Duration) {
+	cb.cacheTTL = ttl
+	for _, c := range cb.metricCache {
+		c.ttl = ttl
+	}
+}
+
 // checkCache checks the cache for metric types.
 // returns:
 //  - array of metrics that need to be collected
@@ -49,6 +49,7 @@ type MockAvailablePlugin struct {
 	pluginName string
 	hitCount   int
 	lastHit    time.Time
+	active     int
 	id         uint32
 	ttl        time.Duration
 	concount   int
@@ -89,6 +90,11 @@ func (m *MockAvailablePlugin) WithLastHit(last time.Time) *MockAvailablePlugin {
 	return m
 }
 
+func (m *MockAvailablePlugin) WithActiveCalls(active int) *MockAvailablePlugin {
+	m.active = active
+	return m
+}
+
 func (m *MockAvailablePlugin) WithID(id uint32) *MockAvailablePlugin {
 	m.id = id
 	return m
@@ -132,6 +138,14 @@ func (m MockAvailablePlugin) LastHit() time.Time {
 	return m.lastHit
 }
 
+func (m MockAvailablePlugin) StartTime() time.Time {
+	return time.Time{}
+}
+
+func (m MockAvailablePlugin) ActiveCalls() int {
+	return m.active
+}
+
 func (m MockAvailablePlugin) String() string {
 	return strings.Join([]string{m.pluginType.String(), m.pluginName, strconv.Itoa(m.Version())}, ":")
 }
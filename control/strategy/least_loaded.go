@@ -0,0 +1,144 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategy
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/intelsdi-x/snap/core"
+)
+
+// leastLoaded provides a strategy that selects the available plugin serving
+// the fewest calls.
+type leastLoaded struct {
+	*cache
+	logger *log.Entry
+}
+
+func NewLeastLoaded(cacheTTL time.Duration) *leastLoaded {
+	return &leastLoaded{
+		NewCache(cacheTTL),
+		log.WithFields(log.Fields{
+			"_module": "control-routing",
+		}),
+	}
+}
+
+// String returns the strategy name.
+func (l *leastLoaded) String() string {
+	return "least-loaded"
+}
+
+// Interchangeable returns true as any plugin may serve a call.
+func (l *leastLoaded) Interchangeable() bool {
+	return true
+}
+
+// CacheTTL returns the TTL for the cache.
+func (l *leastLoaded) CacheTTL(taskID string) (time.Duration, error) {
+	return l.ttl, nil
+}
+
+// SetCacheTTL sets the TTL for the cache.
+func (l *leastLoaded) SetCacheTTL(ttl time.Duration) {
+	l.ttl = ttl
+}
+
+// Select selects the available plugin serving the fewest calls.  Ties are
+// broken by the fewest hits, then by the least recently used and then by the
+// lowest id, so the same plugin is selected from the same available plugins
+// whatever their order.  The active calls of a plugin are read atomically
+// so selection is safe while calls are made to the plugins.
+func (l *leastLoaded) Select(aps []AvailablePlugin, _ string) (AvailablePlugin, error) {
+	var selected AvailablePlugin
+	for _, ap := range aps {
+		if selected == nil || lessLoaded(ap, selected) {
+			selected = ap
+		}
+	}
+	if selected == nil {
+		l.logger.WithFields(log.Fields{
+			"block":    "select",
+			"strategy": l.String(),
+			"error":    ErrCouldNotSelect,
+		}).Error("error selecting")
+		return nil, ErrCouldNotSelect
+	}
+	l.logger.WithFields(log.Fields{
+		"block":        "select",
+		"strategy":     l.String(),
+		"pool size":    len(aps),
+		"index":        selected.String(),
+		"active calls": selected.ActiveCalls(),
+		"hitcount":     selected.HitCount(),
+	}).Debug("plugin selected")
+	return selected, nil
+}
+
+// lessLoaded returns whether a is less loaded than b.
+func lessLoaded(a, b AvailablePlugin) bool {
+	if ac, bc := a.ActiveCalls(), b.ActiveCalls(); ac != bc {
+		return ac < bc
+	}
+	if a.HitCount() != b.HitCount() {
+		return a.HitCount() < b.HitCount()
+	}
+	if !a.LastHit().Equal(b.LastHit()) {
+		return a.LastHit().Before(b.LastHit())
+	}
+	return a.ID() < b.ID()
+}
+
+// Remove selects a plugin
+// Since there is no state to cleanup we only need to return the selected plugin
+func (l *leastLoaded) Remove(aps []AvailablePlugin, taskID string) (AvailablePlugin, error) {
+	return l.Select(aps, taskID)
+}
+
+// CheckCache checks the cache for metric types.
+func (l *leastLoaded) CheckCache(mts []core.Metric, _ string) ([]core.Metric, []core.Metric) {
+	return l.checkCache(mts)
+}
+
+// UpdateCache updates the cache with the given array of metrics.
+func (l *leastLoaded) UpdateCache(mts []core.Metric, _ string) {
+	l.updateCache(mts)
+}
+
+// AllCacheHits returns cache hits across all metrics.
+func (l *leastLoaded) AllCacheHits() uint64 {
+	return l.allCacheHits()
+}
+
+// AllCacheMisses returns cache misses across all metrics.
+func (l *leastLoaded) AllCacheMisses() uint64 {
+	return l.allCacheMisses()
+}
+
+// CacheHits returns the cache hits for a given metric namespace and version.
+func (l *leastLoaded) CacheHits(ns string, version int, _ string) (uint64, error) {
+	return l.cacheHits(ns, version)
+}
+
+// CacheMisses returns the cache misses for a given metric namespace and version.
+func (l *leastLoaded) CacheMisses(ns string, version int, _ string) (uint64, error) {
+	return l.cacheMisses(ns, version)
+}
@@ -0,0 +1,68 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategy
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/intelsdi-x/snap/control/strategy/fixtures"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLeastLoadedRouter(t *testing.T) {
+	Convey("Given a least loaded router", t, func() {
+		router := NewLeastLoaded(100 * time.Millisecond)
+		So(router, ShouldNotBeNil)
+		So(router.String(), ShouldResemble, "least-loaded")
+		Convey("The plugin serving the fewest calls is selected", func() {
+			p1 := NewMockAvailablePlugin().WithName("p1").WithID(1).WithActiveCalls(3)
+			p2 := NewMockAvailablePlugin().WithName("p2").WithID(2).WithActiveCalls(1).WithHitCount(100)
+			sp, err := router.Select([]AvailablePlugin{p1, p2}, "task")
+			So(err, ShouldBeNil)
+			So(sp, ShouldEqual, p2)
+		})
+		Convey("Ties are broken by hits, last hit and id", func() {
+			now := time.Now()
+			p1 := NewMockAvailablePlugin().WithName("p1").WithID(1).WithHitCount(5).WithLastHit(now)
+			p2 := NewMockAvailablePlugin().WithName("p2").WithID(2).WithHitCount(4).WithLastHit(now)
+			sp, err := router.Select([]AvailablePlugin{p1, p2}, "task")
+			So(err, ShouldBeNil)
+			So(sp, ShouldEqual, p2)
+
+			p3 := NewMockAvailablePlugin().WithName("p3").WithID(3).WithHitCount(4).WithLastHit(now.Add(-time.Second))
+			sp, err = router.Select([]AvailablePlugin{p1, p2, p3}, "task")
+			So(err, ShouldBeNil)
+			So(sp, ShouldEqual, p3)
+
+			p4 := NewMockAvailablePlugin().WithName("p4").WithID(4).WithHitCount(4).WithLastHit(now)
+			sp, err = router.Select([]AvailablePlugin{p4, p2}, "task")
+			So(err, ShouldBeNil)
+			So(sp, ShouldEqual, p2)
+		})
+		Convey("No plugin is selected when there are none available", func() {
+			sp, err := router.Select([]AvailablePlugin{}, "task")
+			So(sp, ShouldBeNil)
+			So(err, ShouldEqual, ErrCouldNotSelect)
+		})
+	})
+}
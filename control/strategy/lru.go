@@ -46,11 +46,21 @@ func (l *lru) String() string {
 	return "least-recently-used"
 }
 
+// Interchangeable returns true as any plugin may serve a call.
+func (l *lru) Interchangeable() bool {
+	return true
+}
+
 // CacheTTL returns the TTL for the cache.
 func (l *lru) CacheTTL(taskID string) (time.Duration, error) {
 	return l.ttl, nil
 }
 
+// SetCacheTTL sets the TTL for the cache.
+func (l *lru) SetCacheTTL(ttl time.Duration) {
+	l.ttl = ttl
+}
+
 // Select selects an available plugin using the least-recently-used strategy.
 func (l *lru) Select(aps []AvailablePlugin, _ string) (AvailablePlugin, error) {
 	t := time.Now()
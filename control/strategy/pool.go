@@ -47,8 +47,9 @@ const (
 )
 
 var (
-	// This defines the maximum running instances of a loaded plugin.
-	// It is initialized at runtime via the cli.
+	// This defines the default maximum running instances of a loaded
+	// plugin in a pool created with NewPool.  Use SetMax to change the
+	// maximum of a pool.
 	MaximumRunningPlugins = 3
 )
 
@@ -56,6 +57,7 @@ var (
 	ErrBadType     = errors.New("bad plugin type")
 	ErrBadStrategy = errors.New("bad strategy")
 	ErrPoolEmpty   = errors.New("plugin pool is empty")
+	ErrPoolPaused  = errors.New("plugin pool is paused")
 )
 
 type Pool interface {
@@ -64,7 +66,7 @@ type Pool interface {
 	Eligible() bool
 	Insert(a AvailablePlugin) error
 	Kill(id uint32, reason string)
-	MoveSubscriptions(to Pool) []subscription
+	MoveSubscriptions(to Pool) []Subscription
 	Plugins() MapAvailablePlugin
 	RLock()
 	RUnlock()
@@ -72,15 +74,26 @@ type Pool interface {
 	SelectAP(taskID string, configID map[string]ctypes.ConfigValue) (AvailablePlugin, serror.SnapError)
 	Strategy() RoutingAndCaching
 	Subscribe(taskID string, subType SubscriptionType)
+	Subscriptions() []Subscription
 	SubscriptionCount() int
 	Unsubscribe(taskID string)
 	Version() int
 	RestartCount() int
 	IncRestartCount()
+	ResetRestartCount()
+	SetStrategy(plugin.RoutingStrategyType) error
+	SetCacheTTL(ttl time.Duration)
+	SetMax(max int)
+	Pause()
+	Resume()
+	Paused() bool
 }
 
 type AvailablePlugin interface {
 	core.AvailablePlugin
+	// ActiveCalls returns the number of calls the plugin is serving.  It is
+	// safe to call while calls are made to the plugin.
+	ActiveCalls() int
 	CacheTTL() time.Duration
 	CheckHealth()
 	ConcurrencyCount() int
@@ -93,7 +106,8 @@ type AvailablePlugin interface {
 	Stop(string) error
 }
 
-type subscription struct {
+// Subscription is a task's subscription to the plugins of a pool
+type Subscription struct {
 	SubType SubscriptionType
 	Version int
 	TaskID  string
@@ -111,7 +125,7 @@ type pool struct {
 	key string
 
 	// The subscriptions to this pool.
-	subs map[string]*subscription
+	subs map[string]*Subscription
 
 	// The plugins in the pool.
 	// the primary key is an increasing --> uint from
@@ -125,6 +139,9 @@ type pool struct {
 	// The number of subscriptions per running instance
 	concurrencyCount int
 
+	// The TTL of metrics cached by the strategy
+	cacheTTL time.Duration
+
 	// The routing and caching strategy declared by the plugin.
 	// strategy RoutingAndCaching
 	RoutingAndCaching
@@ -132,6 +149,9 @@ type pool struct {
 	// restartCount the restart count of available plugins
 	// when the DeadAvailablePluginEvent occurs
 	restartCount int
+
+	// paused stops plugins being selected from the pool
+	paused bool
 }
 
 func NewPool(key string, plugins ...AvailablePlugin) (Pool, error) {
@@ -144,7 +164,7 @@ func NewPool(key string, plugins ...AvailablePlugin) (Pool, error) {
 		RWMutex:          &sync.RWMutex{},
 		version:          ver,
 		key:              key,
-		subs:             map[string]*subscription{},
+		subs:             map[string]*Subscription{},
 		plugins:          MapAvailablePlugin{},
 		max:              MaximumRunningPlugins,
 		concurrencyCount: 1,
@@ -180,11 +200,18 @@ func (p *pool) RestartCount() int {
 }
 
 func (p *pool) IncRestartCount() {
-	p.RLock()
-	defer p.RUnlock()
+	p.Lock()
+	defer p.Unlock()
 	p.restartCount++
 }
 
+// ResetRestartCount sets the restart count of a pool back to zero
+func (p *pool) ResetRestartCount() {
+	p.Lock()
+	defer p.Unlock()
+	p.restartCount = 0
+}
+
 // Insert inserts an AvailablePlugin into the pool
 func (p *pool) Insert(a AvailablePlugin) error {
 	if a.Type() != plugin.CollectorPluginType && a.Type() != plugin.ProcessorPluginType && a.Type() != plugin.PublisherPluginType {
@@ -206,6 +233,15 @@ func (p *pool) Insert(a AvailablePlugin) error {
 	return nil
 }
 
+// SetMax sets the max number of plugins which may run in the pool.  A pool
+// of an exclusive plugin is limited to one plugin when the plugin is inserted,
+// so SetMax should be called before plugins are inserted.
+func (p *pool) SetMax(max int) {
+	p.Lock()
+	defer p.Unlock()
+	p.max = max
+}
+
 // applyPluginMeta is called when the first plugin is added to the pool
 func (p *pool) applyPluginMeta(a AvailablePlugin) error {
 	// Checking if plugin is exclusive
@@ -221,25 +257,75 @@ func (p *pool) applyPluginMeta(a AvailablePlugin) error {
 		cacheTTL = a.CacheTTL()
 	}
 
+	p.cacheTTL = cacheTTL
+
 	// Set the concurrency count
 	p.concurrencyCount = a.ConcurrencyCount()
 
 	// Set the routing and caching strategy
-	switch a.RoutingStrategy() {
-	case plugin.DefaultRouting:
-		p.RoutingAndCaching = NewLRU(cacheTTL)
-	case plugin.StickyRouting:
-		p.RoutingAndCaching = NewSticky(cacheTTL)
+	rc, err := newRoutingAndCaching(a.RoutingStrategy(), cacheTTL)
+	if err != nil {
+		return err
+	}
+	p.RoutingAndCaching = rc
+	if a.RoutingStrategy() == plugin.StickyRouting {
 		p.concurrencyCount = 1
-	case plugin.ConfigRouting:
-		p.RoutingAndCaching = NewConfigBased(cacheTTL)
-	default:
-		return ErrBadStrategy
 	}
 
 	return nil
 }
 
+// SetStrategy replaces the routing and caching strategy of the pool.
+// Selections in progress complete with the previous strategy and metrics
+// cached by it are dropped.
+func (p *pool) SetStrategy(r plugin.RoutingStrategyType) error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.RoutingAndCaching == nil {
+		return ErrPoolEmpty
+	}
+	rc, err := newRoutingAndCaching(r, p.cacheTTL)
+	if err != nil {
+		return err
+	}
+	p.RoutingAndCaching = rc
+	p.concurrencyCount = 1
+	if r != plugin.StickyRouting {
+		for _, ap := range p.plugins {
+			p.concurrencyCount = ap.ConcurrencyCount()
+			break
+		}
+	}
+	return nil
+}
+
+// SetCacheTTL sets the TTL of the metrics cached by the pool's strategy,
+// replacing the TTL applied when the first plugin was inserted.
+func (p *pool) SetCacheTTL(ttl time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.cacheTTL = ttl
+	if p.RoutingAndCaching != nil {
+		p.RoutingAndCaching.SetCacheTTL(ttl)
+	}
+}
+
+func newRoutingAndCaching(r plugin.RoutingStrategyType, cacheTTL time.Duration) (RoutingAndCaching, error) {
+	switch r {
+	case plugin.DefaultRouting:
+		return NewLRU(cacheTTL), nil
+	case plugin.StickyRouting:
+		return NewSticky(cacheTTL), nil
+	case plugin.ConfigRouting:
+		return NewConfigBased(cacheTTL), nil
+	case plugin.LeastLoadedRouting:
+		return NewLeastLoaded(cacheTTL), nil
+	}
+	return nil, ErrBadStrategy
+}
+
 // subscribe adds a subscription to the pool.
 // Using subscribe is idempotent.
 func (p *pool) Subscribe(taskID string, subType SubscriptionType) {
@@ -249,7 +335,7 @@ func (p *pool) Subscribe(taskID string, subType SubscriptionType) {
 	if _, exists := p.subs[taskID]; !exists {
 		// Version is the last item in the key, so we split here
 		// to retrieve it for the subscription.
-		p.subs[taskID] = &subscription{
+		p.subs[taskID] = &Subscription{
 			TaskID:  taskID,
 			SubType: subType,
 			Version: p.version,
@@ -334,12 +420,23 @@ func (p *pool) Count() int {
 }
 
 // NOTE: The data returned by subscriptions should be constant and read only.
-func (p *pool) subscriptions() map[string]*subscription {
+func (p *pool) subscriptions() map[string]*Subscription {
 	p.RLock()
 	defer p.RUnlock()
 	return p.subs
 }
 
+// Subscriptions returns a copy of the subscriptions in the pool
+func (p *pool) Subscriptions() []Subscription {
+	p.RLock()
+	defer p.RUnlock()
+	subs := make([]Subscription, 0, len(p.subs))
+	for _, sub := range p.subs {
+		subs = append(subs, *sub)
+	}
+	return subs
+}
+
 // SubscriptionCount returns the number of subscriptions in the pool
 func (p *pool) SubscriptionCount() int {
 	p.RLock()
@@ -347,11 +444,38 @@ func (p *pool) SubscriptionCount() int {
 	return len(p.subs)
 }
 
-// SelectAP selects an available plugin from the pool
+// Pause stops plugins being selected from the pool until Resume is called.
+// The plugins and subscriptions of the pool are kept.
+func (p *pool) Pause() {
+	p.Lock()
+	defer p.Unlock()
+	p.paused = true
+}
+
+// Resume allows plugins to be selected from a paused pool.
+func (p *pool) Resume() {
+	p.Lock()
+	defer p.Unlock()
+	p.paused = false
+}
+
+// Paused returns true if the pool is paused.
+func (p *pool) Paused() bool {
+	p.RLock()
+	defer p.RUnlock()
+	return p.paused
+}
+
+// SelectAP selects an available plugin from the pool.  ErrPoolPaused is
+// returned while the pool is paused.
 func (p *pool) SelectAP(taskID string, config map[string]ctypes.ConfigValue) (AvailablePlugin, serror.SnapError) {
 	p.RLock()
 	defer p.RUnlock()
 
+	if p.paused {
+		return nil, serror.New(ErrPoolPaused)
+	}
+
 	aps := p.plugins.Values()
 
 	var id string
@@ -391,12 +515,12 @@ func (p *pool) generatePID() uint32 {
 }
 
 // MoveSubscriptions moves subscriptions to another pool
-func (p *pool) MoveSubscriptions(to Pool) []subscription {
-	var subs []subscription
+func (p *pool) MoveSubscriptions(to Pool) []Subscription {
+	var subs []Subscription
 	// If attempting to move between the same pool
 	// bail to prevent deadlock.
 	if to.(*pool) == p {
-		return []subscription{}
+		return []Subscription{}
 	}
 	p.Lock()
 	defer p.Unlock()
@@ -230,8 +230,8 @@ func TestPoolSelectAPDefaultRouter(t *testing.T) {
 
 func TestPoolSelectAPConfigRouter(t *testing.T) {
 	Convey("Given task id and configuration", t, func() {
-		cfg := map[string]ctypes.ConfigValue{"foo": ctypes.ConfigValueStr{"bar"}}
-		otherCfg := map[string]ctypes.ConfigValue{"foo": ctypes.ConfigValueStr{"baz"}}
+		cfg := map[string]ctypes.ConfigValue{"foo": ctypes.ConfigValueStr{Value: "bar"}}
+		otherCfg := map[string]ctypes.ConfigValue{"foo": ctypes.ConfigValueStr{Value: "baz"}}
 
 		Convey("When plugin is defined with config based strategy", func() {
 			plugin := NewMockAvailablePlugin().WithStrategy(plugin.ConfigRouting)
@@ -276,7 +276,7 @@ func TestPoolSelectAPStickyRouter(t *testing.T) {
 			So(ap1, ShouldNotBeNil)
 			So(err, ShouldBeNil)
 
-			cfg := map[string]ctypes.ConfigValue{"foo": ctypes.ConfigValueStr{"bar"}}
+			cfg := map[string]ctypes.ConfigValue{"foo": ctypes.ConfigValueStr{Value: "bar"}}
 			ap2, err := pool.SelectAP("TaskID", cfg)
 			So(ap2, ShouldNotBeNil)
 			So(err, ShouldBeNil)
@@ -288,3 +288,54 @@ func TestPoolSelectAPStickyRouter(t *testing.T) {
 		})
 	})
 }
+
+func TestPoolSetStrategy(t *testing.T) {
+	Convey("For plugin defined with sticky strategy", t, func() {
+		ap := NewMockAvailablePlugin().WithStrategy(plugin.StickyRouting)
+		pool, _ := NewPool(ap.String(), ap)
+
+		Convey("When the strategy is changed to least recently used", func() {
+			So(pool.SetStrategy(plugin.DefaultRouting), ShouldBeNil)
+
+			Convey("Then another task can select the plugin", func() {
+				So(pool.Strategy().String(), ShouldEqual, "least-recently-used")
+				ap, err := pool.SelectAP("TaskID", nil)
+				So(ap, ShouldNotBeNil)
+				So(err, ShouldBeNil)
+				ap, err = pool.SelectAP("AnotherTaskID", nil)
+				So(ap, ShouldNotBeNil)
+				So(err, ShouldBeNil)
+			})
+		})
+		Convey("When the strategy is unknown", func() {
+			So(pool.SetStrategy(plugin.RoutingStrategyType(99)), ShouldEqual, ErrBadStrategy)
+		})
+	})
+}
+
+func TestPoolPause(t *testing.T) {
+	Convey("Given a pool with a subscription", t, func() {
+		ap := NewMockAvailablePlugin().WithStrategy(plugin.DefaultRouting)
+		pool, _ := NewPool(ap.String(), ap)
+		pool.Subscribe("TaskID", BoundSubscriptionType)
+
+		Convey("When the pool is paused", func() {
+			pool.Pause()
+
+			Convey("Then no plugin is selected and the subscription is kept", func() {
+				So(pool.Paused(), ShouldBeTrue)
+				selected, err := pool.SelectAP("TaskID", nil)
+				So(selected, ShouldBeNil)
+				So(err.Error(), ShouldEqual, ErrPoolPaused.Error())
+				So(pool.SubscriptionCount(), ShouldEqual, 1)
+			})
+			Convey("Then the plugin is selected again once it is resumed", func() {
+				pool.Resume()
+				So(pool.Paused(), ShouldBeFalse)
+				selected, err := pool.SelectAP("TaskID", nil)
+				So(selected, ShouldNotBeNil)
+				So(err, ShouldBeNil)
+			})
+		})
+	})
+}
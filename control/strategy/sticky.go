@@ -76,11 +76,24 @@ func (s *sticky) String() string {
 	return "sticky"
 }
 
+// Interchangeable returns false as calls are routed to a particular plugin.
+func (s *sticky) Interchangeable() bool {
+	return false
+}
+
 // CacheTTL returns the TTL for the cache.
 func (s *sticky) CacheTTL(taskID string) (time.Duration, error) {
 	return s.cacheTTL, nil
 }
 
+// SetCacheTTL sets the TTL for the caches.
+func (s *sticky) SetCacheTTL(ttl time.Duration) {
+	s.cacheTTL = ttl
+	for _, c := range s.metricCache {
+		c.ttl = ttl
+	}
+}
+
 // checkCache checks the cache for metric types.
 // returns:
 //  - array of metrics that need to be collected
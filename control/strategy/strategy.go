@@ -44,7 +44,11 @@ type RoutingAndCaching interface {
 	AllCacheHits() uint64
 	AllCacheMisses() uint64
 	CacheTTL(taskID string) (time.Duration, error)
+	SetCacheTTL(ttl time.Duration)
 	String() string
+	// Interchangeable returns whether a call may be served by any plugin
+	// in place of the one selected, as when selection only spreads load.
+	Interchangeable() bool
 }
 
 // Values returns slice of map values
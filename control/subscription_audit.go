@@ -0,0 +1,210 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+)
+
+var (
+	// ErrSubscribedPluginNotLoaded - error message when a subscription is
+	// to a plugin version which is no longer loaded
+	ErrSubscribedPluginNotLoaded = errors.New("subscribed plugin is not loaded")
+	// ErrSubscribedMetricsMissing - error message when a subscription is to
+	// a collector none of whose metrics are in the catalog
+	ErrSubscribedMetricsMissing = errors.New("subscribed collector has no metrics in the catalog")
+	// ErrSubscribedMetricMissing - error message when a metric subscribed to
+	// is no longer provided by the collector subscribed to
+	ErrSubscribedMetricMissing = errors.New("subscribed metric is not in the catalog")
+	// ErrSubscriptionNotLatest - error message when a subscription to the
+	// latest version of a plugin is to an older version than is loaded
+	ErrSubscriptionNotLatest = errors.New("subscription to the latest plugin is to an older version")
+)
+
+// SubscriptionIssue is a task's subscription to a plugin pool which no
+// longer resolves against the loaded plugins and metric catalog.
+type SubscriptionIssue struct {
+	TaskID string
+	// PluginKey is the key of the pool subscribed to
+	PluginKey string
+	// Latest is whether the subscription is to the latest version of the
+	// plugin rather than to an explicit version
+	Latest bool
+	// Namespace is the metric subscribed to which is missing from the
+	// catalog, if the issue is with a single metric
+	Namespace string
+	Err       error
+}
+
+// subscribedMetrics holds the metrics each task subscribed to so that the
+// subscriptions can be audited metric by metric.
+type subscribedMetrics struct {
+	sync.RWMutex
+	// tasks maps a task ID to the metrics it subscribed to by the name of
+	// the collector providing them
+	tasks map[string]map[string][]core.Metric
+}
+
+func newSubscribedMetrics() *subscribedMetrics {
+	return &subscribedMetrics{
+		tasks: make(map[string]map[string][]core.Metric),
+	}
+}
+
+func (s *subscribedMetrics) add(taskID, collector string, mts []core.Metric) {
+	if len(mts) == 0 {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	collectors, ok := s.tasks[taskID]
+	if !ok {
+		collectors = make(map[string][]core.Metric)
+		s.tasks[taskID] = collectors
+	}
+	collectors[collector] = append(collectors[collector], mts...)
+}
+
+// get returns the metrics provided by the collector which the task
+// subscribed to.
+func (s *subscribedMetrics) get(taskID, collector string) []core.Metric {
+	s.RLock()
+	defer s.RUnlock()
+	return s.tasks[taskID][collector]
+}
+
+// forget drops the metrics subscribed to by the task.
+func (s *subscribedMetrics) forget(taskID string) {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.tasks, taskID)
+}
+
+// AuditSubscriptions re-resolves the subscriptions to every pool against the
+// loaded plugins and the metric catalog, and returns the subscriptions to
+// plugins which are no longer loaded, to metrics which the collector
+// subscribed to no longer provides and to the latest version of plugins which
+// have since been upgraded.  A collector subscription made without metrics is
+// reported when the collector has no metrics in the catalog.  The issues are
+// ordered by pool, task and namespace.
+func (p *pluginControl) AuditSubscriptions() []SubscriptionIssue {
+	cataloged := map[string]bool{}
+	p.metricCatalog.Walk(func(mt *metricType) bool {
+		if mt.Plugin != nil {
+			cataloged[mt.Plugin.Key()] = true
+		}
+		return true
+	})
+
+	pools := map[string]strategy.Pool{}
+	aps := p.pluginRunner.AvailablePlugins()
+	aps.RLock()
+	for key, pool := range aps.table {
+		pools[key] = pool
+	}
+	aps.RUnlock()
+
+	var issues []SubscriptionIssue
+	for key, pool := range pools {
+		subs := pool.Subscriptions()
+		if len(subs) == 0 {
+			continue
+		}
+		typ, name, version, err := core.ParsePluginKey(key)
+		if err != nil {
+			continue
+		}
+		var problem error
+		if _, err := p.pluginManager.get(key); err != nil {
+			problem = ErrSubscribedPluginNotLoaded
+		} else if typ == core.CollectorPluginType && !cataloged[key] {
+			problem = ErrSubscribedMetricsMissing
+		}
+		latest, err := p.pluginManager.get(core.PluginKey(typ, name, 0))
+		newer := err == nil && latest.Version() > version
+		for _, sub := range subs {
+			unbound := sub.SubType == strategy.UnboundSubscriptionType
+			issue := SubscriptionIssue{TaskID: sub.TaskID, PluginKey: key, Latest: unbound}
+			var mts []core.Metric
+			if typ == core.CollectorPluginType {
+				mts = p.subscribedMetrics.get(sub.TaskID, name)
+			}
+			switch {
+			case problem == ErrSubscribedMetricsMissing && len(mts) > 0:
+				issues = append(issues, p.missingMetrics(issue, mts, version)...)
+				continue
+			case problem != nil:
+				issue.Err = problem
+			case unbound && newer:
+				issue.Err = ErrSubscriptionNotLatest
+			default:
+				issues = append(issues, p.missingMetrics(issue, mts, version)...)
+				continue
+			}
+			issues = append(issues, issue)
+		}
+	}
+	sort.Sort(bySubscription(issues))
+	return issues
+}
+
+// missingMetrics returns an issue for each of the metrics requested by the
+// subscription which is not in the catalog at the version of the collector
+// subscribed to.  Optional metrics are not reported.
+func (p *pluginControl) missingMetrics(issue SubscriptionIssue, mts []core.Metric, version int) []SubscriptionIssue {
+	var issues []SubscriptionIssue
+	for _, mt := range mts {
+		// metrics requested at the latest version make up the unbound
+		// subscription, the others the subscriptions to their version
+		if issue.Latest != (mt.Version() < 1) || (!issue.Latest && mt.Version() != version) {
+			continue
+		}
+		if core.IsOptional(mt) {
+			continue
+		}
+		if _, err := p.metricCatalog.Get(mt.Namespace(), version); err == nil {
+			continue
+		}
+		missing := issue
+		missing.Namespace = mt.Namespace().String()
+		missing.Err = ErrSubscribedMetricMissing
+		issues = append(issues, missing)
+	}
+	return issues
+}
+
+type bySubscription []SubscriptionIssue
+
+func (b bySubscription) Len() int      { return len(b) }
+func (b bySubscription) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b bySubscription) Less(i, j int) bool {
+	if b[i].PluginKey != b[j].PluginKey {
+		return b[i].PluginKey < b[j].PluginKey
+	}
+	if b[i].TaskID != b[j].TaskID {
+		return b[i].TaskID < b[j].TaskID
+	}
+	return b[i].Namespace < b[j].Namespace
+}
@@ -0,0 +1,135 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func subscribePool(c *pluginControl, key, taskID string, subType strategy.SubscriptionType) {
+	pool, err := c.pluginRunner.AvailablePlugins().getOrCreatePool(key)
+	So(err, ShouldBeNil)
+	pool.Subscribe(taskID, subType)
+}
+
+func TestAuditSubscriptions(t *testing.T) {
+	Convey("Subscriptions which no longer resolve are reported", t, func() {
+		c := New(GetDefaultConfig())
+		pm := c.pluginManager.(*pluginManager)
+		v1 := &loadedPlugin{
+			Type:         plugin.CollectorPluginType,
+			Meta:         plugin.PluginMeta{Name: "mock", Version: 1},
+			ConfigPolicy: cpolicy.New(),
+		}
+		pm.loadedPlugins.add(v1)
+		pm.loadedPlugins.add(&loadedPlugin{
+			Type:         plugin.CollectorPluginType,
+			Meta:         plugin.PluginMeta{Name: "mock", Version: 2},
+			ConfigPolicy: cpolicy.New(),
+		})
+		mt := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Version_: 1}
+		So(c.metricCatalog.AddLoadedMetricType(v1, mt), ShouldBeNil)
+
+		subscribePool(c, "collector:mock:1", "bound", strategy.BoundSubscriptionType)
+		subscribePool(c, "collector:mock:1", "latest", strategy.UnboundSubscriptionType)
+		subscribePool(c, "collector:mock:2", "nometrics", strategy.BoundSubscriptionType)
+		subscribePool(c, "publisher:gone:1", "unloaded", strategy.BoundSubscriptionType)
+
+		issues := c.AuditSubscriptions()
+		So(issues, ShouldResemble, []SubscriptionIssue{
+			{TaskID: "latest", PluginKey: "collector:mock:1", Latest: true, Err: ErrSubscriptionNotLatest},
+			{TaskID: "nometrics", PluginKey: "collector:mock:2", Err: ErrSubscribedMetricsMissing},
+			{TaskID: "unloaded", PluginKey: "publisher:gone:1", Err: ErrSubscribedPluginNotLoaded},
+		})
+	})
+}
+
+func TestAuditSubscribedMetrics(t *testing.T) {
+	Convey("Metrics dropped by an upgraded collector are reported", t, func() {
+		c := New(GetDefaultConfig())
+		pm := c.pluginManager.(*pluginManager)
+		v1 := &loadedPlugin{
+			Type:         plugin.CollectorPluginType,
+			Meta:         plugin.PluginMeta{Name: "mock", Version: 1},
+			ConfigPolicy: cpolicy.New(),
+		}
+		v2 := &loadedPlugin{
+			Type:         plugin.CollectorPluginType,
+			Meta:         plugin.PluginMeta{Name: "mock", Version: 2},
+			ConfigPolicy: cpolicy.New(),
+		}
+		pm.loadedPlugins.add(v1)
+		pm.loadedPlugins.add(v2)
+		foo := core.NewNamespace("intel", "mock", "foo")
+		bar := core.NewNamespace("intel", "mock", "bar")
+		for _, ns := range []core.Namespace{foo, bar} {
+			So(c.metricCatalog.AddLoadedMetricType(v1, plugin.MetricType{Namespace_: ns, Version_: 1}), ShouldBeNil)
+		}
+		So(c.metricCatalog.AddLoadedMetricType(v2, plugin.MetricType{Namespace_: foo, Version_: 2}), ShouldBeNil)
+
+		subscribePool(c, "collector:mock:1", "bound", strategy.BoundSubscriptionType)
+		c.subscribedMetrics.add("bound", "mock", []core.Metric{
+			plugin.MetricType{Namespace_: foo, Version_: 1},
+			plugin.MetricType{Namespace_: bar, Version_: 1},
+		})
+		subscribePool(c, "collector:mock:2", "upgraded", strategy.UnboundSubscriptionType)
+		c.subscribedMetrics.add("upgraded", "mock", []core.Metric{
+			plugin.MetricType{Namespace_: foo, Version_: -1},
+			plugin.MetricType{Namespace_: bar, Version_: -1},
+		})
+
+		issues := c.AuditSubscriptions()
+		So(issues, ShouldResemble, []SubscriptionIssue{
+			{TaskID: "upgraded", PluginKey: "collector:mock:2", Latest: true, Namespace: bar.String(), Err: ErrSubscribedMetricMissing},
+		})
+
+		Convey("and are no longer reported once the task unsubscribes", func() {
+			c.subscribedMetrics.forget("upgraded")
+			So(c.AuditSubscriptions(), ShouldResemble, []SubscriptionIssue(nil))
+		})
+	})
+	Convey("Gathering collectors records the metrics requested of each", t, func() {
+		c := New(GetDefaultConfig())
+		lp := &loadedPlugin{
+			Type:         plugin.CollectorPluginType,
+			Meta:         plugin.PluginMeta{Name: "mock", Version: 1},
+			ConfigPolicy: cpolicy.New(),
+		}
+		c.pluginManager.(*pluginManager).loadedPlugins.add(lp)
+		foo := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Version_: 1}
+		bar := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar"), Version_: 1}
+		So(c.metricCatalog.AddLoadedMetricType(lp, foo), ShouldBeNil)
+		So(c.metricCatalog.AddLoadedMetricType(lp, bar), ShouldBeNil)
+
+		gathered, serrs := c.gatherCollectors([]core.Metric{foo, bar})
+		So(serrs, ShouldBeEmpty)
+		So(len(gathered), ShouldEqual, 1)
+		So(gathered[0].metrics, ShouldResemble, []core.Metric{foo, bar})
+	})
+}
@@ -0,0 +1,181 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+# Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package control
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// spawningRunner starts fake instances of the loaded plugins, failing to
+// start the plugin named failName.
+type spawningRunner struct {
+	*runner
+	failName string
+	recorder *stopRecorder
+}
+
+func (r *spawningRunner) spawnPlugin(details *pluginDetails) (*availablePlugin, error) {
+	for _, lp := range r.pluginManager.all() {
+		if lp.Details != details {
+			continue
+		}
+		if lp.Name() == r.failName {
+			return nil, errors.New("plugin could not start")
+		}
+		ap := &availablePlugin{
+			name:       lp.Name(),
+			version:    lp.Version(),
+			pluginType: lp.Type,
+			client:     &recordingClient{name: lp.Name(), recorder: r.recorder},
+			ePlugin:    nopExecutablePlugin{},
+		}
+		if err := r.availablePlugins.insert(ap); err != nil {
+			return nil, err
+		}
+		return ap, nil
+	}
+	return nil, errors.New("plugin not loaded")
+}
+
+// loadRunnablePlugin loads a plugin whose executable can be verified before
+// it is started.
+func loadRunnablePlugin(c *pluginControl, dir string, typ plugin.PluginType, name string) *loadedPlugin {
+	path := filepath.Join(dir, name)
+	So(ioutil.WriteFile(path, []byte(name), 0755), ShouldBeNil)
+	lp := &loadedPlugin{
+		Type:         typ,
+		Meta:         plugin.PluginMeta{Name: name, Version: 1},
+		ConfigPolicy: cpolicy.New(),
+		State:        LoadedState,
+		Details:      &pluginDetails{Path: path, CheckSum: sha256.Sum256([]byte(name))},
+	}
+	So(c.pluginManager.(*pluginManager).loadedPlugins.add(lp), ShouldBeNil)
+	return lp
+}
+
+func runningCount(c *pluginControl, lp *loadedPlugin) int {
+	pool, err := c.pluginRunner.AvailablePlugins().getPool(lp.Key())
+	So(err, ShouldBeNil)
+	if pool == nil {
+		return 0
+	}
+	So(pool.SubscriptionCount(), ShouldEqual, 0)
+	return pool.Count()
+}
+
+func TestSubscribeDepsRollback(t *testing.T) {
+	Convey("A failed subscription stops the plugins started for it", t, func() {
+		dir, err := ioutil.TempDir("", "snap-rollback")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		c := New(GetDefaultConfig())
+		c.Started = true
+		r := &spawningRunner{runner: c.pluginRunner.(*runner), failName: "broken", recorder: &stopRecorder{}}
+		c.pluginRunner = r
+		col := loadRunnablePlugin(c, dir, plugin.CollectorPluginType, "col")
+		mt := catalogMetric(c, col)
+		pub := loadRunnablePlugin(c, dir, plugin.PublisherPluginType, "pub")
+		broken := loadRunnablePlugin(c, dir, plugin.PublisherPluginType, "broken")
+
+		serrs := c.SubscribeDeps("task", []core.Metric{mt}, []core.Plugin{pub, broken})
+		So(len(serrs), ShouldEqual, 1)
+		So(r.recorder.stopped, ShouldResemble, []string{"pub", "col"})
+		So(runningCount(c, col), ShouldEqual, 0)
+		So(runningCount(c, pub), ShouldEqual, 0)
+		So(runningCount(c, broken), ShouldEqual, 0)
+	})
+	Convey("A failed subscription only stops the instances it started", t, func() {
+		dir, err := ioutil.TempDir("", "snap-rollback")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		c := New(GetDefaultConfig())
+		c.Started = true
+		r := &spawningRunner{runner: c.pluginRunner.(*runner), failName: "broken", recorder: &stopRecorder{}}
+		c.pluginRunner = r
+		pub := loadRunnablePlugin(c, dir, plugin.PublisherPluginType, "pub")
+		broken := loadRunnablePlugin(c, dir, plugin.PublisherPluginType, "broken")
+		So(c.SubscribeDeps("task-1", nil, []core.Plugin{pub}), ShouldBeEmpty)
+
+		serrs := c.SubscribeDeps("task-2", nil, []core.Plugin{pub, broken})
+		So(len(serrs), ShouldEqual, 1)
+		// the second subscription to pub started another instance
+		So(r.recorder.stopped, ShouldResemble, []string{"pub"})
+		pool, err := c.pluginRunner.AvailablePlugins().getPool(pub.Key())
+		So(err, ShouldBeNil)
+		So(pool.Count(), ShouldEqual, 1)
+		So(subscribedTasks(pool), ShouldResemble, []string{"task-1"})
+	})
+}
+
+func TestSubscribeDepsGatherFailure(t *testing.T) {
+	missing := plugin.MetricType{Namespace_: core.NewNamespace("intel", "missing", "bar"), Version_: 1}
+	subtree := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "*")}
+	Convey("A subscription to a missing metric subscribes to nothing", t, func() {
+		dir, err := ioutil.TempDir("", "snap-rollback")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		c := New(GetDefaultConfig())
+		c.Started = true
+		r := &spawningRunner{runner: c.pluginRunner.(*runner), recorder: &stopRecorder{}}
+		c.pluginRunner = r
+		foo := addSubtreeCollector(c, "foo", core.NewNamespace("intel", "mock", "foo"))
+		pub := loadRunnablePlugin(c, dir, plugin.PublisherPluginType, "pub")
+
+		serrs := c.SubscribeDeps("task", []core.Metric{missing, subtree}, []core.Plugin{pub})
+		So(serrs, ShouldNotBeEmpty)
+		So(foo.SubscriptionCount(), ShouldEqual, 0)
+		// no pool was created for the publisher
+		_, err = c.pluginRunner.AvailablePlugins().getPool(pub.Key())
+		So(err, ShouldNotBeNil)
+		So(r.recorder.stopped, ShouldBeEmpty)
+		So(c.subtrees.table["task"], ShouldBeEmpty)
+	})
+	Convey("An unsubscription from a missing metric leaves the subscriptions in place", t, func() {
+		dir, err := ioutil.TempDir("", "snap-rollback")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		c := New(GetDefaultConfig())
+		c.Started = true
+		r := &spawningRunner{runner: c.pluginRunner.(*runner), recorder: &stopRecorder{}}
+		c.pluginRunner = r
+		foo := addSubtreeCollector(c, "foo", core.NewNamespace("intel", "mock", "foo"))
+		pub := loadRunnablePlugin(c, dir, plugin.PublisherPluginType, "pub")
+		So(c.SubscribeDeps("task", []core.Metric{subtree}, []core.Plugin{pub}), ShouldBeEmpty)
+
+		serrs := c.UnsubscribeDeps("task", []core.Metric{missing, subtree}, []core.Plugin{pub})
+		So(serrs, ShouldNotBeEmpty)
+		So(foo.SubscriptionCount(), ShouldEqual, 1)
+		pool, err := c.pluginRunner.AvailablePlugins().getPool(pub.Key())
+		So(err, ShouldBeNil)
+		So(pool.SubscriptionCount(), ShouldEqual, 1)
+		So(c.subtrees.table["task"], ShouldHaveLength, 1)
+	})
+}
@@ -0,0 +1,224 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// subtreeSubscription is the subscription of a task to the metrics under a
+// namespace prefix, including metrics cataloged after it was made.
+type subtreeSubscription struct {
+	prefix  core.Namespace
+	version int
+	// config is the config the metrics under the subtree are subscribed
+	// with
+	config *cdata.ConfigDataNode
+	// plugins are the collectors subscribed to for the subtree by key
+	plugins map[string]core.Plugin
+}
+
+// matches returns whether the subscription is for the same subtree as st.
+func (s *subtreeSubscription) matches(st *subtreeSubscription) bool {
+	return s.prefix.String() == st.prefix.String() && s.version == st.version
+}
+
+// subtreeSubscriptions holds the subtree subscriptions of each task by ID.
+type subtreeSubscriptions struct {
+	sync.Mutex
+	table map[string][]*subtreeSubscription
+}
+
+func newSubtreeSubscriptions() *subtreeSubscriptions {
+	return &subtreeSubscriptions{
+		table: make(map[string][]*subtreeSubscription),
+	}
+}
+
+func (s *subtreeSubscriptions) add(taskID string, sts []*subtreeSubscription) {
+	s.Lock()
+	defer s.Unlock()
+	for _, st := range sts {
+		s.table[taskID] = append(s.table[taskID], st)
+	}
+}
+
+// remove removes the subscription of the task to the subtree of st and
+// returns the collectors subscribed to for it.
+func (s *subtreeSubscriptions) remove(taskID string, st *subtreeSubscription) map[string]core.Plugin {
+	s.Lock()
+	defer s.Unlock()
+	sts := s.table[taskID]
+	for i, sub := range sts {
+		if !sub.matches(st) {
+			continue
+		}
+		s.table[taskID] = append(sts[:i], sts[i+1:]...)
+		if len(s.table[taskID]) == 0 {
+			delete(s.table, taskID)
+		}
+		return sub.plugins
+	}
+	return nil
+}
+
+// subtreePrefix returns the prefix of a namespace whose last element is a
+// static "*".  Subscribing to such a namespace subscribes to the subtree
+// under the prefix.  A dynamic last element, whose value is also "*", is part
+// of the namespace of a metric rather than a subtree.
+func subtreePrefix(ns core.Namespace) (core.Namespace, bool) {
+	if len(ns) < 2 {
+		return nil, false
+	}
+	if last := ns[len(ns)-1]; last.Value != "*" || last.IsDynamic() {
+		return nil, false
+	}
+	return ns[:len(ns)-1], true
+}
+
+// splitSubtrees returns the metrics which are not subtree subscriptions and
+// the subtree subscriptions for the others.
+func splitSubtrees(mts []core.Metric) ([]core.Metric, []*subtreeSubscription) {
+	var (
+		metrics  = make([]core.Metric, 0, len(mts))
+		subtrees []*subtreeSubscription
+	)
+	for _, mt := range mts {
+		prefix, ok := subtreePrefix(mt.Namespace())
+		if !ok {
+			metrics = append(metrics, mt)
+			continue
+		}
+		subtrees = append(subtrees, &subtreeSubscription{
+			prefix:  prefix,
+			version: mt.Version(),
+			config:  mt.Config(),
+			plugins: make(map[string]core.Plugin),
+		})
+	}
+	return metrics, subtrees
+}
+
+// subtreeMetrics returns a metric for each namespace in the catalog under
+// the prefix of the subscription with a metric of its version, with a copy of
+// the subscription's config.
+func (p *pluginControl) subtreeMetrics(st *subtreeSubscription) []core.Metric {
+	var mts []core.Metric
+	seen := map[string]bool{}
+	p.metricCatalog.Walk(func(mt *metricType) bool {
+		ns := mt.Namespace()
+		if len(ns) <= len(st.prefix) || (st.version > 0 && mt.Version() != st.version) {
+			return true
+		}
+		for i, e := range st.prefix {
+			if ns[i].Value != e.Value {
+				return true
+			}
+		}
+		if !seen[ns.String()] {
+			seen[ns.String()] = true
+			config := cdata.NewNode()
+			if st.config != nil {
+				config.Merge(st.config)
+			}
+			mts = append(mts, plugin.MetricType{Namespace_: ns, Version_: st.version, Config_: config})
+		}
+		return true
+	})
+	return mts
+}
+
+// validateSubtree validates the subscription to each metric under the
+// subtree as a subscription to the metric alone would be.
+func (p *pluginControl) validateSubtree(st *subtreeSubscription) []serror.SnapError {
+	var serrs []serror.SnapError
+	for _, mt := range p.subtreeMetrics(st) {
+		serrs = append(serrs, p.validateMetricTypeSubscription(mt, mt.Config())...)
+	}
+	return serrs
+}
+
+// subscribeSubtree subscribes the task to the collectors of the metrics
+// under the subtree which it is not yet subscribed to for it, returning the
+// pools subscribed to.  The metrics are validated first and none are
+// subscribed to if any fails validation.
+func (p *pluginControl) subscribeSubtree(taskID string, st *subtreeSubscription) ([]subscribedPool, serror.SnapError) {
+	mts := p.subtreeMetrics(st)
+	if len(mts) == 0 {
+		return nil, nil
+	}
+	for _, mt := range mts {
+		if serrs := p.validateMetricTypeSubscription(mt, mt.Config()); len(serrs) > 0 {
+			return nil, serrs[0]
+		}
+	}
+	collectors, errs := p.gatherCollectors(mts)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	var subscribed []subscribedPool
+	for _, gc := range collectors {
+		key := pluginKey(gc.plugin)
+		if _, ok := st.plugins[key]; ok {
+			continue
+		}
+		sp, serr := p.subscribeCollector(taskID, gc)
+		if sp.pool != nil {
+			subscribed = append(subscribed, sp)
+		}
+		if serr != nil {
+			return subscribed, serr
+		}
+		st.plugins[key] = gc.plugin
+	}
+	return subscribed, nil
+}
+
+// resubscribeSubtrees resolves the subtree subscriptions again after plugins
+// are loaded or unloaded, subscribing tasks to the collectors of metrics
+// newly cataloged under their subtrees.
+func (p *pluginControl) resubscribeSubtrees() {
+	p.subtrees.Lock()
+	defer p.subtrees.Unlock()
+	for taskID, sts := range p.subtrees.table {
+		for _, st := range sts {
+			for key := range st.plugins {
+				if _, err := p.pluginManager.get(key); err != nil {
+					delete(st.plugins, key)
+				}
+			}
+			if _, serr := p.subscribeSubtree(taskID, st); serr != nil {
+				controlLogger.WithFields(log.Fields{
+					"_block":  "resubscribe-subtrees",
+					"task-id": taskID,
+					"prefix":  st.prefix.String(),
+					"version": st.version,
+				}).Error(serr)
+			}
+		}
+	}
+}
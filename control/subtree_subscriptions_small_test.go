@@ -0,0 +1,127 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// addSubtreeCollector loads a collector running in a pool with metrics of
+// the namespaces.
+func addSubtreeCollector(c *pluginControl, name string, namespaces ...core.Namespace) strategy.Pool {
+	lp := &loadedPlugin{
+		Type:         plugin.CollectorPluginType,
+		Meta:         plugin.PluginMeta{Name: name, Version: 1},
+		ConfigPolicy: cpolicy.New(),
+		State:        LoadedState,
+	}
+	So(c.pluginManager.(*pluginManager).loadedPlugins.add(lp), ShouldBeNil)
+	for _, ns := range namespaces {
+		mt := plugin.MetricType{Namespace_: ns, Version_: 1}
+		So(c.metricCatalog.AddLoadedMetricType(lp, mt), ShouldBeNil)
+	}
+	ap := newFakeAvailablePlugin(plugin.CollectorPluginType, name, 1, &fakeCollectorClient{})
+	pool := addPool(c.pluginRunner.AvailablePlugins(), lp.Key(), ap)
+	// subscribing must not start more instances
+	pool.SetMax(1)
+	return pool
+}
+
+func subscribedTasks(pool strategy.Pool) []string {
+	taskIDs := []string{}
+	for _, sub := range pool.Subscriptions() {
+		taskIDs = append(taskIDs, sub.TaskID)
+	}
+	return taskIDs
+}
+
+func TestSubtreeSubscriptions(t *testing.T) {
+	Convey("A subtree subscription subscribes to current and future collectors under the prefix", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		foo := addSubtreeCollector(c, "foo", core.NewNamespace("intel", "mock", "foo"))
+		other := addSubtreeCollector(c, "other", core.NewNamespace("intel", "other", "foo"))
+		subtree := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "*")}
+
+		So(c.SubscribeDeps("task-1", []core.Metric{subtree}, nil), ShouldBeEmpty)
+		So(subscribedTasks(foo), ShouldResemble, []string{"task-1"})
+		So(subscribedTasks(other), ShouldBeEmpty)
+
+		bar := addSubtreeCollector(c, "bar", core.NewNamespace("intel", "mock", "bar", "baz"))
+		c.resubscribeSubtrees()
+		So(subscribedTasks(bar), ShouldResemble, []string{"task-1"})
+		So(subscribedTasks(other), ShouldBeEmpty)
+
+		So(c.UnsubscribeDeps("task-1", []core.Metric{subtree}, nil), ShouldBeEmpty)
+		So(subscribedTasks(foo), ShouldBeEmpty)
+		So(subscribedTasks(bar), ShouldBeEmpty)
+		c.resubscribeSubtrees()
+		So(subscribedTasks(bar), ShouldBeEmpty)
+	})
+	Convey("A subtree subscription without matching metrics waits for them", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		subtree := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "*")}
+
+		So(c.SubscribeDeps("task-1", []core.Metric{subtree}, nil), ShouldBeEmpty)
+		foo := addSubtreeCollector(c, "foo", core.NewNamespace("intel", "mock", "foo"))
+		c.resubscribeSubtrees()
+		So(subscribedTasks(foo), ShouldResemble, []string{"task-1"})
+	})
+	Convey("A subtree subscription of a version only subscribes to metrics of the version", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		foo := addSubtreeCollector(c, "foo", core.NewNamespace("intel", "mock", "foo"))
+		subtree := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "*"), Version_: 2}
+
+		So(c.SubscribeDeps("task-1", []core.Metric{subtree}, nil), ShouldBeEmpty)
+		So(subscribedTasks(foo), ShouldBeEmpty)
+	})
+	Convey("A namespace ending in a dynamic element is not a subtree", t, func() {
+		_, ok := subtreePrefix(core.NewNamespace("intel", "mock").AddDynamicElement("host", "host name"))
+		So(ok, ShouldBeFalse)
+		prefix, ok := subtreePrefix(core.NewNamespace("intel", "mock", "*"))
+		So(ok, ShouldBeTrue)
+		So(prefix.String(), ShouldEqual, "/intel/mock")
+	})
+	Convey("The metrics under a subtree are validated against their config policy", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		addConfigurableCollector(c, &configRecordingClient{})
+		subtree := plugin.MetricType{Namespace_: core.NewNamespace("intel", "tunable", "*")}
+		serrs := c.ValidateDeps([]core.Metric{subtree}, nil)
+		So(len(serrs), ShouldEqual, 1)
+		So(serrs[0].Fields()[SubscriptionErrorCategoryField], ShouldEqual, ConfigPolicyError)
+		serrs = c.SubscribeDeps("task-1", []core.Metric{subtree}, nil)
+		So(len(serrs), ShouldEqual, 1)
+		So(serrs[0].Fields()[SubscriptionErrorCategoryField], ShouldEqual, ConfigPolicyError)
+		configured := plugin.MetricType{Namespace_: subtree.Namespace(), Config_: configWithLimit(5)}
+		So(c.ValidateDeps([]core.Metric{configured}, nil), ShouldBeEmpty)
+	})
+}
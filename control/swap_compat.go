@@ -0,0 +1,117 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrSwapIncompatible is returned by SwapPlugins when the plugin swapped
+	// in does not accept or return the content types, or expose the metrics,
+	// of the plugin swapped out.  SwapPluginsForce swaps them regardless.
+	ErrSwapIncompatible = errors.New("Plugin swapped in is incompatible with the plugin swapped out")
+)
+
+// swapCompatibility returns an ErrSwapIncompatible error if tasks using out
+// could break when in replaces it.  Publishers and processors swapped in must
+// accept, and processors return, each content type out did.  Collectors must
+// expose each metric namespace out did.
+func (p *pluginControl) swapCompatibility(in *loadedPlugin, out core.CatalogedPlugin) serror.SnapError {
+	outLp, err := p.pluginManager.get(pluginKey(out))
+	if err != nil {
+		// unloading out fails for the same reason
+		return nil
+	}
+	return p.compatibility(in, outLp, p.pluginNamespaces(outLp))
+}
+
+// compatibility returns an ErrSwapIncompatible error if in does not accept or
+// return the content types of out, or does not expose each of the metric
+// namespaces of out.
+func (p *pluginControl) compatibility(in, out *loadedPlugin, namespaces []string) serror.SnapError {
+	f := map[string]interface{}{}
+	switch in.Type {
+	case plugin.CollectorPluginType:
+		if missing := missingNamespaces(p.pluginNamespaces(in), namespaces); len(missing) > 0 {
+			f["missing-namespaces"] = missing
+		}
+	case plugin.ProcessorPluginType:
+		if missing := missingContentTypes(in.Meta.ReturnedContentTypes, out.Meta.ReturnedContentTypes); len(missing) > 0 {
+			f["missing-returned-content-types"] = missing
+		}
+		fallthrough
+	case plugin.PublisherPluginType:
+		if missing := missingContentTypes(in.Meta.AcceptedContentTypes, out.Meta.AcceptedContentTypes); len(missing) > 0 {
+			f["missing-accepted-content-types"] = missing
+		}
+	}
+	if len(f) == 0 {
+		return nil
+	}
+	f["in-plugin"] = in.Key()
+	f["out-plugin"] = out.Key()
+	return serror.New(ErrSwapIncompatible, f)
+}
+
+// missingContentTypes returns the content types of want which none of have
+// match.
+func missingContentTypes(have, want []string) []string {
+	missing := []string{}
+	for _, w := range want {
+		if !feedsInto([]string{w}, have) {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
+
+// pluginNamespaces returns the namespaces of the metrics of lp in the catalog,
+// in catalog order.
+func (p *pluginControl) pluginNamespaces(lp *loadedPlugin) []string {
+	var namespaces []string
+	p.metricCatalog.Walk(func(mt *metricType) bool {
+		if mt.Plugin != nil && mt.Plugin.Key() == lp.Key() {
+			namespaces = append(namespaces, mt.Namespace().String())
+		}
+		return true
+	})
+	return namespaces
+}
+
+// missingNamespaces returns the namespaces of want which have does not
+// contain.
+func missingNamespaces(have, want []string) []string {
+	found := map[string]bool{}
+	for _, ns := range have {
+		found[ns] = true
+	}
+	missing := []string{}
+	for _, ns := range want {
+		if !found[ns] {
+			missing = append(missing, ns)
+		}
+	}
+	return missing
+}
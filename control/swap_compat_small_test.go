@@ -0,0 +1,151 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intelsdi-x/gomit"
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// swapInManager loads in, with metrics of the namespaces if it is a
+// collector, whatever plugin is requested.
+type swapInManager struct {
+	*pluginManager
+	in         *loadedPlugin
+	namespaces []core.Namespace
+}
+
+func (m *swapInManager) LoadPlugin(*pluginDetails, gomit.Emitter) (*loadedPlugin, serror.SnapError) {
+	if err := m.loadedPlugins.add(m.in); err != nil {
+		return nil, err
+	}
+	for _, ns := range m.namespaces {
+		mt := plugin.MetricType{Namespace_: ns, Version_: m.in.Version()}
+		if err := m.metricCatalog.AddLoadedMetricType(m.in, mt); err != nil {
+			return nil, serror.New(err)
+		}
+	}
+	return m.in, nil
+}
+
+// swapPlugin returns version of the named plugin of type typ in a
+// LoadedState.
+func swapPlugin(typ plugin.PluginType, name string, version int) *loadedPlugin {
+	return &loadedPlugin{
+		Type:         typ,
+		Meta:         plugin.PluginMeta{Name: name, Version: version, Type: typ},
+		ConfigPolicy: cpolicy.New(),
+		State:        LoadedState,
+		Details:      &pluginDetails{IsAutoLoaded: true},
+	}
+}
+
+// requestedSwap returns a requested plugin for a file in dir.
+func requestedSwap(dir string) *core.RequestedPlugin {
+	path := filepath.Join(dir, "snap-plugin")
+	So(ioutil.WriteFile(path, []byte("plugin"), 0755), ShouldBeNil)
+	rp, err := core.NewRequestedPlugin(path)
+	So(err, ShouldBeNil)
+	return rp
+}
+
+func TestSwapCompatibility(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snap-swap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	Convey("A processor which does not accept a content type is not swapped in", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		pm := c.pluginManager.(*pluginManager)
+		out := swapPlugin(plugin.ProcessorPluginType, "passthru", 1)
+		out.Meta.AcceptedContentTypes = []string{plugin.SnapGOBContentType, plugin.SnapJSONContentType}
+		out.Meta.ReturnedContentTypes = []string{plugin.SnapGOBContentType}
+		So(pm.loadedPlugins.add(out), ShouldBeNil)
+		in := swapPlugin(plugin.ProcessorPluginType, "passthru", 2)
+		in.Meta.AcceptedContentTypes = []string{plugin.SnapGOBContentType}
+		in.Meta.ReturnedContentTypes = []string{plugin.SnapGOBContentType}
+		c.pluginManager = &swapInManager{pluginManager: pm, in: in}
+
+		serr := c.SwapPlugins(requestedSwap(dir), out)
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, ErrSwapIncompatible.Error())
+		So(serr.Fields()["missing-accepted-content-types"], ShouldResemble, []string{plugin.SnapJSONContentType})
+		So(serr.Fields()["missing-returned-content-types"], ShouldBeNil)
+		_, err := pm.get(in.Key())
+		So(err, ShouldEqual, ErrPluginNotFound)
+		_, err = pm.get(out.Key())
+		So(err, ShouldBeNil)
+
+		Convey("unless the swap is forced", func() {
+			So(c.SwapPluginsForce(requestedSwap(dir), out), ShouldBeNil)
+			_, err := pm.get(in.Key())
+			So(err, ShouldBeNil)
+			_, err = pm.get(out.Key())
+			So(err, ShouldEqual, ErrPluginNotFound)
+		})
+	})
+	Convey("A publisher accepting any snap content type is swapped in", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		pm := c.pluginManager.(*pluginManager)
+		out := swapPlugin(plugin.PublisherPluginType, "file", 1)
+		out.Meta.AcceptedContentTypes = []string{plugin.SnapGOBContentType}
+		So(pm.loadedPlugins.add(out), ShouldBeNil)
+		in := swapPlugin(plugin.PublisherPluginType, "file", 2)
+		in.Meta.AcceptedContentTypes = []string{plugin.SnapAllContentType}
+		c.pluginManager = &swapInManager{pluginManager: pm, in: in}
+
+		So(c.SwapPlugins(requestedSwap(dir), out), ShouldBeNil)
+	})
+	Convey("A collector which does not expose a metric is not swapped in", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		pm := c.pluginManager.(*pluginManager)
+		out := swapPlugin(plugin.CollectorPluginType, "mock", 1)
+		So(pm.loadedPlugins.add(out), ShouldBeNil)
+		catalogMetric(c, out)
+		So(c.metricCatalog.AddLoadedMetricType(out, plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "bar"), Version_: 1}), ShouldBeNil)
+		in := swapPlugin(plugin.CollectorPluginType, "mock", 2)
+		c.pluginManager = &swapInManager{pluginManager: pm, in: in, namespaces: []core.Namespace{core.NewNamespace("intel", "mock", "foo")}}
+
+		serr := c.SwapPlugins(requestedSwap(dir), out)
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, ErrSwapIncompatible.Error())
+		So(serr.Fields()["missing-namespaces"], ShouldResemble, []string{"/intel/mock/bar"})
+		mts, err := c.metricCatalog.GetVersions(core.NewNamespace("intel", "mock", "foo"))
+		So(err, ShouldBeNil)
+		So(len(mts), ShouldEqual, 1)
+	})
+}
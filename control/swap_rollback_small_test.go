@@ -0,0 +1,108 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intelsdi-x/gomit"
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// swapFailManager loads version 2 of the mock collector with a metric and
+// fails to unload the plugin of failKey.
+type swapFailManager struct {
+	*pluginManager
+	failKey string
+}
+
+func (m *swapFailManager) LoadPlugin(*pluginDetails, gomit.Emitter) (*loadedPlugin, serror.SnapError) {
+	lp := newCollector("mock")
+	lp.Meta.Version = 2
+	lp.State = LoadedState
+	lp.Details = &pluginDetails{IsAutoLoaded: true}
+	if err := m.loadedPlugins.add(lp); err != nil {
+		return nil, err
+	}
+	mt := plugin.MetricType{Namespace_: core.NewNamespace("intel", "mock", "foo"), Version_: 2}
+	if err := m.metricCatalog.AddLoadedMetricType(lp, mt); err != nil {
+		return nil, serror.New(err)
+	}
+	return lp, nil
+}
+
+func (m *swapFailManager) UnloadPlugin(pl core.Plugin) (*loadedPlugin, serror.SnapError) {
+	if pluginKey(pl) == m.failKey {
+		return nil, serror.New(errors.New("unload failed"))
+	}
+	return m.pluginManager.UnloadPlugin(pl)
+}
+
+func TestSwapPluginsRollback(t *testing.T) {
+	Convey("Given a swap which fails to unload the plugin swapped out", t, func() {
+		dir, err := ioutil.TempDir("", "snap-swap")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+		path := filepath.Join(dir, "snap-collector-mock")
+		So(ioutil.WriteFile(path, []byte("mock"), 0755), ShouldBeNil)
+		rp, err := core.NewRequestedPlugin(path)
+		So(err, ShouldBeNil)
+
+		c := New(GetDefaultConfig())
+		c.Started = true
+		pm := c.pluginManager.(*pluginManager)
+		out := newCollector("mock")
+		out.State = LoadedState
+		out.Details = &pluginDetails{IsAutoLoaded: true}
+		So(pm.loadedPlugins.add(out), ShouldBeNil)
+		catalogMetric(c, out)
+		c.pluginManager = &swapFailManager{pluginManager: pm, failKey: out.Key()}
+
+		serr := c.SwapPlugins(rp, out)
+
+		Convey("the error describes the rollback", func() {
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, ErrSwapRolledBack.Error())
+			So(serr.Fields()["original-unload-error"], ShouldEqual, "unload failed")
+			So(serr.Fields()["rolled-back-plugin"], ShouldEqual, "collector:mock:2")
+			So(serr.Fields()["plugin-key"], ShouldEqual, "collector:mock:1")
+		})
+		Convey("the plugin swapped in is unloaded with its metrics", func() {
+			_, err := pm.get("collector:mock:2")
+			So(err, ShouldEqual, ErrPluginNotFound)
+			_, err = pm.get("collector:mock:1")
+			So(err, ShouldBeNil)
+			mts, err := c.metricCatalog.GetVersions(core.NewNamespace("intel", "mock", "foo"))
+			So(err, ShouldBeNil)
+			So(len(mts), ShouldEqual, 1)
+			So(mts[0].Version(), ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,134 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/ctypes"
+)
+
+// TagFilterConfigKey is the metric config key holding a comma separated list
+// of key=value tags, such as "env=prod,region=us".  Only the collected
+// metrics carrying every listed tag are returned for the subscription.
+const TagFilterConfigKey = "snap.tag_filter"
+
+// tagFilterPolicy validates the tag filter in a metric's config.
+var tagFilterPolicy = newTagFilterPolicy()
+
+func newTagFilterPolicy() *cpolicy.ConfigPolicyNode {
+	rule, _ := cpolicy.NewStringRule(TagFilterConfigKey, false)
+	node := cpolicy.NewPolicyNode()
+	node.Add(rule)
+	return node
+}
+
+// validateTagFilter returns an error if the tag filter in the config table
+// is not a valid list of key=value tags.
+func validateTagFilter(table map[string]ctypes.ConfigValue) error {
+	if _, errs := tagFilterPolicy.Process(table); errs.HasErrors() {
+		return errs.Errors()[0]
+	}
+	v, ok := table[TagFilterConfigKey].(ctypes.ConfigValueStr)
+	if !ok {
+		return nil
+	}
+	if _, err := parseTagFilter(v.Value); err != nil {
+		return &cpolicy.KeyError{Key: TagFilterConfigKey, Err: err}
+	}
+	return nil
+}
+
+// parseTagFilter parses a comma separated list of key=value tags.
+func parseTagFilter(s string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, kv := range strings.Split(s, ",") {
+		t := strings.SplitN(strings.TrimSpace(kv), "=", 2)
+		if len(t) != 2 || t[0] == "" {
+			return nil, fmt.Errorf("invalid tag filter (%s): expected key=value", kv)
+		}
+		tags[t[0]] = t[1]
+	}
+	return tags, nil
+}
+
+// filterMetricsByTags drops the collected metrics which do not carry every
+// tag in the tag filter of the config of the requested metric they match.
+// The filter is read from the requested metrics since the key is not passed
+// to the plugins.
+func filterMetricsByTags(requested, mts []core.Metric) []core.Metric {
+	filters := tagFilters(requested)
+	if len(filters) == 0 {
+		return mts
+	}
+	filtered := mts[:0]
+	for _, m := range mts {
+		if matchesTagFilter(m, filters) {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+// tagFilter holds the tags the metrics collected for the requested namespace
+// must carry.
+type tagFilter struct {
+	namespace core.Namespace
+	tags      map[string]string
+}
+
+// tagFilters returns the tag filters in the configs of the requested metrics.
+func tagFilters(requested []core.Metric) []tagFilter {
+	var filters []tagFilter
+	for _, r := range requested {
+		if r.Config() == nil {
+			continue
+		}
+		v, ok := r.Config().Table()[TagFilterConfigKey].(ctypes.ConfigValueStr)
+		if !ok {
+			continue
+		}
+		tags, err := parseTagFilter(v.Value)
+		if err != nil {
+			continue
+		}
+		filters = append(filters, tagFilter{namespace: r.Namespace(), tags: tags})
+	}
+	return filters
+}
+
+func matchesTagFilter(m core.Metric, filters []tagFilter) bool {
+	for _, f := range filters {
+		if !namespaceMatches(f.namespace, m.Namespace()) {
+			continue
+		}
+		tags := m.Tags()
+		for k, val := range f.tags {
+			if tv, ok := tags[k]; !ok || tv != val {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
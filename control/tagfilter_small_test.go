@@ -0,0 +1,114 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+	"time"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/cdata"
+	"github.com/intelsdi-x/snap/core/ctypes"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTagFilter(t *testing.T) {
+	Convey("Given metrics subscribed to with a tag filter", t, func() {
+		cfg := cdata.NewNode()
+		cfg.AddItem(TagFilterConfigKey, ctypes.ConfigValueStr{Value: "env=prod, region=us"})
+		requested := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("a"), Config_: cfg},
+			plugin.MetricType{Namespace_: core.NewNamespace("b"), Config_: cfg},
+			plugin.MetricType{Namespace_: core.NewNamespace("c"), Config_: cfg},
+			plugin.MetricType{Namespace_: core.NewNamespace("d"), Config_: cdata.NewNode()},
+		}
+		mts := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("a"), Tags_: map[string]string{"env": "prod", "region": "us"}},
+			plugin.MetricType{Namespace_: core.NewNamespace("b"), Tags_: map[string]string{"env": "dev", "region": "us"}},
+			plugin.MetricType{Namespace_: core.NewNamespace("c"), Tags_: map[string]string{"env": "prod"}},
+			plugin.MetricType{Namespace_: core.NewNamespace("d")},
+		}
+		Convey("only metrics carrying every tag are kept", func() {
+			filtered := filterMetricsByTags(requested, mts)
+			So(len(filtered), ShouldEqual, 2)
+			So(filtered[0].Namespace().String(), ShouldEqual, "/a")
+			So(filtered[1].Namespace().String(), ShouldEqual, "/d")
+		})
+	})
+	Convey("The filter of a dynamic metric applies to the metrics collected for it", t, func() {
+		cfg := cdata.NewNode()
+		cfg.AddItem(TagFilterConfigKey, ctypes.ConfigValueStr{Value: "env=prod"})
+		requested := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "*", "load"), Config_: cfg},
+		}
+		mts := []core.Metric{
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "cpu0", "load"), Tags_: map[string]string{"env": "prod"}},
+			plugin.MetricType{Namespace_: core.NewNamespace("intel", "cpu1", "load"), Tags_: map[string]string{"env": "dev"}},
+		}
+		filtered := filterMetricsByTags(requested, mts)
+		So(len(filtered), ShouldEqual, 1)
+		So(filtered[0].Namespace().String(), ShouldEqual, "/intel/cpu0/load")
+	})
+	Convey("validateTagFilter", t, func() {
+		Convey("accepts a list of key=value tags", func() {
+			cfg := cdata.NewNode()
+			cfg.AddItem(TagFilterConfigKey, ctypes.ConfigValueStr{Value: "env=prod"})
+			So(validateTagFilter(cfg.Table()), ShouldBeNil)
+		})
+		Convey("rejects a malformed filter", func() {
+			cfg := cdata.NewNode()
+			cfg.AddItem(TagFilterConfigKey, ctypes.ConfigValueStr{Value: "env"})
+			So(validateTagFilter(cfg.Table()), ShouldNotBeNil)
+		})
+		Convey("rejects a filter which is not a string", func() {
+			cfg := cdata.NewNode()
+			cfg.AddItem(TagFilterConfigKey, ctypes.ConfigValueInt{Value: 1})
+			So(validateTagFilter(cfg.Table()), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestTagFilterConfigKey(t *testing.T) {
+	Convey("The tag filter is applied without being passed to the plugin", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		cli := &configRecordingClient{}
+		mt := addConfigurableCollector(c, cli).(plugin.MetricType)
+		cfg := configWithLimit(5)
+		cfg.AddItem(TagFilterConfigKey, ctypes.ConfigValueStr{Value: "env=prod"})
+		mt.Config_ = cfg
+
+		metrics, errs := c.CollectMetrics([]core.Metric{mt}, time.Now().Add(time.Second), "task", nil)
+		So(errs, ShouldBeEmpty)
+		So(metrics, ShouldBeEmpty)
+		So(cli.config, ShouldNotBeNil)
+		So(cli.config.Table()["limit"], ShouldResemble, ctypes.ConfigValueInt{Value: 5})
+		_, ok := cli.config.Table()[TagFilterConfigKey]
+		So(ok, ShouldBeFalse)
+
+		tags := map[string]map[string]string{"/intel/tunable": {"env": "prod"}}
+		metrics, errs = c.CollectMetrics([]core.Metric{mt}, time.Now().Add(time.Second), "task", tags)
+		So(errs, ShouldBeEmpty)
+		So(metrics, ShouldHaveLength, 1)
+	})
+}
@@ -0,0 +1,79 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+// SetRoutingStrategyForType changes the routing and caching strategy of the
+// running plugins of the plugin type as SetRoutingStrategy does for each of
+// them.  Plugins of the type started later use the strategy rather than the
+// one they declare.  Plugins of types without a strategy set use the
+// strategy they declare.
+func (p *pluginControl) SetRoutingStrategyForType(t core.PluginType, r plugin.RoutingStrategyType) error {
+	f := map[string]interface{}{
+		"plugin-type": t.String(),
+	}
+	if !p.Started {
+		return serror.New(ErrControllerNotStarted, f)
+	}
+	if r < plugin.DefaultRouting || r > plugin.LeastLoadedRouting {
+		return serror.New(strategy.ErrBadStrategy, f)
+	}
+	aps := p.pluginRunner.AvailablePlugins()
+	aps.Lock()
+	aps.typeStrategies[t] = r
+	var keys []string
+	for key := range aps.table {
+		if typ, _, _, err := core.ParsePluginKey(key); err == nil && typ == t {
+			keys = append(keys, key)
+		}
+	}
+	aps.Unlock()
+
+	for _, key := range keys {
+		if err := p.SetRoutingStrategy(key, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyTypeStrategy sets the routing strategy set for the type of the plugin
+// on the pool the plugin was inserted into.  The caller must hold the lock.
+func (ap *availablePlugins) applyTypeStrategy(pool strategy.Pool, pl *availablePlugin) {
+	r, ok := ap.typeStrategies[core.PluginType(pl.pluginType)]
+	if !ok {
+		return
+	}
+	if err := pool.SetStrategy(r); err != nil {
+		controlLogger.WithFields(log.Fields{
+			"_block":   "apply-type-strategy",
+			"pool-key": core.PluginKey(core.PluginType(pl.pluginType), pl.name, pl.version),
+			"strategy": r.String(),
+		}).Error(err)
+	}
+}
@@ -0,0 +1,67 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSetRoutingStrategyForType(t *testing.T) {
+	Convey("The strategy is set on the running plugins of the type", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		addFakeCollector(c, "mock", &fakeCollectorClient{})
+		addFakePlugin(c, plugin.PublisherPluginType, "file", &failingClient{})
+		So(c.SetRoutingStrategyForType(core.PublisherPluginType, plugin.StickyRouting), ShouldBeNil)
+		aps := c.pluginRunner.AvailablePlugins()
+		pool, err := aps.getPool("publisher:file:1")
+		So(err, ShouldBeNil)
+		So(pool.Strategy().String(), ShouldEqual, "sticky")
+		pool, err = aps.getPool("collector:mock:1")
+		So(err, ShouldBeNil)
+		So(pool.Strategy().String(), ShouldEqual, "least-recently-used")
+	})
+	Convey("Plugins of the type started later use the strategy", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		So(c.SetRoutingStrategyForType(core.PublisherPluginType, plugin.LeastLoadedRouting), ShouldBeNil)
+		aps := c.pluginRunner.AvailablePlugins()
+		So(aps.insert(newFakeAvailablePlugin(plugin.PublisherPluginType, "file", 1, &failingClient{})), ShouldBeNil)
+		So(aps.insert(newFakeAvailablePlugin(plugin.CollectorPluginType, "mock", 1, &failingClient{})), ShouldBeNil)
+		pool, err := aps.getPool("publisher:file:1")
+		So(err, ShouldBeNil)
+		So(pool.Strategy().String(), ShouldEqual, "least-loaded")
+		pool, err = aps.getPool("collector:mock:1")
+		So(err, ShouldBeNil)
+		So(pool.Strategy().String(), ShouldEqual, "least-recently-used")
+	})
+	Convey("An unknown strategy is rejected", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		So(c.SetRoutingStrategyForType(core.CollectorPluginType, plugin.RoutingStrategyType(99)), ShouldNotBeNil)
+	})
+}
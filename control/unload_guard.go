@@ -0,0 +1,75 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/intelsdi-x/snap/control/strategy"
+	"github.com/intelsdi-x/snap/core"
+	"github.com/intelsdi-x/snap/core/serror"
+)
+
+var (
+	// ErrPluginSubscribed is returned by Unload when tasks are subscribed to
+	// the plugin.  UnloadForce unloads the plugin regardless.
+	ErrPluginSubscribed = errors.New("Plugin has task subscriptions")
+)
+
+// checkUnloadSubscriptions returns an ErrPluginSubscribed error listing the
+// tasks which would be left without a plugin if pl were unloaded.  Bound
+// subscriptions always are, unbound ones only if no other version of the
+// plugin is loaded to move them to.
+func (p *pluginControl) checkUnloadSubscriptions(pl core.Plugin) serror.SnapError {
+	key := pluginKey(pl)
+	aps := p.pluginRunner.AvailablePlugins()
+	aps.RLock()
+	pool, ok := aps.table[key]
+	aps.RUnlock()
+	if !ok {
+		return nil
+	}
+	otherVersion := false
+	for _, lp := range p.pluginManager.all() {
+		if lp.TypeName() == pl.TypeName() && lp.Name() == pl.Name() && lp.Version() != pl.Version() {
+			otherVersion = true
+			break
+		}
+	}
+	taskIDs := []string{}
+	for _, sub := range pool.Subscriptions() {
+		if sub.SubType == strategy.UnboundSubscriptionType && otherVersion {
+			continue
+		}
+		taskIDs = append(taskIDs, sub.TaskID)
+	}
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	sort.Strings(taskIDs)
+	return serror.New(ErrPluginSubscribed, map[string]interface{}{
+		"plugin-name":        pl.Name(),
+		"plugin-version":     pl.Version(),
+		"plugin-type":        pl.TypeName(),
+		"subscription-count": len(taskIDs),
+		"task-ids":           taskIDs,
+	})
+}
@@ -0,0 +1,87 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	"github.com/intelsdi-x/snap/control/plugin"
+	"github.com/intelsdi-x/snap/control/strategy"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// subscribedCollector loads version 1 of the mock collector with a pool
+// holding a subscription of the given type.
+func subscribedCollector(c *pluginControl, subType strategy.SubscriptionType) {
+	lp := newCollector("mock")
+	lp.State = LoadedState
+	lp.Details = &pluginDetails{IsAutoLoaded: true}
+	So(c.pluginManager.(*pluginManager).loadedPlugins.add(lp), ShouldBeNil)
+	pool, err := c.pluginRunner.AvailablePlugins().getOrCreatePool(lp.Key())
+	So(err, ShouldBeNil)
+	pool.Subscribe("task-1", subType)
+}
+
+func TestUnloadSubscribed(t *testing.T) {
+	Convey("A plugin with a bound subscription is not unloaded", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		subscribedCollector(c, strategy.BoundSubscriptionType)
+		lp, _ := c.pluginManager.get("collector:mock:1")
+		_, serr := c.Unload(lp)
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, ErrPluginSubscribed.Error())
+		So(serr.Fields()["subscription-count"], ShouldEqual, 1)
+		So(serr.Fields()["task-ids"], ShouldResemble, []string{"task-1"})
+		_, err := c.pluginManager.get("collector:mock:1")
+		So(err, ShouldBeNil)
+
+		Convey("unless it is forced", func() {
+			_, serr := c.UnloadForce(lp)
+			So(serr, ShouldBeNil)
+			_, err := c.pluginManager.get("collector:mock:1")
+			So(err, ShouldEqual, ErrPluginNotFound)
+		})
+	})
+	Convey("A plugin with an unbound subscription and no other version is not unloaded", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		subscribedCollector(c, strategy.UnboundSubscriptionType)
+		lp, _ := c.pluginManager.get("collector:mock:1")
+		_, serr := c.Unload(lp)
+		So(serr, ShouldNotBeNil)
+		So(serr.Error(), ShouldEqual, ErrPluginSubscribed.Error())
+	})
+	Convey("A plugin with an unbound subscription and another version loaded is unloaded", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		subscribedCollector(c, strategy.UnboundSubscriptionType)
+		v2 := newCollector("mock")
+		v2.Meta = plugin.PluginMeta{Name: "mock", Version: 2}
+		v2.State = LoadedState
+		So(c.pluginManager.(*pluginManager).loadedPlugins.add(v2), ShouldBeNil)
+		lp, _ := c.pluginManager.get("collector:mock:1")
+		_, serr := c.Unload(lp)
+		So(serr, ShouldBeNil)
+	})
+}
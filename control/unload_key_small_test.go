@@ -0,0 +1,58 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package control
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnloadByKey(t *testing.T) {
+	Convey("Given a loaded collector", t, func() {
+		c := New(GetDefaultConfig())
+		c.Started = true
+		lp := newCollector("mock")
+		lp.State = LoadedState
+		lp.Details = &pluginDetails{IsAutoLoaded: true}
+		So(c.pluginManager.(*pluginManager).loadedPlugins.add(lp), ShouldBeNil)
+
+		Convey("a malformed key is an error", func() {
+			_, serr := c.UnloadByKey("collector:mock")
+			So(serr, ShouldNotBeNil)
+			So(serr.Fields()["plugin-key"], ShouldEqual, "collector:mock")
+		})
+		Convey("a plugin which is not loaded is an error", func() {
+			_, serr := c.UnloadByKey("collector:mock:2")
+			So(serr, ShouldNotBeNil)
+			So(serr.Error(), ShouldEqual, ErrPluginNotFound.Error())
+		})
+		Convey("the plugin of the key is unloaded", func() {
+			up, serr := c.UnloadByKey("collector:mock:1")
+			So(serr, ShouldBeNil)
+			So(up.Name(), ShouldEqual, "mock")
+			So(up.Version(), ShouldEqual, 1)
+			_, err := c.pluginManager.get("collector:mock:1")
+			So(err, ShouldEqual, ErrPluginNotFound)
+		})
+	})
+}
@@ -19,13 +19,17 @@ limitations under the License.
 
 package control_event
 
+import "time"
+
 const (
 	AvailablePluginDead      = "Control.AvailablePluginDead"
 	AvailablePluginRestarted = "Control.RestartedAvailablePlugin"
+	PluginCrashed            = "Control.PluginCrashed"
 	PluginRestartsExceeded   = "Control.PluginRestartsExceeded"
 	PluginLoaded             = "Control.PluginLoaded"
 	PluginUnloaded           = "Control.PluginUnloaded"
 	PluginsSwapped           = "Control.PluginsSwapped"
+	PluginsSwapRolledBack    = "Control.PluginsSwapRolledBack"
 	PluginSubscribed         = "Control.PluginSubscribed"
 	PluginUnsubscribed       = "Control.PluginUnsubscribed"
 	ProcessorSubscribed      = "Control.ProcessorSubscribed"
@@ -34,6 +38,26 @@ const (
 	MetricUnsubscribed       = "Control.MetricUnsubscribed"
 	HealthCheckFailed        = "Control.PluginHealthCheckFailed"
 	MoveSubscription         = "Control.PluginSubscriptionMoved"
+	SignatureInvalidated     = "Control.PluginSignatureInvalidated"
+	MetricStale              = "Control.MetricStale"
+	CollectFallback          = "Control.CollectFallback"
+	PluginReloaded           = "Control.PluginReloaded"
+	StrategyChanged          = "Control.PluginStrategyChanged"
+	PoolStats                = "Control.PoolStats"
+	PluginPaused             = "Control.PluginPaused"
+	PluginResumed            = "Control.PluginResumed"
+	KeyringReloaded          = "Control.KeyringReloaded"
+	CollectResponseCapped    = "Control.CollectResponseCapped"
+	SignatureValidated       = "Control.PluginSignatureValidated"
+)
+
+// Outcomes of validating the signature of a plugin being loaded.
+const (
+	SignatureValid   = "valid"
+	SignatureInvalid = "invalid"
+	// SignatureSkipped is the outcome when plugin trust is disabled or an
+	// unsigned plugin is loaded with plugin trust in warn mode
+	SignatureSkipped = "skipped"
 )
 
 type LoadPluginEvent struct {
@@ -70,12 +94,33 @@ func (e *DeadAvailablePluginEvent) Namespace() string {
 	return AvailablePluginDead
 }
 
+// PluginCrashedEvent is emitted once when an available plugin is found dead
+// because it failed its health checks.
+type PluginCrashedEvent struct {
+	Name    string
+	Version int
+	Type    int
+	Key     string
+	Id      uint32
+	// Error is the error of the last failed health check
+	Error string
+}
+
+func (e *PluginCrashedEvent) Namespace() string {
+	return PluginCrashed
+}
+
 type RestartedAvailablePluginEvent struct {
 	Name    string
 	Version int
 	Type    int
 	Key     string
 	Id      uint32
+	// RestartCount is the number of times the plugins of the pool have been
+	// restarted, including this restart
+	RestartCount int
+	// Backoff is how long the restart was delayed for
+	Backoff time.Duration
 }
 
 func (e *MaxPluginRestartsExceededEvent) Namespace() string {
@@ -106,6 +151,22 @@ func (s SwapPluginsEvent) Namespace() string {
 	return PluginsSwapped
 }
 
+// SwapPluginsRolledBackEvent is emitted when a swap fails to unload the plugin
+// being swapped out and the plugin loaded in its place is unloaded again.
+type SwapPluginsRolledBackEvent struct {
+	RolledBackPluginName    string
+	RolledBackPluginVersion int
+	OutPluginName           string
+	OutPluginVersion        int
+	PluginType              int
+	// Error is the error unloading the plugin being swapped out
+	Error string
+}
+
+func (s SwapPluginsRolledBackEvent) Namespace() string {
+	return PluginsSwapRolledBack
+}
+
 type PluginSubscriptionEvent struct {
 	PluginName       string
 	PluginVersion    int
@@ -150,3 +211,129 @@ type MovePluginSubscriptionEvent struct {
 func (mse MovePluginSubscriptionEvent) Namespace() string {
 	return MoveSubscription
 }
+
+type SignatureInvalidatedEvent struct {
+	Name    string
+	Version int
+	Type    int
+	Error   string
+}
+
+func (sie SignatureInvalidatedEvent) Namespace() string {
+	return SignatureInvalidated
+}
+
+type MetricStaleEvent struct {
+	TaskId          string
+	MetricNamespace string
+	LastSeen        time.Time
+}
+
+func (mse MetricStaleEvent) Namespace() string {
+	return MetricStale
+}
+
+type CollectFallbackEvent struct {
+	TaskId         string
+	PrimaryPlugin  string
+	FallbackPlugin string
+	Namespaces     []string
+}
+
+func (cfe CollectFallbackEvent) Namespace() string {
+	return CollectFallback
+}
+
+type PluginReloadedEvent struct {
+	Name    string
+	Version int
+	Type    int
+	Signed  bool
+}
+
+func (e PluginReloadedEvent) Namespace() string {
+	return PluginReloaded
+}
+
+type StrategyChangedEvent struct {
+	PluginName       string
+	PluginVersion    int
+	PluginType       int
+	PreviousStrategy string
+	Strategy         string
+}
+
+func (sce StrategyChangedEvent) Namespace() string {
+	return StrategyChanged
+}
+
+type PoolStatsEvent struct {
+	PluginName        string
+	PluginVersion     int
+	PluginType        int
+	MemberCount       int
+	SubscriptionCount int
+	HitCount          int
+	// HitRate is the hits per second since the previous PoolStatsEvent
+	HitRate float64
+}
+
+func (pse PoolStatsEvent) Namespace() string {
+	return PoolStats
+}
+
+type PluginPausedEvent struct {
+	PluginName    string
+	PluginVersion int
+	PluginType    int
+}
+
+func (ppe PluginPausedEvent) Namespace() string {
+	return PluginPaused
+}
+
+type PluginResumedEvent struct {
+	PluginName    string
+	PluginVersion int
+	PluginType    int
+}
+
+func (pre PluginResumedEvent) Namespace() string {
+	return PluginResumed
+}
+
+type KeyringReloadedEvent struct {
+	KeyringFile string
+}
+
+func (kre KeyringReloadedEvent) Namespace() string {
+	return KeyringReloaded
+}
+
+type CollectResponseCappedEvent struct {
+	TaskId        string
+	PluginName    string
+	PluginVersion int
+	// Accepted is the number of metrics accepted from the collection
+	Accepted int
+	// Dropped is the number of metrics dropped past the cap
+	Dropped int
+}
+
+func (crce CollectResponseCappedEvent) Namespace() string {
+	return CollectResponseCapped
+}
+
+type SignatureValidationEvent struct {
+	Path string
+	// Outcome is one of SignatureValid, SignatureInvalid or SignatureSkipped
+	Outcome string
+	// Keyrings are the keyring files the signature was validated against
+	Keyrings   []string
+	TrustLevel int
+	Error      string
+}
+
+func (sve SignatureValidationEvent) Namespace() string {
+	return SignatureValidated
+}
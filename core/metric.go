@@ -20,6 +20,7 @@ limitations under the License.
 package core
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -45,6 +46,34 @@ type Metric interface {
 	Unit() string
 }
 
+// MetricError is returned by a collection for a metric the collector failed
+// to collect while it collected the other metrics requested with it.
+type MetricError struct {
+	// Namespace is the namespace of the metric as returned by Namespace.String
+	Namespace string
+	Err       string
+}
+
+func (m *MetricError) Error() string {
+	return fmt.Sprintf("unable to collect metric %s: %s", m.Namespace, m.Err)
+}
+
+// PluginCollectError is returned by a collection allowing partial results for
+// a plugin none of the requested metrics could be collected from, while the
+// metrics of the other plugins were.
+type PluginCollectError struct {
+	// PluginKey is the {type}:{name}:{version} key of the plugin
+	PluginKey string
+	// Namespaces are the namespaces of the metrics requested from the plugin
+	// as returned by Namespace.String
+	Namespaces []string
+	Err        string
+}
+
+func (p *PluginCollectError) Error() string {
+	return fmt.Sprintf("unable to collect from plugin %s: %s", p.PluginKey, p.Err)
+}
+
 type Namespace []NamespaceElement
 
 // String returns the string representation of the namespace with "/" joining
@@ -160,10 +189,48 @@ type RequestedMetric interface {
 	Version() int
 }
 
+// OptionalMetric is a RequestedMetric which may be marked optional.  A
+// missing optional metric is skipped rather than failing the subscription.
+type OptionalMetric interface {
+	RequestedMetric
+	Optional() bool
+}
+
+// IsOptional returns true if the requested metric is marked optional.
+func IsOptional(m RequestedMetric) bool {
+	om, ok := m.(OptionalMetric)
+	return ok && om.Optional()
+}
+
 type CatalogedMetric interface {
 	RequestedMetric
 	LastAdvertisedTime() time.Time
 	Policy() *cpolicy.ConfigPolicyNode
 	Description() string
 	Unit() string
+	CollectionCost() CollectionCost
+}
+
+// CollectionCost is a collector's hint of how expensive collecting its
+// metrics is relative to other collectors.
+type CollectionCost int
+
+const (
+	// UnknownCollectionCost is the cost of collectors which give no hint
+	UnknownCollectionCost CollectionCost = iota
+	// CheapCollectionCost is the cost of collectors which are quick to collect
+	CheapCollectionCost
+	// ExpensiveCollectionCost is the cost of collectors which are slow or
+	// costly to collect
+	ExpensiveCollectionCost
+)
+
+var collectionCosts = map[CollectionCost]string{
+	UnknownCollectionCost:   "",
+	CheapCollectionCost:     "cheap",
+	ExpensiveCollectionCost: "expensive",
+}
+
+func (c CollectionCost) String() string {
+	return collectionCosts[c]
 }
@@ -23,6 +23,8 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"io/ioutil"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
@@ -65,10 +67,36 @@ const (
 	PublisherPluginType
 )
 
+// PluginKey returns the key identifying a plugin in the
+// {type}:{name}:{version} form used to look up loaded plugins and their pools.
+func PluginKey(typ PluginType, name string, version int) string {
+	return fmt.Sprintf("%s:%s:%d", typ.String(), name, version)
+}
+
+// ParsePluginKey returns the type, name and version of the plugin identified
+// by a key in the form returned by PluginKey.
+func ParsePluginKey(key string) (PluginType, string, int, error) {
+	tnv := strings.Split(key, ":")
+	if len(tnv) != 3 {
+		return -1, "", 0, fmt.Errorf("invalid plugin key given %s", key)
+	}
+	typ, err := ToPluginType(tnv[0])
+	if err != nil {
+		return -1, "", 0, err
+	}
+	version, err := strconv.Atoi(tnv[2])
+	if err != nil {
+		return -1, "", 0, fmt.Errorf("invalid plugin key given %s", key)
+	}
+	return typ, tnv[1], version, nil
+}
+
 type AvailablePlugin interface {
 	Plugin
 	HitCount() int
 	LastHit() time.Time
+	// StartTime returns when the plugin was started
+	StartTime() time.Time
 	ID() uint32
 }
 
@@ -93,6 +121,13 @@ type SubscribedPlugin interface {
 	Config() *cdata.ConfigDataNode
 }
 
+// PipelineStage is a processor in a pipeline along with the positions in the
+// pipeline of the stages it routes its output to
+type PipelineStage struct {
+	Plugin Plugin
+	Next   []int
+}
+
 type RequestedPlugin struct {
 	path       string
 	checkSum   [sha256.Size]byte
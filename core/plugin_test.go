@@ -115,3 +115,23 @@ func TestRequestedPlugin(t *testing.T) {
 		})
 	})
 }
+
+func TestPluginKey(t *testing.T) {
+	Convey("PluginKey", t, func() {
+		key := PluginKey(CollectorPluginType, "mock", 2)
+		So(key, ShouldEqual, "collector:mock:2")
+		Convey("is parsed by ParsePluginKey", func() {
+			typ, name, version, err := ParsePluginKey(key)
+			So(err, ShouldBeNil)
+			So(typ, ShouldEqual, CollectorPluginType)
+			So(name, ShouldEqual, "mock")
+			So(version, ShouldEqual, 2)
+		})
+	})
+	Convey("ParsePluginKey returns an error for an invalid key", t, func() {
+		for _, key := range []string{"collector:mock", "notatype:mock:1", "collector:mock:latest"} {
+			_, _, _, err := ParsePluginKey(key)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
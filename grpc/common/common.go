@@ -35,9 +35,11 @@ import (
 // Convert a core.Metric to common.Metric protobuf message
 func ToMetric(co core.Metric) *Metric {
 	cm := &Metric{
-		Namespace: ToNamespace(co.Namespace()),
-		Version:   int64(co.Version()),
-		Tags:      co.Tags(),
+		Namespace:   ToNamespace(co.Namespace()),
+		Version:     int64(co.Version()),
+		Tags:        co.Tags(),
+		Unit:        co.Unit(),
+		Description: co.Description(),
 		Timestamp: &Time{
 			Sec:  co.Timestamp().Unix(),
 			Nsec: int64(co.Timestamp().Nanosecond()),
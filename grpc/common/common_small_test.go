@@ -0,0 +1,59 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+# Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/intelsdi-x/snap/core"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMetricRoundTrip(t *testing.T) {
+	Convey("A metric converted to its message and back keeps its fields", t, func() {
+		now := time.Unix(1475000000, 0)
+		mt := &metric{
+			namespace:          core.NewNamespace("intel", "mock", "foo"),
+			version:            2,
+			tags:               map[string]string{"host": "localhost"},
+			timeStamp:          now,
+			lastAdvertisedTime: now,
+			data:               int64(42),
+			unit:               "mock unit",
+			description:        "mock description",
+		}
+		b, err := proto.Marshal(ToMetric(mt))
+		So(err, ShouldBeNil)
+		msg := &Metric{}
+		So(proto.Unmarshal(b, msg), ShouldBeNil)
+
+		got := ToCoreMetric(msg)
+		So(got.Namespace(), ShouldResemble, mt.Namespace())
+		So(got.Version(), ShouldEqual, 2)
+		So(got.Tags(), ShouldResemble, mt.Tags())
+		So(got.Timestamp().Equal(now), ShouldBeTrue)
+		So(got.Data(), ShouldEqual, int64(42))
+		So(got.Unit(), ShouldEqual, "mock unit")
+		So(got.Description(), ShouldEqual, "mock description")
+	})
+}
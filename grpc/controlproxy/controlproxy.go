@@ -20,6 +20,7 @@ package controlproxy
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"golang.org/x/net/context"
@@ -97,7 +98,7 @@ func (c ControlProxy) ProcessMetrics(contentType string, content []byte, pluginN
 }
 
 func (c ControlProxy) CollectMetrics(mts []core.Metric, deadline time.Time, taskID string, AllTags map[string]map[string]string) ([]core.Metric, []error) {
-	var allTags map[string]*rpc.Map
+	allTags := make(map[string]*rpc.Map, len(AllTags))
 	for k, v := range AllTags {
 		tags := &rpc.Map{}
 		for kn, vn := range v {
@@ -124,13 +125,11 @@ func (c ControlProxy) CollectMetrics(mts []core.Metric, deadline time.Time, task
 		errs = append(errs, err)
 		return nil, errs
 	}
-	rerrs := replyErrorsToErrors(reply.Errors)
-	if len(rerrs) > 0 {
-		errs = append(errs, rerrs...)
-		return nil, errs
-	}
+	// the metrics which were collected are returned along with the errors
+	// of those which were not
+	errs = append(errs, replyErrorsToCollectErrors(reply.Errors)...)
 	metrics := common.ToCoreMetrics(reply.Metrics)
-	return metrics, nil
+	return metrics, errs
 }
 
 func (c ControlProxy) GetPluginContentTypes(n string, t core.PluginType, v int) ([]string, []string, error) {
@@ -248,6 +247,26 @@ func replyErrorsToErrors(errs []string) []error {
 	return erro
 }
 
+// metricErrorPrefix begins the message of a *core.MetricError.
+const metricErrorPrefix = "unable to collect metric "
+
+// replyErrorsToCollectErrors converts the errors of a collection, returning
+// a *core.MetricError for those reporting a metric which was not collected as
+// the errors are sent as their messages.
+func replyErrorsToCollectErrors(errs []string) []error {
+	erro := replyErrorsToErrors(errs)
+	for i, e := range errs {
+		if !strings.HasPrefix(e, metricErrorPrefix) {
+			continue
+		}
+		msg := strings.TrimPrefix(e, metricErrorPrefix)
+		if sep := strings.Index(msg, ": "); sep >= 0 {
+			erro[i] = &core.MetricError{Namespace: msg[:sep], Err: msg[sep+2:]}
+		}
+	}
+	return erro
+}
+
 // Constructs a protobuf message for publish/process given the relevant information
 func GetPubProcReq(contentType string, content []byte, pluginName string, pluginVersion int, config map[string]ctypes.ConfigValue, taskID string) *rpc.PubProcMetricsRequest {
 	newConfig := common.ToConfigMap(config)
@@ -295,6 +295,64 @@ func TestCollectMetrics(t *testing.T) {
 		})
 	})
 
+	Convey("Control.CollectMetrics returns metrics and errors", t, func() {
+		reply := &rpc.CollectMetricsResponse{
+			Metrics: []*common.Metric{&common.Metric{
+				Namespace:          common.ToNamespace(core.NewNamespace("testing", "this")),
+				Version:            6,
+				Tags:               map[string]string{},
+				Timestamp:          &common.Time{Sec: time.Now().Unix(), Nsec: int64(time.Now().Nanosecond())},
+				LastAdvertisedTime: &common.Time{Sec: time.Now().Unix(), Nsec: int64(time.Now().Nanosecond())},
+			}},
+			Errors: []string{"error in collect"},
+		}
+
+		proxy := ControlProxy{Client: mockClient{CollectReply: reply}}
+		mts, errs := proxy.CollectMetrics([]core.Metric{}, time.Now(), "", map[string]map[string]string{})
+
+		Convey("So the collected metrics should be returned", func() {
+			So(len(mts), ShouldEqual, 1)
+			So(mts[0].Namespace(), ShouldResemble, core.NewNamespace("testing", "this"))
+		})
+
+		Convey("So the error should be returned", func() {
+			So(len(errs), ShouldEqual, 1)
+			So(errs[0].Error(), ShouldResemble, "error in collect")
+		})
+	})
+
+	Convey("Control.CollectMetrics returns metrics and the errors of metrics not collected", t, func() {
+		me := &core.MetricError{Namespace: "/testing/that", Err: "rpc error: unavailable"}
+		reply := &rpc.CollectMetricsResponse{
+			Metrics: []*common.Metric{&common.Metric{
+				Namespace:          common.ToNamespace(core.NewNamespace("testing", "this")),
+				Version:            6,
+				Tags:               map[string]string{},
+				Timestamp:          &common.Time{Sec: time.Now().Unix(), Nsec: int64(time.Now().Nanosecond())},
+				LastAdvertisedTime: &common.Time{Sec: time.Now().Unix(), Nsec: int64(time.Now().Nanosecond())},
+			}},
+			Errors: []string{me.Error(), "error in collect"},
+		}
+
+		proxy := ControlProxy{Client: mockClient{CollectReply: reply}}
+		mts, errs := proxy.CollectMetrics([]core.Metric{}, time.Now(), "", map[string]map[string]string{})
+
+		Convey("So the collected metrics should be returned", func() {
+			So(len(mts), ShouldEqual, 1)
+		})
+
+		Convey("So the metric error should be a *core.MetricError", func() {
+			So(len(errs), ShouldEqual, 2)
+			So(errs[0], ShouldResemble, me)
+		})
+
+		Convey("So other errors should be returned as they are", func() {
+			_, ok := errs[1].(*core.MetricError)
+			So(ok, ShouldBeFalse)
+			So(errs[1].Error(), ShouldResemble, "error in collect")
+		})
+	})
+
 	Convey("Control.CollectMetrics returns sucessfully", t, func() {
 		reply := &rpc.CollectMetricsResponse{
 			Metrics: []*common.Metric{&common.Metric{
@@ -111,14 +111,15 @@ func (s *Server) getMetricsFromTree(w http.ResponseWriter, r *http.Request, para
 		dynamicElements = getDynamicElements(mt.Namespace(), indexes)
 	}
 	mb := &rbody.Metric{
-		Namespace:       mt.Namespace().String(),
-		Version:         mt.Version(),
-		Dynamic:         dyn,
-		DynamicElements: dynamicElements,
-		Description:     mt.Description(),
-		Unit:            mt.Unit(),
+		Namespace:               mt.Namespace().String(),
+		Version:                 mt.Version(),
+		Dynamic:                 dyn,
+		DynamicElements:         dynamicElements,
+		Description:             mt.Description(),
+		Unit:                    mt.Unit(),
+		CollectionCost:          mt.CollectionCost().String(),
 		LastAdvertisedTimestamp: mt.LastAdvertisedTime().Unix(),
-		Href: catalogedMetricURI(r.Host, mt),
+		Href:                    catalogedMetricURI(r.Host, mt),
 	}
 	rt := mt.Policy().RulesAsTable()
 	policies := make([]rbody.PolicyTable, 0, len(rt))
@@ -166,6 +167,7 @@ func respondWithMetrics(host string, mets []core.CatalogedMetric, w http.Respons
 			Dynamic:                 dyn,
 			DynamicElements:         dynamicElements,
 			Unit:                    met.Unit(),
+			CollectionCost:          met.CollectionCost().String(),
 			Policy:                  policies,
 			Href:                    catalogedMetricURI(host, met),
 		})
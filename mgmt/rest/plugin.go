@@ -44,7 +44,12 @@ import (
 	"github.com/intelsdi-x/snap/mgmt/rest/rbody"
 )
 
-const PluginAlreadyLoaded = "plugin is already loaded"
+const (
+	PluginAlreadyLoaded = "plugin is already loaded"
+	// PluginSubscribed is the error returned when unloading a plugin
+	// which tasks are subscribed to
+	PluginSubscribed = "Plugin has task subscriptions"
+)
 
 var (
 	ErrMissingPluginName = errors.New("missing plugin name")
@@ -248,8 +253,20 @@ func (s *Server) unloadPlugin(w http.ResponseWriter, r *http.Request, p httprout
 		pluginType: plType,
 	})
 	if se != nil {
-		se.SetFields(f)
-		respond(500, rbody.FromSnapError(se), w)
+		// keep the fields describing why the unload failed
+		fields := se.Fields()
+		if fields == nil {
+			fields = map[string]interface{}{}
+		}
+		for k, v := range f {
+			fields[k] = v
+		}
+		se.SetFields(fields)
+		ec := 500
+		if se.Error() == PluginSubscribed {
+			ec = 409
+		}
+		respond(ec, rbody.FromSnapError(se), w)
 		return
 	}
 	pr := &rbody.PluginUnloaded{
@@ -293,6 +310,7 @@ func getPlugins(mm managesMetrics, detail bool, h string, plName string, plType
 				Type:             p.TypeName(),
 				HitCount:         p.HitCount(),
 				LastHitTimestamp: p.LastHit().Unix(),
+				StartTimestamp:   p.StartTime().Unix(),
 				ID:               p.ID(),
 				Href:             pluginURI(h, p),
 			}
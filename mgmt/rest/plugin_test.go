@@ -22,9 +22,15 @@ limitations under the License.
 package rest
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/codegangsta/negroni"
+	"github.com/julienschmidt/httprouter"
+
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	"github.com/intelsdi-x/snap/core"
 	"github.com/intelsdi-x/snap/core/serror"
@@ -54,6 +60,9 @@ func (m MockLoadedPlugin) HitCount() int { return 0 }
 func (m MockLoadedPlugin) LastHit() time.Time {
 	return time.Now()
 }
+func (m MockLoadedPlugin) StartTime() time.Time {
+	return time.Now()
+}
 func (m MockLoadedPlugin) ID() uint32 { return 0 }
 
 type MockManagesMetrics struct{}
@@ -101,6 +110,39 @@ func (m MockManagesMetrics) GetAutodiscoverPaths() []string {
 	return nil
 }
 
+// subscribedManagesMetrics fails to unload plugins which tasks are
+// subscribed to.
+type subscribedManagesMetrics struct {
+	MockManagesMetrics
+}
+
+func (m subscribedManagesMetrics) Unload(core.Plugin) (core.CatalogedPlugin, serror.SnapError) {
+	return nil, serror.New(errors.New(PluginSubscribed), map[string]interface{}{
+		"task-ids":           "task-1",
+		"subscription-count": 1,
+	})
+}
+
+func TestUnloadSubscribedPlugin(t *testing.T) {
+	Convey("Unloading a plugin tasks are subscribed to conflicts", t, func() {
+		s := &Server{mm: subscribedManagesMetrics{}}
+		rec := httptest.NewRecorder()
+		req, err := http.NewRequest("DELETE", "/v1/plugins/collector/foo/1", nil)
+		So(err, ShouldBeNil)
+		s.unloadPlugin(negroni.NewResponseWriter(rec), req, httprouter.Params{
+			{Key: "type", Value: "collector"},
+			{Key: "name", Value: "foo"},
+			{Key: "version", Value: "1"},
+		})
+		So(rec.Code, ShouldEqual, 409)
+		body := rec.Body.String()
+		So(body, ShouldContainSubstring, PluginSubscribed)
+		So(body, ShouldContainSubstring, "task-ids")
+		So(body, ShouldContainSubstring, "subscription-count")
+		So(body, ShouldContainSubstring, "plugin-name")
+	})
+}
+
 func TestGetPlugins(t *testing.T) {
 	mm := MockManagesMetrics{}
 	host := "localhost"
@@ -43,6 +43,7 @@ type Metric struct {
 	DynamicElements         []DynamicElement `json:"dynamic_elements,omitempty"`
 	Description             string           `json:"description,omitempty"`
 	Unit                    string           `json:"unit,omitempty"`
+	CollectionCost          string           `json:"collection_cost,omitempty"`
 	Policy                  []PolicyTable    `json:"policy,omitempty"`
 	Href                    string           `json:"href"`
 }
@@ -105,6 +105,7 @@ type AvailablePlugin struct {
 	Type             string `json:"type"`
 	HitCount         int    `json:"hitcount"`
 	LastHitTimestamp int64  `json:"last_hit_timestamp"`
+	StartTimestamp   int64  `json:"start_timestamp"`
 	ID               uint32 `json:"id"`
 	Href             string `json:"href"`
 }
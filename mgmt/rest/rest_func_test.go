@@ -449,9 +449,9 @@ func deletePluginConfigItem(port int, typ string, name, ver string, fields []str
 // we create a mock config struct to mock what is in snapd.go
 
 type mockConfig struct {
-	LogLevel   int    `json:"-"yaml:"-"`
-	GoMaxProcs int    `json:"-"yaml:"-"`
-	LogPath    string `json:"-"yaml:"-"`
+	LogLevel   int    `json:"-" yaml:"-"`
+	GoMaxProcs int    `json:"-" yaml:"-"`
+	LogPath    string `json:"-" yaml:"-"`
 	Control    *control.Config
 	Scheduler  *scheduler.Config `json:"-",yaml:"-"`
 	RestAPI    *Config           `json:"-",yaml:"-"`
@@ -71,14 +71,14 @@ var (
 //         UnmarshalJSON method in this same file needs to be modified to
 //         match the field mapping that is defined here
 type Config struct {
-	Enable           bool   `json:"enable"yaml:"enable"`
-	Port             int    `json:"port"yaml:"port"`
-	Address          string `json:"addr"yaml:"addr"`
-	HTTPS            bool   `json:"https"yaml:"https"`
-	RestCertificate  string `json:"rest_certificate"yaml:"rest_certificate"`
-	RestKey          string `json:"rest_key"yaml:"rest_key"`
-	RestAuth         bool   `json:"rest_auth"yaml:"rest_auth"`
-	RestAuthPassword string `json:"rest_auth_password"yaml:"rest_auth_password"`
+	Enable           bool   `json:"enable" yaml:"enable"`
+	Port             int    `json:"port" yaml:"port"`
+	Address          string `json:"addr" yaml:"addr"`
+	HTTPS            bool   `json:"https" yaml:"https"`
+	RestCertificate  string `json:"rest_certificate" yaml:"rest_certificate"`
+	RestKey          string `json:"rest_key" yaml:"rest_key"`
+	RestAuth         bool   `json:"rest_auth" yaml:"rest_auth"`
+	RestAuthPassword string `json:"rest_auth_password" yaml:"rest_auth_password"`
 	portSetByConfig  bool   ``
 }
 
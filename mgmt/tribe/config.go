@@ -47,16 +47,16 @@ const (
 //         UnmarshalJSON method in this same file needs to be modified to
 //         match the field mapping that is defined here
 type Config struct {
-	Name                      string             `json:"name"yaml:"name"`
-	Enable                    bool               `json:"enable"yaml:"enable"`
-	BindAddr                  string             `json:"bind_addr"yaml:"bind_addr"`
-	BindPort                  int                `json:"bind_port"yaml:"bind_port"`
-	Seed                      string             `json:"seed"yaml:"seed"`
-	MemberlistConfig          *memberlist.Config `json:"-"yaml:"-"`
-	RestAPIProto              string             `json:"-"yaml:"-"`
-	RestAPIPassword           string             `json:"-"yaml:"-"`
-	RestAPIPort               int                `json:"-"yaml:"-"`
-	RestAPIInsecureSkipVerify string             `json:"-"yaml:"-"`
+	Name                      string             `json:"name" yaml:"name"`
+	Enable                    bool               `json:"enable" yaml:"enable"`
+	BindAddr                  string             `json:"bind_addr" yaml:"bind_addr"`
+	BindPort                  int                `json:"bind_port" yaml:"bind_port"`
+	Seed                      string             `json:"seed" yaml:"seed"`
+	MemberlistConfig          *memberlist.Config `json:"-" yaml:"-"`
+	RestAPIProto              string             `json:"-" yaml:"-"`
+	RestAPIPassword           string             `json:"-" yaml:"-"`
+	RestAPIPort               int                `json:"-" yaml:"-"`
+	RestAPIInsecureSkipVerify string             `json:"-" yaml:"-"`
 }
 
 const (
@@ -45,12 +45,9 @@ var (
 	ErrCheckSignature = errors.New("Error checking signature")
 )
 
-//ValidateSignature is exported for plugin authoring
+//ValidateSignature is exported for plugin authoring.  The signature is valid
+//if any of the keyrings verifies it.
 func (s *SigningManager) ValidateSignature(keyringFiles []string, signedFile string, signature []byte) error {
-	var signedby string
-	var e error
-	var checked *openpgp.Entity
-
 	signed, err := os.Open(signedFile)
 	if err != nil {
 		return fmt.Errorf("%v: %v\n%v", ErrSignedFileNotFound, signedFile, err)
@@ -58,33 +55,44 @@ func (s *SigningManager) ValidateSignature(keyringFiles []string, signedFile str
 	defer signed.Close()
 
 	//Go through all the keyrings til either signature is valid or end of keyrings
+	err = fmt.Errorf("%v\n%v", ErrCheckSignature, nil)
 	for _, keyringFile := range keyringFiles {
-		keyringf, err := os.Open(keyringFile)
-		if err != nil {
-			return fmt.Errorf("%v: %v\n%v", ErrKeyringFileNotFound, keyringFile, err)
+		if err = checkSignature(keyringFile, signed, signature); err == nil {
+			return nil
 		}
-		defer keyringf.Close()
+		signed.Seek(0, 0)
+	}
+	return err
+}
+
+// checkSignature returns an error if the keyring cannot be read or does not
+// verify the signature of the signed file.
+func checkSignature(keyringFile string, signed *os.File, signature []byte) error {
+	keyringf, err := os.Open(keyringFile)
+	if err != nil {
+		return fmt.Errorf("%v: %v\n%v", ErrKeyringFileNotFound, keyringFile, err)
+	}
+	defer keyringf.Close()
 
-		//Read both armored and unarmored keyrings
-		keyring, err := openpgp.ReadArmoredKeyRing(keyringf)
+	//Read both armored and unarmored keyrings
+	keyring, err := openpgp.ReadArmoredKeyRing(keyringf)
+	if err != nil {
+		keyringf.Seek(0, 0)
+		keyring, err = openpgp.ReadKeyRing(keyringf)
 		if err != nil {
-			keyringf.Seek(0, 0)
-			keyring, err = openpgp.ReadKeyRing(keyringf)
-			if err != nil {
-				return fmt.Errorf("%v: %v\n%v", ErrUnableToReadKeyring, keyringFile, err)
-			}
+			return fmt.Errorf("%v: %v\n%v", ErrUnableToReadKeyring, keyringFile, err)
 		}
+	}
 
-		//Check the armored detached signature
-		checked, e = openpgp.CheckArmoredDetachedSignature(keyring, signed, bytes.NewReader(signature))
-		if e == nil {
-			for k := range checked.Identities {
-				signedby = signedby + k
-			}
-			fmt.Printf("Signature made %v using RSA key ID %v\nGood signature from %v\n", time.Now().Format(time.RFC1123), checked.PrimaryKey.KeyIdShortString(), signedby)
-			return nil
-		}
-		signed.Seek(0, 0)
+	//Check the armored detached signature
+	checked, err := openpgp.CheckArmoredDetachedSignature(keyring, signed, bytes.NewReader(signature))
+	if err != nil {
+		return fmt.Errorf("%v\n%v", ErrCheckSignature, err)
+	}
+	var signedby string
+	for k := range checked.Identities {
+		signedby = signedby + k
 	}
-	return fmt.Errorf("%v\n%v", ErrCheckSignature, e)
+	fmt.Printf("Signature made %v using RSA key ID %v\nGood signature from %v\n", time.Now().Format(time.RFC1123), checked.PrimaryKey.KeyIdShortString(), signedby)
+	return nil
 }
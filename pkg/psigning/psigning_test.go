@@ -50,6 +50,12 @@ func TestValidateSignature(t *testing.T) {
 		So(err, ShouldBeNil)
 	})
 
+	Convey("Valid files and good signature. Unreadable keyrings are skipped", t, func() {
+		keyringFiles := []string{"", signatureFile, "pubring.gpg"}
+		err := s.ValidateSignature(keyringFiles, signedFile, signature)
+		So(err, ShouldBeNil)
+	})
+
 	Convey("Validate unsigned file with signature", t, func() {
 		err := s.ValidateSignature(keyringFile, unsignedFile, signature)
 		So(err, ShouldNotBeNil)
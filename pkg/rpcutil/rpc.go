@@ -21,21 +21,43 @@ package rpcutil
 
 import (
 	"fmt"
+	"net"
 	"time"
 
 	"google.golang.org/grpc"
 )
 
-// GetClientConnection returns a grcp.ClientConn that is unsecured
+// GetClientConnection returns a grcp.ClientConn that is unsecured.  Any opts
+// are applied after, and so override, the defaults.
 // TODO: Add TLS security to connection
-func GetClientConnection(addr string, port int) (*grpc.ClientConn, error) {
+func GetClientConnection(addr string, port int, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	grpcDialOpts := []grpc.DialOption{
 		grpc.WithTimeout(2 * time.Second),
 	}
 	grpcDialOpts = append(grpcDialOpts, grpc.WithInsecure())
+	grpcDialOpts = append(grpcDialOpts, opts...)
 	conn, err := grpc.Dial(fmt.Sprintf("%v:%v", addr, port), grpcDialOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return conn, nil
 }
+
+// GetUnixClientConnection returns a grpc.ClientConn that is unsecured and
+// dialed over the Unix domain socket at path.  Any opts are applied after, and
+// so override, the defaults.
+func GetUnixClientConnection(path string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	grpcDialOpts := []grpc.DialOption{
+		grpc.WithTimeout(2 * time.Second),
+		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+			return net.DialTimeout("unix", addr, timeout)
+		}),
+	}
+	grpcDialOpts = append(grpcDialOpts, grpc.WithInsecure())
+	grpcDialOpts = append(grpcDialOpts, opts...)
+	conn, err := grpc.Dial(path, grpcDialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
@@ -197,6 +197,7 @@ type metric struct {
 	namespace core.Namespace
 	version   int
 	config    *cdata.ConfigDataNode
+	optional  bool
 }
 
 func (m *metric) Namespace() core.Namespace {
@@ -211,6 +212,10 @@ func (m *metric) Version() int {
 	return m.version
 }
 
+func (m *metric) Optional() bool {
+	return m.optional
+}
+
 func (m *metric) Data() interface{}             { return nil }
 func (m *metric) Description() string           { return "" }
 func (m *metric) Unit() string                  { return "" }
@@ -258,6 +263,7 @@ func (c *collectorJob) Run() {
 				namespace: ns,
 				version:   rmt.Version(),
 				config:    config,
+				optional:  core.IsOptional(rmt),
 			}
 			metrics = append(metrics, metric)
 		}
@@ -274,6 +280,23 @@ func (c *collectorJob) Run() {
 
 	c.metrics = ret
 	if errs != nil {
+		// Metrics the collectors failed to collect are reported alongside
+		// the metrics which were collected and do not fail the job.
+		var jobErrs []error
+		for _, e := range errs {
+			if me, ok := e.(*core.MetricError); ok {
+				log.WithFields(log.Fields{
+					"_module":   "scheduler-job",
+					"block":     "run",
+					"job-type":  "collector",
+					"namespace": me.Namespace,
+					"error":     me.Err,
+				}).Warn("collector failed to collect metric")
+				continue
+			}
+			jobErrs = append(jobErrs, e)
+		}
+		errs = jobErrs
 		for _, e := range errs {
 			log.WithFields(log.Fields{
 				"_module":  "scheduler-job",
@@ -90,6 +90,12 @@ type managesPluginContentTypes interface {
 	GetPluginContentTypes(n string, t core.PluginType, v int) ([]string, []string, error)
 }
 
+// validatesPipelines is implemented by control to reject workflows whose
+// processors route their output back into their own input
+type validatesPipelines interface {
+	ValidatePipeline([]core.PipelineStage) serror.SnapError
+}
+
 type collectsMetrics interface {
 	ExpandWildcards(core.Namespace) ([]core.Namespace, serror.SnapError)
 	CollectMetrics([]core.Metric, time.Time, string, map[string]map[string]string) ([]core.Metric, []error)
@@ -341,6 +347,16 @@ func (s *scheduler) createTask(sch schedule.Schedule, wfMap *wmap.WorkflowMap, s
 		}
 	}
 
+	// Reject processors routing their output back into their own input
+	if vp, ok := s.metricManager.(validatesPipelines); ok {
+		if serr := vp.ValidatePipeline(wf.pipelineStages()); serr != nil {
+			te.errs = append(te.errs, serr)
+			f := buildErrorsLog(te.Errors(), logger)
+			f.Error("workflow pipeline not valid")
+			return nil, te
+		}
+	}
+
 	// Bind plugin content type selections in workflow
 	err = wf.BindPluginContentTypes(&task.RemoteManagers)
 	if err != nil {
@@ -817,6 +833,7 @@ func (s *scheduler) gatherMetricsAndPlugins(wf *schedulerWorkflow) map[string]de
 				namespace: ns,
 				version:   m.Version(),
 				config:    wf.configTree.Get(ns.Strings()),
+				optional:  core.IsOptional(m),
 			})
 		}
 	}
@@ -174,6 +174,18 @@ func (m mockScheduleResponse) missedIntervals() uint {
 	return 0
 }
 
+// pipelineValidatingManager records the pipeline it is asked to validate.
+type pipelineValidatingManager struct {
+	*mockMetricManager
+	stages []core.PipelineStage
+	err    serror.SnapError
+}
+
+func (m *pipelineValidatingManager) ValidatePipeline(stages []core.PipelineStage) serror.SnapError {
+	m.stages = stages
+	return m.err
+}
+
 func TestScheduler(t *testing.T) {
 	log.SetLevel(log.FatalLevel)
 	Convey("NewTask", t, func() {
@@ -243,6 +255,24 @@ func TestScheduler(t *testing.T) {
 
 		})
 
+		Convey("validates the processor pipeline", func() {
+			c.failValidatingMetrics = false
+			pv := &pipelineValidatingManager{mockMetricManager: c}
+			s2 := New(GetDefaultConfig())
+			s2.SetMetricManager(pv)
+			So(s2.Start(), ShouldBeNil)
+			_, te := s2.CreateTask(schedule.NewSimpleSchedule(time.Second*1), w, false)
+			So(te.Errors(), ShouldBeEmpty)
+			So(len(pv.stages), ShouldEqual, 2)
+			So(pv.stages[0].Next, ShouldResemble, []int{1})
+			So(pv.stages[1].Next, ShouldBeEmpty)
+
+			pv.err = serror.New(errors.New("processor cycle"))
+			_, te = s2.CreateTask(schedule.NewSimpleSchedule(time.Second*1), w, false)
+			So(te, ShouldNotBeNil)
+			So(te.Errors()[0], ShouldEqual, pv.err)
+		})
+
 		Convey("returns an error when scheduler started and MetricManager is not set", func() {
 			s1 := New(GetDefaultConfig())
 			err := s1.Start()
@@ -129,7 +129,7 @@ func Sample() *WorkflowMap {
 
 // A map of a desired workflow that is used to create a scheduleWorkflow
 type WorkflowMap struct {
-	CollectNode *CollectWorkflowMapNode `json:"collect"yaml:"collect"`
+	CollectNode *CollectWorkflowMapNode `json:"collect" yaml:"collect"`
 }
 
 func NewWorkflowMap() *WorkflowMap {
@@ -151,11 +151,11 @@ func (w *WorkflowMap) ToYaml() ([]byte, error) {
 }
 
 type CollectWorkflowMapNode struct {
-	Metrics      map[string]metricInfo             `json:"metrics"yaml:"metrics"`
-	Config       map[string]map[string]interface{} `json:"config,omitempty"yaml:"config"`
-	Tags         map[string]map[string]string      `json:"tags,omitempty"yaml:"tags"`
-	ProcessNodes []ProcessWorkflowMapNode          `json:"process,omitempty"yaml:"process"`
-	PublishNodes []PublishWorkflowMapNode          `json:"publish,omitempty"yaml:"publish"`
+	Metrics      map[string]metricInfo             `json:"metrics" yaml:"metrics"`
+	Config       map[string]map[string]interface{} `json:"config,omitempty" yaml:"config"`
+	Tags         map[string]map[string]string      `json:"tags,omitempty" yaml:"tags"`
+	ProcessNodes []ProcessWorkflowMapNode          `json:"process,omitempty" yaml:"process"`
+	PublishNodes []PublishWorkflowMapNode          `json:"publish,omitempty" yaml:"publish"`
 }
 
 func (c *CollectWorkflowMapNode) GetMetrics() []Metric {
@@ -166,6 +166,7 @@ func (c *CollectWorkflowMapNode) GetMetrics() []Metric {
 		metrics[i] = Metric{
 			namespace: strings.Split(ns, "/"),
 			version:   v.Version_,
+			Optional_: v.Optional_,
 		}
 		i++
 	}
@@ -230,13 +231,13 @@ func (c *CollectWorkflowMapNode) AddConfigItem(ns, key string, value interface{}
 }
 
 type ProcessWorkflowMapNode struct {
-	Name         string                   `json:"plugin_name"yaml:"plugin_name"`
-	Version      int                      `json:"plugin_version"yaml:"plugin_version"`
-	ProcessNodes []ProcessWorkflowMapNode `json:"process,omitempty"yaml:"process"`
-	PublishNodes []PublishWorkflowMapNode `json:"publish,omitempty"yaml:"publish"`
+	Name         string                   `json:"plugin_name" yaml:"plugin_name"`
+	Version      int                      `json:"plugin_version" yaml:"plugin_version"`
+	ProcessNodes []ProcessWorkflowMapNode `json:"process,omitempty" yaml:"process"`
+	PublishNodes []PublishWorkflowMapNode `json:"publish,omitempty" yaml:"publish"`
 	// TODO processor config
-	Config map[string]interface{} `json:"config,omitempty"yaml:"config"`
-	Target string                 `json:"target"yaml:"target"`
+	Config map[string]interface{} `json:"config,omitempty" yaml:"config"`
+	Target string                 `json:"target" yaml:"target"`
 }
 
 func NewProcessNode(name string, version int) *ProcessWorkflowMapNode {
@@ -274,11 +275,11 @@ func (p *ProcessWorkflowMapNode) GetConfigNode() (*cdata.ConfigDataNode, error)
 }
 
 type PublishWorkflowMapNode struct {
-	Name    string `json:"plugin_name"yaml:"plugin_name"`
-	Version int    `json:"plugin_version"yaml:"plugin_version"`
+	Name    string `json:"plugin_name" yaml:"plugin_name"`
+	Version int    `json:"plugin_version" yaml:"plugin_version"`
 	// TODO publisher config
-	Config map[string]interface{} `json:"config,omitempty"yaml:"config"`
-	Target string                 `json:"target"yaml:"target"`
+	Config map[string]interface{} `json:"config,omitempty" yaml:"config"`
+	Target string                 `json:"target" yaml:"target"`
 }
 
 func NewPublishNode(name string, version int) *PublishWorkflowMapNode {
@@ -304,12 +305,14 @@ func (p *PublishWorkflowMapNode) GetConfigNode() (*cdata.ConfigDataNode, error)
 }
 
 type metricInfo struct {
-	Version_ int `json:"version"yaml:"version"`
+	Version_  int  `json:"version" yaml:"version"`
+	Optional_ bool `json:"optional,omitempty" yaml:"optional"`
 }
 
 type Metric struct {
 	namespace []string
 	version   int
+	Optional_ bool
 }
 
 func (m Metric) Namespace() []string {
@@ -320,6 +323,12 @@ func (m Metric) Version() int {
 	return m.version
 }
 
+// Optional returns true if the task should not fail when the metric is
+// not available.
+func (m Metric) Optional() bool {
+	return m.Optional_
+}
+
 func isValidNamespaceString(ns string) bool {
 	b, err := regexp.MatchString("^(/[a-z0-9]+)+$", ns)
 	if err != nil {
@@ -110,6 +110,12 @@ func TestWfGetRequestedMetrics(t *testing.T) {
 		So(wmap.CollectNode.GetMetrics()[0].Namespace(), ShouldResemble, []string{"foo", "bar"})
 		wmap.CollectNode.GetMetrics()[0].Version()
 		So(wmap.CollectNode.GetMetrics()[0].Version(), ShouldResemble, 1)
+		So(wmap.CollectNode.GetMetrics()[0].Optional(), ShouldBeFalse)
+	})
+	Convey("Optional metrics of a workflow map", t, func() {
+		wmap, err := FromJson([]byte(`{"collect": {"metrics": {"/foo/bar": {"version": 1, "optional": true}}}}`))
+		So(err, ShouldBeNil)
+		So(wmap.CollectNode.GetMetrics()[0].Optional(), ShouldBeTrue)
 	})
 }
 
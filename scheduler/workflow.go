@@ -87,7 +87,7 @@ func convertCollectionNode(cnode *wmap.CollectWorkflowMapNode, wf *schedulerWork
 	mts := cnode.GetMetrics()
 	wf.metrics = make([]core.RequestedMetric, len(mts))
 	for i, m := range mts {
-		wf.metrics[i] = &metric{namespace: core.NewNamespace(m.Namespace()...), version: m.Version()}
+		wf.metrics[i] = &metric{namespace: core.NewNamespace(m.Namespace()...), version: m.Version(), optional: m.Optional()}
 	}
 	// get tags defined
 	wf.tags = cnode.GetTags()
@@ -236,6 +236,29 @@ func (p *publishNode) TypeName() string {
 
 type wfContentTypes map[string]map[string][]string
 
+// pipelineStages returns the processors of the workflow running on the local
+// manager as pipeline stages, each routing to the processors nested below it.
+func (s *schedulerWorkflow) pipelineStages() []core.PipelineStage {
+	var stages []core.PipelineStage
+	var add func(prs []*processNode) []int
+	add = func(prs []*processNode) []int {
+		var added []int
+		for _, pr := range prs {
+			if pr.Target != "" {
+				add(pr.ProcessNodes)
+				continue
+			}
+			i := len(stages)
+			stages = append(stages, core.PipelineStage{Plugin: pr})
+			stages[i].Next = add(pr.ProcessNodes)
+			added = append(added, i)
+		}
+		return added
+	}
+	add(s.processNodes)
+	return stages
+}
+
 // BindPluginContentTypes
 func (s *schedulerWorkflow) BindPluginContentTypes(mgrs *managers) error {
 	return bindPluginContentTypes(s.publishNodes, s.processNodes, []string{plugin.SnapGOBContentType}, mgrs)
@@ -114,15 +114,15 @@ const (
 //         UnmarshalJSON method in this same file needs to be modified to
 //         match the field mapping that is defined here
 type Config struct {
-	LogLevel    int               `json:"log_level,omitempty"yaml:"log_level,omitempty"`
-	GoMaxProcs  int               `json:"gomaxprocs,omitempty"yaml:"gomaxprocs,omitempty"`
-	LogPath     string            `json:"log_path,omitempty"yaml:"log_path,omitempty"`
-	LogTruncate bool              `json:"log_truncate,omitempty"yaml:"log_truncate,omitempty"`
-	LogColors   bool              `json:"log_colors,omitempty"yaml:"log_colors,omitempty"`
-	Control     *control.Config   `json:"control,omitempty"yaml:"control,omitempty"`
-	Scheduler   *scheduler.Config `json:"scheduler,omitempty"yaml:"scheduler,omitempty"`
-	RestAPI     *rest.Config      `json:"restapi,omitempty"yaml:"restapi,omitempty"`
-	Tribe       *tribe.Config     `json:"tribe,omitempty"yaml:"tribe,omitempty"`
+	LogLevel    int               `json:"log_level,omitempty" yaml:"log_level,omitempty"`
+	GoMaxProcs  int               `json:"gomaxprocs,omitempty" yaml:"gomaxprocs,omitempty"`
+	LogPath     string            `json:"log_path,omitempty" yaml:"log_path,omitempty"`
+	LogTruncate bool              `json:"log_truncate,omitempty" yaml:"log_truncate,omitempty"`
+	LogColors   bool              `json:"log_colors,omitempty" yaml:"log_colors,omitempty"`
+	Control     *control.Config   `json:"control,omitempty" yaml:"control,omitempty"`
+	Scheduler   *scheduler.Config `json:"scheduler,omitempty" yaml:"scheduler,omitempty"`
+	RestAPI     *rest.Config      `json:"restapi,omitempty" yaml:"restapi,omitempty"`
+	Tribe       *tribe.Config     `json:"tribe,omitempty" yaml:"tribe,omitempty"`
 }
 
 const (